@@ -0,0 +1,162 @@
+package goparquet
+
+import (
+	"io"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// AppendRowGroupFrom appends the row group with the index rowGroup of the file r to the file, without decoding and
+// encoding its values again. The column chunks are copied as they are, with their compressed pages, statistics and
+// encodings, and with their column and offset indexes. Only the offsets in the meta data are changed. Bloom filters
+// are not copied. The schema of r must be the same as the schema of the writer, the rows that were added with
+// AddData before are flushed in their own row group first. As the pages keep their repetition and definition levels,
+// a schema that is only compatible like by the CompatibleWith method of parquetschema.SchemaDefinition is not enough.
+func (fw *FileWriter) AppendRowGroupFrom(r *FileReader, rowGroup int) (err error) {
+	defer fw.replaceWithContextErr(&err)
+
+	if fw.closed {
+		return ErrWriterClosed
+	}
+	if rowGroup < 0 || rowGroup >= len(r.meta.RowGroups) {
+		return errors.Errorf("row group %d is out of range, the file has %d row groups", rowGroup, len(r.meta.RowGroups))
+	}
+	if err := checkSchemaCompatible(r.meta.Schema, fw.getSchemaArray()); err != nil {
+		return err
+	}
+
+	if fw.rowGroupNumRecords() > 0 {
+		if err := fw.FlushRowGroup(); err != nil {
+			return err
+		}
+	}
+	if fw.w.Pos() == 0 {
+		if err := writeFull(fw.w, magic); err != nil {
+			return err
+		}
+	}
+
+	src := r.meta.RowGroups[rowGroup]
+	in := r.sectionReader()
+	rowGroupOffset := fw.w.Pos()
+	rg := &parquet.RowGroup{
+		Columns:        make([]*parquet.ColumnChunk, 0, len(src.Columns)),
+		NumRows:        src.NumRows,
+		SortingColumns: src.SortingColumns,
+		FileOffset:     &rowGroupOffset,
+	}
+	var (
+		indexes             []*pageIndex
+		totalCompressedSize int64
+	)
+	for _, chunk := range src.Columns {
+		cc, index, err := fw.copyColumnChunk(in, chunk)
+		if err != nil {
+			return err
+		}
+		rg.Columns = append(rg.Columns, cc)
+		rg.TotalByteSize += cc.MetaData.TotalUncompressedSize
+		totalCompressedSize += cc.MetaData.TotalCompressedSize
+		if index != nil {
+			indexes = append(indexes, index)
+		}
+	}
+	rg.TotalCompressedSize = &totalCompressedSize
+	// the ordinal is only an int16, it is left out in files with more row groups
+	if len(fw.rowGroups) <= math.MaxInt16 {
+		ordinal := int16(len(fw.rowGroups))
+		rg.Ordinal = &ordinal
+	}
+
+	fw.rowGroups = append(fw.rowGroups, rg)
+	fw.pageIndexes = append(fw.pageIndexes, indexes...)
+	fw.totalNumRecords += rg.NumRows
+	return nil
+}
+
+// copyColumnChunk copies the pages of the column chunk from in to the file, and returns the column chunk with the
+// new offsets, and its page index if the chunk has an offset index.
+func (fw *FileWriter) copyColumnChunk(in io.ReadSeeker, chunk *parquet.ColumnChunk) (*parquet.ColumnChunk, *pageIndex, error) {
+	md := chunk.MetaData
+	if md == nil {
+		return nil, nil, errors.New("missing meta data of a column chunk")
+	}
+	path := strings.Join(md.PathInSchema, ".")
+	if chunk.FilePath != nil {
+		return nil, nil, errors.Errorf("the data of column %q is in another file", path)
+	}
+	if chunk.CryptoMetadata != nil || chunk.EncryptedColumnMetadata != nil {
+		return nil, nil, errors.Errorf("column %q is encrypted", path)
+	}
+
+	// the indexes are read first, the reader is at the chunk afterwards
+	columnIndex, err := readColumnIndex(in, chunk)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "column %q", path)
+	}
+	offsetIndex, err := readOffsetIndex(in, chunk)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "column %q", path)
+	}
+
+	start := chunkStart(md)
+	if _, err := in.Seek(start, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	offset := fw.w.Pos()
+	if _, err := io.CopyN(fw.w, in, md.TotalCompressedSize); err != nil {
+		return nil, nil, errors.Wrapf(err, "copying column %q failed", path)
+	}
+	shift := func(pos int64) int64 {
+		return pos - start + offset
+	}
+
+	meta := *md
+	meta.DataPageOffset = shift(md.DataPageOffset)
+	if md.DictionaryPageOffset != nil && md.GetDictionaryPageOffset() >= start {
+		dictOffset := shift(md.GetDictionaryPageOffset())
+		meta.DictionaryPageOffset = &dictOffset
+	}
+	if md.IndexPageOffset != nil {
+		indexOffset := shift(md.GetIndexPageOffset())
+		meta.IndexPageOffset = &indexOffset
+	}
+	meta.BloomFilterOffset = nil
+	cc := &parquet.ColumnChunk{
+		FileOffset: offset,
+		MetaData:   &meta,
+	}
+
+	if offsetIndex == nil {
+		return cc, nil, nil
+	}
+	locations := make([]*parquet.PageLocation, len(offsetIndex.PageLocations))
+	for i, loc := range offsetIndex.PageLocations {
+		l := *loc
+		l.Offset = shift(loc.Offset)
+		locations[i] = &l
+	}
+	return cc, &pageIndex{
+		chunk:       cc,
+		columnIndex: columnIndex,
+		offsetIndex: &parquet.OffsetIndex{PageLocations: locations},
+	}, nil
+}
+
+// checkSchemaCompatible checks that the schema elements of a file are the same as the schema elements of the writer.
+func checkSchemaCompatible(src, dst []*parquet.SchemaElement) error {
+	if len(src) != len(dst) {
+		return errors.Errorf("the schema of the source file has %d elements, but the schema of the writer has %d", len(src), len(dst))
+	}
+	// the name of the root element is not part of the schema
+	for i := 1; i < len(src); i++ {
+		if !reflect.DeepEqual(src[i], dst[i]) {
+			return errors.Errorf("the schema of the source file doesn't match the schema of the writer: %s is not %s", src[i], dst[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendRowGroupFrom(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			required int32 kind;
+		}
+	`)
+	require.NoError(t, err)
+
+	writeFile := func(from, to int, opts ...FileWriterOption) []byte {
+		buf := &bytes.Buffer{}
+		opts = append([]FileWriterOption{WithSchemaDefinition(sd), WithMaxPageRows(100)}, opts...)
+		w := NewFileWriter(buf, opts...)
+		for i := from; i < to; i++ {
+			row := map[string]interface{}{
+				"id":   int64(i),
+				"kind": int32(i % 3),
+			}
+			if i%5 != 0 {
+				row["name"] = []byte(fmt.Sprintf("name %d", i%7))
+			}
+			require.NoError(t, w.AddData(row))
+			if i == (from+to)/2 {
+				require.NoError(t, w.FlushRowGroup())
+			}
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	src1 := writeFile(0, 500, WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+	src2 := writeFile(500, 800, WithDictionaryEncodingDefault(false))
+
+	r1, err := NewFileReader(bytes.NewReader(src1))
+	require.NoError(t, err)
+	r2, err := NewFileReader(bytes.NewReader(src2))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(-1), "kind": int32(0)}))
+	for _, rg := range []struct {
+		r     *FileReader
+		index int
+	}{{r1, 0}, {r1, 1}, {r2, 1}, {r2, 0}} {
+		require.NoError(t, w.AppendRowGroupFrom(rg.r, rg.index))
+	}
+	require.EqualError(t, w.AppendRowGroupFrom(r1, 2), "row group 2 is out of range, the file has 2 row groups")
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 5, r.RowGroupCount())
+	require.Equal(t, int64(801), r.NumRows())
+
+	// the chunks keep their codecs, encodings and statistics
+	sources := []*parquet.RowGroup{r1.meta.RowGroups[0], r1.meta.RowGroups[1], r2.meta.RowGroups[1], r2.meta.RowGroups[0]}
+	for i, src := range sources {
+		rg := r.meta.RowGroups[i+1]
+		require.Equal(t, src.NumRows, rg.NumRows)
+		require.Equal(t, int16(i+1), rg.GetOrdinal())
+		for j, cc := range rg.Columns {
+			srcMeta := src.Columns[j].MetaData
+			require.Equal(t, srcMeta.Codec, cc.MetaData.Codec)
+			require.Equal(t, srcMeta.Encodings, cc.MetaData.Encodings)
+			require.Equal(t, srcMeta.Statistics, cc.MetaData.Statistics)
+			require.Equal(t, srcMeta.DictionaryPageOffset != nil, cc.MetaData.DictionaryPageOffset != nil)
+		}
+	}
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, r.meta.RowGroups[1].Columns[0].MetaData.Codec)
+	require.NotNil(t, r.meta.RowGroups[1].Columns[1].MetaData.DictionaryPageOffset)
+	require.Nil(t, r.meta.RowGroups[3].Columns[1].MetaData.DictionaryPageOffset)
+
+	// the page indexes point at the copied pages
+	for rg := 1; rg < r.RowGroupCount(); rg++ {
+		for _, cc := range r.meta.RowGroups[rg].Columns {
+			offsetIndex, err := readOffsetIndex(bytes.NewReader(buf.Bytes()), cc)
+			require.NoError(t, err)
+			require.Equal(t, chunkOffsetIndex(t, buf.Bytes(), cc.MetaData), offsetIndex)
+		}
+		ci, err := r.ColumnIndex(rg, "id")
+		require.NoError(t, err)
+		require.NotNil(t, ci)
+		require.True(t, len(ci.FirstRows) > 1)
+		require.Equal(t, parquet.BoundaryOrder_ASCENDING, ci.BoundaryOrder)
+	}
+	values, err := r.ReadColumnRows(4, "id", 100, 102)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(600), int64(601)}, values)
+
+	// the rows are the rows of the row groups in the order they were appended
+	ids := []int64{-1}
+	for i := 0; i <= 250; i++ {
+		ids = append(ids, int64(i))
+	}
+	for i := 251; i < 500; i++ {
+		ids = append(ids, int64(i))
+	}
+	for i := 651; i < 800; i++ {
+		ids = append(ids, int64(i))
+	}
+	for i := 500; i <= 650; i++ {
+		ids = append(ids, int64(i))
+	}
+	for _, id := range ids {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, id, row["id"])
+		if id < 0 {
+			require.Equal(t, map[string]interface{}{"id": int64(-1), "kind": int32(0)}, row)
+			continue
+		}
+		require.Equal(t, int32(id%3), row["kind"])
+		if id%5 != 0 {
+			require.Equal(t, []byte(fmt.Sprintf("name %d", id%7)), row["name"])
+		} else {
+			require.NotContains(t, row, "name")
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestAppendRowGroupFromSchemaMismatch(t *testing.T) {
+	src, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(src))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for schema, expected := range map[string]string{
+		`message other { required int64 id; optional binary name (STRING); }`: "",
+		`message test { required int64 id; required binary name (STRING); }`:  "the schema of the source file doesn't match the schema of the writer: ",
+		`message test { required int64 id; optional binary name; }`:           "the schema of the source file doesn't match the schema of the writer: ",
+		`message test { required int64 id; }`:                                 "the schema of the source file has 3 elements, but the schema of the writer has 2",
+	} {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err)
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+		err = w.AppendRowGroupFrom(r, 0)
+		if expected == "" {
+			require.NoError(t, err, schema)
+			continue
+		}
+		require.Error(t, err, schema)
+		require.Contains(t, err.Error(), expected, schema)
+	}
+}
@@ -0,0 +1,72 @@
+package goparquet
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/andybalholm/brotli"
+	"github.com/pkg/errors"
+)
+
+// BrotliDefaultQuality is the brotli quality of the default BROTLI compressor, it is the default of arrow-cpp.
+const BrotliDefaultQuality = 8
+
+// brotliCompressor is the BROTLI codec.
+type brotliCompressor struct {
+	quality int
+}
+
+// NewBrotliCompressor returns a BROTLI block compressor that compresses with the given quality, between
+// brotli.BestSpeed (0) and brotli.BestCompression (11). Register it with RegisterBlockCompressor to use a different
+// quality than BrotliDefaultQuality.
+func NewBrotliCompressor(quality int) (BlockCompressor, error) {
+	if quality < brotli.BestSpeed || quality > brotli.BestCompression {
+		return nil, errors.Errorf("brotli: invalid quality %d, it must be between %d and %d", quality, brotli.BestSpeed, brotli.BestCompression)
+	}
+
+	return &brotliCompressor{quality: quality}, nil
+}
+
+func (b *brotliCompressor) WithOptions(opts CompressorOptions) (BlockCompressor, error) {
+	if opts.Level == 0 {
+		return NewBrotliCompressor(BrotliDefaultQuality)
+	}
+	return NewBrotliCompressor(opts.Level)
+}
+
+func (b *brotliCompressor) CompressBlock(block []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := brotli.NewWriterLevel(buf, b.quality)
+	if _, err := w.Write(block); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (b *brotliCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return b.DecompressBlockSize(block, -1)
+}
+
+func (b *brotliCompressor) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	if size < 0 {
+		return ioutil.ReadAll(brotli.NewReader(bytes.NewReader(block)))
+	}
+
+	return b.decompressBlockTo(make([]byte, size), block)
+}
+
+func (b *brotliCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	res, err := readBlockTo(brotli.NewReader(bytes.NewReader(block)), dst)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > len(dst) {
+		return nil, errors.Errorf("brotli: the decompressed data is larger than the page size of %d byte", len(dst))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrotliCompressor(t *testing.T) {
+	_, err := NewBrotliCompressor(brotli.BestCompression + 1)
+	require.EqualError(t, err, "brotli: invalid quality 12, it must be between 0 and 11")
+	_, err = NewBrotliCompressor(-1)
+	require.Error(t, err)
+
+	block := bytes.Repeat([]byte("lorem ipsum dolor sit amet "), 1000)
+	var sizes []int
+	for _, quality := range []int{brotli.BestSpeed, brotli.BestCompression} {
+		c, err := NewBrotliCompressor(quality)
+		require.NoError(t, err)
+
+		comp, err := c.CompressBlock(block)
+		require.NoError(t, err)
+		sizes = append(sizes, len(comp))
+
+		data, err := decompressWithSize(c, comp, len(block))
+		require.NoError(t, err)
+		require.Equal(t, block, data)
+
+		// the stream decompresses to more than the page size
+		_, err = decompressWithSize(c, comp, 100)
+		require.EqualError(t, err, "brotli: the decompressed data is larger than the page size of 100 byte")
+	}
+	require.True(t, sizes[0] > sizes[1], "unexpected compressed sizes %v", sizes)
+
+	_, err = decompressBlock([]byte("not brotli"), parquet.CompressionCodec_BROTLI, 100)
+	require.Error(t, err)
+}
+
+func TestWriteBrotliWithQuality(t *testing.T) {
+	c, err := NewBrotliCompressor(brotli.BestCompression)
+	require.NoError(t, err)
+	RegisterBlockCompressor(parquet.CompressionCodec_BROTLI, c)
+	defer RegisterBlockCompressor(parquet.CompressionCodec_BROTLI, &brotliCompressor{quality: BrotliDefaultQuality})
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCompressionCodec(parquet.CompressionCodec_BROTLI))
+	s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprintf("value %d", i%10))}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value %d", i%10)), row["a"])
+	}
+}
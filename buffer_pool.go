@@ -0,0 +1,56 @@
+package goparquet
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// maxPooledBufferBits is the size class of the largest pooled buffer, 64 MiB. Larger pages are rare, their buffers
+// are allocated and left to the garbage collector.
+const maxPooledBufferBits = 26
+
+// pageBuffers is the pool of the buffers for the compressed and the decompressed data of the pages that are read.
+var pageBuffers bufferPool
+
+// bufferPool pools byte slices by their size, rounded up to the next power of two. A buffer of a size class can
+// hold every size of the class, so pages of slightly different sizes share the buffers.
+type bufferPool struct {
+	pools [maxPooledBufferBits + 1]sync.Pool
+}
+
+// bufferSizeClass returns the size class for size, the buffers of class c have a capacity of 1<<c. It returns -1
+// if a buffer of the size is not pooled.
+func bufferSizeClass(size int) int {
+	if size <= 0 {
+		return -1
+	}
+	c := bits.Len(uint(size - 1))
+	if c > maxPooledBufferBits {
+		return -1
+	}
+	return c
+}
+
+// get returns a buffer with the length size. The content of the buffer is undefined.
+func (p *bufferPool) get(size int) []byte {
+	c := bufferSizeClass(size)
+	if c < 0 {
+		return make([]byte, size)
+	}
+
+	if buf, ok := p.pools[c].Get().([]byte); ok {
+		return buf[:size]
+	}
+	return make([]byte, size, 1<<uint(c))
+}
+
+// put returns a buffer to the pool. The buffer must not be used afterwards, and it must not be put twice. Buffers
+// that are not from get can be put if their capacity is a size class.
+func (p *bufferPool) put(buf []byte) {
+	c := bufferSizeClass(cap(buf))
+	if c < 0 || cap(buf) != 1<<uint(c) {
+		return
+	}
+
+	p.pools[c].Put(buf[:0])
+}
@@ -0,0 +1,100 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferPool(t *testing.T) {
+	var p bufferPool
+
+	buf := p.get(1000)
+	require.Equal(t, 1000, len(buf))
+	require.Equal(t, 1024, cap(buf))
+	p.put(buf)
+
+	buf = p.get(513)
+	require.Equal(t, 513, len(buf))
+	require.Equal(t, 1024, cap(buf))
+
+	require.Equal(t, 0, len(p.get(0)))
+	require.Equal(t, 1, cap(p.get(1)))
+
+	// too large to be pooled
+	require.Equal(t, -1, bufferSizeClass(1<<maxPooledBufferBits+1))
+	require.Equal(t, maxPooledBufferBits, bufferSizeClass(1<<maxPooledBufferBits))
+
+	// a buffer that is not of a size class is dropped
+	p.put(make([]byte, 1000))
+}
+
+func TestBlockReaderRelease(t *testing.T) {
+	page := bytes.Repeat([]byte("parquet page data "), 100)
+	block := snappy.Encode(nil, page)
+
+	r, err := newBlockReader(bytes.NewReader(block), parquet.CompressionCodec_SNAPPY, int32(len(block)), int32(len(page)))
+	require.NoError(t, err)
+	require.NotNil(t, r.buf)
+	require.Equal(t, len(page), len(r.buf))
+
+	r.release()
+	require.Nil(t, r.buf)
+	_, err = r.Read(make([]byte, 1))
+	require.Equal(t, io.EOF, err)
+	// releasing twice doesn't put the buffer twice
+	r.release()
+}
+
+func TestPageBufferReuse(t *testing.T) {
+	for _, opts := range [][]FileWriterOption{
+		{WithCompressionCodec(parquet.CompressionCodec_SNAPPY)},
+		{WithCompressionCodec(parquet.CompressionCodec_GZIP), WithDataPageV2()},
+		{WithCompressionCodec(parquet.CompressionCodec_UNCOMPRESSED)},
+	} {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+		s, err := NewByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+		for rg := 0; rg < 5; rg++ {
+			for i := 0; i < 100; i++ {
+				require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprintf("row group %d value %d", rg, i))}))
+			}
+			require.NoError(t, w.FlushRowGroup())
+		}
+		require.NoError(t, w.Close())
+
+		// the values of the first row groups must not change when the page buffers are reused by the later ones
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+
+		var values [][]byte
+		for rg := 0; rg < 5; rg++ {
+			values, err = r.ReadByteArrayValues(rg, "a", values)
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 500, len(rows))
+		require.Equal(t, 500, len(values))
+		for i := range rows {
+			expected := []byte(fmt.Sprintf("row group %d value %d", i/100, i%100))
+			require.Equal(t, expected, rows[i]["a"])
+			require.Equal(t, expected, values[i])
+		}
+	}
+}
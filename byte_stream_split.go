@@ -0,0 +1,105 @@
+package goparquet
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// byteStreamSplitDecoder is the BYTE_STREAM_SPLIT decoder. The encoding scatters the bytes of each value into
+// width streams, the first stream contains the first byte of every value, the second stream the second byte and so
+// on. To reassemble a value all the streams are required, so the decoder reads the whole page data at once. The
+// page readers pass the number of not null values of the page to initNumValues, the NumValues in the page header is
+// not usable as it is, since it contains the null values too.
+type byteStreamSplitDecoder struct {
+	width int
+
+	data     []byte
+	count    int
+	position int
+}
+
+// init reads the data without a number of values, the number of values is the data length divided by the width.
+func (d *byteStreamSplitDecoder) init(r io.Reader) error {
+	return d.initNumValues(r, -1)
+}
+
+// initNumValues reads the data of n values, it must have exactly n*width bytes. A negative n is an unknown number of
+// values.
+func (d *byteStreamSplitDecoder) initNumValues(r io.Reader, n int32) error {
+	if d.width <= 0 {
+		return errors.Errorf("byte_stream_split: invalid value width %d", d.width)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if len(data)%d.width != 0 {
+		return errors.Errorf("byte_stream_split: data size %d is not a multiple of value width %d", len(data), d.width)
+	}
+	if n >= 0 && int64(len(data)) != int64(n)*int64(d.width) {
+		return errors.Errorf("byte_stream_split: data size %d doesn't match %d values of width %d", len(data), n, d.width)
+	}
+
+	d.data = data
+	d.count = len(data) / d.width
+	d.position = 0
+	return nil
+}
+
+// nextValue reassembles the next value in little endian order into the dst, dst must be exactly width long.
+func (d *byteStreamSplitDecoder) nextValue(dst []byte) error {
+	if d.position >= d.count {
+		return io.EOF
+	}
+
+	for i := range dst {
+		dst[i] = d.data[i*d.count+d.position]
+	}
+	d.position++
+
+	return nil
+}
+
+// byteStreamSplitEncoder buffers the values of the page in little endian order and writes the byte streams on
+// Close, since every stream needs the total number of values.
+type byteStreamSplitEncoder struct {
+	width int
+
+	w    io.Writer
+	data []byte
+}
+
+func (e *byteStreamSplitEncoder) init(w io.Writer) error {
+	if e.width <= 0 {
+		return errors.Errorf("byte_stream_split: invalid value width %d", e.width)
+	}
+
+	e.w = w
+	e.data = e.data[:0]
+	return nil
+}
+
+func (e *byteStreamSplitEncoder) estimatedSize() int {
+	return len(e.data)
+}
+
+// addValue adds a value in little endian order to the buffer.
+func (e *byteStreamSplitEncoder) addValue(v []byte) {
+	e.data = append(e.data, v...)
+}
+
+func (e *byteStreamSplitEncoder) Close() error {
+	count := len(e.data) / e.width
+	streams := make([]byte, len(e.data))
+	for i := 0; i < count; i++ {
+		for j := 0; j < e.width; j++ {
+			streams[j*count+i] = e.data[i*e.width+j]
+		}
+	}
+
+	return writeFull(e.w, streams)
+}
@@ -0,0 +1,125 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteStreamSplitGolden(t *testing.T) {
+	// 1.0 = 0x3f800000, 2.0 = 0x40000000 and -0.5 = 0xbf000000, each stream holds one byte of every value
+	floats := []interface{}{float32(1.0), float32(2.0), float32(-0.5)}
+	floatData := []byte{
+		0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00,
+		0x80, 0x00, 0x00,
+		0x3f, 0x40, 0xbf,
+	}
+
+	// 1.0 = 0x3ff0000000000000 and -2.5 = 0xc004000000000000
+	doubles := []interface{}{float64(1.0), float64(-2.5)}
+	doubleData := []byte{
+		0x00, 0x00,
+		0x00, 0x00,
+		0x00, 0x00,
+		0x00, 0x00,
+		0x00, 0x00,
+		0x00, 0x00,
+		0xf0, 0x04,
+		0x3f, 0xc0,
+	}
+
+	fixtures := []struct {
+		name   string
+		enc    valuesEncoder
+		dec    valuesDecoder
+		values []interface{}
+		data   []byte
+	}{
+		{"float", &floatByteStreamSplitEncoder{}, &floatByteStreamSplitDecoder{}, floats, floatData},
+		{"double", &doubleByteStreamSplitEncoder{}, &doubleByteStreamSplitDecoder{}, doubles, doubleData},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, f.enc.init(buf))
+			require.NoError(t, f.enc.encodeValues(f.values))
+			require.NoError(t, f.enc.Close())
+			require.Equal(t, f.data, buf.Bytes())
+
+			require.NoError(t, f.dec.init(bytes.NewReader(f.data)))
+			read := make([]interface{}, len(f.values)+1)
+			n, err := f.dec.decodeValues(read)
+			require.Equal(t, io.EOF, err)
+			require.Equal(t, len(f.values), n)
+			require.Equal(t, f.values, read[:n])
+		})
+	}
+}
+
+func TestByteStreamSplitSpecialValues(t *testing.T) {
+	values := []interface{}{math.NaN(), math.Inf(1), math.Inf(-1), math.Copysign(0, -1), 0.0, math.MaxFloat64, math.SmallestNonzeroFloat64}
+
+	buf := &bytes.Buffer{}
+	enc := &doubleByteStreamSplitEncoder{}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encodeValues(values))
+	require.NoError(t, enc.Close())
+
+	dec := &doubleByteStreamSplitDecoder{}
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	read := make([]interface{}, len(values))
+	n, err := dec.decodeValues(read)
+	require.NoError(t, err)
+	require.Equal(t, len(values), n)
+	for i := range values {
+		require.Equal(t, math.Float64bits(values[i].(float64)), math.Float64bits(read[i].(float64)))
+	}
+}
+
+func TestByteStreamSplitInvalidSize(t *testing.T) {
+	dec := &floatByteStreamSplitDecoder{}
+	require.Error(t, dec.init(bytes.NewReader([]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05})))
+
+	dec64 := &doubleByteStreamSplitDecoder{}
+	require.Error(t, dec64.init(bytes.NewReader([]byte{0x00, 0x01, 0x02, 0x03})))
+
+	// the data must have the number of values of the page
+	data := make([]byte, 16)
+	require.NoError(t, dec.initNumValues(bytes.NewReader(data), 4))
+	require.EqualError(t, dec.initNumValues(bytes.NewReader(data), 3), "byte_stream_split: data size 16 doesn't match 3 values of width 4")
+	require.EqualError(t, dec64.initNumValues(bytes.NewReader(data), 3), "byte_stream_split: data size 16 doesn't match 3 values of width 8")
+}
+
+func TestByteStreamSplitGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. The FLOAT column has one
+	// data page v1, the optional DOUBLE column has two GZIP compressed data pages v1 with null values.
+	f, err := os.Open("testdata/byte_stream_split.parquet")
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := NewFileReader(f)
+	require.NoError(t, err)
+	for _, col := range r.meta.RowGroups[0].Columns {
+		require.Contains(t, col.MetaData.Encodings, parquet.Encoding_BYTE_STREAM_SPLIT)
+	}
+
+	require.Equal(t, int64(200), r.NumRows())
+	for i := 0; i < 200; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"f": float32(i)*0.25 - 3}
+		if i%4 != 0 {
+			expected["d"] = float64(i) * 1.5e-3
+		}
+		require.Equal(t, expected, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
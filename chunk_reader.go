@@ -1,15 +1,24 @@
 package goparquet
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"math"
 	"math/bits"
+	"sync"
 
 	"github.com/pkg/errors"
 
 	"github.com/fraugster/parquet-go/parquet"
 )
 
+// ErrPageChecksum is returned when the CRC32 of a page doesn't match the checksum in its page header. The error
+// names the column and the position of the page in the column chunk, starting at 0 with the dictionary page.
+var ErrPageChecksum = errors.New("page checksum mismatch")
+
 type getValueDecoderFn func(parquet.Encoding) (valuesDecoder, error)
 type getLevelDecoder func(parquet.Encoding) (levelDecoder, error)
 
@@ -27,27 +36,9 @@ func getDictValuesDecoder(typ *parquet.SchemaElement) (valuesDecoder, error) {
 	case parquet.Type_DOUBLE:
 		return &doublePlainDecoder{}, nil
 	case parquet.Type_INT32:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int32PlainDecoder{unSigned: unSigned}, nil
+		return &int32PlainDecoder{unSigned: isUnsignedInt32(typ)}, nil
 	case parquet.Type_INT64:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int64PlainDecoder{unSigned: unSigned}, nil
+		return &int64PlainDecoder{unSigned: isUnsignedInt64(typ)}, nil
 	case parquet.Type_INT96:
 		return &int96PlainDecoder{}, nil
 	}
@@ -55,173 +46,106 @@ func getDictValuesDecoder(typ *parquet.SchemaElement) (valuesDecoder, error) {
 	return nil, errors.Errorf("type %s is not supported for dict value encoder", typ)
 }
 
-func getBooleanValuesDecoder(pageEncoding parquet.Encoding, dictValues []interface{}) (valuesDecoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &booleanPlainDecoder{}, nil
-	case parquet.Encoding_RLE:
-		return &booleanRLEDecoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictDecoder{values: dictValues}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for boolean", pageEncoding)
+func createDataReader(r io.Reader, codec parquet.CompressionCodec, compressedSize int32, uncompressedSize int32) (*blockReader, error) {
+	if compressedSize < 0 || uncompressedSize < 0 {
+		return nil, errors.New("invalid page data size")
 	}
-}
 
-func getByteArrayValuesDecoder(pageEncoding parquet.Encoding, dictValues []interface{}) (valuesDecoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &byteArrayPlainDecoder{}, nil
-	case parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY:
-		return &byteArrayDeltaLengthDecoder{}, nil
-	case parquet.Encoding_DELTA_BYTE_ARRAY:
-		return &byteArrayDeltaDecoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictDecoder{values: dictValues}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for binary", pageEncoding)
-	}
+	return newBlockReader(r, codec, compressedSize, uncompressedSize)
 }
 
-func getFixedLenByteArrayValuesDecoder(pageEncoding parquet.Encoding, len int, dictValues []interface{}) (valuesDecoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &byteArrayPlainDecoder{length: len}, nil
-	case parquet.Encoding_DELTA_BYTE_ARRAY:
-		return &byteArrayDeltaDecoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictDecoder{values: dictValues}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for fixed_len_byte_array(%d)", pageEncoding, len)
+// initValuesDecoder initializes dec with the values section of a data page. A page that has only null values can have
+// no value bytes at all, then most encodings can't even read their header, so dec is replaced by a decoder without
+// values.
+func initValuesDecoder(dec valuesDecoder, r *blockReader) (valuesDecoder, error) {
+	if r.Len() == 0 {
+		return emptyValuesDecoder{}, nil
 	}
-}
 
-func getInt32ValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &int32PlainDecoder{unSigned: unSigned}, nil
-	case parquet.Encoding_DELTA_BINARY_PACKED:
-		return &int32DeltaBPDecoder{unSigned: unSigned}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictDecoder{values: dictValues}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for int32", pageEncoding)
-	}
-}
-
-func getInt64ValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &int64PlainDecoder{unSigned: unSigned}, nil
-	case parquet.Encoding_DELTA_BINARY_PACKED:
-		return &int64DeltaBPDecoder{unSigned: unSigned}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictDecoder{values: dictValues}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for int64", pageEncoding)
-	}
+	return dec, dec.init(r)
 }
 
-func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
-	// Change the deprecated value
-	if pageEncoding == parquet.Encoding_PLAIN_DICTIONARY {
-		pageEncoding = parquet.Encoding_RLE_DICTIONARY
+// initValuesCountDecoder is initValuesDecoder for the decoders that need the number n of not null values in the page.
+func initValuesCountDecoder(dec valuesCountDecoder, r *blockReader, n int32) (valuesDecoder, error) {
+	if r.Len() == 0 {
+		return emptyValuesDecoder{}, nil
 	}
 
-	switch *typ.Type {
-	case parquet.Type_BOOLEAN:
-		return getBooleanValuesDecoder(pageEncoding, dictValues)
-
-	case parquet.Type_BYTE_ARRAY:
-		return getByteArrayValuesDecoder(pageEncoding, dictValues)
-
-	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
-		if typ.TypeLength == nil {
-			return nil, errors.Errorf("type %s with nil type len", typ.Type)
-		}
-		return getFixedLenByteArrayValuesDecoder(pageEncoding, int(*typ.TypeLength), dictValues)
-	case parquet.Type_FLOAT:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &floatPlainDecoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictDecoder{values: dictValues}, nil
-		}
-
-	case parquet.Type_DOUBLE:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &doublePlainDecoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictDecoder{values: dictValues}, nil
-		}
+	return dec, dec.initNumValues(r, n)
+}
 
-	case parquet.Type_INT32:
-		return getInt32ValuesDecoder(pageEncoding, typ, dictValues)
+// emptyValuesDecoder is the values decoder of a page without value bytes.
+type emptyValuesDecoder struct{}
 
-	case parquet.Type_INT64:
-		return getInt64ValuesDecoder(pageEncoding, typ, dictValues)
+func (emptyValuesDecoder) init(io.Reader) error {
+	return nil
+}
 
-	case parquet.Type_INT96:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &int96PlainDecoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictDecoder{values: dictValues}, nil
-		}
+func (emptyValuesDecoder) decodeValues([]interface{}) (int, error) {
+	return 0, io.EOF
+}
 
-	default:
-		return nil, errors.Errorf("unsupported type %s", typ.Type)
-	}
+func (emptyValuesDecoder) decodeInt32Batch([]int32) (int, error) {
+	return 0, io.EOF
+}
 
-	return nil, errors.Errorf("unsupported encoding %s for %s type", pageEncoding, typ.Type)
+func (emptyValuesDecoder) decodeInt64Batch([]int64) (int, error) {
+	return 0, io.EOF
 }
 
-func createDataReader(r io.Reader, codec parquet.CompressionCodec, compressedSize int32, uncompressedSize int32) (io.Reader, error) {
-	if compressedSize < 0 || uncompressedSize < 0 {
-		return nil, errors.New("invalid page data size")
-	}
+func (emptyValuesDecoder) decodeFloat64Batch([]float64) (int, error) {
+	return 0, io.EOF
+}
 
-	return newBlockReader(r, codec, compressedSize, uncompressedSize)
+func (emptyValuesDecoder) decodeByteArrayBatch([][]byte) (int, error) {
+	return 0, io.EOF
 }
 
-func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData, dDecoder, rDecoder getLevelDecoder) ([]pageReader, error) {
+// readPages reads the pages of the column chunk, the data pages from the offset dataStart until the offset end. The
+// dictionary values are read into the array of dictBuf, if there is a dictionary page.
+func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData, dataStart, end int64, dDecoder, rDecoder getLevelDecoder, dictBuf []interface{}, validateCRC bool) ([]pageReader, error) {
 	var (
 		dictPage *dictPageReader
 		pages    []pageReader
 	)
 
-	for {
-		if chunkMeta.TotalCompressedSize-r.Count() <= 0 {
+	for ordinal := 0; ; ordinal++ {
+		if r.offset >= end {
 			break
 		}
+		pageStart := r.offset
 		ph := &parquet.PageHeader{}
 		if err := readThrift(ph, r); err != nil {
 			return nil, err
 		}
 
+		// the first page is read to find the dictionary page, a data page before dataStart is skipped
+		if ph.Type != parquet.PageType_DICTIONARY_PAGE && pageStart < dataStart {
+			if _, err := r.Seek(dataStart, io.SeekStart); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var (
+			pageData io.Reader = r
+			buf      []byte
+		)
+		if validateCRC && ph.Crc != nil {
+			var err error
+			if buf, err = readCheckedPage(r, ph); err != nil {
+				return nil, errors.Wrapf(err, "page %d", ordinal)
+			}
+			pageData = bytes.NewReader(buf)
+		}
+
 		if ph.Type == parquet.PageType_DICTIONARY_PAGE {
 			if dictPage != nil {
 				return nil, errors.New("there should be only one dictionary")
 			}
+			if len(pages) > 0 {
+				return nil, errors.New("the dictionary page must be the first page of the column chunk")
+			}
 			p := &dictPageReader{}
 			de, err := getDictValuesDecoder(col.Element())
 			if err != nil {
@@ -231,20 +155,18 @@ func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData,
 				return nil, err
 			}
 
-			// re-use the value dictionary store
-			p.values = col.getColumnStore().values.values
-			if err := p.read(r, ph, chunkMeta.Codec); err != nil {
+			p.values = dictBuf
+			err = p.read(pageData, ph, chunkMeta.Codec)
+			pageBuffers.put(buf)
+			if err != nil {
 				return nil, err
 			}
 
 			dictPage = p
-			// Go to the next data Page
-			// if we have a DictionaryPageOffset we should return to DataPageOffset
-			if chunkMeta.DictionaryPageOffset != nil {
-				if *chunkMeta.DictionaryPageOffset != r.offset {
-					if _, err := r.Seek(chunkMeta.DataPageOffset, io.SeekStart); err != nil {
-						return nil, err
-					}
+			// Go to the first data page, if it doesn't follow the dictionary page directly
+			if r.offset < dataStart {
+				if _, err := r.Seek(dataStart, io.SeekStart); err != nil {
+					return nil, err
 				}
 			}
 			continue // go to next page
@@ -274,7 +196,9 @@ func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData,
 			return nil, err
 		}
 
-		if err := p.read(r, ph, chunkMeta.Codec); err != nil {
+		err := p.read(pageData, ph, chunkMeta.Codec)
+		pageBuffers.put(buf)
+		if err != nil {
 			return nil, err
 		}
 		pages = append(pages, p)
@@ -283,55 +207,88 @@ func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData,
 	return pages, nil
 }
 
+// readCheckedPage reads the compressed data of the page and checks it against the CRC32 of the page header. The
+// returned buffer is from the page buffer pool.
+func readCheckedPage(r io.Reader, ph *parquet.PageHeader) ([]byte, error) {
+	size := ph.GetCompressedPageSize()
+	if size < 0 {
+		return nil, errors.Errorf("negative compressed page size %d", size)
+	}
+
+	buf := pageBuffers.get(int(size))
+	if n, err := io.ReadFull(r, buf); err != nil {
+		pageBuffers.put(buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.Errorf("compressed data must be %d byte but its %d byte", size, n)
+		}
+		return nil, errors.Wrap(err, "read failed")
+	}
+
+	if crc32.ChecksumIEEE(buf) != uint32(ph.GetCrc()) {
+		pageBuffers.put(buf)
+		return nil, ErrPageChecksum
+	}
+	return buf, nil
+}
+
+// chunkStart returns the offset of the first page of the column chunk, which is the dictionary page if there is one.
+// Some writers don't set the dictionary_page_offset, or set it to 0, even though the chunk starts with a dictionary
+// page. Their data_page_offset points to the dictionary page, which is then read as the first page.
+func chunkStart(meta *parquet.ColumnMetaData) int64 {
+	if dict := meta.GetDictionaryPageOffset(); dict > 0 && dict < meta.DataPageOffset {
+		return dict
+	}
+	return meta.DataPageOffset
+}
+
 func skipChunk(r io.Seeker, col *Column, chunk *parquet.ColumnChunk) error {
 	if chunk.FilePath != nil {
 		return fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
 	}
 
-	c := col.Index()
 	// chunk.FileOffset is useless so ChunkMetaData is required here
 	// as we cannot read it from r
 	// see https://issues.apache.org/jira/browse/PARQUET-291
 	if chunk.MetaData == nil {
-		return errors.Errorf("missing meta data for Column %c", c)
+		return errors.Errorf("missing meta data for column %q", col.FlatName())
 	}
 
 	if typ := *col.Element().Type; chunk.MetaData.Type != typ {
-		return errors.Errorf("wrong type in Column chunk metadata, expected %s was %s",
-			typ, chunk.MetaData.Type)
+		return errors.Errorf("wrong type in column chunk metadata of column %q, expected %s was %s",
+			col.FlatName(), typ, chunk.MetaData.Type)
 	}
 
-	offset := chunk.MetaData.DataPageOffset
-	if chunk.MetaData.DictionaryPageOffset != nil {
-		offset = *chunk.MetaData.DictionaryPageOffset
-	}
+	offset := chunkStart(chunk.MetaData)
 
 	offset += chunk.MetaData.TotalCompressedSize
 	_, err := r.Seek(offset, io.SeekStart)
 	return err
 }
 
-func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) ([]pageReader, error) {
+// readChunk reads the pages of the column chunk. If locations is not nil, only the data pages at the locations are
+// read, they must be consecutive pages of the chunk. The dictionary page is always read.
+func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk, dictBuf []interface{}, validateCRC bool, locations []*parquet.PageLocation) ([]pageReader, error) {
 	if chunk.FilePath != nil {
 		return nil, fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
 	}
 
-	c := col.Index()
 	// chunk.FileOffset is useless so ChunkMetaData is required here
 	// as we cannot read it from r
 	// see https://issues.apache.org/jira/browse/PARQUET-291
 	if chunk.MetaData == nil {
-		return nil, errors.Errorf("missing meta data for Column %c", c)
+		return nil, errors.Errorf("missing meta data for column %q", col.FlatName())
 	}
 
 	if typ := *col.Element().Type; chunk.MetaData.Type != typ {
-		return nil, errors.Errorf("wrong type in Column chunk metadata, expected %s was %s",
-			typ, chunk.MetaData.Type)
+		return nil, errors.Errorf("wrong type in column chunk metadata of column %q, expected %s was %s",
+			col.FlatName(), typ, chunk.MetaData.Type)
 	}
 
-	offset := chunk.MetaData.DataPageOffset
-	if chunk.MetaData.DictionaryPageOffset != nil {
-		offset = *chunk.MetaData.DictionaryPageOffset
+	offset := chunkStart(chunk.MetaData)
+	dataStart, end := chunk.MetaData.DataPageOffset, offset+chunk.MetaData.TotalCompressedSize
+	if len(locations) > 0 {
+		last := locations[len(locations)-1]
+		dataStart, end = locations[0].Offset, last.Offset+int64(last.CompressedPageSize)
 	}
 	// Seek to the beginning of the first Page
 	_, err := r.Seek(offset, io.SeekStart)
@@ -374,7 +331,7 @@ func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) ([]page
 			return &levelDecoderWrapper{decoder: constDecoder(0), max: col.MaxDefinitionLevel()}, nil
 		}
 	}
-	return readPages(reader, col, chunk.MetaData, dDecoder, rDecoder)
+	return readPages(reader, col, chunk.MetaData, dataStart, end, dDecoder, rDecoder, dictBuf, validateCRC)
 }
 
 func readPageData(col *Column, pages []pageReader) error {
@@ -389,43 +346,156 @@ func readPageData(col *Column, pages []pageReader) error {
 		if int32(n) != pages[i].numValues() {
 			return errors.Errorf("expect %d value but read %d", pages[i].numValues(), n)
 		}
+		pages[i].release()
 
 		// using append to make sure we handle the multiple data page correctly
 		s.rLevels.appendArray(rl)
 		s.dLevels.appendArray(dl)
 
-		s.values.values = append(s.values.values, data...)
+		// the values that are not null are at the start of data, the rest is left for the null values
+		s.values.values = append(s.values.values, data[:countDefined(dl, col.MaxDefinitionLevel())]...)
 		s.values.noDictMode = true
 	}
 
 	return nil
 }
 
-func readRowGroup(r io.ReadSeeker, schema SchemaReader, rowGroups *parquet.RowGroup) error {
-	dataCols := schema.Columns()
-	schema.resetData()
-	schema.setNumRecords(rowGroups.NumRows)
+// countDefined returns the number of definition levels that are maxD, which are the values that are not null.
+func countDefined(dLevels *packedArray, maxD uint16) int {
+	if dLevels == nil {
+		return 0
+	}
+	if dLevels.bw == 0 {
+		return dLevels.count
+	}
+
+	var n int
+	for i := 0; i < dLevels.count; i++ {
+		if dl, _ := dLevels.at(i); dl == int32(maxD) {
+			n++
+		}
+	}
+	return n
+}
+
+// readRowGroup reads the column chunks of the row group into the columns of the schema. The records before the row
+// with the index fromRow in the row group are skipped, and the pages before the page with this row are not read if
+// the column chunk has an offset index.
+func readRowGroup(r io.ReadSeeker, schema SchemaReader, rowGroups *parquet.RowGroup, validateCRC bool, fromRow int64) error {
+	dataCols, err := resetRowGroup(schema, rowGroups)
+	if err != nil {
+		return err
+	}
 	for _, c := range dataCols {
-		idx := c.Index()
-		if len(rowGroups.Columns) <= idx {
-			return fmt.Errorf("column index %d is out of bounds", idx)
+		if err := readColumnChunk(r, schema, c, rowGroups, validateCRC, fromRow); err != nil {
+			return err
 		}
-		chunk := rowGroups.Columns[c.Index()]
-		if !schema.isSelected(c.flatName) {
-			if err := skipChunk(r, c, chunk); err != nil {
-				return err
+	}
+
+	return nil
+}
+
+// readRowGroupConcurrently reads the column chunks of the row group like readRowGroup, with up to concurrency
+// column chunks read and decoded in parallel. Each worker reads through its own io.SectionReader of r. After the
+// first error no more column chunks are started, the reads of the running workers fail from then on, so they stop
+// between two pages, and the error is returned once they are done.
+func readRowGroupConcurrently(r io.ReaderAt, schema SchemaReader, rowGroups *parquet.RowGroup, validateCRC bool, fromRow int64, concurrency int) error {
+	dataCols, err := resetRowGroup(schema, rowGroups)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	// the context is only canceled by the first error, the errors of the reads that fail because of it are dropped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	columns := make(chan *Column)
+	for i := 0; i < concurrency && i < len(dataCols); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sr := newContextReader(ctx, io.NewSectionReader(r, 0, math.MaxInt64))
+			for c := range columns {
+				if err := readColumnChunk(sr, schema, c, rowGroups, validateCRC, fromRow); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
 			}
-			c.data.skipped = true
-			continue
+		}()
+	}
+
+feed:
+	for _, c := range dataCols {
+		select {
+		case columns <- c:
+		case <-ctx.Done():
+			break feed
 		}
-		pages, err := readChunk(r, c, chunk)
-		if err != nil {
+	}
+	close(columns)
+	wg.Wait()
+
+	return firstErr
+}
+
+// resetRowGroup prepares the schema for reading the row group and returns its data columns.
+func resetRowGroup(schema SchemaReader, rowGroups *parquet.RowGroup) ([]*Column, error) {
+	dataCols := schema.Columns()
+	schema.resetData()
+	schema.setNumRecords(rowGroups.NumRows)
+	if err := checkCodecs(dataCols, rowGroups, schema.isSelected); err != nil {
+		return nil, err
+	}
+	return dataCols, nil
+}
+
+// readColumnChunk reads the column chunk of the column c in the row group into the column, or skips it if the
+// column is not selected. The records before the row with the index fromRow are skipped.
+func readColumnChunk(r io.ReadSeeker, schema SchemaReader, c *Column, rowGroups *parquet.RowGroup, validateCRC bool, fromRow int64) error {
+	idx := c.Index()
+	if len(rowGroups.Columns) <= idx {
+		return fmt.Errorf("column index %d is out of bounds", idx)
+	}
+	chunk := rowGroups.Columns[c.Index()]
+	if !schema.isSelected(c.flatName) {
+		if err := skipChunk(r, c, chunk); err != nil {
 			return err
 		}
-		if err := readPageData(c, pages); err != nil {
-			return err
+		c.data.skipped = true
+		return nil
+	}
+
+	var locations []*parquet.PageLocation
+	skip := fromRow
+	if fromRow > 0 {
+		index, err := readOffsetIndex(r, chunk)
+		if err != nil {
+			return errors.Wrapf(err, "reading column %q failed", c.FlatName())
+		}
+		if index != nil && len(index.PageLocations) > 0 {
+			var firstRow int64
+			locations, firstRow = pagesForRows(index, fromRow, rowGroups.NumRows)
+			skip = fromRow - firstRow
 		}
 	}
 
+	// the dictionary can't re-use the array of the values of the column store, the values of the pages are appended
+	// to it while the later pages still look up the dictionary values
+	pages, err := readChunk(r, c, chunk, nil, validateCRC, locations)
+	if err != nil {
+		return errors.Wrapf(err, "reading column %q failed", c.FlatName())
+	}
+	if err := readPageData(c, pages); err != nil {
+		return errors.Wrapf(err, "reading column %q failed", c.FlatName())
+	}
+	if err := c.data.skipRecords(skip, int32(c.maxD)); err != nil {
+		return errors.Wrapf(err, "reading column %q failed", c.FlatName())
+	}
 	return nil
 }
@@ -1,6 +1,18 @@
 package goparquet
 
-import "testing"
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
 
 func TestFuzzCrashReadRowGroup(t *testing.T) {
 	data := []byte("PAR1\x150\x19,H\f0000000000" +
@@ -20,3 +32,164 @@ func TestFuzzCrashReadRowGroup(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func TestReadDictionaryPageWithoutOffset(t *testing.T) {
+	writeFile := func(opts ...FileWriterOption) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+		s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_OPTIONAL)))
+		for i := 0; i < 100; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprint(i % 7))}))
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	tests := map[string]func(md *parquet.ColumnMetaData){
+		"as written": func(md *parquet.ColumnMetaData) {},
+		// parquet-mr didn't set the dictionary_page_offset, the data_page_offset points to the dictionary page.
+		"unset": func(md *parquet.ColumnMetaData) {
+			md.DataPageOffset = *md.DictionaryPageOffset
+			md.DictionaryPageOffset = nil
+		},
+		"zero": func(md *parquet.ColumnMetaData) {
+			md.DataPageOffset = *md.DictionaryPageOffset
+			zero := int64(0)
+			md.DictionaryPageOffset = &zero
+		},
+	}
+
+	for _, opts := range [][]FileWriterOption{nil, {WithDataPageV2()}} {
+		data := writeFile(opts...)
+		for name, modify := range tests {
+			t.Run(name, func(t *testing.T) {
+				r, err := NewFileReader(bytes.NewReader(data))
+				require.NoError(t, err)
+				md := r.meta.RowGroups[0].Columns[0].MetaData
+				require.NotNil(t, md.DictionaryPageOffset)
+				modify(md)
+
+				for i := 0; i < 100; i++ {
+					row, err := r.NextRow()
+					require.NoError(t, err)
+					require.Equal(t, []byte(fmt.Sprint(i%7)), row["a"])
+				}
+				_, err = r.NextRow()
+				require.Equal(t, io.EOF, err)
+			})
+		}
+	}
+}
+
+// rewritePages rewrites the pages of all column chunks in the file data. fn can modify the header and the data of
+// each page, the offsets and sizes in the file meta data are updated for the rewritten pages.
+func rewritePages(t *testing.T, data []byte, fn func(col string, ordinal int, ph *parquet.PageHeader, body []byte)) []byte {
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	out.Write(magic)
+	for _, rg := range r.meta.RowGroups {
+		for _, cc := range rg.Columns {
+			md := cc.MetaData
+			start := chunkStart(md)
+			in := bytes.NewReader(data[start : start+md.TotalCompressedSize])
+
+			chunkOffset := int64(out.Len())
+			if md.DictionaryPageOffset != nil {
+				md.DictionaryPageOffset = &chunkOffset
+			}
+			for ordinal := 0; in.Len() > 0; ordinal++ {
+				ph := &parquet.PageHeader{}
+				require.NoError(t, readThrift(ph, in))
+				body := make([]byte, ph.CompressedPageSize)
+				_, err := io.ReadFull(in, body)
+				require.NoError(t, err)
+
+				if ph.Type != parquet.PageType_DICTIONARY_PAGE && md.DataPageOffset < chunkOffset {
+					md.DataPageOffset = int64(out.Len())
+				}
+				fn(strings.Join(md.PathInSchema, "."), ordinal, ph, body)
+				require.NoError(t, writeThrift(ph, out))
+				out.Write(body)
+			}
+			cc.FileOffset = chunkOffset
+			md.TotalCompressedSize = int64(out.Len()) - chunkOffset
+		}
+	}
+
+	footerStart := out.Len()
+	require.NoError(t, writeThrift(r.meta, out))
+	require.NoError(t, binary.Write(out, binary.LittleEndian, int32(out.Len()-footerStart)))
+	out.Write(magic)
+	return out.Bytes()
+}
+
+func TestReadPageCRC(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCRC(false))
+	as, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(as, parquet.FieldRepetitionType_OPTIONAL)))
+	bs, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("b", NewDataColumn(bs, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprint(i % 7)), "b": int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	// writeFile sets the checksum of every page, and flips a bit in the data of the page ordinal of column col after
+	// the checksum is computed.
+	writeFile := func(col string, ordinal int) []byte {
+		return rewritePages(t, buf.Bytes(), func(c string, o int, ph *parquet.PageHeader, body []byte) {
+			crc := int32(crc32.ChecksumIEEE(body))
+			ph.Crc = &crc
+			if c == col && o == ordinal {
+				body[len(body)-1] ^= 0x01
+			}
+		})
+	}
+
+	readFile := func(data []byte, validate bool) ([]map[string]interface{}, error) {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithCRC32Validation(validate))
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				return rows, nil
+			} else if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	rows, err := readFile(writeFile("", 0), true)
+	require.NoError(t, err)
+	require.Len(t, rows, 100)
+	require.Equal(t, map[string]interface{}{"a": []byte("1"), "b": int64(99)}, rows[99])
+
+	// the last value of column b is corrupted, which is only noticed with the validation
+	data := writeFile("b", 0)
+	rows, err = readFile(data, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": []byte("1"), "b": int64(99) ^ 1<<56}, rows[99])
+
+	_, err = readFile(data, true)
+	require.Equal(t, ErrPageChecksum, errors.Cause(err))
+	require.EqualError(t, err, `reading row 0 failed: reading column "b" failed: page 0: page checksum mismatch`)
+
+	// page 0 of column a is the dictionary page
+	_, err = readFile(writeFile("a", 1), true)
+	require.Equal(t, ErrPageChecksum, errors.Cause(err))
+	require.EqualError(t, err, `reading row 0 failed: reading column "a" failed: page 1: page checksum mismatch`)
+
+	// pages without checksum are not validated
+	rows, err = readFile(buf.Bytes(), true)
+	require.NoError(t, err)
+	require.Len(t, rows, 100)
+}
@@ -1,163 +1,15 @@
 package goparquet
 
 import (
+	"hash/crc32"
+	"io"
 	"sort"
+	"sync"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/pkg/errors"
 )
 
-func getBooleanValuesEncoder(pageEncoding parquet.Encoding, store *dictStore) (valuesEncoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &booleanPlainEncoder{}, nil
-	case parquet.Encoding_RLE:
-		return &booleanRLEEncoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictEncoder{dictStore: *store}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for boolean", pageEncoding)
-	}
-}
-
-func getByteArrayValuesEncoder(pageEncoding parquet.Encoding, store *dictStore) (valuesEncoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &byteArrayPlainEncoder{}, nil
-	case parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY:
-		return &byteArrayDeltaLengthEncoder{}, nil
-	case parquet.Encoding_DELTA_BYTE_ARRAY:
-		return &byteArrayDeltaEncoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictEncoder{dictStore: *store}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for binary", pageEncoding)
-	}
-}
-
-func getFixedLenByteArrayValuesEncoder(pageEncoding parquet.Encoding, len int, store *dictStore) (valuesEncoder, error) {
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &byteArrayPlainEncoder{length: len}, nil
-	case parquet.Encoding_DELTA_BYTE_ARRAY:
-		return &byteArrayDeltaEncoder{}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictEncoder{dictStore: *store}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for fixed_len_byte_array(%d)", pageEncoding, len)
-	}
-}
-
-func getInt32ValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, store *dictStore) (valuesEncoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &int32PlainEncoder{unSigned: unSigned}, nil
-	case parquet.Encoding_DELTA_BINARY_PACKED:
-		return &int32DeltaBPEncoder{unSigned: unSigned}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictEncoder{
-			dictStore: *store,
-		}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for int32", pageEncoding)
-	}
-}
-
-func getInt64ValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, store *dictStore) (valuesEncoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
-	switch pageEncoding {
-	case parquet.Encoding_PLAIN:
-		return &int64PlainEncoder{unSigned: unSigned}, nil
-	case parquet.Encoding_DELTA_BINARY_PACKED:
-		return &int64DeltaBPEncoder{unSigned: unSigned}, nil
-	case parquet.Encoding_RLE_DICTIONARY:
-		return &dictEncoder{
-			dictStore: *store,
-		}, nil
-	default:
-		return nil, errors.Errorf("unsupported encoding %s for int64", pageEncoding)
-	}
-}
-
-func getValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, store *dictStore) (valuesEncoder, error) {
-	// Change the deprecated value
-	if pageEncoding == parquet.Encoding_PLAIN_DICTIONARY {
-		pageEncoding = parquet.Encoding_RLE_DICTIONARY
-	}
-
-	switch *typ.Type {
-	case parquet.Type_BOOLEAN:
-		return getBooleanValuesEncoder(pageEncoding, store)
-
-	case parquet.Type_BYTE_ARRAY:
-		return getByteArrayValuesEncoder(pageEncoding, store)
-
-	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
-		if typ.TypeLength == nil {
-			return nil, errors.Errorf("type %s with nil type len", typ.Type)
-		}
-		return getFixedLenByteArrayValuesEncoder(pageEncoding, int(*typ.TypeLength), store)
-
-	case parquet.Type_FLOAT:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &floatPlainEncoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictEncoder{
-				dictStore: *store,
-			}, nil
-		}
-
-	case parquet.Type_DOUBLE:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &doublePlainEncoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictEncoder{
-				dictStore: *store,
-			}, nil
-		}
-
-	case parquet.Type_INT32:
-		return getInt32ValuesEncoder(pageEncoding, typ, store)
-
-	case parquet.Type_INT64:
-		return getInt64ValuesEncoder(pageEncoding, typ, store)
-
-	case parquet.Type_INT96:
-		switch pageEncoding {
-		case parquet.Encoding_PLAIN:
-			return &int96PlainEncoder{}, nil
-		case parquet.Encoding_RLE_DICTIONARY:
-			return &dictEncoder{
-				dictStore: *store,
-			}, nil
-		}
-
-	default:
-		return nil, errors.Errorf("unsupported type: %s", typ.Type)
-	}
-
-	return nil, errors.Errorf("unsupported encoding %s for %s type", pageEncoding, typ.Type)
-}
-
 func getDictValuesEncoder(typ *parquet.SchemaElement) (valuesEncoder, error) {
 	switch *typ.Type {
 	case parquet.Type_BYTE_ARRAY:
@@ -172,27 +24,9 @@ func getDictValuesEncoder(typ *parquet.SchemaElement) (valuesEncoder, error) {
 	case parquet.Type_DOUBLE:
 		return &doublePlainEncoder{}, nil
 	case parquet.Type_INT32:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int32PlainEncoder{unSigned: unSigned}, nil
+		return &int32PlainEncoder{}, nil
 	case parquet.Type_INT64:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int64PlainEncoder{unSigned: unSigned}, nil
+		return &int64PlainEncoder{}, nil
 	case parquet.Type_INT96:
 		return &int96PlainEncoder{}, nil
 	}
@@ -200,12 +34,11 @@ func getDictValuesEncoder(typ *parquet.SchemaElement) (valuesEncoder, error) {
 	return nil, errors.Errorf("type %s is not supported for dict value encoder", typ)
 }
 
-func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.CompressionCodec, pageFn newDataPageFunc, kvMetaData map[string]string) (*parquet.ColumnChunk, error) {
+func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.CompressionCodec, compressor BlockCompressor, pageFn newDataPageFunc, maxDictSize, maxPageSize int64, maxPageRows int, enableCRC bool, maxStatsSize int, kvMetaData map[string]string, pool *compressPool) (*parquet.ColumnChunk, *pageIndex, error) {
 	pos := w.Pos() // Save the position before writing data
 	chunkOffset := pos
 	var (
 		dictPageOffset *int64
-		useDict        bool
 		// NOTE :
 		// This is documentation on these two field :
 		//  - TotalUncompressedSize: total byte size of all uncompressed pages in this column chunk (including the headers) *
@@ -215,49 +48,113 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		totalComp   int64
 		totalUnComp int64
 	)
+	dictPage, plainPage := pageFn(true), pageFn(false)
+	for _, page := range []pageWriter{dictPage, plainPage} {
+		if err := page.init(schema, col, codec, compressor, enableCRC, maxStatsSize); err != nil {
+			return nil, nil, err
+		}
+	}
+	dictEnc, _ := dictPage.dictEncodings()
+
+	var (
+		dictPages []*encodedPage
+		dictLen   int
+		start     pageRange
+	)
 	if col.data.useDictionary() {
-		useDict = true
+		var err error
+		if dictPages, dictLen, start, err = encodeDictionaryPages(dictPage, col, maxDictSize, maxPageSize, maxPageRows); err != nil {
+			return nil, nil, errors.Wrapf(err, "writing data page of column %q failed", col.FlatName())
+		}
+	}
+
+	// the levels are always RLE encoded
+	encodings := &chunkEncodings{encodings: []parquet.Encoding{parquet.Encoding_RLE}}
+	if len(dictPages) > 0 {
 		tmp := pos // make a copy, do not use the pos here
 		dictPageOffset = &tmp
-		dict := &dictPageWriter{}
-		if err := dict.init(schema, col, codec); err != nil {
-			return nil, err
+		dict := &dictPageWriter{enc: dictEnc, values: col.data.values.values[:dictLen]}
+		if err := dict.init(schema, col, codec, compressor, enableCRC, maxStatsSize); err != nil {
+			return nil, nil, err
 		}
 		compSize, unCompSize, err := dict.write(w)
 		if err != nil {
-			return nil, err
+			return nil, nil, errors.Wrapf(err, "writing dictionary page of column %q failed", col.FlatName())
 		}
 		totalComp = w.Pos() - pos
 		// Header size plus the rLevel and dLevel size
 		headerSize := totalComp - int64(compSize)
 		totalUnComp = int64(unCompSize) + headerSize
 		pos = w.Pos() // Move position for data pos
+		encodings.add(parquet.PageType_DICTIONARY_PAGE, dictEnc)
 	}
 
-	page := pageFn(useDict)
+	index := newPageIndex()
+	pageStats := newChunkStatistics(col)
+	// writePage writes the pages in the order they were encoded, after they are compressed
+	writePage := func(ep *encodedPage) error {
+		<-ep.done
+		if ep.err != nil {
+			return errors.Wrapf(ep.err, "writing data page of column %q failed", col.FlatName())
+		}
+
+		start := w.Pos()
+		if err := ep.write(w); err != nil {
+			return errors.Wrapf(err, "writing data page of column %q failed", col.FlatName())
+		}
 
-	if err := page.init(schema, col, codec); err != nil {
-		return nil, err
+		written := w.Pos() - start
+		index.addPage(start, written, ep.p, ep.stats)
+		pageStats.add(ep.p, ep.stats)
+		encodings.add(ep.header.Type, ep.encoding())
+		totalComp += written
+		// Header size plus the rLevel and dLevel size
+		totalUnComp += int64(ep.header.UncompressedPageSize) + written - int64(ep.header.CompressedPageSize)
+		return nil
 	}
 
-	compSize, unCompSize, err := page.write(w)
-	if err != nil {
-		return nil, err
+	var pending []*encodedPage
+	// compressPage compresses the page ep of the page writer page, and writes the pages before it that are compressed
+	compressPage := func(page pageWriter, ep *encodedPage) error {
+		pool.compress(page, ep)
+		pending = append(pending, ep)
+		// the number of pages that wait for their compression is limited, so is the memory they use
+		for len(pending) > pool.size() {
+			if err := writePage(pending[0]); err != nil {
+				return err
+			}
+			pending = pending[1:]
+		}
+		return nil
 	}
 
-	totalComp += w.Pos() - pos
-	// Header size plus the rLevel and dLevel size
-	headerSize := totalComp - int64(compSize)
-	totalUnComp += int64(unCompSize) + headerSize
+	for _, ep := range dictPages {
+		if err := compressPage(dictPage, ep); err != nil {
+			return nil, nil, err
+		}
+	}
+	// the pages after the dictionary pages use the encoding of the column. A column chunk without a dictionary has at
+	// least one data page, even if the column has no values.
+	levels := col.data.levelCount()
+	for p := start; len(dictPages) == 0 || p.levelEnd < levels; p = p.next() {
+		ep, err := plainPage.encode(p, maxPageSize, maxPageRows)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "writing data page of column %q failed", col.FlatName())
+		}
+		p = ep.p
 
-	encodings := make([]parquet.Encoding, 0, 3)
-	encodings = append(encodings,
-		parquet.Encoding_RLE,
-		col.data.encoding(),
-	)
-	if useDict {
-		encodings[1] = parquet.Encoding_PLAIN // In dictionary we use PLAIN for the data, not the column encoding
-		encodings = append(encodings, parquet.Encoding_RLE_DICTIONARY)
+		if err := compressPage(plainPage, ep); err != nil {
+			return nil, nil, err
+		}
+
+		if p.levelEnd >= levels {
+			break
+		}
+	}
+	for _, ep := range pending {
+		if err := writePage(ep); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	keyValueMetaData := make([]*parquet.KeyValue, 0, len(kvMetaData))
@@ -269,22 +166,12 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		return keyValueMetaData[i].Key < keyValueMetaData[j].Key
 	})
 
-	nullCount := int64(col.data.values.nullValueCount())
-	distinctCount := int64(col.data.values.numDistinctValues())
-
-	stats := &parquet.Statistics{
-		MinValue:      col.data.minValue(),
-		MaxValue:      col.data.maxValue(),
-		NullCount:     &nullCount,
-		DistinctCount: &distinctCount,
-	}
-
 	ch := &parquet.ColumnChunk{
 		FilePath:   nil, // No support for external
 		FileOffset: chunkOffset,
 		MetaData: &parquet.ColumnMetaData{
 			Type:                  col.data.parquetType(),
-			Encodings:             encodings,
+			Encodings:             encodings.encodings,
 			PathInSchema:          col.pathArray(),
 			Codec:                 codec,
 			NumValues:             int64(col.data.values.numValues() + col.data.values.nullValueCount()),
@@ -294,8 +181,8 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 			DataPageOffset:        pos,
 			IndexPageOffset:       nil,
 			DictionaryPageOffset:  dictPageOffset,
-			Statistics:            stats,
-			EncodingStats:         nil,
+			Statistics:            columnStatistics(col, pageStats),
+			EncodingStats:         encodings.stats,
 		},
 		OffsetIndexOffset: nil,
 		OffsetIndexLength: nil,
@@ -303,20 +190,326 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		ColumnIndexLength: nil,
 	}
 
-	return ch, nil
+	index.chunk = ch
+	index.setBoundaryOrder(col.Element())
+
+	return ch, index, nil
+}
+
+// encodeDictionaryPages encodes the pages of col with the dictionary encoding of the page writer page, until the
+// dictionary values that the pages use are larger than maxDictSize bytes. The dictionary size is checked after each
+// page, like parquet-mr does, and the page that makes the dictionary too large is dropped. It returns the encoded
+// pages, the number of dictionary values they use, which are the first values of the dictionary, and the empty range
+// after the last page, where the pages with the encoding of the column start. A maxDictSize of zero or less means
+// there is no limit.
+func encodeDictionaryPages(page pageWriter, col *Column, maxDictSize, maxPageSize int64, maxPageRows int) ([]*encodedPage, int, pageRange, error) {
+	var (
+		pages    []*encodedPage
+		dictLen  int
+		dictSize int64
+		start    pageRange
+		values   = col.data.values
+		levels   = col.data.levelCount()
+	)
+	for start.levelEnd < levels {
+		ep, err := page.encode(start, maxPageSize, maxPageRows)
+		if err != nil {
+			return nil, 0, start, err
+		}
+
+		// the dictionary has the values in the order they were added, so the values of the page use its first n values
+		n, size := dictLen, dictSize
+		for _, idx := range values.data[ep.p.valueStart:ep.p.valueEnd] {
+			for ; int(idx) >= n; n++ {
+				size += int64(col.data.sizeOf(values.values[n]))
+			}
+		}
+		if maxDictSize > 0 && size > maxDictSize {
+			break
+		}
+
+		pages = append(pages, ep)
+		dictLen, dictSize = n, size
+		start = ep.p.next()
+	}
+	return pages, dictLen, start, nil
+}
+
+// encodedPage is a data page that is encoded, and compressed once done is closed.
+type encodedPage struct {
+	p      pageRange
+	header *parquet.PageHeader
+	// stats are the statistics of the page, they are in the header as well.
+	stats *parquet.Statistics
+	// levels are written uncompressed before the compressed data, they are only used by DATA_PAGE_V2 pages.
+	levels []byte
+	data   []byte
+	comp   []byte
+
+	done chan struct{}
+	err  error
+}
+
+func newEncodedPage(p pageRange, header *parquet.PageHeader, stats *parquet.Statistics, levels, data []byte) *encodedPage {
+	return &encodedPage{
+		p:      p,
+		header: header,
+		stats:  stats,
+		levels: levels,
+		data:   data,
+		done:   make(chan struct{}),
+	}
+}
+
+// encoding returns the encoding of the values of the page.
+func (ep *encodedPage) encoding() parquet.Encoding {
+	if ep.header.DataPageHeaderV2 != nil {
+		return ep.header.DataPageHeaderV2.Encoding
+	}
+	return ep.header.DataPageHeader.Encoding
+}
+
+// compress compresses the data of the page and sets the sizes and the checksum of its header.
+func (ep *encodedPage) compress(compressor BlockCompressor, codec parquet.CompressionCodec, enableCRC bool) error {
+	comp, err := compressor.CompressBlock(ep.data)
+	if err != nil {
+		return errors.Wrapf(err, "compressing data failed with %s method", codec)
+	}
+	ep.comp = comp
+	ep.header.CompressedPageSize = int32(len(ep.levels) + len(comp))
+	ep.header.UncompressedPageSize = int32(len(ep.levels) + len(ep.data))
+	if enableCRC {
+		ep.header.Crc = pageChecksum(ep.levels, comp)
+	}
+	return nil
+}
+
+// write writes the header and the data of the compressed page.
+func (ep *encodedPage) write(w io.Writer) error {
+	if err := writeThrift(ep.header, w); err != nil {
+		return err
+	}
+	if err := writeFull(w, ep.levels); err != nil {
+		return err
+	}
+	return writeFull(w, ep.comp)
+}
+
+// compressPool compresses the pages in a fixed number of goroutines. A nil pool compresses them right away.
+type compressPool struct {
+	workers int
+	jobs    chan func()
+	wg      sync.WaitGroup
+}
+
+func newCompressPool(workers int) *compressPool {
+	cp := &compressPool{
+		workers: workers,
+		jobs:    make(chan func()),
+	}
+	for i := 0; i < workers; i++ {
+		cp.wg.Add(1)
+		go func() {
+			defer cp.wg.Done()
+			for job := range cp.jobs {
+				job()
+			}
+		}()
+	}
+	return cp
+}
+
+// compress compresses the page ep of the page writer, and closes ep.done when it is done.
+func (cp *compressPool) compress(page pageWriter, ep *encodedPage) {
+	job := func() {
+		ep.err = page.compress(ep)
+		close(ep.done)
+	}
+	if cp == nil {
+		job()
+		return
+	}
+	cp.jobs <- job
+}
+
+// size returns the number of pages that can be compressed at the same time.
+func (cp *compressPool) size() int {
+	if cp == nil {
+		return 0
+	}
+	return cp.workers
 }
 
-func writeRowGroup(w writePos, schema SchemaWriter, codec parquet.CompressionCodec, pageFn newDataPageFunc, h *flushRowGroupOptionHandle) ([]*parquet.ColumnChunk, error) {
+// close waits until the pages are compressed and stops the goroutines.
+func (cp *compressPool) close() {
+	if cp == nil {
+		return
+	}
+	close(cp.jobs)
+	cp.wg.Wait()
+}
+
+// chunkEncodings collects the encodings of the pages of a column chunk, and the number of pages of each page type
+// with each encoding.
+type chunkEncodings struct {
+	encodings []parquet.Encoding
+	stats     []*parquet.PageEncodingStats
+}
+
+func (ce *chunkEncodings) add(typ parquet.PageType, enc parquet.Encoding) {
+	found := false
+	for _, e := range ce.encodings {
+		if e == enc {
+			found = true
+			break
+		}
+	}
+	if !found {
+		ce.encodings = append(ce.encodings, enc)
+	}
+
+	for _, s := range ce.stats {
+		if s.PageType == typ && s.Encoding == enc {
+			s.Count++
+			return
+		}
+	}
+	ce.stats = append(ce.stats, &parquet.PageEncodingStats{PageType: typ, Encoding: enc, Count: 1})
+}
+
+// pageRange is the part of a column chunk that is written into a data page. The levels are the positions in the
+// repetition and definition levels, the values are the positions of the values that are not null.
+type pageRange struct {
+	levelStart, levelEnd int
+	valueStart, valueEnd int
+	rows                 int
+}
+
+// next returns the empty range that starts after p.
+func (p pageRange) next() pageRange {
+	return pageRange{levelStart: p.levelEnd, levelEnd: p.levelEnd, valueStart: p.valueEnd, valueEnd: p.valueEnd}
+}
+
+func (p pageRange) numValues() int32 {
+	return int32(p.levelEnd - p.levelStart)
+}
+
+func (p pageRange) numNulls() int32 {
+	return int32(p.levelEnd - p.levelStart - (p.valueEnd - p.valueStart))
+}
+
+// minPageSizeCheck is the minimum number of levels between two checks of the page size.
+const minPageSizeCheck = 100
+
+// encodePageValues encodes the rows of col from the start of p into w, until the estimated size of the values and
+// the levels reaches maxPageSize, or until the page has maxPageRows rows. The size is checked after batches of rows,
+// and the next check is estimated from the size per level so far, like parquet-mr does. It returns p with the end of
+// the encoded rows, which is at least one row unless the column has no levels. A maxPageSize and a maxPageRows of 0
+// or less encode all remaining rows.
+func encodePageValues(w io.Writer, enc valuesEncoder, col *Column, p pageRange, maxPageSize int64, maxPageRows int) (pageRange, error) {
+	if err := enc.init(w); err != nil {
+		return p, err
+	}
+
+	var (
+		levels     = col.data.levelCount()
+		rLevels    = col.data.rLevels
+		dLevels    = col.data.dLevels
+		maxD       = int32(col.MaxDefinitionLevel())
+		levelBits  = int64(rLevels.bw + dLevels.bw)
+		nextCheck  = p.levelEnd + minPageSizeCheck
+		batchStart = p.valueEnd
+		scratch    []interface{}
+		err        error
+	)
+	for p.levelEnd < levels {
+		// the row ends before the next repetition level of 0
+		end := p.levelEnd + 1
+		if rLevels.bw > 0 {
+			for ; end < levels; end++ {
+				if rl, _ := rLevels.at(end); rl == 0 {
+					break
+				}
+			}
+		}
+		if dLevels.bw == 0 {
+			p.valueEnd += end - p.levelEnd
+		} else {
+			for i := p.levelEnd; i < end; i++ {
+				if dl, _ := dLevels.at(i); dl == maxD {
+					p.valueEnd++
+				}
+			}
+		}
+		p.levelEnd = end
+		p.rows++
+
+		if maxPageRows > 0 && p.rows >= maxPageRows {
+			break
+		}
+		if maxPageSize <= 0 || (p.levelEnd < nextCheck && p.levelEnd < levels) {
+			continue
+		}
+		if scratch, err = encodeStoreValues(enc, col.data.values, batchStart, p.valueEnd, scratch); err != nil {
+			return p, err
+		}
+		batchStart = p.valueEnd
+
+		size := int64(enc.estimatedSize()) + (int64(p.levelEnd-p.levelStart)*levelBits+7)/8
+		if size >= maxPageSize {
+			break
+		}
+		// check again when the page is expected to be half way to the maximum size
+		perLevel := size/int64(p.levelEnd-p.levelStart) + 1
+		nextCheck = p.levelEnd + int((maxPageSize-size)/perLevel/2)
+		if nextCheck < p.levelEnd+minPageSizeCheck {
+			nextCheck = p.levelEnd + minPageSizeCheck
+		}
+	}
+
+	if _, err = encodeStoreValues(enc, col.data.values, batchStart, p.valueEnd, scratch); err != nil {
+		return p, err
+	}
+	return p, enc.Close()
+}
+
+// pageChecksum returns the CRC32 of the page data for the page header, the data is the concatenation of the parts.
+func pageChecksum(parts ...[]byte) *int32 {
+	h := crc32.NewIEEE()
+	for _, p := range parts {
+		_, _ = h.Write(p)
+	}
+	crc := int32(h.Sum32())
+	return &crc
+}
+
+// columnStatistics returns the statistics of the column chunk of col, from the statistics of its data pages.
+func columnStatistics(col *Column, pages *chunkStatistics) *parquet.Statistics {
+	stats := pages.statistics()
+	// the distinct values are only known if the values went through the dictionary store
+	if col.data.values.typed == nil && !col.data.values.noIndex {
+		distinctCount := int64(col.data.values.numDistinctValues())
+		stats.DistinctCount = &distinctCount
+	}
+	return stats
+}
+
+func writeRowGroup(w writePos, schema SchemaWriter, compressorFn func(col string) (parquet.CompressionCodec, BlockCompressor), pageFn func(col string) newDataPageFunc, maxDictSize, maxPageSize int64, maxPageRows int, enableCRC bool, maxStatsSize int, h *flushRowGroupOptionHandle, pool *compressPool) ([]*parquet.ColumnChunk, []*pageIndex, error) {
 	dataCols := schema.Columns()
-	var res = make([]*parquet.ColumnChunk, 0, len(dataCols))
+	var (
+		res     = make([]*parquet.ColumnChunk, 0, len(dataCols))
+		indexes = make([]*pageIndex, 0, len(dataCols))
+	)
 	for _, ci := range dataCols {
-		ch, err := writeChunk(w, schema, ci, codec, pageFn, h.getMetaData(ci.FlatName()))
+		codec, compressor := compressorFn(ci.FlatName())
+		ch, index, err := writeChunk(w, schema, ci, codec, compressor, pageFn(ci.FlatName()), maxDictSize, maxPageSize, maxPageRows, enableCRC, maxStatsSize, h.getMetaData(ci.FlatName()), pool)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		res = append(res, ch)
+		indexes = append(indexes, index)
 	}
 
-	return res, nil
+	return res, indexes, nil
 }
@@ -0,0 +1,182 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// ColumnIndex is the column index of a column chunk. It has the min and max value of every data page of the chunk,
+// which allows to skip the pages that can't contain the values that are searched for.
+type ColumnIndex struct {
+	// NullPages is true for the pages that have only null values. They have no min and max value.
+	NullPages []bool
+	// MinValues and MaxValues are the min and max values of the pages, with the same Go types as the values that are
+	// read from the column, e.g. int64 for INT64 and []byte for BYTE_ARRAY columns. They are nil for null pages. The
+	// values of DATE and TIMESTAMP columns are not converted to a time.Time, see ColumnStatistics.MinValue.
+	MinValues []interface{}
+	MaxValues []interface{}
+	// NullCounts are the number of null values of the pages, it is nil if the writer didn't write them.
+	NullCounts []int64
+	// BoundaryOrder tells whether the min and max values are sorted from page to page.
+	BoundaryOrder parquet.BoundaryOrder
+	// FirstRows are the indexes of the first row of the pages in the row group, if the column chunk has an offset
+	// index, or nil. The rows of page i end before the first row of page i+1, the last page ends with the row group.
+	// The rows of the pages that can't be skipped can be read with ReadColumnRows.
+	FirstRows []int64
+
+	order sortOrder
+}
+
+// ColumnIndex returns the column index of the column chunk of the column colName in the row group with the index
+// rowGroup. It returns nil if the column chunk has no column index.
+func (f *FileReader) ColumnIndex(rowGroup int, colName string) (*ColumnIndex, error) {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := rg.Columns[col.Index()]
+	r := f.sectionReader()
+	index, err := readColumnIndex(r, chunk)
+	if err != nil || index == nil {
+		return nil, err
+	}
+
+	ci, err := newColumnIndex(col.Element(), index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid column index of column %q", colName)
+	}
+
+	offsetIndex, err := readOffsetIndex(r, chunk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading column %q failed", colName)
+	}
+	if offsetIndex != nil && len(offsetIndex.PageLocations) == len(ci.NullPages) {
+		for _, loc := range offsetIndex.PageLocations {
+			ci.FirstRows = append(ci.FirstRows, loc.FirstRowIndex)
+		}
+	}
+
+	return ci, nil
+}
+
+// readColumnIndex reads the column index of the column chunk, it returns nil if the chunk has no column index.
+func readColumnIndex(r io.ReadSeeker, chunk *parquet.ColumnChunk) (*parquet.ColumnIndex, error) {
+	if chunk.ColumnIndexOffset == nil || chunk.ColumnIndexLength == nil {
+		return nil, nil
+	}
+
+	size := chunk.GetColumnIndexLength()
+	if size <= 0 {
+		return nil, errors.Errorf("invalid column index length %d", size)
+	}
+	if _, err := r.Seek(chunk.GetColumnIndexOffset(), io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.Wrap(err, "reading column index failed")
+	}
+
+	index := &parquet.ColumnIndex{}
+	if err := readThrift(index, bytes.NewReader(buf)); err != nil {
+		return nil, errors.Wrap(err, "reading column index failed")
+	}
+	return index, nil
+}
+
+// newColumnIndex decodes the min and max values of the column index of a column chunk of the column elem.
+func newColumnIndex(elem *parquet.SchemaElement, index *parquet.ColumnIndex) (*ColumnIndex, error) {
+	n := len(index.NullPages)
+	if len(index.MinValues) != n || len(index.MaxValues) != n {
+		return nil, errors.Errorf("%d null pages, but %d min and %d max values", n, len(index.MinValues), len(index.MaxValues))
+	}
+	if index.NullCounts != nil && len(index.NullCounts) != n {
+		return nil, errors.Errorf("%d null pages, but %d null counts", n, len(index.NullCounts))
+	}
+
+	ci := &ColumnIndex{
+		NullPages:     index.NullPages,
+		MinValues:     make([]interface{}, n),
+		MaxValues:     make([]interface{}, n),
+		NullCounts:    index.NullCounts,
+		BoundaryOrder: index.BoundaryOrder,
+		order:         columnSortOrder(elem),
+	}
+	for i, null := range index.NullPages {
+		if null {
+			continue
+		}
+		var err error
+		if ci.MinValues[i], err = decodeStatValue(elem, index.MinValues[i]); err != nil {
+			return nil, errors.Wrapf(err, "min value of page %d", i)
+		}
+		if ci.MaxValues[i], err = decodeStatValue(elem, index.MaxValues[i]); err != nil {
+			return nil, errors.Wrapf(err, "max value of page %d", i)
+		}
+	}
+
+	return ci, nil
+}
+
+// decodeStatValue decodes a PLAIN encoded min or max value of the column elem. Byte arrays are stored without their
+// length, they are returned as they are.
+func decodeStatValue(elem *parquet.SchemaElement, b []byte) (interface{}, error) {
+	switch elem.GetType() {
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return b, nil
+	}
+
+	dec, err := getValuesDecoder(parquet.Encoding_PLAIN, elem, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.init(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 1)
+	if n, err := dec.decodeValues(values); err != nil || n != 1 {
+		return nil, errors.Errorf("invalid value %x", b)
+	}
+	return values[0], nil
+}
+
+// PagesNotBetween returns the ordinals of the pages that have no value between min and max (inclusive), which can be
+// skipped when searching for these values. The pages with only null values are always returned. min and max must
+// have the Go type of the column values. If the sort order of the column is undefined, only the null pages are
+// returned.
+func (ci *ColumnIndex) PagesNotBetween(min, max interface{}) ([]int, error) {
+	for i, null := range ci.NullPages {
+		if null {
+			continue
+		}
+		if err := checkValueTypes(ci.MinValues[i], min, max); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	var pages []int
+	for i, null := range ci.NullPages {
+		if null || (ci.order != sortOrderUndefined &&
+			(compareValues(ci.MaxValues[i], min, ci.order) < 0 || compareValues(ci.MinValues[i], max, ci.order) > 0)) {
+			pages = append(pages, i)
+		}
+	}
+	return pages, nil
+}
+
+// checkValueTypes checks that the values have the same type as the column value v.
+func checkValueTypes(v interface{}, values ...interface{}) error {
+	want := fmt.Sprintf("%T", v)
+	for _, value := range values {
+		if fmt.Sprintf("%T", value) != want {
+			return errors.Errorf("the value %v is of type %T, but the column values are of type %s", value, value, want)
+		}
+	}
+	return nil
+}
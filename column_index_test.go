@@ -0,0 +1,166 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadColumnIndex(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			required binary name (STRING);
+			optional float score;
+			required int96 ts;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageSize(256), WithMaxDictionarySize(1))
+	for i := 0; i < 1000; i++ {
+		row := map[string]interface{}{
+			"id":   int64(i),
+			"name": []byte(fmt.Sprintf("name %04d", 1000-i)),
+			"ts":   [12]byte{byte(i)},
+		}
+		// the scores increase, then decrease, and the last rows have no score
+		switch {
+		case i < 400:
+			row["score"] = float32(i)
+		case i < 800:
+			row["score"] = float32(1000 - i)
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	ci, err := r.ColumnIndex(0, "id")
+	require.NoError(t, err)
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, ci.BoundaryOrder)
+	require.True(t, len(ci.NullPages) > 5, "%d pages", len(ci.NullPages))
+	require.Len(t, ci.FirstRows, len(ci.NullPages))
+	require.Equal(t, int64(0), ci.MinValues[0])
+	require.Equal(t, int64(999), ci.MaxValues[len(ci.MaxValues)-1])
+	for i := range ci.NullPages {
+		require.False(t, ci.NullPages[i])
+		require.Equal(t, int64(0), ci.NullCounts[i])
+		require.Equal(t, ci.FirstRows[i], ci.MinValues[i])
+	}
+
+	// the pages that are not skipped have all the rows with the values
+	skipped, err := ci.PagesNotBetween(int64(500), int64(510))
+	require.NoError(t, err)
+	require.Len(t, skipped, len(ci.NullPages)-1)
+	var read []interface{}
+	for page := range ci.NullPages {
+		if len(skipped) > 0 && skipped[0] == page {
+			skipped = skipped[1:]
+			continue
+		}
+		end := int64(1000)
+		if page+1 < len(ci.FirstRows) {
+			end = ci.FirstRows[page+1]
+		}
+		rows, err := r.ReadColumnRows(0, "id", ci.FirstRows[page], end)
+		require.NoError(t, err)
+		read = append(read, rows...)
+	}
+	require.Contains(t, read, int64(500))
+	require.Contains(t, read, int64(510))
+
+	_, err = ci.PagesNotBetween(int32(500), int32(510))
+	require.EqualError(t, err, "the value 500 is of type int32, but the column values are of type int64")
+
+	ci, err = r.ColumnIndex(0, "name")
+	require.NoError(t, err)
+	require.Equal(t, parquet.BoundaryOrder_DESCENDING, ci.BoundaryOrder)
+	require.Equal(t, []byte("name 1000"), ci.MaxValues[0])
+	skipped, err = ci.PagesNotBetween([]byte("name 0001"), []byte("name 0001"))
+	require.NoError(t, err)
+	require.Len(t, skipped, len(ci.NullPages)-1)
+	require.NotContains(t, skipped, len(ci.NullPages)-1)
+
+	ci, err = r.ColumnIndex(0, "score")
+	require.NoError(t, err)
+	require.Equal(t, parquet.BoundaryOrder_UNORDERED, ci.BoundaryOrder)
+	last := len(ci.NullPages) - 1
+	require.True(t, ci.NullPages[last])
+	require.Nil(t, ci.MinValues[last])
+	skipped, err = ci.PagesNotBetween(float32(2000), float32(3000))
+	require.NoError(t, err)
+	require.Equal(t, len(ci.NullPages), len(skipped))
+	skipped, err = ci.PagesNotBetween(float32(50), float32(50))
+	require.NoError(t, err)
+	require.Contains(t, skipped, last)
+	require.NotContains(t, skipped, 0)
+
+	ci, err = r.ColumnIndex(0, "ts")
+	require.NoError(t, err)
+	require.Nil(t, ci)
+}
+
+func TestColumnIndexGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library, in the layout of
+	// parquet-mr 1.11 and later: the column indexes of both column chunks and then their offset indexes follow the
+	// row group. The second page of the name column has only null values and empty min and max values.
+	data, err := ioutil.ReadFile("testdata/column_index.parquet")
+	require.NoError(t, err)
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	ci, err := r.ColumnIndex(0, "ts")
+	require.NoError(t, err)
+	require.Equal(t, &ColumnIndex{
+		NullPages:     []bool{false, false, false, false},
+		MinValues:     []interface{}{int64(1600000000000), int64(1600000100000), int64(1600000200000), int64(1600000300000)},
+		MaxValues:     []interface{}{int64(1600000099000), int64(1600000199000), int64(1600000299000), int64(1600000399000)},
+		NullCounts:    []int64{0, 0, 0, 0},
+		BoundaryOrder: parquet.BoundaryOrder_ASCENDING,
+		FirstRows:     []int64{0, 100, 200, 300},
+		order:         sortOrderSigned,
+	}, ci)
+	skipped, err := ci.PagesNotBetween(int64(1600000150000), int64(1600000250000))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 3}, skipped)
+
+	rows, err := r.ReadColumnRows(0, "ts", ci.FirstRows[2], ci.FirstRows[3])
+	require.NoError(t, err)
+	require.Len(t, rows, 100)
+	for i, v := range rows {
+		require.Equal(t, int64(1600000000000+(200+i)*1000), v)
+	}
+
+	ci, err = r.ColumnIndex(0, "name")
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true, false, false}, ci.NullPages)
+	require.Equal(t, []int64{10, 100, 10, 10}, ci.NullCounts)
+	require.Equal(t, parquet.BoundaryOrder_UNORDERED, ci.BoundaryOrder)
+	require.Equal(t, []int64{0, 100, 200, 300}, ci.FirstRows)
+	require.Nil(t, ci.MinValues[1])
+	require.Nil(t, ci.MaxValues[1])
+	for _, page := range []int{0, 2, 3} {
+		require.Equal(t, []byte("n000"), ci.MinValues[page])
+		require.Equal(t, []byte("n049"), ci.MaxValues[page])
+	}
+	skipped, err = ci.PagesNotBetween([]byte("n100"), []byte("n200"))
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, skipped)
+	skipped, err = ci.PagesNotBetween([]byte("n010"), []byte("n010"))
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, skipped)
+
+	rows, err = r.ReadColumnRows(0, "name", 95, 105)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{[]byte("n015"), []byte("n022"), []byte("n029"), []byte("n036"), nil,
+		nil, nil, nil, nil, nil}, rows)
+}
@@ -19,11 +19,15 @@ func main() {
 		pq      string
 		version string
 		comp    string
+		dict    int64
+		rows    int
 	)
 	flag.StringVar(&file, "json", "/data.json", "json file to load")
 	flag.StringVar(&pq, "pq", "/data.pq", "pq to save")
 	flag.StringVar(&version, "version", "v1", "Page v1 or Page v2 (v1 / v2)")
 	flag.StringVar(&comp, "compression", "snappy", "compression method, snappy, gzip, none")
+	flag.Int64Var(&dict, "max-dictionary-size", 0, "max dictionary size of the column chunks, 0 for the default")
+	flag.IntVar(&rows, "max-page-rows", 0, "max number of rows of a page, 0 for no limit")
 
 	flag.Parse()
 
@@ -53,6 +57,13 @@ func main() {
 		panic("invalid version: " + version)
 	}
 
+	if dict > 0 {
+		opts = append(opts, goparquet.WithMaxDictionarySize(dict))
+	}
+	if rows > 0 {
+		opts = append(opts, goparquet.WithMaxPageRows(rows))
+	}
+
 	sc, err := parquetschema.ParseSchemaDefinition(schema)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,96 @@
+// +build ignore
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// minimal writes a file with one required INT64 column and one optional BYTE_ARRAY column, and compares the rows
+// that parquet-tools reads from it with the rows that were written.
+
+type row struct {
+	ID   int64   `json:"id"`
+	Name *string `json:"name"`
+}
+
+func main() {
+	var (
+		pq   string
+		rows int
+	)
+	flag.StringVar(&pq, "pq", "/minimal.parquet", "pq to save")
+	flag.IntVar(&rows, "rows", 1000, "number of rows")
+
+	flag.Parse()
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message minimal {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	if err != nil {
+		panic(err)
+	}
+
+	fl, err := os.Create(pq)
+	if err != nil {
+		panic(err)
+	}
+
+	var expected []row
+	writer := goparquet.NewFileWriter(fl, goparquet.WithSchemaDefinition(sd))
+	for i := 0; i < rows; i++ {
+		r := row{ID: int64(i)}
+		data := map[string]interface{}{"id": r.ID}
+		if i%3 != 0 {
+			name := fmt.Sprintf("name %d", i)
+			r.Name = &name
+			data["name"] = []byte(name)
+		}
+		if err := writer.AddData(data); err != nil {
+			panic(err)
+		}
+		expected = append(expected, r)
+	}
+	if err := writer.Close(); err != nil {
+		panic(err)
+	}
+	if err := fl.Close(); err != nil {
+		panic(err)
+	}
+
+	cmd := exec.Command("java", "-jar", "/parquet-tools.jar", "cat", "--json", pq)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		panic(err)
+	}
+
+	var actual []row
+	dec := json.NewDecoder(&out)
+	for {
+		var r row
+		if err := dec.Decode(&r); err != nil {
+			if err == io.EOF {
+				break
+			}
+			panic(err)
+		}
+		actual = append(actual, r)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		panic("not equal")
+	}
+}
@@ -1,5 +1,7 @@
 #!/usr/bin/env bash
 
+set -e
+
 function rebuild_and_compare() {
   comp=$1
   version=$2
@@ -9,6 +11,19 @@ function rebuild_and_compare() {
   /compare -json /data.json -pq /${out}
 }
 
+# The dictionaries of the age and favorite_fruit columns exceed the max size after the first pages, so their column
+# chunks have dictionary encoded pages followed by PLAIN pages. parquet-mr finds the start of a column chunk by its
+# dictionary page offset and data page offset, and meta prints the encodings of the chunks.
+function rebuild_with_fallback_and_compare() {
+  comp=$1
+  version=$2
+  out="out-${comp}-${version}-fallback.parquet"
+
+  /buildfile -compression ${comp} -version ${version} -max-page-rows 4 -max-dictionary-size 16 -json /data.json -pq /${out}
+  /compare -json /data.json -pq /${out}
+  java -jar /parquet-tools.jar meta /${out}
+}
+
 
 # Create file UNCOMPRESSED / V1
 rebuild_and_compare none v1
@@ -18,3 +33,8 @@ rebuild_and_compare gzip v2
 rebuild_and_compare snappy v1
 rebuild_and_compare snappy v2
 
+rebuild_with_fallback_and_compare gzip v1
+rebuild_with_fallback_and_compare gzip v2
+
+# A file with one required INT64 column and one optional BYTE_ARRAY column, read back with parquet-tools
+/minimal -pq /minimal.parquet
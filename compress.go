@@ -3,8 +3,10 @@ package goparquet
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -22,13 +24,58 @@ type (
 	// BlockCompressor is an interface to describe of a block compressor to be used
 	// in compressing the content of parquet files.
 	BlockCompressor interface {
-		CompressBlock([]byte) ([]byte, error)
-		DecompressBlock([]byte) ([]byte, error)
+		CompressBlock(block []byte) ([]byte, error)
+		DecompressBlock(block []byte) ([]byte, error)
+	}
+
+	// SizedDecompressor can be implemented by a BlockCompressor to get the uncompressed size of the block from the
+	// page header. The reader calls DecompressBlockSize instead of DecompressBlock then, the result must have exactly
+	// this size. It can be used to allocate the result and to stop decompressing corrupt data early.
+	SizedDecompressor interface {
+		DecompressBlockSize(block []byte, size int) ([]byte, error)
+	}
+
+	// CompressorOptions are the codec specific options of a block compressor, to tune the compression of the
+	// pages that the FileWriter writes. They are set for the file with WithCompressorOptions and for single
+	// columns with WithColumnCompressionOptions.
+	CompressorOptions struct {
+		// Level is the compression level: the level of compress/gzip for GZIP, the zstd level between 1 and 22 for
+		// ZSTD, and the quality between 0 and 11 for BROTLI. Zero uses the default level of the codec.
+		Level int
+	}
+
+	// ConfigurableBlockCompressor is implemented by the block compressors that can be configured with
+	// CompressorOptions. WithOptions returns a new compressor with the options, the compressor itself is not
+	// changed.
+	ConfigurableBlockCompressor interface {
+		BlockCompressor
+		WithOptions(opts CompressorOptions) (BlockCompressor, error)
+	}
+
+	// bufferDecompressor is implemented by the built-in compressors, they decompress into dst, a buffer of the page
+	// buffer pool with the uncompressed size as its length. The result is a slice of dst, unless the data is larger.
+	bufferDecompressor interface {
+		decompressBlockTo(dst, block []byte) ([]byte, error)
+	}
+
+	// blockReader reads the decompressed data of a page. If buf is set, the data is in a buffer of the page buffer
+	// pool, and release returns it once all values of the page are decoded. The values decoders only read the page
+	// through the io.Reader, they copy the data, so no decoded value refers to the buffer.
+	blockReader struct {
+		bytes.Reader
+		buf []byte
 	}
 
 	plainCompressor  struct{}
 	snappyCompressor struct{}
-	gzipCompressor   struct{}
+
+	// gzipCompressor keeps the gzip readers and writers in pools, to not allocate the inflate and deflate state for
+	// every page.
+	gzipCompressor struct {
+		level   int
+		readers sync.Pool
+		writers sync.Pool
+	}
 )
 
 func (plainCompressor) CompressBlock(block []byte) ([]byte, error) {
@@ -39,6 +86,10 @@ func (plainCompressor) DecompressBlock(block []byte) ([]byte, error) {
 	return block, nil
 }
 
+func (plainCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	return append(dst[:0], block...), nil
+}
+
 func (snappyCompressor) CompressBlock(block []byte) ([]byte, error) {
 	return snappy.Encode(nil, block), nil
 }
@@ -47,9 +98,40 @@ func (snappyCompressor) DecompressBlock(block []byte) ([]byte, error) {
 	return snappy.Decode(nil, block)
 }
 
-func (gzipCompressor) CompressBlock(block []byte) ([]byte, error) {
+func (snappyCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	return snappy.Decode(dst[:cap(dst)], block)
+}
+
+// NewGzipCompressor returns a GZIP block compressor that compresses with the given level, one of the levels of the
+// compress/gzip package. Register it with RegisterBlockCompressor to use a different level than the default.
+func NewGzipCompressor(level int) (BlockCompressor, error) {
+	if _, err := gzip.NewWriterLevel(ioutil.Discard, level); err != nil {
+		return nil, err
+	}
+
+	return &gzipCompressor{level: level}, nil
+}
+
+func (g *gzipCompressor) WithOptions(opts CompressorOptions) (BlockCompressor, error) {
+	if opts.Level == 0 {
+		return NewGzipCompressor(gzip.DefaultCompression)
+	}
+	return NewGzipCompressor(opts.Level)
+}
+
+func (g *gzipCompressor) CompressBlock(block []byte) ([]byte, error) {
 	buf := &bytes.Buffer{}
-	w := gzip.NewWriter(buf)
+	w, ok := g.writers.Get().(*gzip.Writer)
+	if ok {
+		w.Reset(buf)
+	} else {
+		var err error
+		if w, err = gzip.NewWriterLevel(buf, g.level); err != nil {
+			return nil, err
+		}
+	}
+	defer g.writers.Put(w)
+
 	if _, err := w.Write(block); err != nil {
 		return nil, err
 	}
@@ -60,12 +142,20 @@ func (gzipCompressor) CompressBlock(block []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func (gzipCompressor) DecompressBlock(block []byte) ([]byte, error) {
-	buf := bytes.NewReader(block)
-	r, err := gzip.NewReader(buf)
+func (g *gzipCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return g.DecompressBlockSize(block, -1)
+}
+
+func (g *gzipCompressor) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	if size >= 0 {
+		return g.decompressBlockTo(make([]byte, size), block)
+	}
+
+	r, err := g.getReader(block)
 	if err != nil {
 		return nil, err
 	}
+	defer g.readers.Put(r)
 
 	ret, err := ioutil.ReadAll(r)
 	if err != nil {
@@ -75,19 +165,90 @@ func (gzipCompressor) DecompressBlock(block []byte) ([]byte, error) {
 	return ret, r.Close()
 }
 
-func compressBlock(block []byte, method parquet.CompressionCodec) ([]byte, error) {
-	compressorLock.RLock()
-	defer compressorLock.RUnlock()
+func (g *gzipCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	r, err := g.getReader(block)
+	if err != nil {
+		return nil, err
+	}
+	defer g.readers.Put(r)
+
+	ret, err := readBlockTo(r, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, r.Close()
+}
+
+func (g *gzipCompressor) getReader(block []byte) (*gzip.Reader, error) {
+	buf := bytes.NewReader(block)
+	r, ok := g.readers.Get().(*gzip.Reader)
+	if !ok {
+		return gzip.NewReader(buf)
+	}
+	if err := r.Reset(buf); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// readBlockTo reads the decompressed data from r into dst. If r has more data than fits into dst, the result has one
+// byte more than dst, so the size check of the caller fails without decompressing all of it.
+func readBlockTo(r io.Reader, dst []byte) ([]byte, error) {
+	n, err := io.ReadFull(r, dst)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return dst[:n], nil
+	} else if err != nil {
+		return nil, err
+	}
 
+	var extra [1]byte
+	if _, err := io.ReadFull(r, extra[:]); err == io.EOF {
+		return dst, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return append(dst, extra[0]), nil
+}
+
+// getBlockCompressor returns the registered compressor of the codec. If opts are set, the compressor is configured
+// with them, which requires it to be a ConfigurableBlockCompressor.
+func getBlockCompressor(method parquet.CompressionCodec, opts CompressorOptions) (BlockCompressor, error) {
+	compressorLock.RLock()
 	c, ok := compressors[method]
+	compressorLock.RUnlock()
 	if !ok {
 		return nil, errors.Errorf("method %q is not supported", method.String())
 	}
 
+	if opts == (CompressorOptions{}) {
+		return c, nil
+	}
+
+	cc, ok := c.(ConfigurableBlockCompressor)
+	if !ok {
+		return nil, errors.Errorf("method %q doesn't support compressor options", method.String())
+	}
+	res, err := cc.WithOptions(opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid compressor options for method %q", method.String())
+	}
+
+	return res, nil
+}
+
+func compressBlock(block []byte, method parquet.CompressionCodec) ([]byte, error) {
+	c, err := getBlockCompressor(method, CompressorOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	return c.CompressBlock(block)
 }
 
-func decompressBlock(block []byte, method parquet.CompressionCodec) ([]byte, error) {
+// decompressBlock decompresses the block, size is the uncompressed size from the page header.
+func decompressBlock(block []byte, method parquet.CompressionCodec, size int) ([]byte, error) {
 	compressorLock.RLock()
 	defer compressorLock.RUnlock()
 
@@ -96,42 +257,149 @@ func decompressBlock(block []byte, method parquet.CompressionCodec) ([]byte, err
 		return nil, errors.Errorf("method %q is not supported", method.String())
 	}
 
+	return decompressWithSize(c, block, size)
+}
+
+// decompressWithSize decompresses the block with c, it passes the uncompressed size to c if it is a
+// SizedDecompressor.
+func decompressWithSize(c BlockCompressor, block []byte, size int) ([]byte, error) {
+	if sd, ok := c.(SizedDecompressor); ok {
+		return sd.DecompressBlockSize(block, size)
+	}
+
 	return c.DecompressBlock(block)
 }
 
-func newBlockReader(in io.Reader, codec parquet.CompressionCodec, compressedSize int32, uncompressedSize int32) (io.Reader, error) {
-	buf, err := ioutil.ReadAll(io.LimitReader(in, int64(compressedSize)))
-	if err != nil {
-		return nil, errors.Wrap(err, "read failed")
+// codecName returns the name of the codec, or its number if the codec is not known to the parquet package.
+func codecName(codec parquet.CompressionCodec) string {
+	if s := codec.String(); s != "<UNSET>" {
+		return s
 	}
+	return fmt.Sprintf("%d", int64(codec))
+}
 
-	if len(buf) != int(compressedSize) {
-		return nil, errors.Errorf("compressed data must be %d byte but its %d byte", compressedSize, len(buf))
+// checkCodecs checks that there is a block compressor for the codec of every column chunk of the row group that is
+// read. The error lists all columns with an unsupported codec.
+func checkCodecs(cols []*Column, rowGroup *parquet.RowGroup, selected func(string) bool) error {
+	compressorLock.RLock()
+	defer compressorLock.RUnlock()
+
+	var msgs []string
+	for _, c := range cols {
+		if c.Index() >= len(rowGroup.Columns) || !selected(c.FlatName()) {
+			continue
+		}
+		meta := rowGroup.Columns[c.Index()].MetaData
+		if meta == nil {
+			continue
+		}
+		if _, ok := compressors[meta.Codec]; !ok {
+			msgs = append(msgs, fmt.Sprintf("column %q: compression codec %s not supported", c.FlatName(), codecName(meta.Codec)))
+		}
+	}
+
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// newBlockReader reads the compressed page data from in and returns a reader of the decompressed data. The built-in
+// compressors decompress into a buffer of the page buffer pool, release the reader once the page is decoded to reuse
+// it for the next page.
+func newBlockReader(in io.Reader, codec parquet.CompressionCodec, compressedSize int32, uncompressedSize int32) (*blockReader, error) {
+	compressorLock.RLock()
+	c, ok := compressors[codec]
+	compressorLock.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("compression codec %s not supported", codecName(codec))
+	}
+
+	bd, ok := c.(bufferDecompressor)
+	if !ok {
+		// the result of a user provided compressor might refer to the compressed data, none of them is pooled.
+		buf, err := ioutil.ReadAll(io.LimitReader(in, int64(compressedSize)))
+		if err != nil {
+			return nil, errors.Wrap(err, "read failed")
+		}
+		if len(buf) != int(compressedSize) {
+			return nil, errors.Errorf("compressed data must be %d byte but its %d byte", compressedSize, len(buf))
+		}
+
+		res, err := decompressWithSize(c, buf, int(uncompressedSize))
+		if err != nil {
+			return nil, errors.Wrap(err, "decompression failed")
+		}
+		return newPageData(res, nil, int(uncompressedSize))
 	}
 
-	res, err := decompressBlock(buf, codec)
+	buf := pageBuffers.get(int(compressedSize))
+	defer pageBuffers.put(buf)
+
+	n, err := io.ReadFull(in, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, errors.Errorf("compressed data must be %d byte but its %d byte", compressedSize, n)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "read failed")
+	}
+
+	dst := pageBuffers.get(int(uncompressedSize))
+	res, err := bd.decompressBlockTo(dst, buf)
 	if err != nil {
+		pageBuffers.put(dst)
 		return nil, errors.Wrap(err, "decompression failed")
 	}
+	if len(res) == 0 || len(dst) == 0 || &res[0] != &dst[0] {
+		// the data didn't fit into the buffer, it is not pooled
+		pageBuffers.put(dst)
+		dst = nil
+	}
 
-	if len(res) != int(uncompressedSize) {
-		return nil, errors.Errorf("decompressed data must be %d byte but its %d byte", uncompressedSize, len(res))
+	r, err := newPageData(res, dst, int(uncompressedSize))
+	if err != nil {
+		pageBuffers.put(dst)
 	}
+	return r, err
+}
 
-	return bytes.NewReader(res), nil
+func newPageData(data, buf []byte, size int) (*blockReader, error) {
+	if len(data) != size {
+		return nil, errors.Errorf("decompressed data must be %d byte but its %d byte", size, len(data))
+	}
+
+	r := &blockReader{buf: buf}
+	r.Reset(data)
+	return r, nil
+}
+
+// release returns the buffer of the page data to the pool, the reader is empty afterwards.
+func (b *blockReader) release() {
+	if b == nil {
+		return
+	}
+	b.Reset(nil)
+	if b.buf != nil {
+		pageBuffers.put(b.buf)
+		b.buf = nil
+	}
 }
 
 // RegisterBlockCompressor is a function to to register additional block compressors to the package. By default,
-// only UNCOMPRESSED, GZIP and SNAPPY are supported as parquet compression algorithms. The parquet file format
-// supports more compression algorithms, such as LZO, BROTLI, LZ4 and ZSTD. To limit the amount of external dependencies,
-// the number of supported algorithms was reduced to a core set. If you want to use any of the other compression
-// algorithms, please provide your own implementation of it in a way that satisfies the BlockCompressor interface,
-// and register it using this function from your code.
-func RegisterBlockCompressor(method parquet.CompressionCodec, compressor BlockCompressor) {
+// only UNCOMPRESSED, GZIP, SNAPPY, LZ4 (with the hadoop framing), LZ4_RAW, BROTLI and ZSTD are supported as parquet
+// compression algorithms. The parquet file format supports more compression algorithms, such as LZO. To limit
+// the amount of external dependencies, the number of supported algorithms was reduced to a core set. If you want to
+// use any of the other compression algorithms, please provide your own implementation of it in a way that satisfies
+// the BlockCompressor interface, and register it using this function from your code. The built-in compressors are
+// registered the same way, so registering a compressor for one of the codecs above replaces the built-in one, e.g. to
+// use a different gzip level or an accelerated implementation. The function reports whether a compressor for the
+// codec was replaced.
+func RegisterBlockCompressor(method parquet.CompressionCodec, compressor BlockCompressor) (replaced bool) {
 	compressorLock.Lock()
 	defer compressorLock.Unlock()
 
+	_, replaced = compressors[method]
 	compressors[method] = compressor
+	return replaced
 }
 
 // GetRegisteredBlockCompressors returns a map of compression codecs to block compressors that
@@ -151,6 +419,10 @@ func GetRegisteredBlockCompressors() map[parquet.CompressionCodec]BlockCompresso
 
 func init() {
 	RegisterBlockCompressor(parquet.CompressionCodec_UNCOMPRESSED, plainCompressor{})
-	RegisterBlockCompressor(parquet.CompressionCodec_GZIP, gzipCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_GZIP, &gzipCompressor{level: gzip.DefaultCompression})
 	RegisterBlockCompressor(parquet.CompressionCodec_SNAPPY, snappyCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_LZ4, lz4HadoopCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_LZ4_RAW, lz4RawCompressor{})
+	RegisterBlockCompressor(parquet.CompressionCodec_BROTLI, &brotliCompressor{quality: BrotliDefaultQuality})
+	RegisterBlockCompressor(parquet.CompressionCodec_ZSTD, &zstdCompressor{level: ZstdDefaultLevel})
 }
@@ -1,9 +1,17 @@
 package goparquet
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
 	"testing"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,14 +27,577 @@ occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim
 	methods := []parquet.CompressionCodec{
 		parquet.CompressionCodec_GZIP,
 		parquet.CompressionCodec_SNAPPY,
+		parquet.CompressionCodec_LZ4,
+		parquet.CompressionCodec_LZ4_RAW,
+		parquet.CompressionCodec_BROTLI,
+		parquet.CompressionCodec_ZSTD,
 		parquet.CompressionCodec_UNCOMPRESSED,
 	}
 
 	for _, m := range methods {
 		b, err := compressBlock(block, m)
 		require.NoError(t, err)
-		b2, err := decompressBlock(b, m)
+		b2, err := decompressBlock(b, m, len(block))
 		require.NoError(t, err)
 		assert.Equal(t, block, b2)
 	}
 }
+
+func TestBlockReaderSnappy(t *testing.T) {
+	page := bytes.Repeat([]byte("parquet page data "), 100)
+	block := snappy.Encode(nil, page)
+
+	r, err := newBlockReader(bytes.NewReader(block), parquet.CompressionCodec_SNAPPY, int32(len(block)), int32(len(page)))
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, page, data)
+
+	// the page header claims a different uncompressed size
+	_, err = newBlockReader(bytes.NewReader(block), parquet.CompressionCodec_SNAPPY, int32(len(block)), int32(len(page)+1))
+	require.EqualError(t, err, fmt.Sprintf("decompressed data must be %d byte but its %d byte", len(page)+1, len(page)))
+
+	// the block is shorter than the compressed size
+	_, err = newBlockReader(bytes.NewReader(block), parquet.CompressionCodec_SNAPPY, int32(len(block)+1), int32(len(page)))
+	require.EqualError(t, err, fmt.Sprintf("compressed data must be %d byte but its %d byte", len(block)+1, len(block)))
+
+	// the framed snappy format is not the raw block format
+	_, err = newBlockReader(bytes.NewReader([]byte("\xff\x06\x00\x00sNaPpY")), parquet.CompressionCodec_SNAPPY, 10, 10)
+	require.Error(t, err)
+}
+
+func TestGzipCompressorLevel(t *testing.T) {
+	_, err := NewGzipCompressor(42)
+	require.Error(t, err)
+
+	block := bytes.Repeat([]byte("lorem ipsum dolor sit amet "), 1000)
+	var sizes []int
+	for _, level := range []int{gzip.NoCompression, gzip.BestSpeed, gzip.BestCompression} {
+		c, err := NewGzipCompressor(level)
+		require.NoError(t, err)
+
+		// the pooled reader and writer are reset between the blocks
+		for i := 0; i < 3; i++ {
+			comp, err := c.CompressBlock(block)
+			require.NoError(t, err)
+			data, err := decompressWithSize(c, comp, len(block))
+			require.NoError(t, err)
+			require.Equal(t, block, data)
+			if i == 0 {
+				sizes = append(sizes, len(comp))
+			}
+		}
+	}
+	require.True(t, sizes[0] > sizes[1] && sizes[1] >= sizes[2], "unexpected compressed sizes %v", sizes)
+
+	c, err := NewGzipCompressor(gzip.BestSpeed)
+	require.NoError(t, err)
+	_, err = decompressWithSize(c, []byte("not gzip"), len(block))
+	require.Error(t, err)
+	// a failed block doesn't break the next one
+	comp, err := c.CompressBlock(block)
+	require.NoError(t, err)
+	data, err := decompressWithSize(c, comp, len(block))
+	require.NoError(t, err)
+	require.Equal(t, block, data)
+}
+
+func TestWriteGzipWithLevel(t *testing.T) {
+	c, err := NewGzipCompressor(gzip.BestCompression)
+	require.NoError(t, err)
+	RegisterBlockCompressor(parquet.CompressionCodec_GZIP, c)
+	defer RegisterBlockCompressor(parquet.CompressionCodec_GZIP, &gzipCompressor{level: gzip.DefaultCompression})
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCompressionCodec(parquet.CompressionCodec_GZIP))
+	s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprintf("value %d", i%10))}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value %d", i%10)), row["a"])
+	}
+}
+
+func TestGzipGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library, in the layout parquet-mr
+	// uses with writer.version v1: GZIP compressed pages, BIT_PACKED for the levels with a max level of 0, and
+	// PLAIN_DICTIONARY for the dictionary page and the data pages of the strings. Both columns have three data pages,
+	// so the gzip reader is reset between them.
+	data, err := ioutil.ReadFile("testdata/gzip.parquet")
+	require.NoError(t, err)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for _, col := range r.meta.RowGroups[0].Columns {
+		require.Equal(t, parquet.CompressionCodec_GZIP, col.MetaData.Codec)
+	}
+
+	require.Equal(t, int64(300), r.NumRows())
+	for i := 0; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int64(i*1000003 - 7)}
+		if i%7 != 3 {
+			expected["name"] = []byte(fmt.Sprintf("gzip %d", i%11))
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+type sizeRecorder struct {
+	sizes []int
+}
+
+func (s *sizeRecorder) CompressBlock(block []byte) ([]byte, error) {
+	return block, nil
+}
+
+func (s *sizeRecorder) DecompressBlock(block []byte) ([]byte, error) {
+	return s.DecompressBlockSize(block, -1)
+}
+
+func (s *sizeRecorder) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	s.sizes = append(s.sizes, size)
+	return block, nil
+}
+
+func TestRegisterBlockCompressor(t *testing.T) {
+	rec := &sizeRecorder{}
+	require.False(t, RegisterBlockCompressor(parquet.CompressionCodec_LZO, rec))
+	defer func() {
+		compressorLock.Lock()
+		delete(compressors, parquet.CompressionCodec_LZO)
+		compressorLock.Unlock()
+	}()
+	require.True(t, RegisterBlockCompressor(parquet.CompressionCodec_LZO, rec))
+	require.Equal(t, rec, GetRegisteredBlockCompressors()[parquet.CompressionCodec_LZO])
+
+	r, err := newBlockReader(bytes.NewReader([]byte("data")), parquet.CompressionCodec_LZO, 4, 4)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), data)
+	require.Equal(t, []int{4}, rec.sizes)
+
+	// compressors that aren't a SizedDecompressor only get the block
+	require.True(t, RegisterBlockCompressor(parquet.CompressionCodec_LZO, &failingCompressor{marker: []byte("fail")}))
+	r, err = newBlockReader(bytes.NewReader([]byte("data")), parquet.CompressionCodec_LZO, 4, 4)
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), data)
+
+	// the built-in compressors are registered the same way and can be replaced
+	snappyComp := GetRegisteredBlockCompressors()[parquet.CompressionCodec_SNAPPY]
+	require.True(t, RegisterBlockCompressor(parquet.CompressionCodec_SNAPPY, rec))
+	require.True(t, RegisterBlockCompressor(parquet.CompressionCodec_SNAPPY, snappyComp))
+}
+
+func TestGzipDecompressBound(t *testing.T) {
+	block := bytes.Repeat([]byte("a"), 10000)
+	comp, err := compressBlock(block, parquet.CompressionCodec_GZIP)
+	require.NoError(t, err)
+
+	_, err = newBlockReader(bytes.NewReader(comp), parquet.CompressionCodec_GZIP, int32(len(comp)), 100)
+	require.EqualError(t, err, "decompressed data must be 100 byte but its 101 byte")
+}
+
+func writeWithCompressorOptions(t *testing.T, codec parquet.CompressionCodec, opts CompressorOptions) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCompressionCodec(codec), WithCompressorOptions(opts))
+	s, err := NewByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 10000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprintf("value %d of the %d", i%1000, i%7))}))
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := 0; i < 10000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value %d of the %d", i%1000, i%7)), row["a"])
+	}
+
+	return buf.Bytes(), nil
+}
+
+func TestWriteWithCompressorOptions(t *testing.T) {
+	fast, err := writeWithCompressorOptions(t, parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 1})
+	require.NoError(t, err)
+	best, err := writeWithCompressorOptions(t, parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 19})
+	require.NoError(t, err)
+	require.NotEqual(t, len(fast), len(best))
+	require.True(t, len(best) < len(fast), "level 19 is %d byte, level 1 is %d byte", len(best), len(fast))
+
+	_, err = writeWithCompressorOptions(t, parquet.CompressionCodec_GZIP, CompressorOptions{Level: gzip.BestSpeed})
+	require.NoError(t, err)
+	_, err = writeWithCompressorOptions(t, parquet.CompressionCodec_BROTLI, CompressorOptions{Level: 2})
+	require.NoError(t, err)
+
+	_, err = writeWithCompressorOptions(t, parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 23})
+	require.EqualError(t, err, `invalid compressor options for method "ZSTD": zstd: invalid level 23, it must be between 1 and 22`)
+	_, err = writeWithCompressorOptions(t, parquet.CompressionCodec_SNAPPY, CompressorOptions{Level: 1})
+	require.EqualError(t, err, `method "SNAPPY" doesn't support compressor options`)
+}
+
+func TestWriteWithColumnCompressorLevel(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary fast;
+		required binary best;
+		required binary file;
+	}`)
+	require.NoError(t, err)
+
+	// the columns have the same values, only their compression levels differ
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd),
+		WithCompressionCodec(parquet.CompressionCodec_ZSTD),
+		WithCompressorOptions(CompressorOptions{Level: 1}),
+		WithColumnCompressionOptions("best", parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 19}),
+		WithColumnCompressionOptions("file", parquet.CompressionCodec_GZIP, CompressorOptions{Level: gzip.BestSpeed}),
+	)
+	for i := 0; i < 10000; i++ {
+		v := []byte(fmt.Sprintf("value %d of the %d", i%1000, i%7))
+		require.NoError(t, w.AddData(map[string]interface{}{"fast": v, "best": v, "file": v}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	sizes := map[string]int64{}
+	for _, cc := range r.meta.RowGroups[0].Columns {
+		sizes[cc.MetaData.PathInSchema[0]] = cc.MetaData.TotalCompressedSize
+	}
+	require.True(t, sizes["best"] < sizes["fast"], "level 19 is %d byte, level 1 is %d byte", sizes["best"], sizes["fast"])
+
+	// the page data is the same as with a compressor of the level
+	fast, err := getBlockCompressor(parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 1})
+	require.NoError(t, err)
+	best, err := getBlockCompressor(parquet.CompressionCodec_ZSTD, CompressorOptions{Level: 19})
+	require.NoError(t, err)
+	gz, err := getBlockCompressor(parquet.CompressionCodec_GZIP, CompressorOptions{Level: gzip.BestSpeed})
+	require.NoError(t, err)
+	for i, c := range []BlockCompressor{fast, best, gz} {
+		md := r.meta.RowGroups[0].Columns[i].MetaData
+		pr := bytes.NewReader(buf.Bytes()[chunkStart(md):])
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, pr))
+		compressed := make([]byte, ph.CompressedPageSize)
+		_, err := io.ReadFull(pr, compressed)
+		require.NoError(t, err)
+
+		page, err := decompressBlock(compressed, md.Codec, int(ph.UncompressedPageSize))
+		require.NoError(t, err)
+		expected, err := c.CompressBlock(page)
+		require.NoError(t, err)
+		require.Equal(t, expected, compressed, "column %s", md.PathInSchema[0])
+	}
+}
+
+func TestZstdDecompressBound(t *testing.T) {
+	block := bytes.Repeat([]byte("a"), 10000)
+	comp, err := compressBlock(block, parquet.CompressionCodec_ZSTD)
+	require.NoError(t, err)
+
+	_, err = decompressBlock(comp, parquet.CompressionCodec_ZSTD, 100)
+	require.EqualError(t, err, "zstd: the frame has 10000 byte, but the page has 100 byte")
+
+	_, err = NewZstdCompressor(0)
+	require.Error(t, err)
+}
+
+func TestUnsupportedCodecColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required group meta {
+			required binary payload;
+		}
+	}`)
+	require.NoError(t, err)
+
+	// write the file with a stand-in for LZO, and remove it before reading
+	RegisterBlockCompressor(parquet.CompressionCodec_LZO, &sizeRecorder{})
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_LZO))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":   int64(1),
+		"meta": map[string]interface{}{"payload": []byte("data")},
+	}))
+	require.NoError(t, w.Close())
+	compressorLock.Lock()
+	delete(compressors, parquet.CompressionCodec_LZO)
+	compressorLock.Unlock()
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.EqualError(t, err, `reading row 0 failed: column "id": compression codec LZO not supported; column "meta.payload": compression codec LZO not supported`)
+
+	// only the selected columns are checked
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "meta.payload")
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.EqualError(t, err, `reading row 0 failed: column "meta.payload": compression codec LZO not supported`)
+
+	_, err = r.ReadInt64Values(0, "id", nil)
+	require.EqualError(t, err, `column "id": compression codec LZO not supported`)
+}
+
+// failingCompressor fails to compress the blocks that contain a marker.
+type failingCompressor struct {
+	marker []byte
+}
+
+func (f *failingCompressor) CompressBlock(block []byte) ([]byte, error) {
+	if bytes.Contains(block, f.marker) {
+		return nil, fmt.Errorf("block contains %q", f.marker)
+	}
+	return block, nil
+}
+
+func (f *failingCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return block, nil
+}
+
+func TestWriteCompressionConcurrency(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 nums;
+	}`)
+	require.NoError(t, err)
+
+	writeFile := func(codec parquet.CompressionCodec, opts ...FileWriterOption) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		opts = append([]FileWriterOption{WithSchemaDefinition(sd), WithCompressionCodec(codec), WithMaxPageSize(1024), WithRowGroupRowLimit(3000), WithDataPageV2ForColumn("nums")}, opts...)
+		w := NewFileWriter(buf, opts...)
+		for i := 0; i < 10000; i++ {
+			row := map[string]interface{}{"id": int64(i), "nums": []int32{int32(i), int32(i % 13)}}
+			if i%5 != 0 {
+				row["name"] = []byte(fmt.Sprintf("name %d", i))
+			}
+			if err := w.AddData(row); err != nil {
+				return nil, err
+			}
+		}
+		err := w.Close()
+		return buf.Bytes(), err
+	}
+
+	for _, codec := range []parquet.CompressionCodec{parquet.CompressionCodec_GZIP, parquet.CompressionCodec_ZSTD, parquet.CompressionCodec_UNCOMPRESSED} {
+		expected, err := writeFile(codec)
+		require.NoError(t, err)
+		for _, n := range []int{2, 8} {
+			data, err := writeFile(codec, WithCompressionConcurrency(n))
+			require.NoError(t, err)
+			require.Equal(t, expected, data, "codec %s with %d goroutines", codec, n)
+		}
+	}
+
+	// the error of a page in the last row group is returned by Close
+	RegisterBlockCompressor(parquet.CompressionCodec_LZO, &failingCompressor{marker: []byte("name 9876")})
+	defer func() {
+		compressorLock.Lock()
+		delete(compressors, parquet.CompressionCodec_LZO)
+		compressorLock.Unlock()
+	}()
+	_, err = writeFile(parquet.CompressionCodec_LZO, WithCompressionConcurrency(4))
+	require.EqualError(t, err, `writing data page of column "name" failed: compressing data failed with LZO method: block contains "name 9876"`)
+}
+
+func BenchmarkWriteCompressionConcurrency(b *testing.B) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary name (STRING);
+	}`)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				w := NewFileWriter(ioutil.Discard, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_ZSTD),
+					WithCompressionConcurrency(n), WithDictionaryEncoding("name", false))
+				for j := 0; j < 200000; j++ {
+					if err := w.AddData(map[string]interface{}{"id": int64(j), "name": []byte(fmt.Sprintf("name %d", j*7919))}); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteColumnCompression(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary blob;
+		required group meta {
+			required binary name (STRING);
+		}
+	}`)
+	require.NoError(t, err)
+
+	writeFile := func(opts ...FileWriterOption) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_ZSTD)}, opts...)...)
+		for i := 0; i < 100; i++ {
+			err := w.AddData(map[string]interface{}{
+				"id":   int64(i),
+				"blob": bytes.Repeat([]byte{byte(i)}, 100),
+				"meta": map[string]interface{}{"name": []byte(fmt.Sprintf("name %d", i))},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		err := w.Close()
+		return buf.Bytes(), err
+	}
+
+	data, err := writeFile(
+		WithColumnCompression("blob", parquet.CompressionCodec_UNCOMPRESSED),
+		WithColumnCompression("meta.name", parquet.CompressionCodec_SNAPPY),
+	)
+	require.NoError(t, err)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	codecs := map[string]parquet.CompressionCodec{}
+	for _, cc := range r.meta.RowGroups[0].Columns {
+		md := cc.MetaData
+		codecs[md.PathInSchema[len(md.PathInSchema)-1]] = md.Codec
+		if md.Codec == parquet.CompressionCodec_UNCOMPRESSED {
+			require.Equal(t, md.TotalUncompressedSize, md.TotalCompressedSize)
+		}
+	}
+	require.Equal(t, map[string]parquet.CompressionCodec{
+		"id":   parquet.CompressionCodec_ZSTD,
+		"blob": parquet.CompressionCodec_UNCOMPRESSED,
+		"name": parquet.CompressionCodec_SNAPPY,
+	}, codecs)
+
+	for i := 0; i < 100; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"id":   int64(i),
+			"blob": bytes.Repeat([]byte{byte(i)}, 100),
+			"meta": map[string]interface{}{"name": []byte(fmt.Sprintf("name %d", i))},
+		}, row)
+	}
+
+	// the compressor options only apply to the codec of the file
+	_, err = writeFile(WithCompressorOptions(CompressorOptions{Level: 3}), WithColumnCompression("blob", parquet.CompressionCodec_SNAPPY))
+	require.NoError(t, err)
+
+	_, err = writeFile(WithColumnCompression("blob", parquet.CompressionCodec_LZO))
+	require.EqualError(t, err, `invalid compression codec of column "blob": method "LZO" is not supported`)
+	_, err = writeFile(WithColumnCompression("meta", parquet.CompressionCodec_SNAPPY))
+	require.EqualError(t, err, `column "meta" of WithColumnCompression not found`)
+}
+
+// levelCompressor is a configurable compressor that records the levels of the compressors that compressed blocks.
+type levelCompressor struct {
+	level  int
+	mu     *sync.Mutex
+	levels map[int]bool
+}
+
+func (c *levelCompressor) CompressBlock(block []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.levels[c.level] = true
+	return block, nil
+}
+
+func (c *levelCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return block, nil
+}
+
+func (c *levelCompressor) WithOptions(opts CompressorOptions) (BlockCompressor, error) {
+	return &levelCompressor{level: opts.Level, mu: c.mu, levels: c.levels}, nil
+}
+
+func TestWriteColumnCompressionOptions(t *testing.T) {
+	rec := &levelCompressor{mu: &sync.Mutex{}, levels: map[int]bool{}}
+	RegisterBlockCompressor(parquet.CompressionCodec_LZO, rec)
+	defer func() {
+		compressorLock.Lock()
+		delete(compressors, parquet.CompressionCodec_LZO)
+		compressorLock.Unlock()
+	}()
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary blob;
+		required binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	writeFile := func(opts ...FileWriterOption) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 100; i++ {
+			if err := w.AddData(map[string]interface{}{"id": int64(i), "blob": []byte("blob"), "name": []byte("name")}); err != nil {
+				return nil, err
+			}
+		}
+		err := w.Close()
+		return buf.Bytes(), err
+	}
+
+	data, err := writeFile(
+		WithCompressionCodec(parquet.CompressionCodec_LZO),
+		WithCompressorOptions(CompressorOptions{Level: 3}),
+		WithColumnCompressionOptions("blob", parquet.CompressionCodec_LZO, CompressorOptions{Level: 7}),
+		WithColumnCompression("name", parquet.CompressionCodec_LZO),
+	)
+	require.NoError(t, err)
+	// id and name use the options of the file, blob its own
+	require.Equal(t, map[int]bool{3: true, 7: true}, rec.levels)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for _, cc := range r.meta.RowGroups[0].Columns {
+		require.Equal(t, parquet.CompressionCodec_LZO, cc.MetaData.Codec)
+	}
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": int64(0), "blob": []byte("blob"), "name": []byte("name")}, row)
+
+	// the options of the file don't apply to the columns with their own options, and WithColumnCompression
+	// removes the options of an earlier WithColumnCompressionOptions
+	rec.levels = map[int]bool{}
+	_, err = writeFile(
+		WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		WithColumnCompressionOptions("blob", parquet.CompressionCodec_LZO, CompressorOptions{Level: 9}),
+		WithColumnCompressionOptions("name", parquet.CompressionCodec_LZO, CompressorOptions{Level: 5}),
+		WithColumnCompression("name", parquet.CompressionCodec_LZO),
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[int]bool{9: true, 0: true}, rec.levels)
+
+	_, err = writeFile(WithColumnCompressionOptions("blob", parquet.CompressionCodec_SNAPPY, CompressorOptions{Level: 1}))
+	require.EqualError(t, err, `invalid compression codec of column "blob": method "SNAPPY" doesn't support compressor options`)
+}
@@ -0,0 +1,62 @@
+package goparquet
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader fails all reads and seeks with the error of its context once the context is done, so reading a file
+// stops between pages.
+type contextReader struct {
+	ctx context.Context
+	r   io.ReadSeeker
+}
+
+func newContextReader(ctx context.Context, r io.ReadSeeker) io.ReadSeeker {
+	cr := &contextReader{ctx: ctx, r: r}
+	if ra, ok := r.(io.ReaderAt); ok {
+		return &contextReaderAt{contextReader: cr, ra: ra}
+	}
+	return cr
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+func (cr *contextReader) Seek(offset int64, whence int) (int64, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Seek(offset, whence)
+}
+
+// contextReaderAt is a contextReader of an io.ReaderAt, which keeps it usable for concurrent reads.
+type contextReaderAt struct {
+	*contextReader
+	ra io.ReaderAt
+}
+
+func (cr *contextReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.ra.ReadAt(p, offset)
+}
+
+// contextWriter fails all writes with the error of its context once the context is done, so writing a file stops
+// between pages.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *contextWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
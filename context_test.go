@@ -0,0 +1,119 @@
+package goparquet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+// cancelingReader cancels a context after a number of reads.
+type cancelingReader struct {
+	*bytes.Reader
+	reads  int
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReader) Read(p []byte) (int, error) {
+	r.reads--
+	if r.reads == 0 {
+		r.cancel()
+	}
+	return r.Reader.Read(p)
+}
+
+// cancelingWriter cancels a context after a number of writes.
+type cancelingWriter struct {
+	bytes.Buffer
+	writes int
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	w.writes--
+	if w.writes == 0 {
+		w.cancel()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestReaderContext(t *testing.T) {
+	data := writeWideFile(t, 25000)
+
+	// the context is canceled in the middle of a row group
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithReaderContext(ctx))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	cancel()
+	for i := 0; i < 3; i++ {
+		_, err = r.NextRow()
+		require.Equal(t, context.Canceled, err)
+	}
+
+	// the context is canceled while the second row group is read
+	ctx, cancel = context.WithCancel(context.Background())
+	cr := &cancelingReader{Reader: bytes.NewReader(data), cancel: cancel}
+	r, err = NewFileReaderWithOptions(cr, WithReaderContext(ctx))
+	require.NoError(t, err)
+	for i := 0; i < 10000; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	cr.reads = 5
+	for i := 0; i < 3; i++ {
+		_, err = r.NextRow()
+		require.Equal(t, context.Canceled, err)
+	}
+	// the file is not read after the read that canceled the context
+	require.Equal(t, 0, cr.reads)
+
+	// a canceled context also stops the readers of the columns and row groups
+	ctx, cancel = context.WithCancel(context.Background())
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithReaderContext(ctx))
+	require.NoError(t, err)
+	cancel()
+	_, err = r.ReadInt64Values(0, "c0", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.Canceled.Error())
+	rg, err := r.RowGroup(1)
+	require.NoError(t, err)
+	_, err = rg.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.Canceled.Error())
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithReaderContext(context.Background()))
+	require.NoError(t, err)
+	var n int
+	for ; ; n++ {
+		if _, err := r.NextRow(); err != nil {
+			require.Equal(t, io.EOF, err)
+			break
+		}
+	}
+	require.Equal(t, 25000, n)
+}
+
+func TestWriterContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancelingWriter{writes: 10, cancel: cancel}
+	fw := NewFileWriter(w, WithWriterContext(ctx), WithMaxPageSize(128))
+	s, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, fw.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, fw.AddData(map[string]interface{}{"a": int64(i)}))
+	}
+
+	require.Equal(t, context.Canceled, fw.FlushRowGroup())
+	written := w.Len()
+	require.Equal(t, context.Canceled, fw.AddData(map[string]interface{}{"a": int64(1)}))
+	require.Equal(t, context.Canceled, fw.Close())
+	require.Equal(t, written, w.Len())
+}
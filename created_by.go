@@ -0,0 +1,96 @@
+package goparquet
+
+import (
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// modulePath is the path of this module, to find its version in the build info.
+const modulePath = "github.com/fraugster/parquet-go"
+
+// defaultCreatedBy is the created_by field of the files written by this package if WithCreator isn't used.
+var defaultCreatedBy = createdByFromBuildInfo()
+
+// WriterInfo is the application that wrote a file, as it is recorded in the created_by field of the footer. Most
+// writers use the format "<application> version <version> (build <hash>)", e.g.
+// "parquet-mr version 1.8.1 (build 4aba4dae7bb0d4edbcf7923ae1339f28fd3f7fcf)", where the build is optional.
+type WriterInfo struct {
+	// Raw is the created_by field as it is, or the empty string if the file has none.
+	Raw string
+	// Application, Version and Build are the parts of Raw. They are empty if Raw is not in the format above.
+	Application string
+	Version     string
+	Build       string
+	// Major, Minor and Patch are the numbers of a semantic version like "1.8.1" or "1.12.0-SNAPSHOT". They are zero
+	// if Version doesn't start with them.
+	Major int
+	Minor int
+	Patch int
+}
+
+var (
+	createdByRegexp = regexp.MustCompile(`^(.*?)\s+version\s+([^\s(]+)\s*(?:\(\s*build\s+([^)\s]*)\s*\))?\s*$`)
+	semverRegexp    = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+)
+
+// CreatedBy returns the application that wrote the file. A created_by field that can't be parsed is returned as Raw
+// with the other fields left empty.
+func (f *FileReader) CreatedBy() WriterInfo {
+	return parseCreatedBy(f.meta.GetCreatedBy())
+}
+
+// Version returns the format version of the file from its footer.
+func (f *FileReader) Version() int32 {
+	return f.meta.Version
+}
+
+func parseCreatedBy(createdBy string) WriterInfo {
+	info := WriterInfo{Raw: createdBy}
+	m := createdByRegexp.FindStringSubmatch(createdBy)
+	if m == nil {
+		return info
+	}
+	info.Application, info.Version, info.Build = m[1], m[2], m[3]
+
+	if m := semverRegexp.FindStringSubmatch(info.Version); m != nil {
+		// the numbers can only be out of range, the version is left as it is then
+		major, err1 := strconv.Atoi(m[1])
+		minor, err2 := strconv.Atoi(m[2])
+		patch, err3 := strconv.Atoi(m[3])
+		if err1 == nil && err2 == nil && err3 == nil {
+			info.Major, info.Minor, info.Patch = major, minor, patch
+		}
+	}
+	return info
+}
+
+// createdByFromBuildInfo returns "parquet-go version <version> (build <hash>)" with the version and the hash of this
+// module from the build info of the binary, e.g. "parquet-go version 0.3.0 (build h1:...)". The version is "devel" if
+// the module isn't a versioned dependency, e.g. in its own tests, and the build is left out if the hash is unknown.
+func createdByFromBuildInfo() string {
+	version, sum := "devel", ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mods := append([]*debug.Module{&info.Main}, info.Deps...)
+		for _, mod := range mods {
+			if mod.Path != modulePath {
+				continue
+			}
+			if mod.Replace != nil {
+				mod = mod.Replace
+			}
+			if mod.Version != "" && mod.Version != "(devel)" {
+				version = strings.TrimPrefix(mod.Version, "v")
+			}
+			sum = mod.Sum
+			break
+		}
+	}
+
+	createdBy := "parquet-go version " + version
+	if sum != "" {
+		createdBy += " (build " + sum + ")"
+	}
+	return createdBy
+}
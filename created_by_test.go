@@ -0,0 +1,91 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreatedBy(t *testing.T) {
+	tests := []struct {
+		createdBy string
+		expected  WriterInfo
+	}{
+		{
+			createdBy: "parquet-mr version 1.8.1 (build 4aba4dae7bb0d4edbcf7923ae1339f28fd3f7fcf)",
+			expected:  WriterInfo{Application: "parquet-mr", Version: "1.8.1", Build: "4aba4dae7bb0d4edbcf7923ae1339f28fd3f7fcf", Major: 1, Minor: 8, Patch: 1},
+		},
+		{
+			createdBy: "parquet-cpp-arrow version 14.0.1",
+			expected:  WriterInfo{Application: "parquet-cpp-arrow", Version: "14.0.1", Major: 14, Patch: 1},
+		},
+		{
+			createdBy: "parquet-mr version 1.12.0-SNAPSHOT (build  abc )",
+			expected:  WriterInfo{Application: "parquet-mr", Version: "1.12.0-SNAPSHOT", Build: "abc", Major: 1, Minor: 12},
+		},
+		{
+			createdBy: "impala version 1.0-cdh4.5 (build 8d3a7a5b9f6ba5fe0f0e5a12b2cb77f6e7d0d5c4)",
+			expected:  WriterInfo{Application: "impala", Version: "1.0-cdh4.5", Build: "8d3a7a5b9f6ba5fe0f0e5a12b2cb77f6e7d0d5c4"},
+		},
+		{
+			createdBy: "parquet-go",
+		},
+		{
+			createdBy: "parquet-mr version",
+		},
+		{
+			createdBy: "app version 99999999999999999999.1.2",
+			expected:  WriterInfo{Application: "app", Version: "99999999999999999999.1.2"},
+		},
+		{
+			createdBy: "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.expected.Raw = tt.createdBy
+		require.Equal(t, tt.expected, parseCreatedBy(tt.createdBy), "created_by %q", tt.createdBy)
+	}
+}
+
+func TestFileReaderCreatedBy(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test { required int64 id; }`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCreator("my-app version 2.3.4 (build cafe)"), FileVersion(2))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, WriterInfo{
+		Raw:         "my-app version 2.3.4 (build cafe)",
+		Application: "my-app",
+		Version:     "2.3.4",
+		Build:       "cafe",
+		Major:       2,
+		Minor:       3,
+		Patch:       4,
+	}, r.CreatedBy())
+	require.Equal(t, int32(2), r.Version())
+}
+
+func TestDefaultCreatedBy(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test { required int64 id; }`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	info := r.CreatedBy()
+	require.Equal(t, defaultCreatedBy, info.Raw)
+	require.Equal(t, "parquet-go", info.Application)
+	require.NotEmpty(t, info.Version)
+}
@@ -27,10 +27,40 @@ type ColumnStore struct {
 
 	allowDict bool
 
+	// the block size and mini block count for the delta encodings
+	deltaBlockSize      int
+	deltaMiniBlockCount int
+
 	skipped bool
 }
 
-// useDictionary is simply a function to decide to use dictionary or not,
+// SetDeltaBlockSize sets the block size and the number of mini blocks per block that are used when the column is
+// written with DELTA_BINARY_PACKED, DELTA_LENGTH_BYTE_ARRAY or DELTA_BYTE_ARRAY encoding. The block size must be a
+// multiple of 128, and the number of values in each mini block (blockSize / miniBlockCount) must be a multiple of
+// 32. The default is a block size of 128 with 4 mini blocks, larger blocks can compress long monotonic sequences
+// better.
+func (cs *ColumnStore) SetDeltaBlockSize(blockSize, miniBlockCount int) error {
+	if err := validateDeltaBlockSize(blockSize, miniBlockCount); err != nil {
+		return err
+	}
+
+	cs.deltaBlockSize = blockSize
+	cs.deltaMiniBlockCount = miniBlockCount
+	return nil
+}
+
+// setEncoding sets the value encoding of the column and whether it can use a dictionary. It must be called before
+// values are added.
+func (cs *ColumnStore) setEncoding(enc parquet.Encoding, allowDict bool) {
+	cs.enc = enc
+	cs.allowDict = allowDict
+	if cs.values != nil {
+		cs.values.noIndex = !allowDict
+	}
+}
+
+// useDictionary is simply a function to decide to use dictionary or not. The size of the dictionary is checked
+// while the pages are written, see encodeDictionaryPages.
 func (cs *ColumnStore) useDictionary() bool {
 	if !cs.allowDict {
 		return false
@@ -48,6 +78,15 @@ func (cs *ColumnStore) useDictionary() bool {
 	return dictLen < noDictLen
 }
 
+// levelCount returns the number of repetition and definition levels, which is the number of values including the
+// null values. Typed values have no levels, they are all defined in a required column.
+func (cs *ColumnStore) levelCount() int {
+	if cs.values.typed != nil {
+		return int(cs.values.typedCount)
+	}
+	return cs.rLevels.count
+}
+
 func (cs *ColumnStore) encoding() parquet.Encoding {
 	return cs.enc
 }
@@ -67,6 +106,7 @@ func (cs *ColumnStore) reset(rep parquet.FieldRepetitionType, maxR, maxD uint16)
 		cs.dLevels = &packedArray{}
 	}
 	cs.values.init()
+	cs.values.noIndex = !cs.allowDict
 	cs.rLevels.reset(bits.Len16(maxR))
 	cs.dLevels.reset(bits.Len16(maxD))
 	cs.readPos = 0
@@ -80,10 +120,48 @@ func (cs *ColumnStore) appendRDLevel(rl, dl uint16) {
 	cs.dLevels.appendSingle(int32(dl))
 }
 
+// getValues returns the values of v for the column. The values of a repeated column can be a typed slice like
+// []int64 or a []interface{} with the values as the typed store takes them, which must not be nil.
+func (cs *ColumnStore) getValues(v interface{}) ([]interface{}, error) {
+	list, ok := v.([]interface{})
+	if !ok || cs.repTyp != parquet.FieldRepetitionType_REPEATED {
+		return cs.typedColumnStore.getValues(v)
+	}
+
+	vals := make([]interface{}, 0, len(list))
+	for i, elem := range list {
+		if elem == nil {
+			return nil, errors.Errorf("the element %d of the repeated value is nil", i)
+		}
+		ev, err := cs.typedColumnStore.getValues(elem)
+		if err != nil {
+			return nil, errors.Wrapf(err, "element %d", i)
+		}
+		if len(ev) != 1 {
+			return nil, errors.Errorf("the element %d of the repeated value is not a single value, but a %T", i, elem)
+		}
+		vals = append(vals, ev[0])
+	}
+	return vals, nil
+}
+
 // Add One row, if the value is null, call Add() , if the value is repeated, call all value in array
 // the second argument s the definition level
 // if there is a data the the result should be true, if there is only null (or empty array), the the result should be false
 func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
+	if v == nil {
+		return cs.addConverted(nil, dL, maxRL, rL)
+	}
+	vals, err := cs.getValues(v)
+	if err != nil {
+		return err
+	}
+	return cs.addConverted(vals, dL, maxRL, rL)
+}
+
+// addConverted adds the values vals that getValues returned for a value. If there are none, because the value is
+// null or an empty list, a null value is added.
+func (cs *ColumnStore) addConverted(vals []interface{}, dL uint16, maxRL, rL uint16) error {
 	// if the current column is repeated, we should increase the maxRL here
 	if cs.repTyp == parquet.FieldRepetitionType_REPEATED {
 		maxRL++
@@ -94,19 +172,11 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 	// the dL is a little tricky. there is some case if the REQUIRED field here are nil (since there is something above
 	// them is nil) they can not be the first level, but if they are in the next levels, is actually ok, but the
 	// level is one less
-	if v == nil {
+	if len(vals) == 0 {
 		cs.appendRDLevel(rL, dL)
 		cs.values.addValue(nil, 0)
 		return nil
 	}
-	vals, err := cs.getValues(v)
-	if err != nil {
-		return err
-	}
-	if len(vals) == 0 {
-		// the MaxRl might be increased in the beginning and increased again in the next call but for nil its not important
-		return cs.add(nil, dL, maxRL, rL)
-	}
 
 	for i, j := range vals {
 		cs.values.addValue(j, cs.sizeOf(j))
@@ -125,6 +195,67 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 	return nil
 }
 
+// setTypedValues sets the values of a required and not repeated column from a typed slice. The values are used as
+// they are, without the dictionary store and without boxing them.
+func (cs *ColumnStore) setTypedValues(values interface{}) (int, error) {
+	var n int
+	switch typed := values.(type) {
+	case []int32:
+		if _, ok := cs.typedColumnStore.(*int32Store); !ok {
+			return 0, errors.Errorf("%T values are not supported for %s columns", values, cs.parquetType())
+		}
+		n = len(typed)
+	case []int64:
+		if _, ok := cs.typedColumnStore.(*int64Store); !ok {
+			return 0, errors.Errorf("%T values are not supported for %s columns", values, cs.parquetType())
+		}
+		n = len(typed)
+	case []float64:
+		if _, ok := cs.typedColumnStore.(*doubleStore); !ok {
+			return 0, errors.Errorf("%T values are not supported for %s columns", values, cs.parquetType())
+		}
+		n = len(typed)
+	case [][]byte:
+		s, ok := cs.typedColumnStore.(*byteArrayStore)
+		if !ok {
+			return 0, errors.Errorf("%T values are not supported for %s columns", values, cs.parquetType())
+		}
+		for i, v := range typed {
+			if err := s.checkLength(v); err != nil {
+				return 0, errors.Wrapf(err, "row %d", i)
+			}
+		}
+		n = len(typed)
+	default:
+		return 0, errors.Errorf("unsupported type %T for column values", values)
+	}
+
+	if n > math.MaxInt32 {
+		return 0, errors.Errorf("too many values: %d", n)
+	}
+
+	cs.values.typed = values
+	cs.values.typedCount = int32(n)
+	return n, nil
+}
+
+// addValues adds the values of a column that is not repeated and not in an optional group, a nil value is a null.
+// The index of the value is part of the errors.
+func (cs *ColumnStore) addValues(values []interface{}) (int, error) {
+	if len(values) > math.MaxInt32 {
+		return 0, errors.Errorf("too many values: %d", len(values))
+	}
+	for i, v := range values {
+		if v == nil && cs.repTyp == parquet.FieldRepetitionType_REQUIRED {
+			return 0, errors.Errorf("the value of row %d is nil, but the column is required", i)
+		}
+		if err := cs.add(v, 0, 0, 0); err != nil {
+			return 0, errors.Wrapf(err, "row %d", i)
+		}
+	}
+	return len(values), nil
+}
+
 // getRDLevelAt return the next rLevel in the read position, if there is no value left, it returns true
 // if the position is less than zero, then it returns the current position
 // NOTE: make sure always r is before d, in any function
@@ -155,6 +286,29 @@ func (cs *ColumnStore) getNext() (v interface{}, err error) {
 	return v, nil
 }
 
+// skipRecords skips the levels and values of the next n records. A record starts with the rLevel 0, the values of
+// the other levels with the maxD definition level belong to the same record.
+func (cs *ColumnStore) skipRecords(n int64, maxD int32) error {
+	for i := int64(0); i < n; i++ {
+		for first := true; ; first = false {
+			rl, dl, last := cs.getRDLevelAt(cs.readPos)
+			if last && first {
+				return errors.Errorf("can't skip %d records, there are only %d", n, i)
+			}
+			if last || (!first && rl == 0) {
+				break
+			}
+			if dl == maxD {
+				if _, err := cs.getNext(); err != nil {
+					return err
+				}
+			}
+			cs.readPos++
+		}
+	}
+	return nil
+}
+
 func (cs *ColumnStore) get(maxD, maxR int32) (interface{}, int32, error) {
 	if cs.skipped {
 		return nil, 0, nil
@@ -204,9 +358,11 @@ func (cs *ColumnStore) get(maxD, maxR int32) (interface{}, int32, error) {
 
 func newStore(typed typedColumnStore, enc parquet.Encoding, allowDict bool) *ColumnStore {
 	return &ColumnStore{
-		enc:              enc,
-		allowDict:        allowDict,
-		typedColumnStore: typed,
+		enc:                 enc,
+		allowDict:           allowDict,
+		typedColumnStore:    typed,
+		deltaBlockSize:      defaultDeltaBlockSize,
+		deltaMiniBlockCount: defaultDeltaMiniBlockCount,
 	}
 }
 
@@ -307,7 +463,7 @@ func NewInt96Store(enc parquet.Encoding, allowDict bool, params *ColumnParameter
 // If allowDict is false, a dictionary will never be used to encode the data.
 func NewFloatStore(enc parquet.Encoding, allowDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN:
+	case parquet.Encoding_PLAIN, parquet.Encoding_BYTE_STREAM_SPLIT:
 	default:
 		return nil, errors.Errorf("encoding %q is not supported on this type", enc)
 	}
@@ -319,7 +475,7 @@ func NewFloatStore(enc parquet.Encoding, allowDict bool, params *ColumnParameter
 // If allowDict is false, a dictionary will never be used to encode the data.
 func NewDoubleStore(enc parquet.Encoding, allowDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN:
+	case parquet.Encoding_PLAIN, parquet.Encoding_BYTE_STREAM_SPLIT:
 	default:
 		return nil, errors.Errorf("encoding %q is not supported on this type", enc)
 	}
@@ -343,7 +499,7 @@ func NewByteArrayStore(enc parquet.Encoding, allowDict bool, params *ColumnParam
 // If allowDict is false, a dictionary will never be used to encode the data.
 func NewFixedByteArrayStore(enc parquet.Encoding, allowDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	switch enc {
-	case parquet.Encoding_PLAIN, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY:
+	case parquet.Encoding_PLAIN, parquet.Encoding_DELTA_BYTE_ARRAY:
 	default:
 		return nil, errors.Errorf("encoding %q is not supported on this type", enc)
 	}
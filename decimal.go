@@ -0,0 +1,115 @@
+package goparquet
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// Decimal is a value of a column with the DECIMAL logical or converted type, see WithDecimalsAsDecimal. Its value is
+// Unscaled * 10^-Scale, e.g. 12345 with the scale 2 is 123.45.
+type Decimal struct {
+	// Unscaled is the unscaled value, a nil value is zero.
+	Unscaled *big.Int
+	// Scale is the number of digits after the decimal point.
+	Scale int32
+}
+
+// String returns the value in decimal notation with Scale digits after the decimal point, e.g. "-0.05" for the
+// unscaled value -5 with the scale 2.
+func (d Decimal) String() string {
+	unscaled := d.unscaled()
+	if d.Scale <= 0 {
+		return new(big.Int).Mul(unscaled, pow10(-d.Scale)).String()
+	}
+
+	digits := new(big.Int).Abs(unscaled).String()
+	if n := int(d.Scale) + 1 - len(digits); n > 0 {
+		digits = strings.Repeat("0", n) + digits
+	}
+	point := len(digits) - int(d.Scale)
+	s := digits[:point] + "." + digits[point:]
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Float64 returns the nearest float64 value.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Rat returns the exact value as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	if d.Scale <= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Mul(d.unscaled(), pow10(-d.Scale)))
+	}
+	return new(big.Rat).SetFrac(d.unscaled(), pow10(d.Scale))
+}
+
+// Cmp compares the values of d and o, also if they have different scales. It returns -1 if d is less than o, 0 if
+// they are equal and +1 if d is greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := d.unscaled(), o.unscaled()
+	switch {
+	case d.Scale < o.Scale:
+		a = new(big.Int).Mul(a, pow10(o.Scale-d.Scale))
+	case d.Scale > o.Scale:
+		b = new(big.Int).Mul(b, pow10(d.Scale-o.Scale))
+	}
+	return a.Cmp(b)
+}
+
+// Equal returns true if d and o have the same value, e.g. 1.5 and 1.50.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) unscaled() *big.Int {
+	if d.Unscaled == nil {
+		return new(big.Int)
+	}
+	return d.Unscaled
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// decimalScale returns the scale of the column elem and true if it has the DECIMAL logical or converted type.
+func decimalScale(elem *parquet.SchemaElement) (int32, bool) {
+	if elem == nil {
+		return 0, false
+	}
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetDECIMAL() {
+		return lt.DECIMAL.Scale, true
+	}
+	if elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_DECIMAL {
+		return elem.GetScale(), true
+	}
+	return 0, false
+}
+
+// decodeDecimal returns the value v of a DECIMAL column with the scale as a Decimal. INT32 and INT64 values are the
+// unscaled values, byte arrays are their big-endian two's complement, which is sign extended to any length.
+func decodeDecimal(v interface{}, scale int32) (Decimal, error) {
+	d := Decimal{Scale: scale}
+	switch x := v.(type) {
+	case int32:
+		d.Unscaled = big.NewInt(int64(x))
+	case int64:
+		d.Unscaled = big.NewInt(x)
+	case []byte:
+		d.Unscaled = new(big.Int).SetBytes(x)
+		if len(x) > 0 && x[0]&0x80 != 0 {
+			d.Unscaled.Sub(d.Unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(x))*8))
+		}
+	default:
+		return Decimal{}, errors.Errorf("the value of type %T can't be a decimal", v)
+	}
+	return d, nil
+}
@@ -0,0 +1,221 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func bigInt(t *testing.T, s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	require.True(t, ok, s)
+	return i
+}
+
+// twosComplement returns the big-endian two's complement of i in n bytes.
+func twosComplement(i *big.Int, n int) []byte {
+	v := new(big.Int).Set(i)
+	if v.Sign() < 0 {
+		v.Add(v, new(big.Int).Lsh(big.NewInt(1), uint(n)*8))
+	}
+	b := v.Bytes()
+	return append(make([]byte, n-len(b)), b...)
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		unscaled string
+		scale    int32
+		str      string
+		f        float64
+	}{
+		{"0", 0, "0", 0},
+		{"0", 2, "0.00", 0},
+		{"12345", 2, "123.45", 123.45},
+		{"-12345", 2, "-123.45", -123.45},
+		{"-5", 2, "-0.05", -0.05},
+		{"5", 3, "0.005", 0.005},
+		{"7", -2, "700", 700},
+		{"123456789012345678901234567890123456789012", 10, "12345678901234567890123456789012.3456789012", 1.2345678901234567e31},
+	}
+	for _, tt := range tests {
+		d := Decimal{Unscaled: bigInt(t, tt.unscaled), Scale: tt.scale}
+		require.Equal(t, tt.str, d.String())
+		require.Equal(t, tt.f, d.Float64())
+	}
+	require.Equal(t, "0.0", Decimal{Scale: 1}.String())
+
+	require.Equal(t, 0, Decimal{Unscaled: big.NewInt(15), Scale: 1}.Cmp(Decimal{Unscaled: big.NewInt(150), Scale: 2}))
+	require.True(t, Decimal{Unscaled: big.NewInt(15), Scale: 1}.Equal(Decimal{Unscaled: big.NewInt(150), Scale: 2}))
+	require.Equal(t, -1, Decimal{Unscaled: big.NewInt(-1), Scale: 0}.Cmp(Decimal{Scale: 3}))
+	require.Equal(t, 1, Decimal{Unscaled: big.NewInt(1), Scale: 3}.Cmp(Decimal{Unscaled: big.NewInt(-1), Scale: 0}))
+	require.Equal(t, -1, Decimal{Unscaled: big.NewInt(1234), Scale: 3}.Cmp(Decimal{Unscaled: big.NewInt(124), Scale: 2}))
+	require.Equal(t, big.NewRat(-1, 20), Decimal{Unscaled: big.NewInt(-5), Scale: 2}.Rat())
+}
+
+func TestDecodeDecimal(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		unscaled string
+	}{
+		{int32(-123), "-123"},
+		{int64(1) << 40, "1099511627776"},
+		{[]byte{}, "0"},
+		{[]byte{0x00, 0xff}, "255"},
+		{[]byte{0xff}, "-1"},
+		{[]byte{0xff, 0xff, 0xff, 0xfe}, "-2"},
+		{[]byte{0x80, 0x00}, "-32768"},
+		{twosComplement(bigInt(t, "-99999999999999999999999999999999999999999"), 20), "-99999999999999999999999999999999999999999"},
+	}
+	for _, tt := range tests {
+		d, err := decodeDecimal(tt.v, 2)
+		require.NoError(t, err)
+		require.Equal(t, tt.unscaled, d.Unscaled.String())
+		require.Equal(t, int32(2), d.Scale)
+	}
+
+	_, err := decodeDecimal(float32(1), 2)
+	require.EqualError(t, err, "the value of type float32 can't be a decimal")
+}
+
+func TestReadDecimals(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int32 d32 (DECIMAL(9, 2));
+			optional int64 d64 (DECIMAL(18, 4));
+			optional binary dbin (DECIMAL(50, 10));
+			optional fixed_len_byte_array(16) dfixed (DECIMAL(38, 0));
+			repeated int32 drep (DECIMAL(5, 1));
+			required int32 plain;
+		}
+	`)
+	require.NoError(t, err)
+
+	huge := bigInt(t, "-12345678901234567890123456789012345678901234567890")
+	fixed := bigInt(t, "-99999999999999999999999999999999999999")
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"d32":    int32(-12345),
+		"d64":    int64(123456789),
+		"dbin":   twosComplement(huge, 21),
+		"dfixed": twosComplement(fixed, 16),
+		"drep":   []int32{15, -5},
+		"plain":  int32(1),
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"d32":   int32(7),
+		"plain": int32(2),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithDecimalsAsDecimal())
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"d32":    Decimal{Unscaled: big.NewInt(-12345), Scale: 2},
+		"d64":    Decimal{Unscaled: big.NewInt(123456789), Scale: 4},
+		"dbin":   Decimal{Unscaled: huge, Scale: 10},
+		"dfixed": Decimal{Unscaled: fixed, Scale: 0},
+		"drep":   []Decimal{{Unscaled: big.NewInt(15), Scale: 1}, {Unscaled: big.NewInt(-5), Scale: 1}},
+		"plain":  int32(1),
+	}, row)
+	require.Equal(t, "-123.45", row["d32"].(Decimal).String())
+	require.Equal(t, "-1234567890123456789012345678901234567890.1234567890", row["dbin"].(Decimal).String())
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"d32":   Decimal{Unscaled: big.NewInt(7), Scale: 2},
+		"plain": int32(2),
+	}, row)
+
+	// a decimal is scanned as its string
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithDecimalsAsDecimal())
+	require.NoError(t, err)
+	rows, err := r.Rows("d32", "d64")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	var d32 string
+	var d64 interface{}
+	require.NoError(t, rows.Scan(&d32, &d64))
+	require.Equal(t, "-123.45", d32)
+	require.Equal(t, "12345.6789", d64.(Decimal).String())
+
+	// without the option the values are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "d32", "dfixed")
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"d32": int32(-12345), "dfixed": twosComplement(fixed, 16)}, row)
+}
+
+func TestDecimalGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. It has a DECIMAL column of
+	// each physical type: INT32 and INT64 as Spark writes them for a precision up to 18, a FIXED_LEN_BYTE_ARRAY with
+	// the fewest bytes for a precision of 20 as Spark writes it, and a BYTE_ARRAY with a precision of 50 and the
+	// fewest bytes for each value. The d64 and dbin columns only have the DECIMAL logical type, the others also have
+	// the converted type.
+	data, err := ioutil.ReadFile("testdata/decimal.parquet")
+	require.NoError(t, err)
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecimalsAsDecimal())
+	require.NoError(t, err)
+
+	// the decimals are compared as strings, since a big.Int of 0 can have an empty or a nil slice of words
+	expected := []map[string]string{
+		{
+			"d32":    "-123.45",
+			"d64":    "12345.6789",
+			"dfixed": "-0.001",
+			"dbin":   "-1234567890123456789012345678901234567890.1234567890",
+		},
+		{
+			"d32":    "0.01",
+			"dfixed": "12345678901234567.890",
+			"dbin":   "0.0000000000",
+		},
+		{
+			"d32":    "9999999.99",
+			"d64":    "-0.0001",
+			"dfixed": "-99999999999999999.999",
+			"dbin":   "0.0000000001",
+		},
+		{
+			"d32":  "-9999999.99",
+			"d64":  "99999999999999.9999",
+			"dbin": "1000000000000000000000000000000000000000.0000000000",
+		},
+	}
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		decimals := map[string]string{}
+		for col, v := range row {
+			d, ok := v.(Decimal)
+			require.True(t, ok, "row %d column %s is a %T", i, col, v)
+			decimals[col] = d.String()
+		}
+		require.Equal(t, expected[i], decimals, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// without the option the values are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"d32":    int32(-12345),
+		"d64":    int64(123456789),
+		"dfixed": bytes.Repeat([]byte{0xff}, 9),
+		"dbin":   twosComplement(bigInt(t, "-12345678901234567890123456789012345678901234567890"), 21),
+	}, row)
+}
@@ -11,6 +11,18 @@ import (
 // which was my first choice but its branchy and full of if and else. so I decided to go for second solution and
 // almost copy/paste this two types
 
+// checkDeltaValuesCount returns an error if the values count of the block header is negative, or more than max when
+// the count is limited.
+func checkDeltaValuesCount(count int32, limited bool, max int32) error {
+	if count < 0 {
+		return errors.Errorf("int/delta: invalid total value count %d", count)
+	}
+	if limited && count > max {
+		return errors.Errorf("int/delta: the header has %d values, but the page has only %d values", count, max)
+	}
+	return nil
+}
+
 type deltaBitPackDecoder32 struct {
 	r io.Reader
 
@@ -18,6 +30,9 @@ type deltaBitPackDecoder32 struct {
 	miniBlockCount      int32
 	valuesCount         int32
 	miniBlockValueCount int32
+	// if limitValues is set, the values count in the header can't be more than maxValues, the count of the page
+	limitValues bool
+	maxValues   int32
 
 	previousValue int32
 	minDelta      int32
@@ -31,6 +46,13 @@ type deltaBitPackDecoder32 struct {
 	miniBlockInt32           [8]int32
 }
 
+// setMaxValues limits the values count of the header to the number of values in the page, a larger count is not
+// valid and is rejected in init, before anything is allocated for it.
+func (d *deltaBitPackDecoder32) setMaxValues(n int32) {
+	d.limitValues = true
+	d.maxValues = n
+}
+
 func (d *deltaBitPackDecoder32) initSize(r io.Reader) error {
 	return d.init(r)
 }
@@ -42,9 +64,9 @@ func (d *deltaBitPackDecoder32) init(r io.Reader) error {
 		return err
 	}
 
-	if err := d.readMiniBlockHeader(); err != nil {
-		return err
-	}
+	// the first block is read on the first call to next, there is no block at all if there is only one value
+	d.position = 0
+	d.currentMiniBlock = d.miniBlockCount
 
 	return nil
 }
@@ -54,29 +76,22 @@ func (d *deltaBitPackDecoder32) readBlockHeader() error {
 	if d.blockSize, err = readUVariant32(d.r); err != nil {
 		return errors.Wrap(err, "failed to read block size")
 	}
-	if d.blockSize <= 0 && d.blockSize%128 != 0 {
-		return errors.New("invalid block size")
-	}
 
 	if d.miniBlockCount, err = readUVariant32(d.r); err != nil {
 		return errors.Wrap(err, "failed to read number of mini blocks")
 	}
 
-	if d.miniBlockCount <= 0 || d.blockSize%d.miniBlockCount != 0 {
-		return errors.New("int/delta: invalid number of mini blocks")
+	if err := validateDeltaBlockSize(int(d.blockSize), int(d.miniBlockCount)); err != nil {
+		return errors.Wrap(err, "int/delta")
 	}
-
 	d.miniBlockValueCount = d.blockSize / d.miniBlockCount
-	if d.miniBlockValueCount == 0 {
-		return errors.Errorf("invalid mini block value count, it can't be zero")
-	}
 
 	if d.valuesCount, err = readUVariant32(d.r); err != nil {
 		return errors.Wrapf(err, "failed to read total value count")
 	}
 
-	if d.valuesCount < 0 {
-		return errors.New("invalid total value count")
+	if err := checkDeltaValuesCount(d.valuesCount, d.limitValues, d.maxValues); err != nil {
+		return err
 	}
 
 	if d.previousValue, err = readVariant32(d.r); err != nil {
@@ -117,6 +132,12 @@ func (d *deltaBitPackDecoder32) next() (int32, error) {
 		return 0, io.EOF
 	}
 
+	// the last value is already calculated, there is no delta after it
+	if d.position == d.valuesCount-1 {
+		d.position++
+		return d.previousValue, nil
+	}
+
 	// need new byte?
 	if d.position%8 == 0 {
 		// do we need to advance a mini block?
@@ -144,10 +165,10 @@ func (d *deltaBitPackDecoder32) next() (int32, error) {
 
 		d.miniBlockInt32 = d.currentUnpacker(buf)
 		d.miniBlockPosition += w
-		// there is padding here, read them all from the reader, first deal with the remaining of the current block,
-		// then the next blocks. if the blocks bit width is zero then simply ignore them, but the docs said reader
-		// should accept any arbitrary bit width here.
-		if d.position+8 >= d.valuesCount {
+		// there is padding here, read the remaining of the current mini block from the reader. the unused mini blocks
+		// after it have no data at all, the spec says their bit width should be zero but readers must accept any
+		// arbitrary value there, so they are ignored.
+		if d.position+8 >= d.valuesCount-1 {
 			//  current block
 			l := (d.miniBlockValueCount/8)*w - d.miniBlockPosition
 			if l < 0 {
@@ -155,13 +176,6 @@ func (d *deltaBitPackDecoder32) next() (int32, error) {
 			}
 			remaining := make([]byte, l)
 			_, _ = io.ReadFull(d.r, remaining)
-			for i := d.currentMiniBlock; i < d.miniBlockCount; i++ {
-				w := int32(d.miniBlockBitWidth[d.currentMiniBlock])
-				if w != 0 {
-					remaining := make([]byte, (d.miniBlockValueCount/8)*w)
-					_, _ = io.ReadFull(d.r, remaining)
-				}
-			}
 		}
 	}
 
@@ -181,6 +195,9 @@ type deltaBitPackDecoder64 struct {
 	miniBlockCount      int32
 	valuesCount         int32
 	miniBlockValueCount int32
+	// if limitValues is set, the values count in the header can't be more than maxValues, the count of the page
+	limitValues bool
+	maxValues   int32
 
 	previousValue int64
 	minDelta      int64
@@ -194,6 +211,11 @@ type deltaBitPackDecoder64 struct {
 	miniBlockInt64           [8]int64
 }
 
+func (d *deltaBitPackDecoder64) setMaxValues(n int32) {
+	d.limitValues = true
+	d.maxValues = n
+}
+
 func (d *deltaBitPackDecoder64) init(r io.Reader) error {
 	d.r = r
 
@@ -201,9 +223,9 @@ func (d *deltaBitPackDecoder64) init(r io.Reader) error {
 		return err
 	}
 
-	if err := d.readMiniBlockHeader(); err != nil {
-		return err
-	}
+	// the first block is read on the first call to next, there is no block at all if there is only one value
+	d.position = 0
+	d.currentMiniBlock = d.miniBlockCount
 
 	return nil
 }
@@ -213,29 +235,22 @@ func (d *deltaBitPackDecoder64) readBlockHeader() error {
 	if d.blockSize, err = readUVariant32(d.r); err != nil {
 		return errors.Wrap(err, "failed to read block size")
 	}
-	if d.blockSize <= 0 && d.blockSize%128 != 0 {
-		return errors.New("invalid block size")
-	}
 
 	if d.miniBlockCount, err = readUVariant32(d.r); err != nil {
 		return errors.Wrap(err, "failed to read number of mini blocks")
 	}
 
-	if d.miniBlockCount <= 0 || d.blockSize%d.miniBlockCount != 0 {
-		return errors.New("int/delta: invalid number of mini blocks")
+	if err := validateDeltaBlockSize(int(d.blockSize), int(d.miniBlockCount)); err != nil {
+		return errors.Wrap(err, "int/delta")
 	}
-
 	d.miniBlockValueCount = d.blockSize / d.miniBlockCount
-	if d.miniBlockValueCount == 0 {
-		return errors.Errorf("invalid mini block value count, it can't be zero")
-	}
 
 	if d.valuesCount, err = readUVariant32(d.r); err != nil {
 		return errors.Wrapf(err, "failed to read total value count")
 	}
 
-	if d.valuesCount < 0 {
-		return errors.New("invalid total value count")
+	if err := checkDeltaValuesCount(d.valuesCount, d.limitValues, d.maxValues); err != nil {
+		return err
 	}
 
 	if d.previousValue, err = readVariant64(d.r); err != nil {
@@ -276,6 +291,12 @@ func (d *deltaBitPackDecoder64) next() (int64, error) {
 		return 0, io.EOF
 	}
 
+	// the last value is already calculated, there is no delta after it
+	if d.position == d.valuesCount-1 {
+		d.position++
+		return d.previousValue, nil
+	}
+
 	// need new byte?
 	if d.position%8 == 0 {
 		// do we need to advance a mini block?
@@ -303,10 +324,10 @@ func (d *deltaBitPackDecoder64) next() (int64, error) {
 
 		d.miniBlockInt64 = d.currentUnpacker(buf)
 		d.miniBlockPosition += w
-		// there is padding here, read them all from the reader, first deal with the remaining of the current block,
-		// then the next blocks. if the blocks bit width is zero then simply ignore them, but the docs said reader
-		// should accept any arbitrary bit width here.
-		if d.position+8 >= d.valuesCount {
+		// there is padding here, read the remaining of the current mini block from the reader. the unused mini blocks
+		// after it have no data at all, the spec says their bit width should be zero but readers must accept any
+		// arbitrary value there, so they are ignored.
+		if d.position+8 >= d.valuesCount-1 {
 			//  current block
 			sliceLen := (d.miniBlockValueCount/8)*w - d.miniBlockPosition
 			if sliceLen < 0 {
@@ -314,13 +335,6 @@ func (d *deltaBitPackDecoder64) next() (int64, error) {
 			}
 			remaining := make([]byte, sliceLen)
 			_, _ = io.ReadFull(d.r, remaining)
-			for i := d.currentMiniBlock; i < d.miniBlockCount; i++ {
-				w := int32(d.miniBlockBitWidth[d.currentMiniBlock])
-				if w != 0 {
-					remaining := make([]byte, (d.miniBlockValueCount/8)*w)
-					_, _ = io.ReadFull(d.r, remaining)
-				}
-			}
 		}
 	}
 
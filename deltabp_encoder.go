@@ -11,6 +11,33 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// defaultDeltaBlockSize and defaultDeltaMiniBlockCount are the block size and the number of mini blocks in
+	// each block that are used by the delta encodings if nothing else is set on the column. It is the same as
+	// parquet-mr.
+	defaultDeltaBlockSize      = 128
+	defaultDeltaMiniBlockCount = 4
+)
+
+// validateDeltaBlockSize checks the block size and the mini block count against the spec: the block size must be a
+// positive multiple of 128 and it must be divisible by the number of mini blocks, so that the number of values in
+// each mini block is a multiple of 32.
+func validateDeltaBlockSize(blockSize, miniBlockCount int) error {
+	if blockSize%128 != 0 || blockSize <= 0 {
+		return errors.Errorf("invalid block size, it should be multiply of 128, it is %d", blockSize)
+	}
+
+	if miniBlockCount <= 0 || blockSize%miniBlockCount != 0 {
+		return errors.Errorf("invalid mini block count, it is %d", miniBlockCount)
+	}
+
+	if (blockSize/miniBlockCount)%32 != 0 {
+		return errors.Errorf("invalid mini block count, the mini block value count should be multiply of 32, it is %d", blockSize/miniBlockCount)
+	}
+
+	return nil
+}
+
 type deltaBitPackEncoder32 struct {
 	deltas   []int32
 	bitWidth []uint8
@@ -34,18 +61,11 @@ type deltaBitPackEncoder32 struct {
 func (d *deltaBitPackEncoder32) init(w io.Writer) error {
 	d.w = w
 
-	if d.blockSize%128 != 0 || d.blockSize <= 0 {
-		return errors.Errorf("invalid block size, it should be multiply of 128, it is %d", d.blockSize)
-	}
-
-	if d.miniBlockCount <= 0 || d.blockSize%d.miniBlockCount != 0 {
-		return errors.Errorf("invalid mini block count, it is %d", d.miniBlockCount)
+	if err := validateDeltaBlockSize(d.blockSize, d.miniBlockCount); err != nil {
+		return err
 	}
 
 	d.miniBlockValueCount = d.blockSize / d.miniBlockCount
-	if d.miniBlockValueCount%8 != 0 {
-		return errors.Errorf("invalid mini block count, the mini block value count should be multiply of 8, it is %d", d.miniBlockCount)
-	}
 
 	d.firstValue = 0
 	d.valuesCount = 0
@@ -165,6 +185,16 @@ func (d *deltaBitPackEncoder32) write() error {
 	return writeFull(d.w, d.buffer.Bytes())
 }
 
+// estimatedSize returns the size of the header, the blocks that are already written to the buffer, and the pending
+// deltas with their full width.
+func (d *deltaBitPackEncoder32) estimatedSize() int {
+	size := 4*binary.MaxVarintLen64 + d.buffer.Len()
+	if len(d.deltas) > 0 {
+		size += binary.MaxVarintLen64 + d.miniBlockCount + len(d.deltas)*4
+	}
+	return size
+}
+
 func (d *deltaBitPackEncoder32) Close() error {
 	return d.write()
 }
@@ -193,22 +223,15 @@ type deltaBitPackEncoder64 struct {
 func (d *deltaBitPackEncoder64) init(w io.Writer) error {
 	d.w = w
 
-	if d.blockSize%128 != 0 || d.blockSize <= 0 {
-		return errors.Errorf("invalid block size, it should be multiply of 128, it is %d", d.blockSize)
-	}
-
-	if d.miniBlockCount <= 0 || d.blockSize%d.miniBlockCount != 0 {
-		return errors.Errorf("invalid mini block count, it is %d", d.miniBlockCount)
+	if err := validateDeltaBlockSize(d.blockSize, d.miniBlockCount); err != nil {
+		return err
 	}
 
 	d.miniBlockValueCount = d.blockSize / d.miniBlockCount
-	if d.miniBlockValueCount%8 != 0 {
-		return errors.Errorf("invalid mini block count, the mini block value count should be multiply of 8, it is %d", d.miniBlockCount)
-	}
 
 	d.firstValue = 0
 	d.valuesCount = 0
-	d.minDelta = math.MaxInt32
+	d.minDelta = math.MaxInt64
 	d.deltas = make([]int64, 0, d.blockSize)
 	d.previousValue = 0
 	d.buffer = &bytes.Buffer{}
@@ -269,7 +292,7 @@ func (d *deltaBitPackEncoder64) flush() error {
 			return err
 		}
 	}
-	d.minDelta = math.MaxInt32
+	d.minDelta = math.MaxInt64
 	d.deltas = d.deltas[:0]
 
 	return nil
@@ -324,6 +347,16 @@ func (d *deltaBitPackEncoder64) write() error {
 	return writeFull(d.w, d.buffer.Bytes())
 }
 
+// estimatedSize returns the size of the header, the blocks that are already written to the buffer, and the pending
+// deltas with their full width.
+func (d *deltaBitPackEncoder64) estimatedSize() int {
+	size := 4*binary.MaxVarintLen64 + d.buffer.Len()
+	if len(d.deltas) > 0 {
+		size += binary.MaxVarintLen64 + d.miniBlockCount + len(d.deltas)*8
+	}
+	return size
+}
+
 func (d *deltaBitPackEncoder64) Close() error {
 	return d.write()
 }
@@ -2,9 +2,14 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
 	"math/rand"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,3 +55,211 @@ func TestDelta(t *testing.T) {
 		assert.Equal(t, toR, to1)
 	}
 }
+
+func TestDelta64(t *testing.T) {
+	data := [][]int64{
+		{math.MinInt64, math.MaxInt64, math.MinInt64, math.MaxInt64},
+		{math.MaxInt64, math.MaxInt64 - 1, math.MinInt64 + 1, math.MinInt64, 0, -1, 1},
+		{math.MaxInt64},
+		{},
+	}
+
+	random := make([]int64, 8*1024+5)
+	for i := range random {
+		random[i] = int64(rand.Uint64())
+	}
+	data = append(data, random)
+
+	// the last value is the first one after a full block or mini block
+	for _, l := range []int{9, 33, 129, 1025} {
+		values := make([]int64, l)
+		for i := range values {
+			values[i] = int64(i) * -3
+		}
+		data = append(data, values)
+	}
+
+	descending := make([]int64, 300)
+	for i := range descending {
+		descending[i] = math.MaxInt64 - int64(i)*(math.MaxInt64/150)
+	}
+	data = append(data, descending)
+
+	for _, values := range data {
+		buf := &bytes.Buffer{}
+		enc := &deltaBitPackEncoder64{
+			blockSize:      128,
+			miniBlockCount: 4,
+		}
+		require.NoError(t, enc.init(buf))
+		for _, v := range values {
+			require.NoError(t, enc.addInt64(v))
+		}
+		require.NoError(t, enc.Close())
+
+		dec := &deltaBitPackDecoder64{}
+		require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+		read := make([]int64, len(values))
+		require.NoError(t, decodeInt64(dec, read))
+		require.Equal(t, values, read)
+
+		_, err := dec.next()
+		require.Equal(t, io.EOF, err)
+	}
+}
+
+func TestDeltaBlockSizeValidation(t *testing.T) {
+	s, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetDeltaBlockSize(256, 8))
+	require.NoError(t, s.SetDeltaBlockSize(128, 1))
+	require.Error(t, s.SetDeltaBlockSize(0, 4))
+	require.Error(t, s.SetDeltaBlockSize(100, 4))
+	require.Error(t, s.SetDeltaBlockSize(128, 0))
+	require.Error(t, s.SetDeltaBlockSize(128, 3))
+	require.Error(t, s.SetDeltaBlockSize(128, 8))
+}
+
+func deltaHeader(values ...uint64) []byte {
+	var buf []byte
+	for _, v := range values {
+		var tmp [binary.MaxVarintLen64]byte
+		buf = append(buf, tmp[:binary.PutUvarint(tmp[:], v)]...)
+	}
+	return buf
+}
+
+func TestDeltaDecoderHeaderValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		header []byte
+		max    int32
+		err    string
+	}{
+		{name: "zero block size", header: deltaHeader(0, 4, 10, 0), err: "int/delta: invalid block size, it should be multiply of 128, it is 0"},
+		{name: "block size not multiple of 128", header: deltaHeader(100, 4, 10, 0), err: "int/delta: invalid block size, it should be multiply of 128, it is 100"},
+		{name: "mini block count do not divide", header: deltaHeader(128, 3, 10, 0), err: "int/delta: invalid mini block count, it is 3"},
+		{name: "mini block value count", header: deltaHeader(128, 8, 10, 0), err: "int/delta: invalid mini block count, the mini block value count should be multiply of 32, it is 16"},
+		{name: "too many values", header: deltaHeader(128, 4, 1<<30, 0), max: 10, err: "int/delta: the header has 1073741824 values, but the page has only 10 values"},
+		{name: "valid", header: deltaHeader(128, 4, 10, 0), max: 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d32 := &deltaBitPackDecoder32{}
+			d64 := &deltaBitPackDecoder64{}
+			if tc.max > 0 {
+				d32.setMaxValues(tc.max)
+				d64.setMaxValues(tc.max)
+			}
+			for _, err := range []error{d32.init(bytes.NewReader(tc.header)), d64.init(bytes.NewReader(tc.header))} {
+				if tc.err == "" {
+					require.NoError(t, err)
+				} else {
+					require.EqualError(t, err, tc.err)
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaDecoderInvalidBitWidth(t *testing.T) {
+	// block size 128 with 4 mini blocks, 10 values, first value 0, then the min delta and the bit widths
+	data := append(deltaHeader(128, 4, 10, 0, 0), 33, 0, 0, 0)
+	d32 := &deltaBitPackDecoder32{}
+	require.NoError(t, d32.init(bytes.NewReader(data)))
+	_, err := d32.next()
+	require.EqualError(t, err, "invalid miniblock bit width : 33")
+
+	data = append(deltaHeader(128, 4, 10, 0, 0), 65, 0, 0, 0)
+	d64 := &deltaBitPackDecoder64{}
+	require.NoError(t, d64.init(bytes.NewReader(data)))
+	_, err = d64.next()
+	require.EqualError(t, err, "invalid miniblock bit width : 65")
+}
+
+func TestDeltaDecoderPageValueCount(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	s, err := NewInt32Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": int32(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	// patch the values count in the delta header of the only page, from 10 to 11
+	data := buf.Bytes()
+	header := deltaHeader(128, 4, 10)
+	pos := bytes.Index(data, header)
+	require.True(t, pos > 0)
+	data[pos+len(header)-1] = 11
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "int/delta: the header has 11 values, but the page has only 10 values")
+}
+
+func TestDeltaBlockSizeRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+
+	ts, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, ts.SetDeltaBlockSize(256, 8))
+	require.NoError(t, w.AddColumn("ts", NewDataColumn(ts, parquet.FieldRepetitionType_REQUIRED)))
+
+	str, err := NewByteArrayStore(parquet.Encoding_DELTA_BYTE_ARRAY, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, str.SetDeltaBlockSize(512, 4))
+	require.NoError(t, w.AddColumn("str", NewDataColumn(str, parquet.FieldRepetitionType_REQUIRED)))
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(1600000000000000 + i*1000), "str": []byte(fmt.Sprintf("value-%d", i))}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"ts": int64(1600000000000000 + i*1000), "str": []byte(fmt.Sprintf("value-%d", i))}, row)
+	}
+}
+
+func BenchmarkDeltaBlockSize(b *testing.B) {
+	// a long monotonic sequence of timestamps in micro seconds with some jitter
+	values := make([]int64, 100000)
+	ts := int64(1600000000000000)
+	for i := range values {
+		ts += 1000 + rand.Int63n(100)
+		values[i] = ts
+	}
+
+	for _, bs := range [][2]int{{128, 4}, {256, 8}, {1024, 8}} {
+		b.Run(fmt.Sprintf("%d/%d", bs[0], bs[1]), func(b *testing.B) {
+			var size int
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf := &bytes.Buffer{}
+				enc := &deltaBitPackEncoder64{blockSize: bs[0], miniBlockCount: bs[1]}
+				if err := enc.init(buf); err != nil {
+					b.Fatal(err)
+				}
+				for _, v := range values {
+					if err := enc.addInt64(v); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := enc.Close(); err != nil {
+					b.Fatal(err)
+				}
+				size = buf.Len()
+			}
+			b.ReportMetric(float64(size)/float64(len(values)), "bytes/value")
+		})
+	}
+}
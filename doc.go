@@ -73,8 +73,8 @@
 //
 // With the FileReader, you can then go through the row groups (using PreLoad and SkipRowGroup).
 // and iterate through the row data in each row group (using NextRow). To find out how many rows
-// to expect in total and per row group, use the NumRows and RowGroupNumRows methods. The number
-// of row groups can be determined using the RowGroupCount method.
+// to expect in total and per row group, use the NumRows and RowGroupNumRowsAt methods, which don't
+// read any pages. The number of row groups can be determined using the RowGroupCount method.
 package goparquet
 
 //go:generate go run bitpack_gen.go
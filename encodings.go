@@ -0,0 +1,332 @@
+package goparquet
+
+import (
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+type (
+	// ValuesDecoder is the interface of a decoder for the values section of a data page. It can be
+	// used to add support for additional encodings, see RegisterValuesDecoder.
+	ValuesDecoder interface {
+		// Init is called with the values section of a data page, before the first DecodeValues call.
+		Init(r io.Reader) error
+		// DecodeValues decodes up to len(dst) values into dst and returns the number of decoded values.
+		// When there are no more values in the page, it must return io.EOF. The values must have the same
+		// Go type the package uses for the physical type of the column, which is bool, int32, int64,
+		// [12]byte, float32, float64 or []byte.
+		DecodeValues(dst []interface{}) (int, error)
+	}
+
+	// ValuesDecoderFunc creates a new ValuesDecoder for the column described by the schema element.
+	ValuesDecoderFunc func(typ *parquet.SchemaElement) (ValuesDecoder, error)
+
+	newValuesDecoderFunc func(typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error)
+	newValuesEncoderFunc func(typ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error)
+
+	encodingKey struct {
+		typ parquet.Type
+		enc parquet.Encoding
+	}
+
+	externalValuesDecoder struct {
+		dec ValuesDecoder
+	}
+)
+
+var (
+	valuesDecoders = make(map[encodingKey]newValuesDecoderFunc)
+	valuesEncoders = make(map[encodingKey]newValuesEncoderFunc)
+	encodingLock   sync.RWMutex
+)
+
+func (e *externalValuesDecoder) init(r io.Reader) error {
+	return e.dec.Init(r)
+}
+
+func (e *externalValuesDecoder) decodeValues(dst []interface{}) (int, error) {
+	return e.dec.DecodeValues(dst)
+}
+
+// RegisterValuesDecoder registers a decoder for the values of data pages with the physical type typ
+// and the encoding enc. It can be used to read encodings that are not supported by this package, or to
+// replace one of the built-in decoders. A previously registered decoder for the same type and encoding
+// is replaced.
+func RegisterValuesDecoder(typ parquet.Type, enc parquet.Encoding, fn ValuesDecoderFunc) {
+	registerValuesDecoder(typ, enc, func(elem *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		dec, err := fn(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &externalValuesDecoder{dec: dec}, nil
+	})
+}
+
+func registerValuesDecoder(typ parquet.Type, enc parquet.Encoding, fn newValuesDecoderFunc) {
+	encodingLock.Lock()
+	defer encodingLock.Unlock()
+
+	valuesDecoders[encodingKey{typ: typ, enc: normalizeEncoding(enc)}] = fn
+}
+
+func registerValuesEncoder(typ parquet.Type, enc parquet.Encoding, fn newValuesEncoderFunc) {
+	encodingLock.Lock()
+	defer encodingLock.Unlock()
+
+	valuesEncoders[encodingKey{typ: typ, enc: normalizeEncoding(enc)}] = fn
+}
+
+// normalizeEncoding maps the deprecated PLAIN_DICTIONARY encoding to RLE_DICTIONARY, the data pages
+// of both are encoded the same way.
+func normalizeEncoding(enc parquet.Encoding) parquet.Encoding {
+	if enc == parquet.Encoding_PLAIN_DICTIONARY {
+		return parquet.Encoding_RLE_DICTIONARY
+	}
+	return enc
+}
+
+func unsupportedEncodingError(enc parquet.Encoding, typ parquet.Type) error {
+	return errors.Errorf("encoding %s not supported for %s", enc, typ)
+}
+
+func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
+	if typ.Type == nil {
+		return nil, errors.Errorf("column %q has no physical type", typ.Name)
+	}
+
+	encodingLock.RLock()
+	fn, ok := valuesDecoders[encodingKey{typ: *typ.Type, enc: normalizeEncoding(pageEncoding)}]
+	encodingLock.RUnlock()
+	if !ok {
+		return nil, unsupportedEncodingError(pageEncoding, *typ.Type)
+	}
+
+	return fn(typ, dictValues)
+}
+
+func getValuesEncoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+	if typ.Type == nil {
+		return nil, errors.Errorf("column %q has no physical type", typ.Name)
+	}
+
+	encodingLock.RLock()
+	fn, ok := valuesEncoders[encodingKey{typ: *typ.Type, enc: normalizeEncoding(pageEncoding)}]
+	encodingLock.RUnlock()
+	if !ok {
+		return nil, unsupportedEncodingError(pageEncoding, *typ.Type)
+	}
+
+	return fn(typ, cs)
+}
+
+// int32Value returns the value v of an INT32 column. The values of unsigned columns are uint32 if they are read, and
+// int32 in the column stores of the writer.
+func int32Value(v interface{}) int32 {
+	if u, ok := v.(uint32); ok {
+		return int32(u)
+	}
+	return v.(int32)
+}
+
+// int64Value returns the value v of an INT64 column, which is an uint64 or an int64 like in int32Value.
+func int64Value(v interface{}) int64 {
+	if u, ok := v.(uint64); ok {
+		return int64(u)
+	}
+	return v.(int64)
+}
+
+func isUnsignedInt32(typ *parquet.SchemaElement) bool {
+	if typ.ConvertedType != nil {
+		switch *typ.ConvertedType {
+		case parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16, parquet.ConvertedType_UINT_32:
+			return true
+		}
+	}
+	return typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned
+}
+
+func isUnsignedInt64(typ *parquet.SchemaElement) bool {
+	if typ.ConvertedType != nil && *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
+		return true
+	}
+	return typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned
+}
+
+func typeLength(typ *parquet.SchemaElement) (int, error) {
+	if typ.TypeLength == nil {
+		return 0, errors.Errorf("column %q: type %s with nil type len", typ.Name, typ.Type)
+	}
+	return int(*typ.TypeLength), nil
+}
+
+func newDictDecoder(_ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
+	return &dictDecoder{values: dictValues}, nil
+}
+
+// newDictEncoder returns a dictionary encoder with the dictionary of the column chunk, so the indices of all data
+// pages refer to the values of the dictionary page.
+func newDictEncoder(_ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+	return &dictEncoder{dictStore: dictStore{values: cs.values.values, indices: cs.values.indices}}, nil
+}
+
+func init() {
+	for _, typ := range []parquet.Type{
+		parquet.Type_BOOLEAN,
+		parquet.Type_INT32,
+		parquet.Type_INT64,
+		parquet.Type_INT96,
+		parquet.Type_FLOAT,
+		parquet.Type_DOUBLE,
+		parquet.Type_BYTE_ARRAY,
+		parquet.Type_FIXED_LEN_BYTE_ARRAY,
+	} {
+		registerValuesDecoder(typ, parquet.Encoding_RLE_DICTIONARY, newDictDecoder)
+		registerValuesEncoder(typ, parquet.Encoding_RLE_DICTIONARY, newDictEncoder)
+	}
+
+	registerValuesDecoder(parquet.Type_BOOLEAN, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &booleanPlainDecoder{}, nil
+	})
+	registerValuesDecoder(parquet.Type_BOOLEAN, parquet.Encoding_RLE, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &booleanRLEDecoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_BOOLEAN, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &booleanPlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_BOOLEAN, parquet.Encoding_RLE, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &booleanRLEEncoder{}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_INT32, parquet.Encoding_PLAIN, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		return &int32PlainDecoder{unSigned: isUnsignedInt32(typ)}, nil
+	})
+	registerValuesDecoder(parquet.Type_INT32, parquet.Encoding_DELTA_BINARY_PACKED, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		return &int32DeltaBPDecoder{unSigned: isUnsignedInt32(typ)}, nil
+	})
+	registerValuesEncoder(parquet.Type_INT32, parquet.Encoding_PLAIN, func(_ *parquet.SchemaElement, _ *ColumnStore) (valuesEncoder, error) {
+		return &int32PlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_INT32, parquet.Encoding_DELTA_BINARY_PACKED, func(_ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+		return &int32DeltaBPEncoder{
+			deltaBitPackEncoder32: deltaBitPackEncoder32{
+				blockSize:      cs.deltaBlockSize,
+				miniBlockCount: cs.deltaMiniBlockCount,
+			},
+		}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_INT64, parquet.Encoding_PLAIN, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		return &int64PlainDecoder{unSigned: isUnsignedInt64(typ)}, nil
+	})
+	registerValuesDecoder(parquet.Type_INT64, parquet.Encoding_DELTA_BINARY_PACKED, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		return &int64DeltaBPDecoder{unSigned: isUnsignedInt64(typ)}, nil
+	})
+	registerValuesEncoder(parquet.Type_INT64, parquet.Encoding_PLAIN, func(_ *parquet.SchemaElement, _ *ColumnStore) (valuesEncoder, error) {
+		return &int64PlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_INT64, parquet.Encoding_DELTA_BINARY_PACKED, func(_ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+		return &int64DeltaBPEncoder{
+			deltaBitPackEncoder64: deltaBitPackEncoder64{
+				blockSize:      cs.deltaBlockSize,
+				miniBlockCount: cs.deltaMiniBlockCount,
+			},
+		}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_INT96, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &int96PlainDecoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_INT96, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &int96PlainEncoder{}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_FLOAT, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &floatPlainDecoder{}, nil
+	})
+	registerValuesDecoder(parquet.Type_FLOAT, parquet.Encoding_BYTE_STREAM_SPLIT, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &floatByteStreamSplitDecoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_FLOAT, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &floatPlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_FLOAT, parquet.Encoding_BYTE_STREAM_SPLIT, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &floatByteStreamSplitEncoder{}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_DOUBLE, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &doublePlainDecoder{}, nil
+	})
+	registerValuesDecoder(parquet.Type_DOUBLE, parquet.Encoding_BYTE_STREAM_SPLIT, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &doubleByteStreamSplitDecoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_DOUBLE, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &doublePlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_DOUBLE, parquet.Encoding_BYTE_STREAM_SPLIT, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &doubleByteStreamSplitEncoder{}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &byteArrayPlainDecoder{}, nil
+	})
+	registerValuesDecoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &byteArrayDeltaLengthDecoder{}, nil
+	})
+	registerValuesDecoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, func(*parquet.SchemaElement, []interface{}) (valuesDecoder, error) {
+		return &byteArrayDeltaDecoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_PLAIN, func(*parquet.SchemaElement, *ColumnStore) (valuesEncoder, error) {
+		return &byteArrayPlainEncoder{}, nil
+	})
+	registerValuesEncoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, func(_ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+		return &byteArrayDeltaLengthEncoder{
+			blockSize:      cs.deltaBlockSize,
+			miniBlockCount: cs.deltaMiniBlockCount,
+		}, nil
+	})
+	registerValuesEncoder(parquet.Type_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, func(_ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+		return &byteArrayDeltaEncoder{
+			blockSize:      cs.deltaBlockSize,
+			miniBlockCount: cs.deltaMiniBlockCount,
+		}, nil
+	})
+
+	registerValuesDecoder(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Encoding_PLAIN, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		l, err := typeLength(typ)
+		if err != nil {
+			return nil, err
+		}
+		return &byteArrayPlainDecoder{length: l, column: typ}, nil
+	})
+	registerValuesDecoder(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, func(typ *parquet.SchemaElement, _ []interface{}) (valuesDecoder, error) {
+		l, err := typeLength(typ)
+		if err != nil {
+			return nil, err
+		}
+		return &byteArrayDeltaDecoder{length: l, column: typ}, nil
+	})
+	registerValuesEncoder(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Encoding_PLAIN, func(typ *parquet.SchemaElement, _ *ColumnStore) (valuesEncoder, error) {
+		l, err := typeLength(typ)
+		if err != nil {
+			return nil, err
+		}
+		return &byteArrayPlainEncoder{length: l, column: typ}, nil
+	})
+	registerValuesEncoder(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY, func(typ *parquet.SchemaElement, cs *ColumnStore) (valuesEncoder, error) {
+		l, err := typeLength(typ)
+		if err != nil {
+			return nil, err
+		}
+		return &byteArrayDeltaEncoder{
+			length:         l,
+			column:         typ,
+			blockSize:      cs.deltaBlockSize,
+			miniBlockCount: cs.deltaMiniBlockCount,
+		}, nil
+	})
+}
@@ -0,0 +1,96 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bigEndianInt32Decoder is a made up encoding for the registry test, it stores int32 values in big endian.
+type bigEndianInt32Decoder struct {
+	data []byte
+}
+
+func (d *bigEndianInt32Decoder) Init(r io.Reader) error {
+	var err error
+	d.data, err = ioutil.ReadAll(r)
+	return err
+}
+
+func (d *bigEndianInt32Decoder) DecodeValues(dst []interface{}) (int, error) {
+	n := 0
+	for ; n < len(dst) && len(d.data) >= 4; n++ {
+		dst[n] = int32(binary.BigEndian.Uint32(d.data))
+		d.data = d.data[4:]
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func TestRegisterValuesDecoder(t *testing.T) {
+	typ := &parquet.SchemaElement{Name: "foo", Type: parquet.TypePtr(parquet.Type_INT32)}
+
+	_, err := getValuesDecoder(parquet.Encoding_BIT_PACKED, typ, nil)
+	require.EqualError(t, err, "encoding BIT_PACKED not supported for INT32")
+
+	RegisterValuesDecoder(parquet.Type_INT32, parquet.Encoding_BIT_PACKED, func(elem *parquet.SchemaElement) (ValuesDecoder, error) {
+		assert.Equal(t, typ, elem)
+		return &bigEndianInt32Decoder{}, nil
+	})
+	defer func() {
+		encodingLock.Lock()
+		defer encodingLock.Unlock()
+		delete(valuesDecoders, encodingKey{typ: parquet.Type_INT32, enc: parquet.Encoding_BIT_PACKED})
+	}()
+
+	dec, err := getValuesDecoder(parquet.Encoding_BIT_PACKED, typ, nil)
+	require.NoError(t, err)
+	require.NoError(t, dec.init(bytes.NewReader([]byte{0, 0, 0, 1, 0xff, 0xff, 0xff, 0xfe, 0, 0, 1, 0})))
+
+	dst := make([]interface{}, 5)
+	n, err := dec.decodeValues(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int32(1), int32(-2), int32(256)}, dst[:n])
+
+	_, err = dec.decodeValues(dst)
+	assert.Equal(t, io.EOF, err)
+
+	// other types are not affected by the registration.
+	_, err = getValuesDecoder(parquet.Encoding_BIT_PACKED, &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64)}, nil)
+	assert.EqualError(t, err, "encoding BIT_PACKED not supported for INT64")
+}
+
+func TestUnsupportedEncodings(t *testing.T) {
+	tests := []struct {
+		typ parquet.Type
+		enc parquet.Encoding
+	}{
+		{parquet.Type_INT32, parquet.Encoding_DELTA_BYTE_ARRAY},
+		{parquet.Type_BOOLEAN, parquet.Encoding_DELTA_BINARY_PACKED},
+		{parquet.Type_INT96, parquet.Encoding_BYTE_STREAM_SPLIT},
+		{parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY},
+	}
+
+	typeLen := int32(4)
+	for _, tt := range tests {
+		typ := &parquet.SchemaElement{Type: parquet.TypePtr(tt.typ), TypeLength: &typeLen}
+		want := "encoding " + tt.enc.String() + " not supported for " + tt.typ.String()
+
+		_, err := getValuesDecoder(tt.enc, typ, nil)
+		assert.EqualError(t, err, want)
+		_, err = getValuesEncoder(tt.enc, typ, nil)
+		assert.EqualError(t, err, want)
+	}
+
+	// The deprecated PLAIN_DICTIONARY is the same as RLE_DICTIONARY for data pages.
+	_, err := getValuesDecoder(parquet.Encoding_PLAIN_DICTIONARY, &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32)}, nil)
+	assert.NoError(t, err)
+}
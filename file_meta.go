@@ -11,7 +11,45 @@ import (
 
 var magic = []byte{'P', 'A', 'R', '1'}
 
+// ReadFileMetaData reads the meta data from the footer of the file r, with the schema, the row groups with their
+// column chunks and statistics, and the key-value metadata. It reads only the last 8 bytes of the file with the
+// length of the footer and then the footer, with two reads, and returns the number of bytes that were read. The
+// magic header at the start of the file is not checked, and no schema is built, so it is meant for tools that look
+// at the meta data of many files. Use NewFileReader to read the rows of the file.
+func ReadFileMetaData(r io.ReadSeeker) (*parquet.FileMetaData, int64, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "seek for the file size failed")
+	}
+	return readFooter(seekReaderAt{r}, size)
+}
+
+// ReadFileMetaDataAt is like ReadFileMetaData, but reads the file of size bytes from r.
+func ReadFileMetaDataAt(r io.ReaderAt, size int64) (*parquet.FileMetaData, int64, error) {
+	return readFooter(r, size)
+}
+
+// seekReaderAt reads from an offset of an io.ReadSeeker by seeking to the offset first.
+type seekReaderAt struct {
+	r io.ReadSeeker
+}
+
+func (s seekReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if _, err := s.r.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(s.r, p)
+}
+
 func readFileMetaData(r io.ReadSeeker) (*parquet.FileMetaData, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, errors.Wrap(err, "seek for the file size failed")
+	}
+	if err := checkFileSize(size); err != nil {
+		return nil, err
+	}
+
 	if _, err := r.Seek(0, io.SeekStart); err != nil {
 		return nil, errors.Wrap(err, "seek for the file magic header failed")
 	}
@@ -22,41 +60,66 @@ func readFileMetaData(r io.ReadSeeker) (*parquet.FileMetaData, error) {
 		return nil, errors.Wrap(err, "read the file magic header failed")
 	}
 	if !bytes.Equal(buf, magic) {
-		return nil, errors.Errorf("invalid parquet file header")
+		return nil, errors.Errorf("invalid parquet file header %q", buf)
 	}
 
-	// read and validate footer
-	if _, err := r.Seek(-4, io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek for the file magic footer failed")
-	}
+	meta, _, err := readFooter(seekReaderAt{r}, size)
+	return meta, err
+}
 
-	if _, err := io.ReadFull(r, buf); err != nil {
-		return nil, errors.Wrap(err, "read the file magic header failed")
+// checkFileSize checks that a file of size bytes has at least the magic header, the footer length and the magic
+// footer.
+func checkFileSize(size int64) error {
+	if size < int64(2*len(magic)+4) {
+		return errors.Errorf("invalid parquet file, it has only %d bytes", size)
 	}
-	if !bytes.Equal(buf, magic) {
-		return nil, errors.Errorf("invalid parquet file footer")
+	return nil
+}
+
+// readFooter reads the file meta data from the footer of the file of size bytes, with one read of the footer length
+// and the magic footer and one read of the footer. It returns the number of bytes that were read.
+func readFooter(r io.ReaderAt, size int64) (*parquet.FileMetaData, int64, error) {
+	if err := checkFileSize(size); err != nil {
+		return nil, 0, err
 	}
 
-	// read footer length
-	if _, err := r.Seek(-8, io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek for the footer len failed")
+	// read the footer length and validate the magic footer
+	tail := make([]byte, 8)
+	n, err := r.ReadAt(tail, size-8)
+	read := int64(n)
+	if n < len(tail) {
+		return nil, read, errors.Wrap(shortReadErr(err), "read the footer len failed")
 	}
-	var fl int32
-	if err := binary.Read(r, binary.LittleEndian, &fl); err != nil {
-		return nil, errors.Wrap(err, "read the footer len failed")
+	if !bytes.Equal(tail[4:], magic) {
+		return nil, read, errors.Errorf("invalid parquet file footer %q", tail[4:])
 	}
+	fl := int32(binary.LittleEndian.Uint32(tail))
 	if fl <= 0 {
-		return nil, errors.Errorf("invalid footer len %d", fl)
+		return nil, read, errors.Errorf("invalid footer len %d", fl)
+	}
+	if max := size - int64(2*len(magic)+4); int64(fl) > max {
+		return nil, read, errors.Errorf("invalid footer len %d, the file has only %d bytes for the footer", fl, max)
 	}
 
 	// read file metadata
-	if _, err := r.Seek(-8-int64(fl), io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek file meta data failed")
+	footer := make([]byte, fl)
+	n, err = r.ReadAt(footer, size-8-int64(fl))
+	read += int64(n)
+	if n < len(footer) {
+		return nil, read, errors.Wrap(shortReadErr(err), "read file meta failed")
 	}
 	meta := &parquet.FileMetaData{}
-	if err := readThrift(meta, io.LimitReader(r, int64(fl))); err != nil {
-		return nil, errors.Wrap(err, "read file meta failed")
+	if err := readThrift(meta, bytes.NewReader(footer)); err != nil {
+		return nil, read, errors.Wrap(err, "read file meta failed")
 	}
 
-	return meta, nil
+	return meta, read, nil
+}
+
+// shortReadErr returns the error of a ReadAt that read less bytes than requested, which must not be nil.
+func shortReadErr(err error) error {
+	if err == nil || err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
 }
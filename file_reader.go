@@ -1,8 +1,10 @@
 package goparquet
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -15,50 +17,313 @@ type FileReader struct {
 	meta *parquet.FileMetaData
 	SchemaReader
 	reader io.ReadSeeker
+	// columns are the selected columns, they are also selected in the readers of single row groups
+	columns []string
 
 	rowGroupPosition int
+	rowGroupFirstRow int64
 	currentRecord    int64
 	skipRowGroup     bool
+	rowGroupFilter   func(rg RowGroupStats) bool
+
+	validateCRC     bool
+	readConcurrency int
+	conversion      groupConversion
+	ctx             context.Context
 }
 
 // NewFileReader creates a new FileReader. You can limit the columns that are read by providing
 // the names of the specific columns to read using dotted notation. If no columns are provided,
 // then all columns are read.
 func NewFileReader(r io.ReadSeeker, columns ...string) (*FileReader, error) {
+	return NewFileReaderWithOptions(r, WithColumns(columns...))
+}
+
+// NewFileReaderAt creates a new FileReader that reads the file of size bytes from r. Unlike with an io.ReadSeeker,
+// there is no shared position in the file: the row group readers returned by RowGroup, the page iterators and the
+// column value readers each read with their own position, so they can be used from multiple goroutines at the same
+// time, e.g. to read different row groups or columns of a file in an object store in parallel. The row based reading
+// with NextRow is not safe for concurrent use.
+func NewFileReaderAt(r io.ReaderAt, size int64, options ...FileReaderOption) (*FileReader, error) {
+	return NewFileReaderWithOptions(io.NewSectionReader(r, 0, size), options...)
+}
+
+// FileReaderOption describes an option function that is applied to a FileReader when it is created.
+type FileReaderOption func(f *fileReaderOptions)
+
+type fileReaderOptions struct {
+	columns         []string
+	validateCRC     bool
+	readConcurrency int
+	conversion      groupConversion
+	ctx             context.Context
+}
+
+// WithColumns limits the columns that are read to the columns with the provided names in dotted
+// notation. If no columns are provided, then all columns are read.
+func WithColumns(columns ...string) FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.columns = columns
+	}
+}
+
+// WithCRC32Validation enables the validation of the CRC32 checksums of the pages. Pages without
+// a checksum are not validated, a page whose checksum doesn't match its data fails with
+// ErrPageChecksum. The validation is disabled by default.
+func WithCRC32Validation(enable bool) FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.validateCRC = enable
+	}
+}
+
+// WithReadConcurrency reads and decodes up to n column chunks of a row group in parallel goroutines. It only has an
+// effect if the reader of the file is also an io.ReaderAt, e.g. an *os.File or a *bytes.Reader, and n is larger than
+// 1. If reading a column chunk fails, no more column chunks are started and the error of the failing column is
+// returned. By default, the column chunks are read one after the other.
+func WithReadConcurrency(n int) FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.readConcurrency = n
+	}
+}
+
+// WithListsAsSlices makes NextRow return the values of groups with the LIST logical or converted type as
+// []interface{} slices of their elements, instead of a map with the repeated group of the list, e.g. []interface{}{1,
+// nil} instead of map[string]interface{}{"list": []map[string]interface{}{{"element": 1}, {}}}. A null element is nil,
+// an empty list is an empty slice, and a null list has no key in the row as before. The lists of older writers, e.g.
+// with a repeated primitive column or a repeated group named "array" or "bag", are recognized as well.
+func WithListsAsSlices() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.listsAsSlices = true
+	}
+}
+
+// WithMapsAsGoMaps makes NextRow return the values of groups with the MAP logical type, or the MAP_KEY_VALUE
+// converted type of older writers, as Go maps from the keys to the values, instead of a map with the repeated group
+// of the map. The maps have the Go type of the key column as key type, e.g. map[int64]interface{}, except for
+// BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY keys, which are strings. A null value is nil, of duplicate keys the last value
+// is used. A null key, which some writers allow, makes NextRow fail.
+func WithMapsAsGoMaps() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.maps = mapsAsGoMaps
+	}
+}
+
+// WithMapsAsEntries is like WithMapsAsGoMaps, but the maps are returned as []MapEntry slices of the keys and values
+// in the order in which they are stored, including duplicate keys. The keys have the Go type of the values of the
+// key column, e.g. []byte for BYTE_ARRAY keys.
+func WithMapsAsEntries() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.maps = mapsAsEntries
+	}
+}
+
+// WithInt96AsTime makes NextRow return the values of INT96 columns as time.Time in UTC instead of [12]byte, using
+// Int96ToTime. The values of repeated INT96 columns are returned as []time.Time. INT96 is the deprecated timestamp type
+// of Spark, Hive and Impala, all INT96 columns are converted, whatever their converted or logical type is.
+func WithInt96AsTime() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.int96AsTime = true
+	}
+}
+
+// WithDecimalsAsDecimal makes NextRow return the values of columns with the DECIMAL logical or converted type as a
+// Decimal with the scale of the column, instead of the unscaled int32 or int64 value or its big-endian two's
+// complement bytes. The values of repeated DECIMAL columns are returned as []Decimal.
+func WithDecimalsAsDecimal() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.decimals = true
+	}
+}
+
+// WithUUIDsAsArrays makes NextRow return the values of FIXED_LEN_BYTE_ARRAY(16) columns with the UUID logical type as
+// [16]byte instead of []byte. The values of repeated UUID columns are returned as [][16]byte.
+func WithUUIDsAsArrays() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.uuids = uuidsAsArrays
+	}
+}
+
+// WithUUIDsAsStrings is like WithUUIDsAsArrays, but the values are returned as strings in the canonical format
+// "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" with lower case hex digits, and as []string for repeated columns.
+func WithUUIDsAsStrings() FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.conversion.uuids = uuidsAsStrings
+	}
+}
+
+// WithReaderContext sets a context for reading the file. Once the context is done, all reads from the file fail, and
+// NextRow returns the error of the context, also for the rows of a row group that is already loaded. Reading stops
+// between two reads from the file, e.g. between the pages of a column chunk.
+func WithReaderContext(ctx context.Context) FileReaderOption {
+	return func(f *fileReaderOptions) {
+		f.ctx = ctx
+	}
+}
+
+// NewFileReaderWithOptions creates a new FileReader. You can provide FileReaderOptions to
+// influence the file reader's behaviour.
+func NewFileReaderWithOptions(r io.ReadSeeker, options ...FileReaderOption) (*FileReader, error) {
+	opts := &fileReaderOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.ctx != nil {
+		r = newContextReader(opts.ctx, r)
+	}
+
 	meta, err := readFileMetaData(r)
 	if err != nil {
 		return nil, errors.Wrap(err, "reading file meta data failed")
 	}
 
+	// Reset the reader to the beginning of the file
+	if _, err := r.Seek(4, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return newFileReader(r, meta, opts)
+}
+
+// newFileReader creates a FileReader of the file with the meta data meta, which reads the pages from r.
+func newFileReader(r io.ReadSeeker, meta *parquet.FileMetaData, opts *fileReaderOptions) (*FileReader, error) {
 	schema, err := makeSchema(meta)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating schema failed")
 	}
 
-	schema.setSelectedColumns(columns...)
-	// Reset the reader to the beginning of the file
-	if _, err := r.Seek(4, io.SeekStart); err != nil {
-		return nil, err
-	}
+	schema.setSelectedColumns(opts.columns...)
+	schema.setGroupConversion(opts.conversion)
 	return &FileReader{
-		meta:         meta,
-		SchemaReader: schema,
-		reader:       r,
+		meta:            meta,
+		SchemaReader:    schema,
+		reader:          r,
+		columns:         opts.columns,
+		validateCRC:     opts.validateCRC,
+		readConcurrency: opts.readConcurrency,
+		conversion:      opts.conversion,
+		ctx:             opts.ctx,
 	}, nil
 }
 
-// readRowGroup read the next row group into memory
+// SetSelectedColumns limits the columns that are read to the columns with the provided names in dotted notation. A
+// group selects all columns below it. If no columns are provided, then all columns are read. The pages of the other
+// columns are neither read nor decompressed, and the rows of NextRow don't have their values. The selection applies
+// to the row groups that are loaded afterwards. It returns an error with all unknown names if a name is neither a
+// column nor a group, in that case the selection is not changed.
+func (f *FileReader) SetSelectedColumns(columns ...string) error {
+	var unknown []string
+	for _, name := range columns {
+		found := false
+		for _, col := range f.SchemaReader.Columns() {
+			if col.FlatName() == name || strings.HasPrefix(col.FlatName(), name+".") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, fmt.Sprintf("%q", name))
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.Errorf("unknown columns %s", strings.Join(unknown, ", "))
+	}
+
+	f.SchemaReader.setSelectedColumns(columns...)
+	f.columns = columns
+	return nil
+}
+
+// sectionReader returns a reader of the file with its own position if the file is read from an io.ReaderAt, so that
+// it can be used concurrently with other reads. Otherwise it returns the reader of the file.
+func (f *FileReader) sectionReader() io.ReadSeeker {
+	if ra, ok := f.reader.(io.ReaderAt); ok {
+		return io.NewSectionReader(ra, 0, math.MaxInt64)
+	}
+	return f.reader
+}
+
+// readRowGroup read the next row group into memory, skipping the row groups that are rejected by the row group
+// filter.
 func (f *FileReader) readRowGroup() error {
-	if len(f.meta.RowGroups) <= f.rowGroupPosition {
-		return io.EOF
+	for {
+		if len(f.meta.RowGroups) <= f.rowGroupPosition {
+			return io.EOF
+		}
+		f.rowGroupPosition++
+		if f.rowGroupFilter == nil {
+			break
+		}
+		stats, err := f.rowGroupStats(f.rowGroupPosition - 1)
+		if err != nil {
+			return err
+		}
+		if f.rowGroupFilter(stats) {
+			break
+		}
+	}
+	f.rowGroupFirstRow = f.firstRow(f.rowGroupPosition - 1)
+	return readRowGroupWithConcurrency(f.reader, f.SchemaReader, f.meta.RowGroups[f.rowGroupPosition-1], f.validateCRC, 0, f.readConcurrency)
+}
+
+// readRowGroupWithConcurrency reads the row group with readRowGroupConcurrently if concurrency is larger than 1 and r
+// is an io.ReaderAt, or with readRowGroup otherwise.
+func readRowGroupWithConcurrency(r io.ReadSeeker, schema SchemaReader, rowGroup *parquet.RowGroup, validateCRC bool, fromRow int64, concurrency int) error {
+	if ra, ok := r.(io.ReaderAt); ok && concurrency > 1 {
+		return readRowGroupConcurrently(ra, schema, rowGroup, validateCRC, fromRow, concurrency)
+	}
+	return readRowGroup(r, schema, rowGroup, validateCRC, fromRow)
+}
+
+// firstRow returns the index of the first row of the row group with the index rowGroup in the file.
+func (f *FileReader) firstRow(rowGroup int) int64 {
+	var row int64
+	for _, rg := range f.meta.RowGroups[:rowGroup] {
+		row += rg.NumRows
+	}
+	return row
+}
+
+// RowGroupStats are the statistics of a row group that are passed to the row group filter.
+type RowGroupStats struct {
+	// Index is the index of the row group in the file.
+	Index int
+	// NumRows is the number of rows in the row group.
+	NumRows int64
+	// Columns are the statistics of the column chunks of the row group by the dotted names of their columns. The
+	// column chunks without statistics are missing.
+	Columns map[string]*ColumnStatistics
+}
+
+// SetRowGroupFilter sets a filter that decides from the statistics of a row group whether it is read. The row groups
+// for which filter returns false are skipped by NextRow without reading any of their pages. The statistics have the
+// min and max values decoded like ColumnStatistics, so they can be compared with ColumnStatistics.NotBetween. The
+// filter has no effect on NumRows, the row groups that are already loaded and the readers returned by RowGroup. A
+// nil filter reads all row groups.
+func (f *FileReader) SetRowGroupFilter(filter func(rg RowGroupStats) bool) {
+	f.rowGroupFilter = filter
+}
+
+// rowGroupStats returns the statistics of the row group with the index rowGroup.
+func (f *FileReader) rowGroupStats(rowGroup int) (RowGroupStats, error) {
+	stats := RowGroupStats{
+		Index:   rowGroup,
+		NumRows: f.meta.RowGroups[rowGroup].NumRows,
+		Columns: make(map[string]*ColumnStatistics),
+	}
+	for _, col := range f.SchemaReader.Columns() {
+		cs, err := f.ColumnStatistics(rowGroup, col.FlatName())
+		if err != nil {
+			return stats, errors.Wrapf(err, "reading statistics of row group %d failed", rowGroup)
+		}
+		if cs != nil {
+			stats.Columns[col.FlatName()] = cs
+		}
 	}
-	f.rowGroupPosition++
-	return readRowGroup(f.reader, f.SchemaReader, f.meta.RowGroups[f.rowGroupPosition-1])
+	return stats, nil
 }
 
 // CurrentRowGroup returns information about the current row group.
 func (f *FileReader) CurrentRowGroup() *parquet.RowGroup {
-	if f == nil || f.meta == nil || f.meta.RowGroups == nil || f.rowGroupPosition-1 >= len(f.meta.RowGroups) {
+	if f == nil || f.meta == nil || f.meta.RowGroups == nil || f.rowGroupPosition < 1 || f.rowGroupPosition-1 >= len(f.meta.RowGroups) {
 		return nil
 	}
 	return f.meta.RowGroups[f.rowGroupPosition-1]
@@ -70,11 +335,34 @@ func (f *FileReader) RowGroupCount() int {
 }
 
 // NumRows returns the number of rows in the parquet file. This information is directly taken from
-// the file's meta data.
+// the file's meta data. If it differs from the sum of the rows of the row groups, Warnings says so.
 func (f *FileReader) NumRows() int64 {
 	return f.meta.NumRows
 }
 
+// RowGroupNumRowsAt returns the number of rows in the row group with the index i. Unlike RowGroupNumRows, it is
+// taken from the file's meta data, no row group is loaded.
+func (f *FileReader) RowGroupNumRowsAt(i int) (int64, error) {
+	if i < 0 || i >= len(f.meta.RowGroups) {
+		return 0, errors.Errorf("row group %d is out of range, the file has %d row groups", i, len(f.meta.RowGroups))
+	}
+	return f.meta.RowGroups[i].NumRows, nil
+}
+
+// Warnings returns the problems of the file's meta data that don't prevent reading the file, e.g. a number of rows
+// that differs from the sum of the rows of the row groups, which some writers get wrong.
+func (f *FileReader) Warnings() []string {
+	var warnings []string
+	var sum int64
+	for _, rg := range f.meta.RowGroups {
+		sum += rg.NumRows
+	}
+	if sum != f.meta.NumRows {
+		warnings = append(warnings, fmt.Sprintf("the file has %d rows, but its row groups have %d rows", f.meta.NumRows, sum))
+	}
+	return warnings
+}
+
 func (f *FileReader) advanceIfNeeded() error {
 	if f.rowGroupPosition == 0 || f.currentRecord >= f.SchemaReader.rowGroupNumRecords() || f.skipRowGroup {
 		if err := f.readRowGroup(); err != nil {
@@ -88,7 +376,8 @@ func (f *FileReader) advanceIfNeeded() error {
 	return nil
 }
 
-// RowGroupNumRows returns the number of rows in the current RowGroup.
+// RowGroupNumRows returns the number of rows in the current RowGroup. It loads the next row group if none is loaded,
+// use RowGroupNumRowsAt to get the number of rows of a row group without loading it.
 func (f *FileReader) RowGroupNumRows() (int64, error) {
 	if err := f.advanceIfNeeded(); err != nil {
 		return 0, err
@@ -97,14 +386,82 @@ func (f *FileReader) RowGroupNumRows() (int64, error) {
 	return f.SchemaReader.rowGroupNumRecords(), nil
 }
 
-// NextRow reads the next row from the parquet file. If required, it will load the next row group.
+// NextRow reads the next row from the parquet file. If required, it will load the next row group. The row has the
+// values of the selected columns by their names, the values of groups are nested maps, and the values of repeated
+// fields are slices. Null values are left out, so a column or group that is null has no key in the row. NextRow
+// returns io.EOF after the last row, other errors contain the index of the row in the file.
 func (f *FileReader) NextRow() (map[string]interface{}, error) {
-	if err := f.advanceIfNeeded(); err != nil {
+	if err := f.contextErr(); err != nil {
 		return nil, err
 	}
+	if err := f.advanceIfNeeded(); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		if ctxErr := f.contextErr(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, errors.Wrapf(err, "reading row %d failed", f.rowGroupFirstRow)
+	}
 
+	row := f.rowGroupFirstRow + f.currentRecord
 	f.currentRecord++
-	return f.SchemaReader.getData()
+	data, err := f.SchemaReader.getData()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading row %d failed", row)
+	}
+	return data, nil
+}
+
+// SeekToRow positions the reader at the row with the index n in the file, so that the next call of NextRow returns
+// this row. It loads the row group of the row, also if it is the current row group, so seeking backwards works as
+// well. The pages of the column chunks before the page with the row are not read if the column chunks have an offset
+// index, otherwise they are read and the rows before the row are skipped. The row group filter is not applied to the
+// row group of the row, only to the row groups after it. If n is not less than the number of rows in the file,
+// SeekToRow and the following calls of NextRow return io.EOF.
+func (f *FileReader) SeekToRow(n int64) error {
+	if err := f.contextErr(); err != nil {
+		return err
+	}
+	if n < 0 {
+		return errors.Errorf("invalid row %d", n)
+	}
+
+	var first int64
+	for i, rg := range f.meta.RowGroups {
+		if n >= first+rg.NumRows {
+			first += rg.NumRows
+			continue
+		}
+
+		f.rowGroupPosition = i + 1
+		f.rowGroupFirstRow = first
+		f.currentRecord = 0
+		f.skipRowGroup = true
+		if err := readRowGroupWithConcurrency(f.reader, f.SchemaReader, rg, f.validateCRC, n-first, f.readConcurrency); err != nil {
+			if ctxErr := f.contextErr(); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.Wrapf(err, "seeking to row %d failed", n)
+		}
+		f.currentRecord = n - first
+		f.skipRowGroup = false
+		return nil
+	}
+
+	f.rowGroupPosition = len(f.meta.RowGroups)
+	f.rowGroupFirstRow = first
+	f.currentRecord = 0
+	f.skipRowGroup = true
+	return io.EOF
+}
+
+// contextErr returns the error of the context of the reader, if it has one.
+func (f *FileReader) contextErr() error {
+	if f.ctx == nil {
+		return nil
+	}
+	return f.ctx.Err()
 }
 
 // SkipRowGroup skips the currently loaded row group and advances to the next row group.
@@ -117,15 +474,32 @@ func (f *FileReader) PreLoad() error {
 	return f.advanceIfNeeded()
 }
 
-// MetaData returns a map of metadata key-value pairs stored in the parquet file.
+// KeyValue is a metadata key-value pair of a file or a column chunk. Value is nil if the pair has no value.
+type KeyValue struct {
+	Key   string
+	Value *string
+}
+
+// MetaData returns a map of metadata key-value pairs stored in the parquet file. Pairs without a value are left
+// out, and of duplicate keys the last value is used. Use MetaDataKeyValues to get all of them.
 func (f *FileReader) MetaData() map[string]string {
 	return keyValueMetaDataToMap(f.meta.KeyValueMetadata)
 }
 
+// MetaDataKeyValues returns the metadata key-value pairs stored in the parquet file as they are, in their order and
+// including the duplicate keys and the pairs without a value.
+func (f *FileReader) MetaDataKeyValues() []KeyValue {
+	return keyValueMetaDataToSlice(f.meta.KeyValueMetadata)
+}
+
 // ColumnMetaData returns a map of metadata key-value pairs for the provided column in the current
 // row group. The column name has to be provided in its dotted notation.
 func (f *FileReader) ColumnMetaData(colName string) (map[string]string, error) {
-	for _, col := range f.CurrentRowGroup().Columns {
+	rg := f.CurrentRowGroup()
+	if rg == nil {
+		return nil, errors.New("no row group is loaded")
+	}
+	for _, col := range rg.Columns {
 		if colName == strings.Join(col.MetaData.PathInSchema, ".") {
 			return keyValueMetaDataToMap(col.MetaData.KeyValueMetadata), nil
 		}
@@ -133,12 +507,281 @@ func (f *FileReader) ColumnMetaData(colName string) (map[string]string, error) {
 	return nil, fmt.Errorf("column %q not found", colName)
 }
 
+// ColumnChunkMetaData returns the metadata key-value pairs of the column chunk of the column colName in the row group
+// with the index rowGroup, like MetaDataKeyValues. The column name has to be provided in its dotted notation.
+func (f *FileReader) ColumnChunkMetaData(rowGroup int, colName string) ([]KeyValue, error) {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := rg.Columns[col.Index()].MetaData
+	if meta == nil {
+		return nil, errors.Errorf("missing meta data of column %q", colName)
+	}
+	return keyValueMetaDataToSlice(meta.KeyValueMetadata), nil
+}
+
+func keyValueMetaDataToSlice(kvMetaData []*parquet.KeyValue) []KeyValue {
+	data := make([]KeyValue, 0, len(kvMetaData))
+	for _, kv := range kvMetaData {
+		if kv == nil {
+			continue
+		}
+		pair := KeyValue{Key: kv.Key}
+		if kv.Value != nil {
+			value := *kv.Value
+			pair.Value = &value
+		}
+		data = append(data, pair)
+	}
+	return data
+}
+
 func keyValueMetaDataToMap(kvMetaData []*parquet.KeyValue) map[string]string {
 	data := make(map[string]string)
 	for _, kv := range kvMetaData {
-		if kv.Value != nil {
+		if kv != nil && kv.Value != nil {
 			data[kv.Key] = *kv.Value
 		}
 	}
 	return data
 }
+
+// columnChunk returns the column colName and the row group with the index rowGroup, which has the column chunk of
+// the column.
+func (f *FileReader) columnChunk(rowGroup int, colName string) (*Column, *parquet.RowGroup, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, nil, errors.Errorf("row group %d is out of range, the file has %d row groups", rowGroup, len(f.meta.RowGroups))
+	}
+
+	col := f.SchemaReader.GetColumnByName(colName)
+	if col == nil {
+		return nil, nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+
+	return col, rg, nil
+}
+
+// readColumnValues reads the column chunk of the column colName in the row group with the index rowGroup, and calls
+// fn with the values decoder and the number of not null values of each of its pages.
+func (f *FileReader) readColumnValues(rowGroup int, colName string, typ parquet.Type, fn func(dec valuesDecoder, n int) error) error {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return err
+	}
+	if t := col.Type(); t == nil || *t != typ {
+		return errors.Errorf("column %q is not of type %s", colName, typ)
+	}
+	if err := checkCodecs([]*Column{col}, rg, func(string) bool { return true }); err != nil {
+		return err
+	}
+
+	pages, err := readChunk(f.sectionReader(), col, rg.Columns[col.Index()], nil, f.validateCRC, nil)
+	if err != nil {
+		return errors.Wrapf(err, "reading column %q failed", colName)
+	}
+
+	for _, p := range pages {
+		dec, n, err := p.notNullValues()
+		if err != nil {
+			return errors.Wrapf(err, "reading column %q failed", colName)
+		}
+		if n > 0 {
+			if err := fn(dec, n); err != nil {
+				return errors.Wrapf(err, "reading values of column %q failed", colName)
+			}
+		}
+		p.release()
+	}
+
+	return nil
+}
+
+func checkBatchRead(n, expected int, err error) error {
+	if err == io.EOF || (err == nil && n < expected) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ReadInt32Values reads the values of the INT32 column colName from the row group with the index rowGroup and
+// appends them to dst, without boxing each of them in an interface{}. Null values are skipped, and values of
+// unsigned columns are returned with the same bit pattern. It does not affect the row based reading with NextRow.
+func (f *FileReader) ReadInt32Values(rowGroup int, colName string, dst []int32) ([]int32, error) {
+	err := f.readColumnValues(rowGroup, colName, parquet.Type_INT32, func(dec valuesDecoder, n int) error {
+		bd, ok := dec.(int32BatchDecoder)
+		if !ok {
+			return errors.Errorf("decoder %T does not support reading int32 values", dec)
+		}
+		start := len(dst)
+		dst = append(dst, make([]int32, n)...)
+		read, err := bd.decodeInt32Batch(dst[start:])
+		return checkBatchRead(read, n, err)
+	})
+	return dst, err
+}
+
+// ReadInt64Values reads the values of the INT64 column colName from the row group with the index rowGroup and
+// appends them to dst, without boxing each of them in an interface{}. Null values are skipped, and values of
+// unsigned columns are returned with the same bit pattern. It does not affect the row based reading with NextRow.
+func (f *FileReader) ReadInt64Values(rowGroup int, colName string, dst []int64) ([]int64, error) {
+	err := f.readColumnValues(rowGroup, colName, parquet.Type_INT64, func(dec valuesDecoder, n int) error {
+		bd, ok := dec.(int64BatchDecoder)
+		if !ok {
+			return errors.Errorf("decoder %T does not support reading int64 values", dec)
+		}
+		start := len(dst)
+		dst = append(dst, make([]int64, n)...)
+		read, err := bd.decodeInt64Batch(dst[start:])
+		return checkBatchRead(read, n, err)
+	})
+	return dst, err
+}
+
+// ReadFloat64Values reads the values of the DOUBLE column colName from the row group with the index rowGroup and
+// appends them to dst, without boxing each of them in an interface{}. Null values are skipped. It does not affect
+// the row based reading with NextRow.
+func (f *FileReader) ReadFloat64Values(rowGroup int, colName string, dst []float64) ([]float64, error) {
+	err := f.readColumnValues(rowGroup, colName, parquet.Type_DOUBLE, func(dec valuesDecoder, n int) error {
+		bd, ok := dec.(float64BatchDecoder)
+		if !ok {
+			return errors.Errorf("decoder %T does not support reading float64 values", dec)
+		}
+		start := len(dst)
+		dst = append(dst, make([]float64, n)...)
+		read, err := bd.decodeFloat64Batch(dst[start:])
+		return checkBatchRead(read, n, err)
+	})
+	return dst, err
+}
+
+// ReadByteArrayValues reads the values of the BYTE_ARRAY or FIXED_LEN_BYTE_ARRAY column colName from the row group
+// with the index rowGroup and appends them to dst. Null values are skipped. Values of dictionary encoded pages share
+// the memory of the dictionary, so they must not be modified. It does not affect the row based reading with NextRow.
+func (f *FileReader) ReadByteArrayValues(rowGroup int, colName string, dst [][]byte) ([][]byte, error) {
+	typ := parquet.Type_BYTE_ARRAY
+	if col := f.SchemaReader.GetColumnByName(colName); col != nil && col.Type() != nil && *col.Type() == parquet.Type_FIXED_LEN_BYTE_ARRAY {
+		typ = parquet.Type_FIXED_LEN_BYTE_ARRAY
+	}
+	err := f.readColumnValues(rowGroup, colName, typ, func(dec valuesDecoder, n int) error {
+		bd, ok := dec.(byteArrayBatchDecoder)
+		if !ok {
+			return errors.Errorf("decoder %T does not support reading byte array values", dec)
+		}
+		start := len(dst)
+		dst = append(dst, make([][]byte, n)...)
+		read, err := bd.decodeByteArrayBatch(dst[start:])
+		return checkBatchRead(read, n, err)
+	})
+	return dst, err
+}
+
+// ReadColumnRows reads the rows from to to (exclusive) of the column colName in the row group with the index
+// rowGroup. Each row is the value of the column, or nil if it is null. For repeated columns, each row is a
+// []interface{} with the values of the row that are not null. If the column chunk has an offset index, only the
+// pages that contain the rows are read, otherwise all pages are read and the rows before from are discarded. It does
+// not affect the row based reading with NextRow.
+func (f *FileReader) ReadColumnRows(rowGroup int, colName string, from, to int64) ([]interface{}, error) {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return nil, err
+	}
+	if from < 0 || from > to || to > rg.NumRows {
+		return nil, errors.Errorf("invalid rows %d to %d, the row group has %d rows", from, to, rg.NumRows)
+	}
+	if from == to {
+		return nil, nil
+	}
+	if err := checkCodecs([]*Column{col}, rg, func(string) bool { return true }); err != nil {
+		return nil, err
+	}
+
+	chunk := rg.Columns[col.Index()]
+	r := f.sectionReader()
+	index, err := readOffsetIndex(r, chunk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading column %q failed", colName)
+	}
+
+	var (
+		locations []*parquet.PageLocation
+		firstRow  int64
+	)
+	if index != nil {
+		locations, firstRow = pagesForRows(index, from, to)
+	}
+
+	pages, err := readChunk(r, col, chunk, nil, f.validateCRC, locations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading column %q failed", colName)
+	}
+
+	rows, err := pageRows(col, pages, from-firstRow, to-firstRow)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading column %q failed", colName)
+	}
+	return rows, nil
+}
+
+// pageRows returns the rows from to to (exclusive) of the pages, counted from the first row of the first page.
+func pageRows(col *Column, pages []pageReader, from, to int64) ([]interface{}, error) {
+	maxD := int32(col.MaxDefinitionLevel())
+	repeated := col.MaxRepetitionLevel() > 0
+
+	rows := make([]interface{}, 0, to-from)
+	row := int64(-1)
+	for _, p := range pages {
+		data := make([]interface{}, p.numValues())
+		n, dLevels, rLevels, err := p.readValues(data)
+		p.release()
+		if err != nil {
+			return nil, err
+		}
+		if int32(n) != p.numValues() {
+			return nil, errors.Errorf("expect %d value but read %d", p.numValues(), n)
+		}
+
+		// the values that are not null are at the start of data
+		next := 0
+		for i := 0; i < n; i++ {
+			dl, _ := dLevels.at(i)
+			rl, _ := rLevels.at(i)
+			if rl == 0 {
+				row++
+			}
+
+			var v interface{}
+			if dl == maxD {
+				v = data[next]
+				next++
+			}
+			if row < from || row >= to {
+				continue
+			}
+
+			switch {
+			case !repeated:
+				rows = append(rows, v)
+			case rl == 0:
+				var values []interface{}
+				if v != nil {
+					values = append(values, v)
+				}
+				rows = append(rows, values)
+			case v != nil:
+				rows[len(rows)-1] = append(rows[len(rows)-1].([]interface{}), v)
+			}
+		}
+	}
+
+	if int64(len(rows)) != to-from {
+		return nil, errors.Errorf("expected %d rows but the pages have %d", to-from, len(rows))
+	}
+	return rows, nil
+}
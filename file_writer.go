@@ -1,14 +1,21 @@
 package goparquet
 
 import (
+	"context"
 	"encoding/binary"
-	"errors"
 	"io"
+	"math"
+	"sort"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
 )
 
+// ErrWriterClosed is returned by the methods of a FileWriter that write to the file after Close was called, and by
+// a second call of Close.
+var ErrWriterClosed = errors.New("the file writer is closed")
+
 // FileWriter is used to write data to a parquet file. Always use NewFileWriter
 // to create such an object.
 type FileWriter struct {
@@ -18,23 +25,70 @@ type FileWriter struct {
 	SchemaWriter
 
 	totalNumRecords int64
-	kvStore         map[string]string
+	kvStore         []*parquet.KeyValue
 	createdBy       string
 
 	rowGroupFlushSize int64
+	rowGroupRowLimit  int64
 
-	rowGroups []*parquet.RowGroup
+	rowGroups      []*parquet.RowGroup
+	pageIndexes    []*pageIndex
+	sortingColumns []*parquet.SortingColumn
 
-	codec parquet.CompressionCodec
+	codec                  parquet.CompressionCodec
+	columnCodecs           map[string]parquet.CompressionCodec
+	columnCompressorOpts   map[string]CompressorOptions
+	compressorOpts         CompressorOptions
+	compressionConcurrency int
 
 	newPage newDataPageFunc
+	// columnPageV2 is true for the columns of WithDataPageV2ForColumn and false for the ones of
+	// WithDataPageV1ForColumn.
+	columnPageV2 map[string]bool
+
+	columnEncodings  map[string]parquet.Encoding
+	columnDicts      map[string]bool
+	dictDefault      *bool
+	deltaIntegers    bool
+	encodingsApplied bool
+
+	maxDictSize int64
+	maxPageSize int64
+	maxPageRows int
+	enableCRC   bool
+
+	maxStatsSize int
+
+	ctx    context.Context
+	closed bool
 }
 
+// defaultRowGroupTargetSize is the default size of the row groups, the same default that
+// parquet-mr uses.
+const defaultRowGroupTargetSize = 128 * 1024 * 1024
+
+// defaultMaxDictSize is the default maximum size of a column chunk dictionary, the
+// same default that parquet-mr uses.
+const defaultMaxDictSize = 1024 * 1024
+
+// defaultMaxPageSize is the default size of the uncompressed data of a data page, the same
+// default that parquet-mr uses.
+const defaultMaxPageSize = 1024 * 1024
+
+// defaultMaxPageRows is the default maximum number of rows of a data page, the same default
+// that parquet-mr uses.
+const defaultMaxPageRows = 20000
+
+// defaultMaxStatsSize is the default maximum size of the min and max value in the statistics
+// of a data page, the same size that parquet-mr allows for both of them.
+const defaultMaxStatsSize = 4096
+
 // FileWriterOption describes an option function that is applied to a FileWriter when it is created.
 type FileWriterOption func(fw *FileWriter)
 
 // NewFileWriter creates a new FileWriter. You can provide FileWriterOptions to influence the
-// file writer's behaviour.
+// file writer's behaviour. The file is written from start to end, w is never seeked, so it
+// can be a stream like an HTTP response body or a multipart upload.
 func NewFileWriter(w io.Writer, options ...FileWriterOption) *FileWriter {
 	fw := &FileWriter{
 		w: &writePosStruct{
@@ -43,15 +97,24 @@ func NewFileWriter(w io.Writer, options ...FileWriterOption) *FileWriter {
 		},
 		version:      1,
 		SchemaWriter: &schema{},
-		kvStore:      make(map[string]string),
 		rowGroups:    []*parquet.RowGroup{},
-		createdBy:    "parquet-go",
+		createdBy:    defaultCreatedBy,
 		newPage:      newDataPageV1Writer,
+		maxDictSize:  defaultMaxDictSize,
+		maxPageSize:  defaultMaxPageSize,
+		maxPageRows:  defaultMaxPageRows,
+		maxStatsSize: defaultMaxStatsSize,
+		enableCRC:    true,
+
+		rowGroupFlushSize: defaultRowGroupTargetSize,
 	}
 
 	for _, opt := range options {
 		opt(fw)
 	}
+	if fw.ctx != nil {
+		fw.w = &writePosStruct{w: &contextWriter{ctx: fw.ctx, w: w}}
+	}
 
 	return fw
 }
@@ -63,7 +126,10 @@ func FileVersion(version int32) FileWriterOption {
 	}
 }
 
-// WithCreator sets the creator in the meta data of the file.
+// WithCreator sets the creator in the created_by field of the meta data of the file. Readers use it to work around
+// bugs of older versions of writers, so it should have the format "<application> version <version> (build <hash>)".
+// The default is "parquet-go version <version> (build <hash>)" with the version and hash of this module, see
+// FileReader.CreatedBy.
 func WithCreator(createdBy string) FileWriterOption {
 	return func(fw *FileWriter) {
 		fw.createdBy = createdBy
@@ -77,31 +143,118 @@ func WithCompressionCodec(codec parquet.CompressionCodec) FileWriterOption {
 	}
 }
 
-// WithMetaData sets the key-value meta data on the file.
+// WithColumnCompression sets the compression codec of the column col, given by its full
+// dotted-notation name, overriding the codec of WithCompressionCodec for this column, e.g.
+// WithColumnCompression("blob", parquet.CompressionCodec_UNCOMPRESSED) for a column of data that
+// is already compressed. The options of WithCompressorOptions only apply to the columns with the
+// codec of the file, use WithColumnCompressionOptions to set the options of the column.
+// FlushRowGroup returns an error if the column doesn't exist or there is no compressor registered
+// for the codec.
+func WithColumnCompression(col string, codec parquet.CompressionCodec) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnCodecs == nil {
+			fw.columnCodecs = make(map[string]parquet.CompressionCodec)
+		}
+		fw.columnCodecs[col] = codec
+		delete(fw.columnCompressorOpts, col)
+	}
+}
+
+// WithColumnCompressionOptions sets the compression codec of the column col like WithColumnCompression,
+// and the codec specific options of its compressor, e.g. a higher zstd level for a column that is rarely
+// read. The options of WithCompressorOptions don't apply to the column. The codec must support the
+// options, see ConfigurableBlockCompressor.
+func WithColumnCompressionOptions(col string, codec parquet.CompressionCodec, opts CompressorOptions) FileWriterOption {
+	return func(fw *FileWriter) {
+		WithColumnCompression(col, codec)(fw)
+		if fw.columnCompressorOpts == nil {
+			fw.columnCompressorOpts = make(map[string]CompressorOptions)
+		}
+		fw.columnCompressorOpts[col] = opts
+	}
+}
+
+// WithCompressorOptions sets the codec specific options of the compression codec, e.g. the gzip or zstd level
+// or the brotli quality. The codec must support the options, see ConfigurableBlockCompressor.
+func WithCompressorOptions(opts CompressorOptions) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.compressorOpts = opts
+	}
+}
+
+// WithMetaData sets the key-value meta data on the file. It replaces the key-value meta data of
+// SetKeyValueMetaData and AppendKeyValueMetaData calls of earlier options.
 func WithMetaData(data map[string]string) FileWriterOption {
 	return func(fw *FileWriter) {
-		if data != nil {
-			fw.kvStore = data
-			return
+		fw.kvStore = nil
+		for k, v := range data {
+			fw.AppendKeyValueMetaData(k, v)
 		}
-		fw.kvStore = make(map[string]string)
 	}
 }
 
+// SetKeyValueMetaData sets the value of the key in the key-value meta data of the file, replacing
+// all values that the key had before. It can be called any time before Close. An empty value is
+// written as a key without value. The key-value meta data is written sorted by key, the values of
+// a key in the order in which they were added.
+func (fw *FileWriter) SetKeyValueMetaData(key, value string) {
+	kv := fw.kvStore[:0]
+	for _, entry := range fw.kvStore {
+		if entry.Key != key {
+			kv = append(kv, entry)
+		}
+	}
+	fw.kvStore = kv
+	fw.AppendKeyValueMetaData(key, value)
+}
+
+// AppendKeyValueMetaData adds the key and value to the key-value meta data of the file, also if
+// the key already has a value. Use it only if the readers of the file expect duplicate keys, most
+// readers use only one of the values.
+func (fw *FileWriter) AppendKeyValueMetaData(key, value string) {
+	kv := &parquet.KeyValue{Key: key}
+	if value != "" {
+		kv.Value = &value
+	}
+	fw.kvStore = append(fw.kvStore, kv)
+}
+
 // WithMaxRowGroupSize sets the rough maximum size of a row group before it shall
 // be flushed automatically. Please note that enabling auto-flush will not allow
 // you to set per-column-chunk meta-data upon calling FlushRowGroup. If you
-// require this feature, you need to flush your rowgroups manually.
+// require this feature, you need to flush your rowgroups manually. It is the
+// same as WithRowGroupTargetSize.
 func WithMaxRowGroupSize(size int64) FileWriterOption {
+	return WithRowGroupTargetSize(size)
+}
+
+// WithRowGroupTargetSize sets the target size in bytes of the row groups. AddData
+// flushes the current row group once the estimated uncompressed size of its column
+// chunks, the values and their levels, reaches this size. The last row group that
+// is flushed by Close can be smaller. The default is 128 MiB like parquet-mr, a
+// size of 0 or less disables the automatic flushing.
+func WithRowGroupTargetSize(size int64) FileWriterOption {
 	return func(fw *FileWriter) {
 		fw.rowGroupFlushSize = size
 	}
 }
 
+// WithRowGroupRowLimit sets the maximum number of rows of the row groups. AddData
+// flushes the current row group once it has this many records, or once it reaches
+// the size set by WithRowGroupTargetSize, whichever comes first. The records are
+// counted, not the values of repeated columns. The limit is disabled by default.
+// WriteColumns always writes its values into a single row group.
+func WithRowGroupRowLimit(rows int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.rowGroupRowLimit = rows
+	}
+}
+
 // WithSchemaDefinition sets the schema definition to use for this parquet file.
 func WithSchemaDefinition(sd *parquetschema.SchemaDefinition) FileWriterOption {
 	return func(fw *FileWriter) {
-		if err := fw.SetSchemaDefinition(sd); err != nil {
+		// the options after this one are not set yet, they are validated when the first values are added
+		if err := fw.SchemaWriter.SetSchemaDefinition(sd); err != nil {
 			panic(err)
 		}
 	}
@@ -116,6 +269,162 @@ func WithDataPageV2() FileWriterOption {
 	}
 }
 
+// WithDataPageV2ForColumn enables the V2 page format for a single column, identified by its
+// full dotted-notation name, while the other columns use the format of the file writer.
+// FlushRowGroup returns an error if the column doesn't exist.
+func WithDataPageV2ForColumn(col string) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnPageV2 == nil {
+			fw.columnPageV2 = make(map[string]bool)
+		}
+		fw.columnPageV2[col] = true
+	}
+}
+
+// WithDataPageV1ForColumn keeps the V1 page format for a single column, identified by its full
+// dotted-notation name, in a file with V2 pages of WithDataPageV2, e.g. for a reader of the
+// column that doesn't support V2 pages. FlushRowGroup returns an error if the column doesn't
+// exist.
+func WithDataPageV1ForColumn(col string) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnPageV2 == nil {
+			fw.columnPageV2 = make(map[string]bool)
+		}
+		fw.columnPageV2[col] = false
+	}
+}
+
+// WithColumnEncoding sets the value encoding of the column col, given by its full dotted-notation
+// name, e.g. WithColumnEncoding("event.payload", parquet.Encoding_DELTA_BYTE_ARRAY). An encoding
+// other than PLAIN_DICTIONARY and RLE_DICTIONARY disables the dictionary of the column, these two
+// use a dictionary if the values fit into it and PLAIN otherwise, like the columns without an
+// encoding. The columns of a schema definition without an encoding are PLAIN, with a dictionary
+// except for FLOAT and DOUBLE columns. The encoding must be supported for the type of the column,
+// e.g. DELTA_BINARY_PACKED only for INT32 and INT64 columns, or AddData, WriteColumns and
+// FlushRowGroup return an error. They also return an error if the column doesn't exist, and so
+// does SetSchemaDefinition if it is called after the option.
+func WithColumnEncoding(col string, enc parquet.Encoding) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnEncodings == nil {
+			fw.columnEncodings = make(map[string]parquet.Encoding)
+		}
+		fw.columnEncodings[col] = enc
+	}
+}
+
+// WithDeltaEncodingForIntegers uses the DELTA_BINARY_PACKED encoding instead of PLAIN for the
+// INT32 and INT64 columns that have no encoding set by WithColumnEncoding. The columns still use
+// a dictionary if their values fit into it.
+func WithDeltaEncodingForIntegers() FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.deltaIntegers = true
+	}
+}
+
+// WithDictionaryEncoding enables or disables the dictionary of the column col, given by its full
+// dotted-notation name. A column with an enabled dictionary uses it if the values fit into it and
+// its encoding otherwise, see WithMaxDictionarySize. A disabled dictionary is never built, which
+// saves hashing the values of columns with mostly distinct values, e.g. UUIDs or free text, and the
+// column is written with its encoding right away. This option takes precedence over the dictionary
+// of WithColumnEncoding and WithDictionaryEncodingDefault. AddData, WriteColumns and FlushRowGroup
+// return an error if the column doesn't exist, or if the dictionary is enabled for a BOOLEAN column.
+func WithDictionaryEncoding(col string, enabled bool) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnDicts == nil {
+			fw.columnDicts = make(map[string]bool)
+		}
+		fw.columnDicts[col] = enabled
+	}
+}
+
+// WithDictionaryEncodingDefault enables or disables the dictionary of the columns without an
+// encoding of WithColumnEncoding or a dictionary of WithDictionaryEncoding. By default, the columns
+// of a schema definition use a dictionary except for FLOAT and DOUBLE columns, and the columns of
+// AddColumn use a dictionary if their store allows it. BOOLEAN columns never use a dictionary.
+func WithDictionaryEncodingDefault(enabled bool) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.dictDefault = &enabled
+	}
+}
+
+// WithMaxDictionarySize sets the maximum size in bytes of the dictionary of a column
+// chunk. The size is checked after each data page. Once the distinct values of the pages
+// exceed this size, the page and the rest of the column chunk are written using the column's
+// encoding (usually PLAIN), and the dictionary page only has the values of the pages before.
+// If the first page exceeds it already, the column chunk has no dictionary. The default is
+// 1 MiB, a size of 0 or less disables the limit.
+func WithMaxDictionarySize(size int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxDictSize = size
+	}
+}
+
+// WithMaxPageSize sets the size in bytes of the data pages. A data page is flushed once its
+// estimated uncompressed size reaches this size, a column chunk is written into as many data
+// pages as it needs. Pages end at row boundaries, so a page can be larger if a single row is.
+// The default is 1 MiB, a size of 0 or less writes each column chunk into a single data page.
+func WithMaxPageSize(size int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxPageSize = size
+	}
+}
+
+// WithMaxPageRows sets the maximum number of rows of the data pages. A data page is flushed
+// once it has this many rows, or once it reaches the size set by WithMaxPageSize, whichever
+// comes first. The rows of repeated columns are counted by their records, so a record never
+// spans two pages. The default is 20000 rows, a limit of 0 or less disables it.
+func WithMaxPageRows(rows int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxPageRows = rows
+	}
+}
+
+// WithMaxStatisticsSize sets the maximum size in bytes of the min and max value in the
+// statistics of a data page. If the min or the max value of a page is longer, e.g. a long
+// BYTE_ARRAY value, the statistics of the page have only the null count. The default is
+// 4096 bytes, a size of 0 or less disables the limit.
+func WithMaxStatisticsSize(size int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxStatsSize = size
+	}
+}
+
+// WithCRC enables or disables the CRC32 checksums of the pages. The checksums are written by
+// default, like parquet-mr does, disable them to save the time to compute them.
+func WithCRC(enable bool) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.enableCRC = enable
+	}
+}
+
+// WithCompressionConcurrency compresses the data pages in n goroutines, while the next pages are encoded. The pages
+// are written in the same order as without it, so the file is the same. A row group is only flushed once all its
+// pages are compressed, and a compression error is returned by the flush. By default, the pages are compressed one
+// after the other. With n larger than 1, the BlockCompressor of a codec is called from several goroutines at once, so
+// a compressor registered with RegisterBlockCompressor must be safe for concurrent use.
+func WithCompressionConcurrency(n int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.compressionConcurrency = n
+	}
+}
+
+// WithWriterContext sets a context for writing the file. Once the context is done, all writes to the file fail, and
+// FlushRowGroup, AddData and Close return the error of the context. Writing stops between two writes to the file,
+// e.g. between the pages of a column chunk. The file is incomplete in that case.
+func WithWriterContext(ctx context.Context) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.ctx = ctx
+	}
+}
+
+// replaceWithContextErr replaces the error *err with the error of the context of the writer if the context is done,
+// as writing failed because of it.
+func (fw *FileWriter) replaceWithContextErr(err *error) {
+	if *err != nil && fw.ctx != nil && fw.ctx.Err() != nil {
+		*err = fw.ctx.Err()
+	}
+}
+
 type flushRowGroupOptionHandle struct {
 	cols   map[string]map[string]string
 	global map[string]string
@@ -174,11 +483,193 @@ func WithRowGroupMetaData(kv map[string]string) FlushRowGroupOption {
 	}
 }
 
-// FlushRowGroup writes the current row group to the parquet file.
-func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
-	// Write the entire row group
+// SetSchemaDefinition sets the schema definition of the file, like WithSchemaDefinition does. It returns an error
+// if an option of WithColumnEncoding or WithDictionaryEncoding doesn't match a column of the schema definition or
+// isn't supported for the type of its column.
+func (fw *FileWriter) SetSchemaDefinition(sd *parquetschema.SchemaDefinition) error {
+	if err := fw.SchemaWriter.SetSchemaDefinition(sd); err != nil {
+		return err
+	}
+	// the columns of the schema definition have new stores with the default encodings
+	fw.encodingsApplied = false
+	_, err := fw.resolveColumnEncodings()
+	return err
+}
+
+// columnEncoding is the value encoding of a column and whether it can use a dictionary.
+type columnEncoding struct {
+	col       *Column
+	enc       parquet.Encoding
+	allowDict bool
+}
+
+// applyColumnEncodings sets the encodings of WithColumnEncoding, WithDeltaEncodingForIntegers,
+// WithDictionaryEncoding and WithDictionaryEncodingDefault on the column stores. It does so only
+// once, before the first values are added, as the schema definition can be set after the options.
+// If an option is invalid, no store is changed and the next call returns the error again.
+func (fw *FileWriter) applyColumnEncodings() error {
+	if fw.encodingsApplied {
+		return nil
+	}
+
+	encodings, err := fw.resolveColumnEncodings()
+	if err != nil {
+		return err
+	}
+	for _, e := range encodings {
+		e.col.data.setEncoding(e.enc, e.allowDict)
+	}
+	fw.encodingsApplied = true
+
+	return nil
+}
+
+// resolveColumnEncodings validates the encoding options and returns the encodings of the columns that differ from
+// the ones of their stores.
+func (fw *FileWriter) resolveColumnEncodings() ([]columnEncoding, error) {
+	for name := range fw.columnEncodings {
+		if col := fw.SchemaWriter.GetColumnByName(name); col == nil || col.data == nil {
+			return nil, errors.Errorf("column %q of WithColumnEncoding not found", name)
+		}
+	}
+	for name := range fw.columnDicts {
+		if col := fw.SchemaWriter.GetColumnByName(name); col == nil || col.data == nil {
+			return nil, errors.Errorf("column %q of WithDictionaryEncoding not found", name)
+		}
+	}
+
+	var encodings []columnEncoding
+	for _, col := range fw.SchemaWriter.Columns() {
+		typ := col.data.parquetType()
+		enc, allowDict := col.data.enc, col.data.allowDict
+		if e, ok := fw.columnEncodings[col.FlatName()]; ok {
+			allowDict = e == parquet.Encoding_PLAIN_DICTIONARY || e == parquet.Encoding_RLE_DICTIONARY
+			if allowDict && typ == parquet.Type_BOOLEAN {
+				return nil, errors.Errorf("invalid encoding %s of column %q: BOOLEAN columns can't use a dictionary", e, col.FlatName())
+			}
+			enc = e
+			if allowDict {
+				enc = parquet.Encoding_PLAIN
+			}
+		} else {
+			if fw.deltaIntegers && (typ == parquet.Type_INT32 || typ == parquet.Type_INT64) {
+				enc = parquet.Encoding_DELTA_BINARY_PACKED
+			}
+			if fw.dictDefault != nil && typ != parquet.Type_BOOLEAN {
+				allowDict = *fw.dictDefault
+			}
+		}
+		if enabled, ok := fw.columnDicts[col.FlatName()]; ok {
+			if enabled && typ == parquet.Type_BOOLEAN {
+				return nil, errors.Errorf("invalid dictionary encoding of column %q: BOOLEAN columns can't use a dictionary", col.FlatName())
+			}
+			allowDict = enabled
+		}
+		if enc == col.data.enc && allowDict == col.data.allowDict {
+			continue
+		}
+
+		// the store is only created to check that the encoding is supported for the type
+		if _, err := newColumnStore(typ, enc, allowDict, col.params); err != nil {
+			return nil, errors.Wrapf(err, "invalid encoding %s of column %q", enc, col.FlatName())
+		}
+		encodings = append(encodings, columnEncoding{col: col, enc: enc, allowDict: allowDict})
+	}
+
+	return encodings, nil
+}
+
+// compressorFunc returns the function that returns the compression codec and the block compressor
+// of a column, with the codecs and options of WithColumnCompression and WithColumnCompressionOptions,
+// and the codec and options of the file for the others.
+func (fw *FileWriter) compressorFunc() (func(col string) (parquet.CompressionCodec, BlockCompressor), error) {
+	compressor, err := getBlockCompressor(fw.codec, fw.compressorOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	compressors := map[compressorKey]BlockCompressor{{fw.codec, fw.compressorOpts}: compressor}
+	for name := range fw.columnCodecs {
+		if col := fw.SchemaWriter.GetColumnByName(name); col == nil || col.data == nil {
+			return nil, errors.Errorf("column %q of WithColumnCompression not found", name)
+		}
+		key := fw.compressorKey(name)
+		if _, ok := compressors[key]; ok {
+			continue
+		}
+		if compressors[key], err = getBlockCompressor(key.codec, key.opts); err != nil {
+			return nil, errors.Wrapf(err, "invalid compression codec of column %q", name)
+		}
+	}
+
+	return func(col string) (parquet.CompressionCodec, BlockCompressor) {
+		key := fw.compressorKey(col)
+		return key.codec, compressors[key]
+	}, nil
+}
+
+// compressorKey identifies the block compressors of the columns, the columns with the same codec and options share
+// one.
+type compressorKey struct {
+	codec parquet.CompressionCodec
+	opts  CompressorOptions
+}
+
+// compressorKey returns the codec and the compressor options of the column col.
+func (fw *FileWriter) compressorKey(col string) compressorKey {
+	codec, ok := fw.columnCodecs[col]
+	if !ok {
+		codec = fw.codec
+	}
+	opts, ok := fw.columnCompressorOpts[col]
+	if !ok && codec == fw.codec {
+		opts = fw.compressorOpts
+	}
+	return compressorKey{codec: codec, opts: opts}
+}
+
+// pageFunc returns the function that returns the function to create the data page writers of a
+// column, with the page formats of WithDataPageV2ForColumn and WithDataPageV1ForColumn, and the
+// format of the file for the others.
+func (fw *FileWriter) pageFunc() (func(col string) newDataPageFunc, error) {
+	for name, v2 := range fw.columnPageV2 {
+		if col := fw.SchemaWriter.GetColumnByName(name); col == nil || col.data == nil {
+			option := "WithDataPageV1ForColumn"
+			if v2 {
+				option = "WithDataPageV2ForColumn"
+			}
+			return nil, errors.Errorf("column %q of %s not found", name, option)
+		}
+	}
+
+	return func(col string) newDataPageFunc {
+		v2, ok := fw.columnPageV2[col]
+		switch {
+		case !ok:
+			return fw.newPage
+		case v2:
+			return newDataPageV2Writer
+		default:
+			return newDataPageV1Writer
+		}
+	}, nil
+}
+
+// FlushRowGroup writes the current row group to the parquet file, even if it is smaller than the row group size
+// and row limit, so that the row groups can end where the batches of the caller end. It does nothing if no rows
+// were added since the last row group, it doesn't write empty row groups.
+func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) (err error) {
+	defer fw.replaceWithContextErr(&err)
+
+	if fw.closed {
+		return ErrWriterClosed
+	}
+	if err := fw.applyColumnEncodings(); err != nil {
+		return err
+	}
+
 	if fw.rowGroupNumRecords() == 0 {
-		return errors.New("nothing to write")
+		return nil
 	}
 
 	if fw.w.Pos() == 0 {
@@ -193,17 +684,47 @@ func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
 		o(h)
 	}
 
-	cc, err := writeRowGroup(fw.w, fw.SchemaWriter, fw.codec, fw.newPage, h)
+	compressorFn, err := fw.compressorFunc()
+	if err != nil {
+		return err
+	}
+	pageFn, err := fw.pageFunc()
 	if err != nil {
 		return err
 	}
 
-	fw.rowGroups = append(fw.rowGroups, &parquet.RowGroup{
-		Columns:        cc,
-		TotalByteSize:  0,
-		NumRows:        fw.rowGroupNumRecords(),
-		SortingColumns: nil,
-	})
+	var pool *compressPool
+	if fw.compressionConcurrency > 1 {
+		pool = newCompressPool(fw.compressionConcurrency)
+		defer pool.close()
+	}
+
+	rowGroupOffset := fw.w.Pos()
+	cc, indexes, err := writeRowGroup(fw.w, fw.SchemaWriter, compressorFn, pageFn, fw.maxDictSize, fw.maxPageSize, fw.maxPageRows, fw.enableCRC, fw.maxStatsSize, h, pool)
+	if err != nil {
+		return err
+	}
+
+	var totalByteSize, totalCompressedSize int64
+	for _, c := range cc {
+		totalByteSize += c.MetaData.TotalUncompressedSize
+		totalCompressedSize += c.MetaData.TotalCompressedSize
+	}
+	rg := &parquet.RowGroup{
+		Columns:             cc,
+		TotalByteSize:       totalByteSize,
+		NumRows:             fw.rowGroupNumRecords(),
+		SortingColumns:      fw.sortingColumns,
+		FileOffset:          &rowGroupOffset,
+		TotalCompressedSize: &totalCompressedSize,
+	}
+	// the ordinal is only an int16, it is left out in files with more row groups
+	if len(fw.rowGroups) <= math.MaxInt16 {
+		ordinal := int16(len(fw.rowGroups))
+		rg.Ordinal = &ordinal
+	}
+	fw.rowGroups = append(fw.rowGroups, rg)
+	fw.pageIndexes = append(fw.pageIndexes, indexes...)
 	fw.totalNumRecords += fw.rowGroupNumRecords()
 	// flush the schema
 	fw.SchemaWriter.resetData()
@@ -212,12 +733,30 @@ func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
 }
 
 // AddData adds a new record to the current row group and flushes it if auto-flush is enabled and the size
-// is equal to or greater than the configured maximum row group size.
+// is equal to or greater than the configured maximum row group size, or the row group has reached the
+// configured number of rows.
+//
+// The keys of the record are the names of the columns. The value of a group is a map[string]interface{}, or a
+// []map[string]interface{} if the group is repeated, and the values of repeated primitive columns are slices like
+// []int64. A missing key or a nil value is a null value. A record with a null value in a required column or a value
+// of the wrong type returns an error with the path of the column, and nothing of the record is added.
 func (fw *FileWriter) AddData(m map[string]interface{}) error {
+	if fw.closed {
+		return ErrWriterClosed
+	}
+	if fw.ctx != nil && fw.ctx.Err() != nil {
+		return fw.ctx.Err()
+	}
+	if err := fw.applyColumnEncodings(); err != nil {
+		return err
+	}
 	if err := fw.SchemaWriter.AddData(m); err != nil {
 		return err
 	}
 
+	if fw.rowGroupRowLimit > 0 && fw.rowGroupNumRecords() >= fw.rowGroupRowLimit {
+		return fw.FlushRowGroup()
+	}
 	if fw.rowGroupFlushSize > 0 && fw.SchemaWriter.DataSize() >= fw.rowGroupFlushSize {
 		return fw.FlushRowGroup()
 	}
@@ -226,29 +765,38 @@ func (fw *FileWriter) AddData(m map[string]interface{}) error {
 }
 
 // Close flushes the current row group if necessary, taking the provided
-// options into account, and writes the meta data footer to the file.
+// options into account, and writes the meta data footer to the file. A file
+// without rows has no row groups. Close can only be called once, a second call
+// returns ErrWriterClosed, as do all writes after it, also if Close failed.
 // Please be aware that this only finalizes the writing process. If you
 // provided a file as io.Writer when creating the FileWriter, you still need
 // to Close that file handle separately.
-func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
-	if len(fw.rowGroups) == 0 || fw.rowGroupNumRecords() > 0 {
-		if err := fw.FlushRowGroup(opts...); err != nil {
+func (fw *FileWriter) Close(opts ...FlushRowGroupOption) (err error) {
+	defer fw.replaceWithContextErr(&err)
+
+	if fw.closed {
+		return ErrWriterClosed
+	}
+	err = fw.FlushRowGroup(opts...)
+	fw.closed = true
+	if err != nil {
+		return err
+	}
+
+	if fw.w.Pos() == 0 {
+		if err := writeFull(fw.w, magic); err != nil {
 			return err
 		}
 	}
 
-	kv := make([]*parquet.KeyValue, 0, len(fw.kvStore))
-	for i := range fw.kvStore {
-		v := fw.kvStore[i]
-		addr := &v
-		if v == "" {
-			addr = nil
-		}
-		kv = append(kv, &parquet.KeyValue{
-			Key:   i,
-			Value: addr,
-		})
+	kv := append(make([]*parquet.KeyValue, 0, len(fw.kvStore)), fw.kvStore...)
+	sort.SliceStable(kv, func(i, j int) bool {
+		return kv[i].Key < kv[j].Key
+	})
+	if err := writePageIndexes(fw.w, fw.pageIndexes); err != nil {
+		return err
 	}
+
 	meta := &parquet.FileMetaData{
 		Version:          fw.version,
 		Schema:           fw.getSchemaArray(),
@@ -272,6 +820,74 @@ func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
 	return writeFull(fw.w, magic)
 }
 
+// WriteColumns writes a row group with the values of all columns provided as typed slices, keyed by the flat name
+// of the column. The values of INT32 columns must be a []int32, of INT64 columns a []int64, of DOUBLE columns a
+// []float64, and of BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns a [][]byte. These columns must be required and not
+// repeated, their values are encoded without boxing them in interfaces, and without a dictionary. The values of
+// any column that is not repeated and not in an optional group can also be a []interface{} with the values as
+// AddData takes them, where a nil value is a null. Nulls are only allowed in optional columns, they are written as
+// definition level 0 and counted in the statistics. All columns must have the same number of values. Rows that
+// were added with AddData before are flushed in their own row group first.
+func (fw *FileWriter) WriteColumns(columns map[string]interface{}, opts ...FlushRowGroupOption) error {
+	if fw.closed {
+		return ErrWriterClosed
+	}
+	if err := fw.applyColumnEncodings(); err != nil {
+		return err
+	}
+	if fw.rowGroupNumRecords() > 0 {
+		if err := fw.FlushRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	cols := fw.SchemaWriter.Columns()
+	if len(columns) > len(cols) {
+		for name := range columns {
+			if fw.SchemaWriter.GetColumnByName(name) == nil {
+				return errors.Errorf("column %q not found", name)
+			}
+		}
+	}
+
+	numRows := -1
+	for _, col := range cols {
+		values, ok := columns[col.FlatName()]
+		if !ok {
+			fw.SchemaWriter.resetData()
+			return errors.Errorf("no values for column %q", col.FlatName())
+		}
+
+		var n int
+		var err error
+		if vals, ok := values.([]interface{}); ok {
+			if col.MaxRepetitionLevel() > 0 || col.MaxDefinitionLevel() > 1 || (col.MaxDefinitionLevel() == 1 && col.rep == parquet.FieldRepetitionType_REQUIRED) {
+				fw.SchemaWriter.resetData()
+				return errors.Errorf("column %q is repeated or in an optional group, it can't be written by WriteColumns", col.FlatName())
+			}
+			n, err = col.data.addValues(vals)
+		} else {
+			if col.MaxDefinitionLevel() > 0 || col.MaxRepetitionLevel() > 0 {
+				fw.SchemaWriter.resetData()
+				return errors.Errorf("column %q is optional or repeated, only required columns can be written as typed values", col.FlatName())
+			}
+			n, err = col.data.setTypedValues(values)
+		}
+		if err != nil {
+			fw.SchemaWriter.resetData()
+			return errors.Wrapf(err, "column %q", col.FlatName())
+		}
+		if numRows >= 0 && n != numRows {
+			fw.SchemaWriter.resetData()
+			return errors.Errorf("column %q has %d values, but the other columns have %d values", col.FlatName(), n, numRows)
+		}
+		numRows = n
+	}
+
+	fw.SchemaWriter.setNumRecords(int64(numRows))
+	return fw.FlushRowGroup(opts...)
+}
+
 // CurrentRowGroupSize returns a rough estimation of the uncompressed size of the current row group data. If you selected
 // a compression format other than UNCOMPRESSED, the final size will most likely be smaller and will dpeend on how well
 // your data can be compressed.
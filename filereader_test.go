@@ -2,10 +2,18 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/require"
 )
@@ -108,3 +116,797 @@ func TestByteReaderSelectedInnerFull(t *testing.T) {
 		require.Empty(t, y)
 	}
 }
+
+// readRecorder records the byte ranges that are read from it.
+type readRecorder struct {
+	*bytes.Reader
+	ranges [][2]int64
+}
+
+func (r *readRecorder) Read(p []byte) (int, error) {
+	offset, err := r.Reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.Reader.Read(p)
+	r.ranges = append(r.ranges, [2]int64{offset, offset + int64(n)})
+	return n, err
+}
+
+func (r *readRecorder) ReadAt(p []byte, offset int64) (int, error) {
+	n, err := r.Reader.ReadAt(p, offset)
+	r.ranges = append(r.ranges, [2]int64{offset, offset + int64(n)})
+	return n, err
+}
+
+func TestRowGroupFilter(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			required int32 day (DATE);
+			optional binary name (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(100))
+	for i := 0; i < 300; i++ {
+		row := map[string]interface{}{"id": int64(i), "day": int32(18000 + i/100)}
+		if i < 200 {
+			row["name"] = []byte(fmt.Sprintf("name %03d", i))
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	rr := &readRecorder{Reader: bytes.NewReader(buf.Bytes())}
+	r, err := NewFileReader(rr)
+	require.NoError(t, err)
+	require.Equal(t, 3, r.RowGroupCount())
+	rr.ranges = nil
+
+	var seen []RowGroupStats
+	r.SetRowGroupFilter(func(rg RowGroupStats) bool {
+		seen = append(seen, rg)
+		skip, err := rg.Columns["id"].NotBetween(int64(150), int64(160))
+		require.NoError(t, err)
+		return !skip
+	})
+
+	for i := 100; i < 200; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	require.Len(t, seen, 3)
+	for i, rg := range seen {
+		require.Equal(t, i, rg.Index)
+		require.Equal(t, int64(100), rg.NumRows)
+		require.Equal(t, int64(i*100), rg.Columns["id"].MinValue)
+		require.Equal(t, int64(i*100+99), rg.Columns["id"].MaxValue)
+		require.Equal(t, int32(18000+i), rg.Columns["day"].MinValue)
+	}
+	require.Equal(t, []byte("name 100"), seen[1].Columns["name"].MinValue)
+	require.Nil(t, seen[2].Columns["name"].MinValue)
+	require.Equal(t, int64(100), seen[2].Columns["name"].NullCount)
+
+	// only the column chunks of the second row group are read
+	columns := r.meta.RowGroups[1].Columns
+	start := chunkStart(columns[0].MetaData)
+	last := columns[len(columns)-1].MetaData
+	end := chunkStart(last) + last.TotalCompressedSize
+	require.NotEmpty(t, rr.ranges)
+	for _, rng := range rr.ranges {
+		require.True(t, rng[0] >= start && rng[1] <= end, "read %d to %d outside of the row group from %d to %d", rng[0], rng[1], start, end)
+	}
+}
+
+// writeWideFile writes a file with 20 columns of different types, in row groups of 10000 rows.
+func writeWideFile(t testing.TB, rows int) []byte {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithRowGroupRowLimit(10000))
+	for i := 0; i < 20; i++ {
+		var (
+			s   *ColumnStore
+			err error
+		)
+		switch i % 4 {
+		case 0:
+			s, err = NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+		case 1:
+			s, err = NewDoubleStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		case 2:
+			s, err = NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		case 3:
+			s, err = NewInt32Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		}
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn(fmt.Sprintf("c%d", i), NewDataColumn(s, parquet.FieldRepetitionType_OPTIONAL)))
+	}
+
+	for i := 0; i < rows; i++ {
+		row := map[string]interface{}{}
+		for c := 0; c < 20; c++ {
+			if (i+c)%7 == 0 {
+				continue
+			}
+			var v interface{}
+			switch c % 4 {
+			case 0:
+				v = int64(i * c)
+			case 1:
+				v = float64(i) / float64(c)
+			case 2:
+				v = []byte(fmt.Sprintf("value %d", (i+c)%100))
+			case 3:
+				v = int32(i % 1000)
+			}
+			row[fmt.Sprintf("c%d", c)] = v
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestReadConcurrency(t *testing.T) {
+	data := writeWideFile(t, 25000)
+
+	readAll := func(data []byte, opts ...FileReaderOption) ([]map[string]interface{}, error) {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return rows, err
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	expected, err := readAll(data)
+	require.NoError(t, err)
+	require.Len(t, expected, 25000)
+	for _, n := range []int{2, 4, 32} {
+		rows, err := readAll(data, WithReadConcurrency(n))
+		require.NoError(t, err)
+		require.Equal(t, expected, rows, "concurrency %d", n)
+
+		rows, err = readAll(data, WithReadConcurrency(n), WithColumns("c3", "c10"))
+		require.NoError(t, err)
+		require.Len(t, rows, 25000)
+		require.Equal(t, map[string]interface{}{"c3": expected[5]["c3"], "c10": expected[5]["c10"]}, rows[5])
+	}
+
+	// the pages of column c13 in the second row group are corrupted
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	md := r.meta.RowGroups[1].Columns[13].MetaData
+	corrupted := append([]byte(nil), data...)
+	for i := chunkStart(md); i < chunkStart(md)+md.TotalCompressedSize; i++ {
+		corrupted[i] = 0xff
+	}
+	for _, n := range []int{1, 4} {
+		rows, err := readAll(corrupted, WithReadConcurrency(n))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `reading row 10000 failed: reading column "c13" failed`)
+		require.Len(t, rows, 10000)
+	}
+}
+
+// slowFailingReaderAt counts and delays the reads of the range [slowStart, slowEnd), and fails the reads of the range
+// [failStart, failEnd) once the first slow read started.
+type slowFailingReaderAt struct {
+	*bytes.Reader
+	failStart, failEnd int64
+	slowStart, slowEnd int64
+	slowReads          int32
+
+	once    sync.Once
+	started chan struct{}
+}
+
+func (r *slowFailingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.failStart && off < r.failEnd {
+		<-r.started
+		return 0, errors.New("read failed")
+	}
+	if off >= r.slowStart && off < r.slowEnd {
+		atomic.AddInt32(&r.slowReads, 1)
+		r.once.Do(func() { close(r.started) })
+		time.Sleep(time.Millisecond)
+	}
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestReadConcurrencyStopsRunningWorkers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithMaxPageSize(64))
+	for _, name := range []string{"a", "b"} {
+		s, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn(name, NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	}
+	for i := 0; i < 2000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"a": int64(i), "b": int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	a, b := r.meta.RowGroups[0].Columns[0].MetaData, r.meta.RowGroups[0].Columns[1].MetaData
+
+	ra := &slowFailingReaderAt{
+		Reader:    bytes.NewReader(buf.Bytes()),
+		failStart: chunkStart(a),
+		failEnd:   chunkStart(a) + a.TotalCompressedSize,
+		slowStart: chunkStart(b),
+		slowEnd:   chunkStart(b) + b.TotalCompressedSize,
+		started:   make(chan struct{}),
+	}
+	r, err = NewFileReaderWithOptions(ra, WithReadConcurrency(2))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `reading column "a" failed`)
+
+	// column b has more than 200 pages, its worker stops reading them once column a failed
+	require.Less(t, atomic.LoadInt32(&ra.slowReads), int32(100))
+}
+
+func BenchmarkReadConcurrency(b *testing.B) {
+	data := writeWideFile(b, 50000)
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithReadConcurrency(n))
+				if err != nil {
+					b.Fatal(err)
+				}
+				for g := 0; g < r.RowGroupCount(); g++ {
+					if err := r.readRowGroup(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReadFileMetaDataErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	s, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	require.NoError(t, w.AddData(map[string]interface{}{"a": int64(1)}))
+	require.NoError(t, w.Close())
+	valid := buf.Bytes()
+
+	r, err := NewFileReader(bytes.NewReader(valid))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.NumRows())
+	require.Equal(t, 1, r.RowGroupCount())
+	require.Equal(t, "message msg {\n  required int64 a;\n}\n", r.GetSchemaDefinition().String())
+
+	withFooterLen := func(n uint32) []byte {
+		data := append([]byte(nil), valid...)
+		binary.LittleEndian.PutUint32(data[len(data)-8:], n)
+		return data
+	}
+	footerLen := binary.LittleEndian.Uint32(valid[len(valid)-8:])
+	garbage := append([]byte(nil), valid...)
+	for i := len(garbage) - 8 - int(footerLen); i < len(garbage)-8; i++ {
+		garbage[i] = 0xff
+	}
+
+	for _, tc := range []struct {
+		data []byte
+		err  string
+	}{
+		{nil, "invalid parquet file, it has only 0 bytes"},
+		{[]byte("PAR1PAR1"), "invalid parquet file, it has only 8 bytes"},
+		{append([]byte("PAR0"), valid[4:]...), `invalid parquet file header "PAR0"`},
+		{append(append([]byte(nil), valid[:len(valid)-4]...), "PARX"...), `invalid parquet file footer "PARX"`},
+		{withFooterLen(0), "invalid footer len 0"},
+		{withFooterLen(0x80000000), "invalid footer len -2147483648"},
+		{withFooterLen(uint32(len(valid) - 11)), fmt.Sprintf("invalid footer len %d, the file has only %d bytes for the footer", len(valid)-11, len(valid)-12)},
+		{garbage, "read file meta failed"},
+	} {
+		_, err := NewFileReader(bytes.NewReader(tc.data))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reading file meta data failed: "+tc.err)
+	}
+}
+
+// readerAtOnly hides all methods of the reader but ReadAt.
+type readerAtOnly struct {
+	r io.ReaderAt
+}
+
+func (r readerAtOnly) ReadAt(p []byte, offset int64) (int, error) {
+	return r.r.ReadAt(p, offset)
+}
+
+func TestNewFileReaderAt(t *testing.T) {
+	data := writeWideFile(t, 25000)
+	expected, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	r, err := NewFileReaderAt(readerAtOnly{bytes.NewReader(data)}, int64(len(data)))
+	require.NoError(t, err)
+	require.Equal(t, int64(25000), r.NumRows())
+	require.Equal(t, 3, r.RowGroupCount())
+
+	// the row groups, columns and pages are read from different goroutines at the same time
+	errs := make(chan error, 3*r.RowGroupCount())
+	var wg sync.WaitGroup
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		wg.Add(3)
+		go func(rg int) {
+			defer wg.Done()
+			rgr, err := r.RowGroup(rg)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for {
+				if _, err := rgr.NextRow(); err != nil {
+					if err != io.EOF {
+						errs <- err
+					}
+					return
+				}
+			}
+		}(rg)
+		go func(rg int) {
+			defer wg.Done()
+			values, err := r.ReadInt64Values(rg, "c4", nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			want, err := expected.ReadInt64Values(rg, "c4", nil)
+			if err == nil && len(values) != len(want) {
+				err = fmt.Errorf("row group %d: %d values, expected %d", rg, len(values), len(want))
+			}
+			if err != nil {
+				errs <- err
+			}
+		}(rg)
+		go func(rg int) {
+			defer wg.Done()
+			it, err := r.Pages(rg, "c2")
+			if err != nil {
+				errs <- err
+				return
+			}
+			for {
+				if _, _, err := it.Next(); err != nil {
+					if err != io.EOF {
+						errs <- err
+					}
+					return
+				}
+			}
+		}(rg)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 25000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		want, err := expected.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, want, row)
+	}
+
+	_, err = NewFileReaderAt(readerAtOnly{bytes.NewReader(data)}, int64(len(data)-1))
+	require.Error(t, err)
+}
+
+func TestSetSelectedColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional group address {
+				required binary street (STRING);
+				required binary city (STRING);
+			}
+			required binary payload;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(500))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"id": int64(i),
+			"address": map[string]interface{}{
+				"street": []byte(fmt.Sprintf("street %d", i)),
+				"city":   []byte(fmt.Sprintf("city %d", i%10)),
+			},
+			"payload": bytes.Repeat([]byte{byte(i)}, 100),
+		}))
+	}
+	require.NoError(t, w.Close())
+
+	rr := &readRecorder{Reader: bytes.NewReader(buf.Bytes())}
+	r, err := NewFileReader(rr)
+	require.NoError(t, err)
+
+	require.EqualError(t, r.SetSelectedColumns("id", "addr", "address.zip"), `unknown columns "addr", "address.zip"`)
+	require.NoError(t, r.SetSelectedColumns("address"))
+	rr.ranges = nil
+
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"address": map[string]interface{}{
+				"street": []byte(fmt.Sprintf("street %d", i)),
+				"city":   []byte(fmt.Sprintf("city %d", i%10)),
+			},
+		}, row)
+	}
+
+	// only the chunks of the address columns are read
+	for _, rng := range rr.ranges {
+		found := false
+		for _, rg := range r.meta.RowGroups {
+			for _, cc := range rg.Columns[1:3] {
+				start := chunkStart(cc.MetaData)
+				if rng[0] >= start && rng[1] <= start+cc.MetaData.TotalCompressedSize {
+					found = true
+				}
+			}
+		}
+		require.True(t, found, "read %d to %d outside of the address columns", rng[0], rng[1])
+	}
+
+	// the selection applies to the next row group
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := 0; i < 500; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.SetSelectedColumns("id"))
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": int64(500)}, row)
+}
+
+func TestMetaDataKeyValues(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional group x {
+				required binary name (STRING);
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(1))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(2)}))
+	require.NoError(t, w.Close())
+
+	// files of other writers can have duplicate keys and keys without a value
+	sparkSchema := `{"type":"struct","fields":[{"name":"id","type":"long","nullable":false,"metadata":{}}]}`
+	data := rewriteFooter(t, buf.Bytes(), func(meta *parquet.FileMetaData) {
+		meta.KeyValueMetadata = []*parquet.KeyValue{
+			{Key: "org.apache.spark.sql.parquet.row.metadata", Value: strPtr(sparkSchema)},
+			{Key: "empty"},
+			{Key: "dup", Value: strPtr("first")},
+			{Key: "dup", Value: strPtr("second")},
+		}
+		meta.RowGroups[1].Columns[1].MetaData.KeyValueMetadata = []*parquet.KeyValue{
+			{Key: "b", Value: strPtr("1")},
+			{Key: "a"},
+		}
+	})
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"org.apache.spark.sql.parquet.row.metadata": sparkSchema,
+		"dup": "second",
+	}, r.MetaData())
+	require.Equal(t, []KeyValue{
+		{Key: "org.apache.spark.sql.parquet.row.metadata", Value: strPtr(sparkSchema)},
+		{Key: "empty"},
+		{Key: "dup", Value: strPtr("first")},
+		{Key: "dup", Value: strPtr("second")},
+	}, r.MetaDataKeyValues())
+
+	kv, err := r.ColumnChunkMetaData(1, "x.name")
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue{{Key: "b", Value: strPtr("1")}, {Key: "a"}}, kv)
+	kv, err = r.ColumnChunkMetaData(0, "x.name")
+	require.NoError(t, err)
+	require.Empty(t, kv)
+
+	_, err = r.ColumnChunkMetaData(2, "id")
+	require.EqualError(t, err, "row group 2 is out of range, the file has 2 row groups")
+	_, err = r.ColumnChunkMetaData(0, "x")
+	require.EqualError(t, err, `column "x" not found`)
+
+	// the returned values are copies
+	*r.MetaDataKeyValues()[0].Value = "changed"
+	require.Equal(t, sparkSchema, r.MetaData()["org.apache.spark.sql.parquet.row.metadata"])
+
+	_, err = r.ColumnMetaData("id")
+	require.EqualError(t, err, "no row group is loaded")
+}
+
+func TestMetaDataKeyValuesGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. It has the key-value
+	// metadata Spark 3 writes, and pairs without a value and with duplicate keys in the file and in a column chunk.
+	data, err := ioutil.ReadFile("testdata/key_value_metadata.parquet")
+	require.NoError(t, err)
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	sparkSchema := `{"type":"struct","fields":[{"name":"id","type":"long","nullable":false,"metadata":{}},` +
+		`{"name":"name","type":"string","nullable":true,"metadata":{}}]}`
+	require.Equal(t, []KeyValue{
+		{Key: "org.apache.spark.version", Value: strPtr("3.3.2")},
+		{Key: "org.apache.spark.sql.parquet.row.metadata", Value: strPtr(sparkSchema)},
+		{Key: "no value"},
+		{Key: "dup", Value: strPtr("first")},
+		{Key: "dup", Value: strPtr("second")},
+	}, r.MetaDataKeyValues())
+	require.Equal(t, map[string]string{
+		"org.apache.spark.version":                  "3.3.2",
+		"org.apache.spark.sql.parquet.row.metadata": sparkSchema,
+		"dup": "second",
+	}, r.MetaData())
+
+	kv, err := r.ColumnChunkMetaData(0, "name")
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue{
+		{Key: "origin", Value: strPtr("fixture")},
+		{Key: "flag"},
+		{Key: "origin", Value: strPtr("again")},
+	}, kv)
+	kv, err = r.ColumnChunkMetaData(0, "id")
+	require.NoError(t, err)
+	require.Empty(t, kv)
+
+	for i := 0; i < 10; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int64(i)}
+		if i != 4 {
+			expected["name"] = []byte(fmt.Sprintf("name %d", i))
+		}
+		require.Equal(t, expected, row)
+	}
+	m, err := r.ColumnMetaData("name")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"origin": "again"}, m)
+}
+
+func TestWriteKeyValueMetaData(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test { required int64 id; }`)
+	require.NoError(t, err)
+
+	write := func() []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMetaData(map[string]string{"z": "1", "b": "2", "m": "3"}))
+		w.SetKeyValueMetaData("run.id", "42")
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+		w.SetKeyValueMetaData("b", "overwritten")
+		w.AppendKeyValueMetaData("dup", "first")
+		w.AppendKeyValueMetaData("dup", "second")
+		w.AppendKeyValueMetaData("a", "")
+		w.AppendKeyValueMetaData("m", "4")
+		w.SetKeyValueMetaData("run.id", "43")
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	data := write()
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue{
+		{Key: "a"},
+		{Key: "b", Value: strPtr("overwritten")},
+		{Key: "dup", Value: strPtr("first")},
+		{Key: "dup", Value: strPtr("second")},
+		{Key: "m", Value: strPtr("3")},
+		{Key: "m", Value: strPtr("4")},
+		{Key: "run.id", Value: strPtr("43")},
+		{Key: "z", Value: strPtr("1")},
+	}, r.MetaDataKeyValues())
+
+	// the order doesn't depend on the iteration order of the map of WithMetaData
+	for i := 0; i < 10; i++ {
+		require.Equal(t, data, write())
+	}
+}
+
+func TestSeekToRow(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			repeated int32 tags;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(1000), WithMaxPageSize(512))
+	var expected []map[string]interface{}
+	for i := 0; i < 2500; i++ {
+		row := map[string]interface{}{"id": int64(i)}
+		if i%3 != 0 {
+			row["name"] = []byte(fmt.Sprintf("name %d", i))
+		}
+		if n := i % 4; n > 0 {
+			tags := make([]int32, n)
+			for j := range tags {
+				tags[j] = int32(i + j)
+			}
+			row["tags"] = tags
+		}
+		require.NoError(t, w.AddData(row))
+		expected = append(expected, row)
+	}
+	require.NoError(t, w.Close())
+	indexed := buf.Bytes()
+	plain := rewriteFooter(t, indexed, func(meta *parquet.FileMetaData) {
+		for _, rg := range meta.RowGroups {
+			for _, cc := range rg.Columns {
+				cc.OffsetIndexOffset, cc.OffsetIndexLength = nil, nil
+			}
+		}
+	})
+
+	for _, data := range [][]byte{indexed, plain} {
+		for _, opts := range [][]FileReaderOption{nil, {WithReadConcurrency(3)}} {
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+			require.NoError(t, err)
+
+			// forwards, backwards, within the current row group, and to the first and last rows of row groups
+			for _, n := range []int64{1500, 10, 999, 1000, 2499, 0, 1777, 1778, 1200} {
+				require.NoError(t, r.SeekToRow(n))
+				for i := n; i < n+3 && i < 2500; i++ {
+					row, err := r.NextRow()
+					require.NoError(t, err)
+					require.Equal(t, expected[i], row, "row %d after seeking to %d", i, n)
+				}
+			}
+
+			// the rows after a seek continue in the next row groups
+			require.NoError(t, r.SeekToRow(990))
+			for i := 990; i < 2500; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, expected[i], row)
+			}
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+
+			require.Equal(t, io.EOF, r.SeekToRow(2500))
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+			require.Equal(t, io.EOF, r.SeekToRow(1e9))
+			require.EqualError(t, r.SeekToRow(-1), "invalid row -1")
+
+			require.NoError(t, r.SeekToRow(5))
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, expected[5], row)
+		}
+	}
+
+	// with the offset index, the pages before the row are not read
+	seekRead := func(data []byte) int64 {
+		cr := &countingReader{ReadSeeker: bytes.NewReader(data)}
+		r, err := NewFileReader(cr)
+		require.NoError(t, err)
+		cr.n = 0
+		require.NoError(t, r.SeekToRow(1990))
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[1990], row)
+		return cr.n
+	}
+	indexedRead, plainRead := seekRead(indexed), seekRead(plain)
+	require.True(t, indexedRead < plainRead/2, "read %d byte with the index, %d byte without", indexedRead, plainRead)
+}
+
+func TestNumRowsFromFooter(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test { required int64 id; }`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(40))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	// the counts don't read any pages
+	rr := &readRecorder{Reader: bytes.NewReader(buf.Bytes())}
+	r, err := NewFileReader(rr)
+	require.NoError(t, err)
+	rr.ranges = nil
+	require.Equal(t, int64(100), r.NumRows())
+	for i, expected := range []int64{40, 40, 20} {
+		n, err := r.RowGroupNumRowsAt(i)
+		require.NoError(t, err)
+		require.Equal(t, expected, n)
+	}
+	_, err = r.RowGroupNumRowsAt(3)
+	require.EqualError(t, err, "row group 3 is out of range, the file has 3 row groups")
+	require.Empty(t, rr.ranges)
+	require.Empty(t, r.Warnings())
+
+	data := rewriteFooter(t, buf.Bytes(), func(meta *parquet.FileMetaData) {
+		meta.NumRows = 120
+	})
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(120), r.NumRows())
+	require.Equal(t, []string{"the file has 120 rows, but its row groups have 100 rows"}, r.Warnings())
+}
+
+// readAtRecorder records the byte ranges of the calls of ReadAt.
+type readAtRecorder struct {
+	r      io.ReaderAt
+	ranges [][2]int64
+}
+
+func (r *readAtRecorder) ReadAt(p []byte, offset int64) (int, error) {
+	r.ranges = append(r.ranges, [2]int64{offset, offset + int64(len(p))})
+	return r.r.ReadAt(p, offset)
+}
+
+func TestReadFileMetaData(t *testing.T) {
+	data := writeWideFile(t, 25000)
+	footerLen := int64(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	size := int64(len(data))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	rr := &readRecorder{Reader: bytes.NewReader(data)}
+	meta, n, err := ReadFileMetaData(rr)
+	require.NoError(t, err)
+	require.Equal(t, r.meta, meta)
+	require.Equal(t, footerLen+8, n)
+	require.Equal(t, [][2]int64{{size - 8, size}, {size - 8 - footerLen, size - 8}}, rr.ranges)
+
+	ra := &readAtRecorder{r: bytes.NewReader(data)}
+	meta, n, err = ReadFileMetaDataAt(ra, size)
+	require.NoError(t, err)
+	require.Equal(t, r.meta, meta)
+	require.Equal(t, footerLen+8, n)
+	require.Equal(t, [][2]int64{{size - 8, size}, {size - 8 - footerLen, size - 8}}, ra.ranges)
+
+	_, n, err = ReadFileMetaDataAt(bytes.NewReader(data[:len(data)-4]), size-4)
+	require.EqualError(t, err, fmt.Sprintf("invalid parquet file footer %q", data[len(data)-8:len(data)-4]))
+	require.Equal(t, int64(8), n)
+	_, _, err = ReadFileMetaDataAt(bytes.NewReader(data), size+10)
+	require.EqualError(t, err, "read the footer len failed: unexpected EOF")
+}
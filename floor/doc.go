@@ -36,7 +36,13 @@ function.
 		// ...
 	}
 
-By default, floor will use reflection to map your data structure to a parquet schema. Alternatively,
+By default, floor will use reflection to map your data structure to a parquet schema. The fields of a
+struct are mapped to the columns with their names in lower case, or with the name in their `parquet:"name"`
+tag. Pointer fields can be nil for optional columns, slices and arrays map to LIST groups, maps to MAP groups,
+nested structs to groups and time.Time to DATE and TIMESTAMP columns. Unexported fields and fields with the tag
+`parquet:"-"` are skipped. Every Writer and Reader looks up the fields of a struct type only once. SchemaFromStruct
+returns a schema definition with the columns of a struct type, its tags can set logical types. GenerateStructs
+goes the other way and returns the source of struct types for a schema definition. Alternatively,
 you can choose to bypass the use of reflection by implementing the floor.Marshaller interface. This is
 especially useful if the structure of your parquet schema doesn't exactly match the structure of your
 Go data structure but rather requires some translating or mapping.
@@ -68,8 +74,9 @@ change in the future.
 
 Boolean types and numeric types will be mapped to their parquet equivalents.
 
-In particular, Go's int, int8, int16, int32, uint, uint8, and uint16 types will be mapped to parquet's int32 type, while
-Go's int64, uint32 and uint64 types will be mapped to parquet's int64 type. Go's bool will be mapped to parquet's boolean.
+In particular, Go's integer types will be mapped to the int32 or int64 type of their parquet column. Without a column type,
+Go's int8, int16, int32, uint8, uint16 and uint32 types will be mapped to parquet's int32 type, while Go's int, int64, uint
+and uint64 types will be mapped to parquet's int64 type. Go's bool will be mapped to parquet's boolean.
 
 Go's float32 will be mapped to parquet's float, and Go's float64 will be mapped to parquet's double.
 
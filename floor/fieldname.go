@@ -3,6 +3,8 @@ package floor
 import (
 	"reflect"
 	"strings"
+
+	"github.com/fraugster/parquet-go/parquetschema"
 )
 
 var fieldNameFunc = fieldNameToLower
@@ -15,5 +17,71 @@ func fieldNameToLower(field reflect.StructField) string {
 
 	parquetStructTagFields := strings.Split(parquetStructTag, ",")
 
-	return strings.TrimSpace(parquetStructTagFields[0])
+	// a tag with only options like `parquet:",optional"` keeps the default name
+	if name := strings.TrimSpace(parquetStructTagFields[0]); name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// structField is a field of a struct with the schema definition of its column, which is nil if the schema has no
+// column for the field.
+type structField struct {
+	index     int
+	name      string
+	schemaDef *parquetschema.SchemaDefinition
+}
+
+type structFieldsKey struct {
+	typ    reflect.Type
+	column *parquetschema.ColumnDefinition
+}
+
+// structFieldCache holds the []structField of struct types and the columns of a schema definition. Every Writer and
+// Reader has its own cache for the schema definition of its file, so the schema definitions are not kept alive by the
+// cache once they are done.
+type structFieldCache struct {
+	fields map[structFieldsKey][]structField
+}
+
+// structFields returns the fields of the struct type typ with the columns of schemaDef. Unexported fields and fields
+// with the tag `parquet:"-"` are skipped. The fields are cached, so the reflection on the struct type and the lookup
+// of the columns happen only once per type and column. A nil cache doesn't cache the fields.
+func (c *structFieldCache) structFields(typ reflect.Type, schemaDef *parquetschema.SchemaDefinition) []structField {
+	key := structFieldsKey{typ: typ}
+	if schemaDef != nil {
+		key.column = schemaDef.RootColumn
+	}
+	if fields, ok := c.lookup(key); ok {
+		return fields
+	}
+
+	var fields []structField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldNameFunc(field)
+		if name == "-" {
+			continue
+		}
+		fields = append(fields, structField{index: i, name: name, schemaDef: schemaDef.SubSchema(name)})
+	}
+
+	if c != nil {
+		if c.fields == nil {
+			c.fields = make(map[structFieldsKey][]structField)
+		}
+		c.fields[key] = fields
+	}
+	return fields
+}
+
+func (c *structFieldCache) lookup(key structFieldsKey) ([]structField, bool) {
+	if c == nil {
+		return nil, false
+	}
+	fields, ok := c.fields[key]
+	return fields, ok
 }
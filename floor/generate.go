@@ -0,0 +1,375 @@
+package floor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// GenerateStructs returns the gofmt'ed source of the Go package pkg with struct types for the schema definition sd,
+// which the Writer and Reader use to write and read files of that schema. The type of the message is named after
+// the message, and the types of groups after the type and field they are in, e.g. MessageAddress. The struct
+// fields are the exported CamelCase names of the columns, with the name of the column in the `parquet` tag, so
+// columns named like Go keywords or names that only differ in case keep their names in the file. Names that
+// would collide get a number as suffix.
+//
+// The fields have the types of the columns like SchemaFromStruct expects them: optional columns and groups are
+// pointers, LIST groups are slices and MAP groups are maps. The tags have the options that SchemaFromStruct needs
+// to derive the logical type of a column, like date, timestamp(unit), time(unit), decimal(precision,scale) and
+// uuid, so that SchemaFromStruct returns a schema definition compatible with sd for the generated message type.
+// Only lists and maps whose elements need such options, repeated columns and groups without a LIST or MAP
+// annotation and int96 columns can't be mapped to a field, and return an error.
+func GenerateStructs(sd *parquetschema.SchemaDefinition, pkg string) ([]byte, error) {
+	if sd == nil || sd.RootColumn == nil || sd.RootColumn.SchemaElement == nil {
+		return nil, errors.New("the schema definition is empty")
+	}
+	if !token.IsIdentifier(pkg) || pkg == "_" {
+		return nil, fmt.Errorf("%q is not a valid package name", pkg)
+	}
+
+	g := &structGenerator{typeNames: make(map[string]bool), imports: make(map[string]bool)}
+	if _, err := g.addStruct(goName(sd.RootColumn.SchemaElement.Name), sd.RootColumn, ""); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by floor.GenerateStructs. DO NOT EDIT.\n\npackage %s\n", pkg)
+	if len(g.imports) > 0 {
+		buf.WriteString("\nimport (\n")
+		if g.imports["time"] {
+			buf.WriteString("\t\"time\"\n\n")
+		}
+		if g.imports["floor"] {
+			buf.WriteString("\t\"github.com/fraugster/parquet-go/floor\"\n")
+		}
+		buf.WriteString(")\n")
+	}
+	for i, st := range g.structs {
+		kind := "group"
+		if i == 0 {
+			kind = "message"
+		}
+		fmt.Fprintf(&buf, "\n// %s is the %s %s of the parquet schema.\ntype %[1]s struct {\n", st.name, kind, st.path)
+		for _, f := range st.fields {
+			fmt.Fprintf(&buf, "\t%s %s `parquet:%s`\n", f.name, f.typ, strconv.Quote(f.tag))
+		}
+		buf.WriteString("}\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+// structGenerator collects the struct types and imports of the source that GenerateStructs returns.
+type structGenerator struct {
+	structs   []*generatedStruct
+	typeNames map[string]bool
+	imports   map[string]bool
+}
+
+type generatedStruct struct {
+	name   string
+	path   string
+	fields []generatedField
+}
+
+type generatedField struct {
+	name string
+	typ  string
+	tag  string
+}
+
+// addStruct adds a struct type for the columns of the group col and returns its name, which is name unless
+// another type already has it.
+func (g *structGenerator) addStruct(name string, col *parquetschema.ColumnDefinition, path string) (string, error) {
+	name = uniqueName(name, g.typeNames)
+	st := &generatedStruct{name: name, path: path}
+	if path == "" {
+		st.path = col.SchemaElement.Name
+		if st.path == "" {
+			st.path = "message"
+		}
+	}
+	g.structs = append(g.structs, st)
+
+	fieldNames := make(map[string]bool, len(col.Children))
+	for _, child := range col.Children {
+		colName := child.SchemaElement.Name
+		childPath := colName
+		if path != "" {
+			childPath = path + "." + colName
+		}
+		if colName == "" || colName == "-" || colName != strings.TrimSpace(colName) || strings.ContainsAny(colName, ",\"`") {
+			return "", fmt.Errorf("column %q: the name can't be used in a struct tag", childPath)
+		}
+
+		fieldName := uniqueName(goName(colName), fieldNames)
+		typ, opts, err := g.fieldType(child, name+fieldName, childPath)
+		if err != nil {
+			return "", err
+		}
+		st.fields = append(st.fields, generatedField{name: fieldName, typ: typ, tag: strings.Join(append([]string{colName}, opts...), ",")})
+	}
+	return name, nil
+}
+
+// fieldType returns the Go type and the tag options of a struct field for the column or group col. Groups get a
+// struct type with the name typeName.
+func (g *structGenerator) fieldType(col *parquetschema.ColumnDefinition, typeName, path string) (string, []string, error) {
+	elem := col.SchemaElement
+	if elem.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+		return "", nil, fmt.Errorf("column %q: repeated columns and groups need to be in a LIST or MAP group", path)
+	}
+	optional := elem.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL
+
+	if elem.Type == nil {
+		switch {
+		case isListGroup(elem):
+			typ, err := g.listType(col, typeName, path)
+			return typ, nil, err
+		case isMapGroup(elem):
+			typ, err := g.mapType(col, typeName, path)
+			return typ, nil, err
+		}
+		name, err := g.addStruct(typeName, col, path)
+		if optional {
+			name = "*" + name
+		}
+		return name, nil, err
+	}
+
+	typ, opts, err := g.columnType(elem, path)
+	if err != nil {
+		return "", nil, err
+	}
+	if optional && !strings.HasPrefix(typ, "[]") {
+		typ = "*" + typ
+	}
+	return typ, opts, nil
+}
+
+// elementType returns the Go type of the element of a LIST group or the key or value of a MAP group, which can't
+// have tag options.
+func (g *structGenerator) elementType(col *parquetschema.ColumnDefinition, typeName, path string) (string, error) {
+	typ, opts, err := g.fieldType(col, typeName, path)
+	if err != nil {
+		return "", err
+	}
+	if len(opts) > 0 {
+		return "", fmt.Errorf("column %q: the type of an element of a list or map can't have the options %s", path, strings.Join(opts, ","))
+	}
+	return typ, nil
+}
+
+func (g *structGenerator) listType(col *parquetschema.ColumnDefinition, typeName, path string) (string, error) {
+	if len(col.Children) != 1 || col.Children[0].SchemaElement.Name != "list" || col.Children[0].SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REPEATED ||
+		len(col.Children[0].Children) != 1 || col.Children[0].Children[0].SchemaElement.Name != "element" {
+		return "", fmt.Errorf("group %q: a LIST group needs to have a repeated group list with the column element", path)
+	}
+	element := col.Children[0].Children[0]
+	typ, err := g.elementType(element, typeName+"Element", path+".list.element")
+	if err != nil {
+		return "", err
+	}
+	return "[]" + typ, nil
+}
+
+func (g *structGenerator) mapType(col *parquetschema.ColumnDefinition, typeName, path string) (string, error) {
+	if len(col.Children) != 1 || col.Children[0].SchemaElement.Name != "key_value" || col.Children[0].SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REPEATED ||
+		len(col.Children[0].Children) != 2 || col.Children[0].Children[0].SchemaElement.Name != "key" || col.Children[0].Children[1].SchemaElement.Name != "value" {
+		return "", fmt.Errorf("group %q: a MAP group needs to have a repeated group key_value with the columns key and value", path)
+	}
+	key, value := col.Children[0].Children[0], col.Children[0].Children[1]
+	keyPath := path + ".key_value.key"
+	if key.SchemaElement.Type == nil || key.SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+		return "", fmt.Errorf("column %q: the key of a map needs to be a required column", keyPath)
+	}
+	keyType, err := g.elementType(key, "", keyPath)
+	if err != nil {
+		return "", err
+	}
+	if keyType == "[]byte" {
+		// slices can't be map keys, strings have the same bytes
+		keyType = "string"
+	}
+	valueType, err := g.elementType(value, typeName+"Value", path+".key_value.value")
+	if err != nil {
+		return "", err
+	}
+	return "map[" + keyType + "]" + valueType, nil
+}
+
+// columnType returns the Go type and the tag options of the column elem.
+func (g *structGenerator) columnType(elem *parquet.SchemaElement, path string) (string, []string, error) {
+	// the logical type is preferred over the converted type, which is only used without one
+	lt, ct := elem.LogicalType, elem.ConvertedType
+	hasLogicalType := lt != nil
+	if lt == nil {
+		lt = &parquet.LogicalType{}
+	}
+	is := func(ltSet bool, cts ...parquet.ConvertedType) bool {
+		if hasLogicalType {
+			return ltSet
+		}
+		for _, c := range cts {
+			if ct != nil && *ct == c {
+				return true
+			}
+		}
+		return false
+	}
+
+	var opts []string
+	if is(lt.IsSetDECIMAL(), parquet.ConvertedType_DECIMAL) {
+		precision, scale := elem.GetPrecision(), elem.GetScale()
+		if lt.IsSetDECIMAL() {
+			precision, scale = lt.DECIMAL.Precision, lt.DECIMAL.Scale
+		}
+		opts = []string{fmt.Sprintf("decimal(%d,%d)", precision, scale)}
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "bool", nil, nil
+	case parquet.Type_FLOAT:
+		return "float32", nil, nil
+	case parquet.Type_DOUBLE:
+		return "float64", nil, nil
+	case parquet.Type_INT32:
+		switch {
+		case opts != nil:
+			return "int32", opts, nil
+		case is(lt.IsSetDATE(), parquet.ConvertedType_DATE):
+			g.imports["time"] = true
+			return "time.Time", []string{"date"}, nil
+		case is(lt.IsSetTIME(), parquet.ConvertedType_TIME_MILLIS):
+			g.imports["floor"] = true
+			return "floor.Time", []string{"time(millis)"}, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 8 && lt.INTEGER.IsSigned, parquet.ConvertedType_INT_8):
+			return "int8", nil, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 16 && lt.INTEGER.IsSigned, parquet.ConvertedType_INT_16):
+			return "int16", nil, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 8 && !lt.INTEGER.IsSigned, parquet.ConvertedType_UINT_8):
+			return "uint8", nil, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 16 && !lt.INTEGER.IsSigned, parquet.ConvertedType_UINT_16):
+			return "uint16", nil, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 32 && !lt.INTEGER.IsSigned, parquet.ConvertedType_UINT_32):
+			return "uint32", nil, nil
+		}
+		return "int32", nil, nil
+	case parquet.Type_INT64:
+		switch {
+		case opts != nil:
+			return "int64", opts, nil
+		case is(lt.IsSetTIMESTAMP(), parquet.ConvertedType_TIMESTAMP_MILLIS, parquet.ConvertedType_TIMESTAMP_MICROS):
+			g.imports["time"] = true
+			return "time.Time", []string{"timestamp(" + timeUnitName(timestampUnit(lt), ct) + ")"}, nil
+		case is(lt.IsSetTIME(), parquet.ConvertedType_TIME_MICROS):
+			g.imports["floor"] = true
+			return "floor.Time", []string{"time(" + timeUnitName(timeUnit(lt), ct) + ")"}, nil
+		case is(lt.IsSetINTEGER() && lt.INTEGER.BitWidth == 64 && !lt.INTEGER.IsSigned, parquet.ConvertedType_UINT_64):
+			return "uint64", nil, nil
+		}
+		return "int64", nil, nil
+	case parquet.Type_BYTE_ARRAY:
+		if opts == nil && is(lt.IsSetSTRING() || lt.IsSetENUM() || lt.IsSetJSON(), parquet.ConvertedType_UTF8, parquet.ConvertedType_ENUM, parquet.ConvertedType_JSON) {
+			return "string", nil, nil
+		}
+		return "[]byte", opts, nil
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if elem.GetTypeLength() <= 0 {
+			return "", nil, fmt.Errorf("column %q: fixed_len_byte_array without a type length", path)
+		}
+		if opts == nil && lt.IsSetUUID() && elem.GetTypeLength() == 16 {
+			opts = []string{"uuid"}
+		}
+		return fmt.Sprintf("[%d]byte", elem.GetTypeLength()), opts, nil
+	}
+	return "", nil, fmt.Errorf("column %q: the type %s is not supported by the Writer and Reader", path, elem.GetType())
+}
+
+func isListGroup(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil && elem.LogicalType.IsSetLIST() || elem.IsSetConvertedType() && elem.GetConvertedType() == parquet.ConvertedType_LIST
+}
+
+func isMapGroup(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.IsSetMAP() {
+		return true
+	}
+	return elem.IsSetConvertedType() && (elem.GetConvertedType() == parquet.ConvertedType_MAP || elem.GetConvertedType() == parquet.ConvertedType_MAP_KEY_VALUE)
+}
+
+func timestampUnit(lt *parquet.LogicalType) *parquet.TimeUnit {
+	if lt.TIMESTAMP == nil {
+		return nil
+	}
+	return lt.TIMESTAMP.Unit
+}
+
+func timeUnit(lt *parquet.LogicalType) *parquet.TimeUnit {
+	if lt.TIME == nil {
+		return nil
+	}
+	return lt.TIME.Unit
+}
+
+// timeUnitName returns the name of the time unit in a tag option, from the unit of the logical type or else from
+// the converted type ct.
+func timeUnitName(unit *parquet.TimeUnit, ct *parquet.ConvertedType) string {
+	switch {
+	case unit == nil:
+	case unit.IsSetMILLIS():
+		return "millis"
+	case unit.IsSetNANOS():
+		return "nanos"
+	case unit.IsSetMICROS():
+		return "micros"
+	}
+	if ct != nil && (*ct == parquet.ConvertedType_TIMESTAMP_MILLIS || *ct == parquet.ConvertedType_TIME_MILLIS) {
+		return "millis"
+	}
+	return "micros"
+}
+
+// goName returns the exported CamelCase Go identifier for the column name name, e.g. UserName for user_name. Names
+// that don't start with a letter get the prefix X.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s == "" {
+		return "X"
+	}
+	if first := []rune(s)[0]; !unicode.IsUpper(first) {
+		return "X" + s
+	}
+	return s
+}
+
+// uniqueName returns name, or name with the lowest number from 2 as suffix that is not in names yet, and adds it
+// to names.
+func uniqueName(name string, names map[string]bool) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,263 @@
+package floor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+const generateTestSchema = `message generated_record {
+  required int64 id;
+  required binary type (STRING);
+  optional binary user_name (STRING);
+  optional binary userName (STRING);
+  required int32 small (INT(8, true));
+  required int32 port (INT(16, false));
+  required int64 count (INT(64, false));
+  required int32 flags (INT(32, false));
+  optional double score;
+  required boolean valid;
+  optional binary data;
+  required fixed_len_byte_array(4) hash;
+  required fixed_len_byte_array(16) uuid (UUID);
+  required int64 price (DECIMAL(18, 4));
+  required int64 created (TIMESTAMP(MICROS, true));
+  required int32 day (DATE);
+  optional int64 updated (TIMESTAMP(MILLIS, true));
+  optional group tags (LIST) {
+    repeated group list {
+      required binary element (STRING);
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int64 value;
+    }
+  }
+  optional group history (LIST) {
+    repeated group list {
+      required group element {
+        required binary city (STRING);
+      }
+    }
+  }
+  required group a {
+    required group b {
+      required int32 x;
+    }
+  }
+  optional group a_b {
+    required int32 y;
+  }
+}
+`
+
+// The types GenerateStructs generates for generateTestSchema.
+
+// GeneratedRecord is the message generated_record of the parquet schema.
+type GeneratedRecord struct {
+	Id        int64                           `parquet:"id"`
+	Type      string                          `parquet:"type"`
+	UserName  *string                         `parquet:"user_name"`
+	UserName2 *string                         `parquet:"userName"`
+	Small     int8                            `parquet:"small"`
+	Port      uint16                          `parquet:"port"`
+	Count     uint64                          `parquet:"count"`
+	Flags     uint32                          `parquet:"flags"`
+	Score     *float64                        `parquet:"score"`
+	Valid     bool                            `parquet:"valid"`
+	Data      []byte                          `parquet:"data"`
+	Hash      [4]byte                         `parquet:"hash"`
+	Uuid      [16]byte                        `parquet:"uuid,uuid"`
+	Price     int64                           `parquet:"price,decimal(18,4)"`
+	Created   time.Time                       `parquet:"created,timestamp(micros)"`
+	Day       time.Time                       `parquet:"day,date"`
+	Updated   *time.Time                      `parquet:"updated,timestamp(millis)"`
+	Tags      []string                        `parquet:"tags"`
+	Attrs     map[string]*int64               `parquet:"attrs"`
+	History   []GeneratedRecordHistoryElement `parquet:"history"`
+	A         GeneratedRecordA                `parquet:"a"`
+	AB        *GeneratedRecordAB2             `parquet:"a_b"`
+}
+
+// GeneratedRecordHistoryElement is the group history.list.element of the parquet schema.
+type GeneratedRecordHistoryElement struct {
+	City string `parquet:"city"`
+}
+
+// GeneratedRecordA is the group a of the parquet schema.
+type GeneratedRecordA struct {
+	B GeneratedRecordAB `parquet:"b"`
+}
+
+// GeneratedRecordAB is the group a.b of the parquet schema.
+type GeneratedRecordAB struct {
+	X int32 `parquet:"x"`
+}
+
+// GeneratedRecordAB2 is the group a_b of the parquet schema.
+type GeneratedRecordAB2 struct {
+	Y int32 `parquet:"y"`
+}
+
+func TestGenerateStructs(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(generateTestSchema)
+	require.NoError(t, err)
+
+	src, err := GenerateStructs(sd, "floor")
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile("generate_test.go")
+	require.NoError(t, err)
+	testSrc := string(data)
+	start := strings.Index(testSrc, "// GeneratedRecord is")
+	end := strings.Index(testSrc, "func TestGenerateStructs")
+	expected := "// Code generated by floor.GenerateStructs. DO NOT EDIT.\n\npackage floor\n\nimport (\n\t\"time\"\n)\n\n" + testSrc[start:end-1]
+	require.Equal(t, expected, string(src))
+
+	// the schema of the generated type is the schema it was generated for
+	got, err := SchemaFromStruct(&GeneratedRecord{})
+	require.NoError(t, err)
+	got.RootColumn.SchemaElement.Name = sd.RootColumn.SchemaElement.Name
+	require.Equal(t, generateTestSchema, got.String())
+
+	// and the Writer and Reader use the generated type for files of the schema
+	user, value := "user", int64(42)
+	updated := time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC)
+	record := &GeneratedRecord{
+		Id:        1,
+		Type:      "type",
+		UserName2: &user,
+		Small:     -1,
+		Port:      443,
+		Count:     1 << 50,
+		Flags:     1<<31 + 1,
+		Data:      []byte("data"),
+		Hash:      [4]byte{1, 2, 3, 4},
+		Uuid:      [16]byte{0: 1},
+		Price:     12345678,
+		Created:   time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Day:       time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Updated:   &updated,
+		Tags:      []string{"a", "b"},
+		Attrs:     map[string]*int64{"answer": &value},
+		History:   []GeneratedRecordHistoryElement{{City: "city"}},
+		A:         GeneratedRecordA{B: GeneratedRecordAB{X: 7}},
+		AB:        &GeneratedRecordAB2{Y: 8},
+	}
+	buf := &bytes.Buffer{}
+	w := NewWriter(goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd)))
+	require.NoError(t, w.Write(record))
+	require.NoError(t, w.Close())
+
+	pr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(pr)
+	require.True(t, r.Next())
+	var read GeneratedRecord
+	require.NoError(t, r.Scan(&read))
+	require.Equal(t, record, &read)
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestGenerateStructsConvertedTypes(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required binary name (UTF8);
+		required int32 tiny (INT_8);
+		required int32 flags (UINT_32);
+		required int32 alarm (TIME_MILLIS);
+		optional int64 wake (TIME_MICROS);
+		required int64 seen (TIMESTAMP_MILLIS);
+		optional fixed_len_byte_array(8) amount (DECIMAL(16, 2));
+		optional group pairs (MAP_KEY_VALUE) {
+			repeated group key_value {
+				required binary key;
+				required group value {
+					required int32 x;
+				}
+			}
+		}
+		required group group {
+			required int32 x;
+		}
+	}`)
+	require.NoError(t, err)
+	// names the parser doesn't accept
+	sd.RootColumn.SchemaElement.Name = "1st-message"
+	sd.SubSchema("group").SchemaElement().Name = "1st-message"
+
+	src, err := GenerateStructs(sd, "model")
+	require.NoError(t, err)
+	// the tags are quoted with ' instead of backquotes in the expected source
+	expected := `// Code generated by floor.GenerateStructs. DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+
+	"github.com/fraugster/parquet-go/floor"
+)
+
+// X1stMessage is the message 1st-message of the parquet schema.
+type X1stMessage struct {
+	Name        string                           'parquet:"name"'
+	Tiny        int8                             'parquet:"tiny"'
+	Flags       uint32                           'parquet:"flags"'
+	Alarm       floor.Time                       'parquet:"alarm,time(millis)"'
+	Wake        *floor.Time                      'parquet:"wake,time(micros)"'
+	Seen        time.Time                        'parquet:"seen,timestamp(millis)"'
+	Amount      *[8]byte                         'parquet:"amount,decimal(16,2)"'
+	Pairs       map[string]X1stMessagePairsValue 'parquet:"pairs"'
+	X1stMessage X1stMessageX1stMessage           'parquet:"1st-message"'
+}
+
+// X1stMessagePairsValue is the group pairs.key_value.value of the parquet schema.
+type X1stMessagePairsValue struct {
+	X int32 'parquet:"x"'
+}
+
+// X1stMessageX1stMessage is the group 1st-message of the parquet schema.
+type X1stMessageX1stMessage struct {
+	X int32 'parquet:"x"'
+}
+`
+	require.Equal(t, strings.ReplaceAll(expected, "'", "`"), string(src))
+}
+
+func TestGenerateStructsErrors(t *testing.T) {
+	for schema, expected := range map[string]string{
+		`message m { required int96 ts; }`:                                                                                `column "ts": the type INT96 is not supported by the Writer and Reader`,
+		`message m { repeated int32 ids; }`:                                                                               `column "ids": repeated columns and groups need to be in a LIST or MAP group`,
+		`message m { required group g { repeated group r { required int32 x; } } }`:                                       `column "g.r": repeated columns and groups need to be in a LIST or MAP group`,
+		`message m { optional group l (LIST) { repeated int32 x; } }`:                                                     `group "l": a LIST group needs to have a repeated group list with the column element`,
+		`message m { optional group l (LIST) { repeated group list { required int32 element (DATE); } } }`:                `column "l.list.element": the type of an element of a list or map can't have the options date`,
+		`message m { optional group m (MAP) { repeated group kv { required int32 key; required int32 value; } } }`:        `group "m": a MAP group needs to have a repeated group key_value with the columns key and value`,
+		`message m { optional group m (MAP) { repeated group key_value { optional int32 key; required int32 value; } } }`: `column "m.key_value.key": the key of a map needs to be a required column`,
+	} {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err, schema)
+		_, err = GenerateStructs(sd, "model")
+		require.EqualError(t, err, expected, schema)
+	}
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message m { required int32 x; }`)
+	require.NoError(t, err)
+	_, err = GenerateStructs(sd, "type")
+	require.EqualError(t, err, `"type" is not a valid package name`)
+	_, err = GenerateStructs(nil, "model")
+	require.EqualError(t, err, "the schema definition is empty")
+
+	sd, err = parquetschema.NewSchemaBuilder("m").Required("a,b", parquet.Type_INT32).Build()
+	require.NoError(t, err)
+	_, err = GenerateStructs(sd, "model")
+	require.EqualError(t, err, `column "a,b": the name can't be used in a struct tag`)
+}
@@ -25,7 +25,7 @@ type UnmarshalObject interface {
 }
 
 // UnmarshalElement describes the interface to get the value of an element in an Unmarshaller
-// implementation.
+// implementation. Int32 and Int64 return the values of unsigned columns with the same bit pattern.
 type UnmarshalElement interface {
 	Group() (UnmarshalObject, error)
 	Int32() (int32, error)
@@ -129,19 +129,23 @@ func (e *unmarshElem) Group() (UnmarshalObject, error) {
 }
 
 func (e *unmarshElem) Int32() (int32, error) {
-	i, ok := e.data.(int32)
-	if !ok {
-		return 0, fmt.Errorf("expected int32, found %T instead", e.data)
+	switch i := e.data.(type) {
+	case int32:
+		return i, nil
+	case uint32:
+		return int32(i), nil
 	}
-	return i, nil
+	return 0, fmt.Errorf("expected int32, found %T instead", e.data)
 }
 
 func (e *unmarshElem) Int64() (int64, error) {
-	i, ok := e.data.(int64)
-	if !ok {
-		return 0, fmt.Errorf("expected int64, found %T instead", e.data)
+	switch i := e.data.(type) {
+	case int64:
+		return i, nil
+	case uint64:
+		return int64(i), nil
 	}
-	return i, nil
+	return 0, fmt.Errorf("expected int64, found %T instead", e.data)
 }
 
 func (e *unmarshElem) Int96() ([12]byte, error) {
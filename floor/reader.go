@@ -48,6 +48,10 @@ type Reader struct {
 	data map[string]interface{}
 	err  error
 	eof  bool
+
+	// schemaDef is the schema definition of r, it is only parsed once
+	schemaDef *parquetschema.SchemaDefinition
+	fields    structFieldCache
 }
 
 // Close closes the reader.
@@ -87,7 +91,10 @@ func (r *Reader) Scan(obj interface{}) error {
 	}
 	um, ok := obj.(interfaces.Unmarshaller)
 	if !ok {
-		um = &reflectUnmarshaller{obj: obj, schemaDef: r.r.GetSchemaDefinition()}
+		if r.schemaDef == nil {
+			r.schemaDef = r.r.GetSchemaDefinition()
+		}
+		um = &reflectUnmarshaller{obj: obj, schemaDef: r.schemaDef, fields: &r.fields}
 	}
 
 	return um.UnmarshalParquet(interfaces.NewUnmarshallObject(r.data))
@@ -96,6 +103,7 @@ func (r *Reader) Scan(obj interface{}) error {
 type reflectUnmarshaller struct {
 	obj       interface{}
 	schemaDef *parquetschema.SchemaDefinition
+	fields    *structFieldCache
 }
 
 func (um *reflectUnmarshaller) UnmarshalParquet(record interfaces.UnmarshalObject) error {
@@ -120,27 +128,20 @@ func (um *reflectUnmarshaller) UnmarshalParquet(record interfaces.UnmarshalObjec
 func (um *reflectUnmarshaller) fillStruct(value reflect.Value, record interfaces.UnmarshalObject, schemaDef *parquetschema.SchemaDefinition) error {
 	typ := value.Type()
 
-	numFields := typ.NumField()
-	for i := 0; i < numFields; i++ {
-		fieldValue := value.Field(i)
-
-		fieldName := fieldNameFunc(typ.Field(i))
-
-		fieldSchemaDef := schemaDef.SubSchema(fieldName)
-
-		if fieldSchemaDef == nil {
+	for _, f := range um.fields.structFields(typ, schemaDef) {
+		if f.schemaDef == nil {
 			continue
 		}
 
-		fieldData := record.GetField(fieldName)
+		fieldData := record.GetField(f.name)
 		if fieldData.Error() != nil {
-			if elem := fieldSchemaDef.SchemaElement(); elem.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED {
-				return fmt.Errorf("field %s is %s but couldn't be found in data", fieldName, elem.GetRepetitionType())
+			if elem := f.schemaDef.SchemaElement(); elem.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED {
+				return fmt.Errorf("field %s is %s but couldn't be found in data", f.name, elem.GetRepetitionType())
 			}
 			continue
 		}
 
-		if err := um.fillValue(fieldValue, fieldData, fieldSchemaDef); err != nil {
+		if err := um.fillValue(value.Field(f.index), fieldData, f.schemaDef); err != nil {
 			return err
 		}
 	}
@@ -252,11 +253,11 @@ func (um *reflectUnmarshaller) fillValue(value reflect.Value, data interfaces.Un
 		}
 		value.SetInt(i)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		u, err := getIntValue(data)
+		u, err := getUintValue(data)
 		if err != nil {
 			return err
 		}
-		value.SetUint(uint64(u))
+		value.SetUint(u)
 	case reflect.Float32, reflect.Float64:
 		f, err := getFloatValue(data)
 		if err != nil {
@@ -296,13 +297,18 @@ func (um *reflectUnmarshaller) fillMap(value reflect.Value, data interfaces.Unma
 		return fmt.Errorf("filling map but schema element %s is not annotated as MAP", elem.GetName())
 	}
 
+	value.Set(reflect.MakeMap(value.Type()))
+
+	// an empty map has no repeated group
+	if group, err := data.Group(); err == nil && len(group.GetData()) == 0 {
+		return nil
+	}
+
 	keyValueList, err := data.Map()
 	if err != nil {
 		return err
 	}
 
-	value.Set(reflect.MakeMap(value.Type()))
-
 	keyValueSchemaDef := schemaDef.SubSchema("key_value")
 	keySchemaDef := keyValueSchemaDef.SubSchema("key")
 	valueSchemaDef := keyValueSchemaDef.SubSchema("value")
@@ -356,6 +362,14 @@ func (um *reflectUnmarshaller) fillArrayOrSlice(value reflect.Value, data interf
 		return fmt.Errorf("filling slice or array but schema element %s is not annotated as LIST", elem.GetName())
 	}
 
+	// an empty list has no repeated group
+	if group, err := data.Group(); err == nil && len(group.GetData()) == 0 {
+		if value.Kind() == reflect.Slice {
+			value.Set(reflect.MakeSlice(value.Type(), 0, 0))
+		}
+		return nil
+	}
+
 	elemList, err := data.List()
 	if err != nil {
 		return err
@@ -408,6 +422,21 @@ func getIntValue(data interfaces.UnmarshalElement) (int64, error) {
 	return 0, err
 }
 
+// getUintValue returns the value of an INT32 or INT64 column as an unsigned value, e.g. 4294967295 for the INT32
+// value -1 of a UINT_32 column.
+func getUintValue(data interfaces.UnmarshalElement) (uint64, error) {
+	i32, err := data.Int32()
+	if err == nil {
+		return uint64(uint32(i32)), nil
+	}
+
+	i64, err := data.Int64()
+	if err == nil {
+		return uint64(i64), nil
+	}
+	return 0, err
+}
+
 func getFloatValue(data interfaces.UnmarshalElement) (float64, error) {
 	f32, err := data.Float32()
 	if err == nil {
@@ -555,3 +555,78 @@ func TestFillValue(t *testing.T) {
 	require.NoError(t, um.fillValue(reflect.ValueOf(&tt).Elem(), elem(int32(14620200)), sd.SubSchema("tmilli")))
 	require.Equal(t, tt, MustTime(NewTime(4, 3, 40, 200000000)).UTC())
 }
+
+func TestReadWriteStructFields(t *testing.T) {
+	_ = os.Mkdir("files", 0755)
+
+	sd, err := parquetschema.ParseSchemaDefinition(
+		`message event {
+			required int64 id;
+			optional binary name (STRING);
+			required group tags (LIST) {
+				repeated group list {
+					required binary element (STRING);
+				}
+			}
+			optional group source {
+				required binary host (STRING);
+				optional int32 port;
+			}
+			required int64 ts (TIMESTAMP(NANOS, true));
+		}`)
+	require.NoError(t, err, "parsing schema definition failed")
+
+	hlWriter, err := NewFileWriter(
+		"files/struct_fields.parquet",
+		goparquet.WithCreator("floor-unittest"),
+		goparquet.WithSchemaDefinition(sd),
+	)
+	require.NoError(t, err)
+
+	type source struct {
+		Host string
+		Port *int32
+	}
+
+	type event struct {
+		ID      int64   `parquet:"id"`
+		Name    *string `parquet:"name"`
+		Tags    []string
+		Source  *source
+		TS      time.Time `parquet:"ts"`
+		Ignored string    `parquet:"-"`
+		secret  string
+	}
+
+	name := "first"
+	port := int32(8080)
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+	testData := []event{
+		{ID: 1, Name: &name, Tags: []string{"a", "b"}, Source: &source{Host: "localhost", Port: &port}, TS: ts, Ignored: "x", secret: "y"},
+		{ID: 2, Tags: []string{"b"}, Source: &source{Host: "remote"}, TS: ts.Add(time.Hour)},
+		{ID: 3, Tags: []string{"c"}, TS: ts.Add(2 * time.Hour)},
+	}
+
+	for _, tt := range testData {
+		require.NoError(t, hlWriter.Write(tt))
+	}
+	require.NoError(t, hlWriter.Close())
+
+	hlReader, err := NewFileReader("files/struct_fields.parquet")
+	require.NoError(t, err)
+
+	var result []event
+	for hlReader.Next() {
+		msg := event{Ignored: "kept", secret: "kept"}
+		require.NoError(t, hlReader.Scan(&msg))
+		result = append(result, msg)
+	}
+	require.NoError(t, hlReader.Err())
+	require.NoError(t, hlReader.Close())
+
+	// the skipped fields are neither written nor read
+	for i := range testData {
+		testData[i].Ignored, testData[i].secret = "kept", "kept"
+	}
+	require.Equal(t, testData, result)
+}
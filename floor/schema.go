@@ -0,0 +1,367 @@
+package floor
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	floorTimeType = reflect.TypeOf(Time{})
+)
+
+// SchemaFromStruct returns the schema definition of the columns that the Writer writes for the fields of v, which
+// must be a struct or a pointer to a struct. The columns have the names of the fields as the Writer uses them, and
+// their types are:
+//   - boolean for bool, float for float32 and double for float64
+//   - int32 for int32, with the INT logical type for int8, int16, uint8, uint16 and uint32
+//   - int64 for int and int64, with the INT(64, false) logical type for uint and uint64
+//   - binary with the STRING logical type for string, and binary for []byte
+//   - fixed_len_byte_array(n) for [n]byte
+//   - int64 with the TIMESTAMP(MICROS, true) logical type for time.Time
+//   - int64 with the TIME(MICROS, true) logical type for Time
+//   - a LIST group for slices and arrays, a MAP group for maps and a group for structs
+//
+// Pointers, slices and maps are optional, all other fields are required. The options after the name in the
+// `parquet:"name,options"` tag of a field change its column:
+//   - optional makes the column optional
+//   - decimal(precision,scale) adds the DECIMAL logical type to an integer, []byte or [n]byte field
+//   - uuid adds the UUID logical type to a [16]byte field
+//   - date makes a time.Time field an int32 with the DATE logical type
+//   - timestamp(unit) and time(unit) set the unit of a time.Time or Time field to millis, micros or nanos
+//
+// An error has the path of the field in v, e.g. for fields with unsupported types like channels, functions and
+// interfaces.
+func SchemaFromStruct(v interface{}) (*parquetschema.SchemaDefinition, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a struct or a pointer to a struct", v)
+	}
+
+	s := &structSchema{}
+	b := parquetschema.NewSchemaBuilder("schema").Columns(func(g *parquetschema.GroupBuilder) {
+		s.addFields(g, typ, "")
+	})
+	if s.err != nil {
+		return nil, s.err
+	}
+	return b.Build()
+}
+
+// structSchema adds the columns of struct types to a schema builder. It keeps the first error, and the struct types
+// whose fields are added, to find recursive types.
+type structSchema struct {
+	err   error
+	types []reflect.Type
+}
+
+func (s *structSchema) errorf(path, format string, args ...interface{}) {
+	if s.err == nil {
+		s.err = fmt.Errorf("field %s: %s", path, fmt.Sprintf(format, args...))
+	}
+}
+
+func (s *structSchema) addFields(g *parquetschema.GroupBuilder, typ reflect.Type, prefix string) {
+	for _, t := range s.types {
+		if t == typ {
+			s.errorf(strings.TrimSuffix(prefix, "."), "the type %s is recursive", typ)
+			return
+		}
+	}
+	s.types = append(s.types, typ)
+	defer func() { s.types = s.types[:len(s.types)-1] }()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldNameFunc(field)
+		if name == "-" {
+			continue
+		}
+		path := prefix + field.Name
+		opts, err := parseSchemaTagOptions(field.Tag.Get("parquet"))
+		if err != nil {
+			s.errorf(path, "%v", err)
+			return
+		}
+		s.addValue(g, name, field.Type, path, opts)
+	}
+}
+
+// addValue adds the column name for values of the type typ.
+func (s *structSchema) addValue(g *parquetschema.GroupBuilder, name string, typ reflect.Type, path string, opts schemaTagOptions) {
+	rep := parquet.FieldRepetitionType_REQUIRED
+	switch typ.Kind() {
+	case reflect.Ptr:
+		typ = typ.Elem()
+		rep = parquet.FieldRepetitionType_OPTIONAL
+	case reflect.Slice, reflect.Map:
+		rep = parquet.FieldRepetitionType_OPTIONAL
+	}
+	if opts.optional {
+		rep = parquet.FieldRepetitionType_OPTIONAL
+	}
+
+	var colOpts []parquetschema.ColumnOption
+	column := func(physical parquet.Type, allowDecimal bool) {
+		if opts.decimal != nil {
+			if !allowDecimal {
+				s.errorf(path, "the type %s can't be a decimal", typ)
+				return
+			}
+			colOpts = append(colOpts,
+				parquetschema.WithLogicalType(&parquet.LogicalType{DECIMAL: opts.decimal}),
+				parquetschema.WithConvertedType(parquet.ConvertedType_DECIMAL))
+		}
+		switch rep {
+		case parquet.FieldRepetitionType_OPTIONAL:
+			g.Optional(name, physical, colOpts...)
+		default:
+			g.Required(name, physical, colOpts...)
+		}
+	}
+	integer := func(physical parquet.Type, bitWidth int8, signed bool) {
+		if opts.decimal == nil {
+			colOpts = append(colOpts, intLogicalType(bitWidth, signed)...)
+		}
+		column(physical, true)
+	}
+
+	if opts.uuid && (typ.Kind() != reflect.Array || typ.Len() != 16 || typ.Elem().Kind() != reflect.Uint8) {
+		s.errorf(path, "the type %s can't be a UUID", typ)
+		return
+	}
+	if (opts.date || opts.timestampUnit != nil) && typ != timeType {
+		s.errorf(path, "the type %s is not a time.Time", typ)
+		return
+	}
+	if opts.timeUnit != nil && typ != floorTimeType {
+		s.errorf(path, "the type %s is not a floor.Time", typ)
+		return
+	}
+
+	switch typ {
+	case timeType:
+		if opts.date {
+			colOpts = append(colOpts,
+				parquetschema.WithLogicalType(&parquet.LogicalType{DATE: parquet.NewDateType()}),
+				parquetschema.WithConvertedType(parquet.ConvertedType_DATE))
+			column(parquet.Type_INT32, false)
+			return
+		}
+		unit := opts.timestampUnit
+		if unit == nil {
+			unit = &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}
+		}
+		colOpts = append(colOpts, parquetschema.WithLogicalType(&parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: unit}}))
+		switch {
+		case unit.IsSetMILLIS():
+			colOpts = append(colOpts, parquetschema.WithConvertedType(parquet.ConvertedType_TIMESTAMP_MILLIS))
+		case unit.IsSetMICROS():
+			colOpts = append(colOpts, parquetschema.WithConvertedType(parquet.ConvertedType_TIMESTAMP_MICROS))
+		}
+		column(parquet.Type_INT64, false)
+		return
+	case floorTimeType:
+		unit := opts.timeUnit
+		if unit == nil {
+			unit = &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}
+		}
+		colOpts = append(colOpts, parquetschema.WithLogicalType(&parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: unit}}))
+		switch {
+		case unit.IsSetMILLIS():
+			colOpts = append(colOpts, parquetschema.WithConvertedType(parquet.ConvertedType_TIME_MILLIS))
+			column(parquet.Type_INT32, false)
+		case unit.IsSetMICROS():
+			colOpts = append(colOpts, parquetschema.WithConvertedType(parquet.ConvertedType_TIME_MICROS))
+			column(parquet.Type_INT64, false)
+		default:
+			column(parquet.Type_INT64, false)
+		}
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		column(parquet.Type_BOOLEAN, false)
+	case reflect.Int32:
+		column(parquet.Type_INT32, true)
+	case reflect.Int8:
+		integer(parquet.Type_INT32, 8, true)
+	case reflect.Int16:
+		integer(parquet.Type_INT32, 16, true)
+	case reflect.Int, reflect.Int64:
+		column(parquet.Type_INT64, true)
+	case reflect.Uint8:
+		integer(parquet.Type_INT32, 8, false)
+	case reflect.Uint16:
+		integer(parquet.Type_INT32, 16, false)
+	case reflect.Uint32:
+		integer(parquet.Type_INT32, 32, false)
+	case reflect.Uint, reflect.Uint64:
+		integer(parquet.Type_INT64, 64, false)
+	case reflect.Float32:
+		column(parquet.Type_FLOAT, false)
+	case reflect.Float64:
+		column(parquet.Type_DOUBLE, false)
+	case reflect.String:
+		if opts.decimal == nil {
+			colOpts = append(colOpts,
+				parquetschema.WithLogicalType(&parquet.LogicalType{STRING: parquet.NewStringType()}),
+				parquetschema.WithConvertedType(parquet.ConvertedType_UTF8))
+		}
+		column(parquet.Type_BYTE_ARRAY, false)
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			if typ.Kind() == reflect.Slice {
+				column(parquet.Type_BYTE_ARRAY, true)
+				return
+			}
+			colOpts = append(colOpts, parquetschema.WithTypeLength(int32(typ.Len())))
+			if opts.uuid {
+				colOpts = append(colOpts, parquetschema.WithLogicalType(&parquet.LogicalType{UUID: parquet.NewUUIDType()}))
+			}
+			column(parquet.Type_FIXED_LEN_BYTE_ARRAY, true)
+			return
+		}
+		if opts.decimal != nil {
+			s.errorf(path, "the type %s can't be a decimal", typ)
+			return
+		}
+		g.Group(name, rep, func(g *parquetschema.GroupBuilder) {
+			g.Group("list", parquet.FieldRepetitionType_REPEATED, func(g *parquetschema.GroupBuilder) {
+				s.addValue(g, "element", typ.Elem(), path+".element", schemaTagOptions{})
+			})
+		}, parquetschema.WithConvertedType(parquet.ConvertedType_LIST))
+	case reflect.Map:
+		if opts.decimal != nil {
+			s.errorf(path, "the type %s can't be a decimal", typ)
+			return
+		}
+		if k := typ.Key().Kind(); k == reflect.Ptr || k == reflect.Slice || k == reflect.Map {
+			s.errorf(path, "the key type %s of the map can't be null", typ.Key())
+			return
+		}
+		g.Group(name, rep, func(g *parquetschema.GroupBuilder) {
+			g.Group("key_value", parquet.FieldRepetitionType_REPEATED, func(g *parquetschema.GroupBuilder) {
+				s.addValue(g, "key", typ.Key(), path+".key", schemaTagOptions{})
+				s.addValue(g, "value", typ.Elem(), path+".value", schemaTagOptions{})
+			})
+		}, parquetschema.WithConvertedType(parquet.ConvertedType_MAP))
+	case reflect.Struct:
+		if opts.decimal != nil {
+			s.errorf(path, "the type %s can't be a decimal", typ)
+			return
+		}
+		g.Group(name, rep, func(g *parquetschema.GroupBuilder) {
+			s.addFields(g, typ, path+".")
+		})
+	default:
+		s.errorf(path, "unsupported type %s", typ)
+	}
+}
+
+func intLogicalType(bitWidth int8, signed bool) []parquetschema.ColumnOption {
+	convertedType := fmt.Sprintf("INT_%d", bitWidth)
+	if !signed {
+		convertedType = "U" + convertedType
+	}
+	ct, _ := parquet.ConvertedTypeFromString(convertedType)
+	return []parquetschema.ColumnOption{
+		parquetschema.WithLogicalType(&parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: bitWidth, IsSigned: signed}}),
+		parquetschema.WithConvertedType(ct),
+	}
+}
+
+// schemaTagOptions are the options of a `parquet` struct tag for SchemaFromStruct.
+type schemaTagOptions struct {
+	optional      bool
+	uuid          bool
+	date          bool
+	decimal       *parquet.DecimalType
+	timestampUnit *parquet.TimeUnit
+	timeUnit      *parquet.TimeUnit
+}
+
+// parseSchemaTagOptions parses the options after the name of the `parquet` struct tag tag. The options are
+// separated by commas, which are part of an option in parentheses like decimal(18,4).
+func parseSchemaTagOptions(tag string) (schemaTagOptions, error) {
+	var opts schemaTagOptions
+	var options []string
+	depth, start := 0, 0
+	for i, r := range tag {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				options = append(options, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	options = append(options, tag[start:])
+
+	// the first one is the name of the column
+	for _, option := range options[1:] {
+		option = strings.TrimSpace(option)
+		name, args := option, ""
+		if i := strings.IndexByte(option, '('); i >= 0 && strings.HasSuffix(option, ")") {
+			name, args = option[:i], option[i+1:len(option)-1]
+		}
+
+		switch strings.ToLower(name) {
+		case "":
+		case "optional":
+			opts.optional = true
+		case "uuid":
+			opts.uuid = true
+		case "date":
+			opts.date = true
+		case "decimal":
+			parts := strings.Split(args, ",")
+			if len(parts) != 2 {
+				return opts, fmt.Errorf("invalid option %q, expected decimal(precision,scale)", option)
+			}
+			precision, err1 := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 32)
+			scale, err2 := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 32)
+			if err1 != nil || err2 != nil {
+				return opts, fmt.Errorf("invalid option %q, expected decimal(precision,scale)", option)
+			}
+			opts.decimal = &parquet.DecimalType{Precision: int32(precision), Scale: int32(scale)}
+		case "timestamp", "time":
+			var unit parquet.TimeUnit
+			switch strings.ToLower(strings.TrimSpace(args)) {
+			case "millis":
+				unit.MILLIS = parquet.NewMilliSeconds()
+			case "micros":
+				unit.MICROS = parquet.NewMicroSeconds()
+			case "nanos":
+				unit.NANOS = parquet.NewNanoSeconds()
+			default:
+				return opts, fmt.Errorf("invalid option %q, expected %s(millis), %[2]s(micros) or %[2]s(nanos)", option, strings.ToLower(name))
+			}
+			if strings.ToLower(name) == "time" {
+				opts.timeUnit = &unit
+			} else {
+				opts.timestampUnit = &unit
+			}
+		default:
+			return opts, fmt.Errorf("unknown option %q", option)
+		}
+	}
+	return opts, nil
+}
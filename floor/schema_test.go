@@ -0,0 +1,217 @@
+package floor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaTestAddress struct {
+	City string
+	Zip  *int32 `parquet:"zip_code"`
+}
+
+type schemaTestRecord struct {
+	ID       int64 `parquet:"id"`
+	Name     string
+	Nick     *string
+	Small    int8
+	Port     uint16
+	Count    uint64
+	Int      int
+	Uint     uint
+	Uint32   uint32
+	Score    float64
+	Ratio    float32
+	Valid    bool
+	Data     []byte
+	Hash     [4]byte
+	ID2      [16]byte `parquet:"uuid,uuid"`
+	Price    int64    `parquet:",decimal(18,4)"`
+	Created  time.Time
+	Day      time.Time  `parquet:"day,date"`
+	Updated  *time.Time `parquet:"updated,timestamp(millis)"`
+	Alarm    Time       `parquet:"alarm,time(millis)"`
+	Tags     []string
+	Matrix   [][]int32
+	Attrs    map[string]*int64
+	Address  schemaTestAddress
+	Previous *schemaTestAddress
+	History  []schemaTestAddress
+	Ignored  chan int `parquet:"-"`
+	Level    int32    `parquet:",optional"`
+	private  int
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	sd, err := SchemaFromStruct(&schemaTestRecord{})
+	require.NoError(t, err)
+
+	expected := `message schema {
+  required int64 id;
+  required binary name (STRING);
+  optional binary nick (STRING);
+  required int32 small (INT(8, true));
+  required int32 port (INT(16, false));
+  required int64 count (INT(64, false));
+  required int64 int;
+  required int64 uint (INT(64, false));
+  required int32 uint32 (INT(32, false));
+  required double score;
+  required float ratio;
+  required boolean valid;
+  optional binary data;
+  required fixed_len_byte_array(4) hash;
+  required fixed_len_byte_array(16) uuid (UUID);
+  required int64 price (DECIMAL(18, 4));
+  required int64 created (TIMESTAMP(MICROS, true));
+  required int32 day (DATE);
+  optional int64 updated (TIMESTAMP(MILLIS, true));
+  required int32 alarm (TIME(MILLIS, true));
+  optional group tags (LIST) {
+    repeated group list {
+      required binary element (STRING);
+    }
+  }
+  optional group matrix (LIST) {
+    repeated group list {
+      optional group element (LIST) {
+        repeated group list {
+          required int32 element;
+        }
+      }
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int64 value;
+    }
+  }
+  required group address {
+    required binary city (STRING);
+    optional int32 zip_code;
+  }
+  optional group previous {
+    required binary city (STRING);
+    optional int32 zip_code;
+  }
+  optional group history (LIST) {
+    repeated group list {
+      required group element {
+        required binary city (STRING);
+        optional int32 zip_code;
+      }
+    }
+  }
+  optional int32 level;
+}
+`
+	require.Equal(t, expected, sd.String())
+	require.NoError(t, sd.ValidateStrict())
+
+	// the Writer and Reader use the schema for the struct
+	nick, zip, attr := "nick", int32(12345), int64(7)
+	updated := time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC)
+	alarm, err := NewTime(7, 30, 0, 0)
+	require.NoError(t, err)
+	record := &schemaTestRecord{
+		ID:      1,
+		Name:    "name",
+		Nick:    &nick,
+		Small:   -8,
+		Port:    8080,
+		Count:   1 << 40,
+		Int:     1 << 40,
+		Uint:    1<<63 + 5,
+		Uint32:  4000000000,
+		Score:   1.5,
+		Ratio:   0.25,
+		Valid:   true,
+		Data:    []byte("data"),
+		Hash:    [4]byte{1, 2, 3, 4},
+		ID2:     [16]byte{15: 1},
+		Price:   123456,
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Day:     time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Updated: &updated,
+		Alarm:   alarm.UTC(),
+		Tags:    []string{"a", "b"},
+		Matrix:  [][]int32{{1, 2}, {}, {3}},
+		Attrs:   map[string]*int64{"attr": &attr},
+		Address: schemaTestAddress{City: "city", Zip: &zip},
+		History: []schemaTestAddress{{City: "old"}},
+		Level:   3,
+	}
+
+	buf := &bytes.Buffer{}
+	fw := goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd))
+	w := NewWriter(fw)
+	require.NoError(t, w.Write(record))
+	// empty slices and maps are read as empty, nil ones as nil
+	epoch := time.Unix(0, 0).UTC()
+	empty := &schemaTestRecord{Created: epoch, Day: epoch, Alarm: Time{}.UTC(), Tags: []string{}, Attrs: map[string]*int64{}}
+	require.NoError(t, w.Write(empty))
+	require.NoError(t, w.Close())
+
+	pr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(pr)
+	require.True(t, r.Next())
+	var got schemaTestRecord
+	require.NoError(t, r.Scan(&got))
+	require.Equal(t, record, &got)
+	require.True(t, r.Next())
+	got = schemaTestRecord{}
+	require.NoError(t, r.Scan(&got))
+	require.Equal(t, empty, &got)
+	require.False(t, r.Next())
+	require.NoError(t, r.Err())
+}
+
+func TestSchemaFromStructErrors(t *testing.T) {
+	type recursive struct {
+		Next *recursive
+	}
+
+	for _, tt := range []struct {
+		v   interface{}
+		err string
+	}{
+		{42, "int is not a struct or a pointer to a struct"},
+		{nil, "<nil> is not a struct or a pointer to a struct"},
+		{struct{ C chan int }{}, "field C: unsupported type chan int"},
+		{struct{ A struct{ F func() } }{}, "field A.F: unsupported type func()"},
+		{struct{ L []interface{} }{}, "field L.element: unsupported type interface {}"},
+		{struct{ M map[string]complex64 }{}, "field M.value: unsupported type complex64"},
+		{struct{ M map[*string]int }{}, "field M: the key type *string of the map can't be null"},
+		{recursive{}, "field Next: the type floor.recursive is recursive"},
+		{struct {
+			D float64 `parquet:",decimal(10,2)"`
+		}{}, "field D: the type float64 can't be a decimal"},
+		{struct {
+			D int64 `parquet:",decimal(10)"`
+		}{}, `field D: invalid option "decimal(10)", expected decimal(precision,scale)`},
+		{struct {
+			U [8]byte `parquet:",uuid"`
+		}{}, "field U: the type [8]uint8 can't be a UUID"},
+		{struct {
+			T int64 `parquet:",timestamp(seconds)"`
+		}{}, `field T: invalid option "timestamp(seconds)", expected timestamp(millis), timestamp(micros) or timestamp(nanos)`},
+		{struct {
+			T int64 `parquet:",date"`
+		}{}, "field T: the type int64 is not a time.Time"},
+		{struct {
+			X int64 `parquet:",compressed"`
+		}{}, `field X: unknown option "compressed"`},
+		{struct {
+			D int32 `parquet:",decimal(12,2)"`
+		}{}, "invalid schema: field d is int32 and annotated as DECIMAL but precision 12 is out of bounds; needs to be 1 <= precision <= 9"},
+	} {
+		_, err := SchemaFromStruct(tt.v)
+		require.EqualError(t, err, tt.err)
+	}
+}
@@ -42,6 +42,10 @@ func NewFileWriter(file string, opts ...goparquet.FileWriterOption) (*Writer, er
 type Writer struct {
 	w *goparquet.FileWriter
 	f io.Closer
+
+	// schemaDef is the schema definition of w, it is only parsed once
+	schemaDef *parquetschema.SchemaDefinition
+	fields    structFieldCache
 }
 
 // Write adds a new object to be written to the parquet file. If
@@ -49,12 +53,16 @@ type Writer struct {
 // will be called to determine the data, otherwise reflection will be
 // used.
 func (w *Writer) Write(obj interface{}) error {
+	if w.schemaDef == nil {
+		w.schemaDef = w.w.GetSchemaDefinition()
+	}
+
 	m, ok := obj.(interfaces.Marshaller)
 	if !ok {
-		m = &reflectMarshaller{obj: obj, schemaDef: w.w.GetSchemaDefinition()}
+		m = &reflectMarshaller{obj: obj, schemaDef: w.schemaDef, fields: &w.fields}
 	}
 
-	data := interfaces.NewMarshallObjectWithSchema(nil, w.w.GetSchemaDefinition())
+	data := interfaces.NewMarshallObjectWithSchema(nil, w.schemaDef)
 	if err := m.MarshalParquet(data); err != nil {
 		return err
 	}
@@ -69,6 +77,7 @@ func (w *Writer) Write(obj interface{}) error {
 type reflectMarshaller struct {
 	obj       interface{}
 	schemaDef *parquetschema.SchemaDefinition
+	fields    *structFieldCache
 }
 
 func (m *reflectMarshaller) MarshalParquet(record interfaces.MarshalObject) error {
@@ -97,17 +106,10 @@ func (m *reflectMarshaller) decodeStruct(record interfaces.MarshalObject, value
 		return fmt.Errorf("object needs to be a struct or a *struct, it's a %v instead", typ)
 	}
 
-	numFields := typ.NumField()
-	for i := 0; i < numFields; i++ {
-		fieldValue := value.Field(i)
+	for _, f := range m.fields.structFields(typ, schemaDef) {
+		field := record.AddField(f.name)
 
-		fieldName := fieldNameFunc(typ.Field(i))
-
-		subSchemaDef := schemaDef.SubSchema(fieldName)
-
-		field := record.AddField(fieldName)
-
-		err := m.decodeValue(field, fieldValue, subSchemaDef)
+		err := m.decodeValue(field, value.Field(f.index), f.schemaDef)
 		if err != nil {
 			return err
 		}
@@ -148,6 +150,19 @@ func (m *reflectMarshaller) decodeTimestampValue(elem *parquet.SchemaElement, fi
 	return nil
 }
 
+// intColumnType returns the physical type of the integer column schemaDef, or the type that SchemaFromStruct uses
+// for integers of the kind if the schema definition has none.
+func intColumnType(schemaDef *parquetschema.SchemaDefinition, kind reflect.Kind) parquet.Type {
+	if elem := schemaDef.SchemaElement(); elem != nil && elem.Type != nil {
+		return elem.GetType()
+	}
+	switch kind {
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return parquet.Type_INT64
+	}
+	return parquet.Type_INT32
+}
+
 func (m *reflectMarshaller) decodeValue(field interfaces.MarshalElement, value reflect.Value, schemaDef *parquetschema.SchemaDefinition) error {
 	if value.Kind() == reflect.Ptr {
 		if value.IsNil() {
@@ -179,17 +194,19 @@ func (m *reflectMarshaller) decodeValue(field interfaces.MarshalElement, value r
 	case reflect.Bool:
 		field.SetBool(value.Bool())
 		return nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
-		field.SetInt32(int32(value.Int()))
-		return nil
-	case reflect.Int64:
-		field.SetInt64(value.Int())
-		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16:
-		field.SetInt32(int32(value.Uint()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if intColumnType(schemaDef, value.Kind()) == parquet.Type_INT32 {
+			field.SetInt32(int32(value.Int()))
+		} else {
+			field.SetInt64(value.Int())
+		}
 		return nil
-	case reflect.Uint32, reflect.Uint64:
-		field.SetInt64(int64(value.Uint()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if intColumnType(schemaDef, value.Kind()) == parquet.Type_INT32 {
+			field.SetInt32(int32(value.Uint()))
+		} else {
+			field.SetInt64(int64(value.Uint()))
+		}
 		return nil
 	case reflect.Float32:
 		field.SetFloat32(float32(value.Float()))
@@ -1,6 +1,7 @@
 package floor
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"testing"
@@ -58,6 +59,18 @@ func TestDecodeStruct(t *testing.T) {
 			ExpectErr:      false,
 			Schema:         `message test { required int32 foo; }`,
 		},
+		{
+			Input:          struct{ Foo int }{Foo: 1 << 40},
+			ExpectedOutput: map[string]interface{}{"foo": int64(1 << 40)},
+			ExpectErr:      false,
+			Schema:         `message test { required int64 foo; }`,
+		},
+		{
+			Input:          struct{ Foo uint32 }{Foo: 4000000000},
+			ExpectedOutput: map[string]interface{}{"foo": int32(-294967296)},
+			ExpectErr:      false,
+			Schema:         `message test { required int32 foo (INT(32, false)); }`,
+		},
 		{
 			Input:          struct{ Foo float32 }{Foo: 42.5},
 			ExpectedOutput: map[string]interface{}{"foo": float32(42.5)},
@@ -289,10 +302,23 @@ func TestDecodeStruct(t *testing.T) {
 				Foo: 23,
 				bar: 42,
 			},
-			ExpectedOutput: map[string]interface{}{"foo": int64(23), "bar": int32(42)},
+			ExpectedOutput: map[string]interface{}{"foo": int64(23)},
 			ExpectErr:      false,
 			Schema:         `message test { required int64 foo; }`,
 		},
+		{
+			Input: struct {
+				Foo int64
+				Bar chan int `parquet:"-"`
+				Baz int32    `parquet:"-,optional"`
+			}{
+				Foo: 23,
+				Baz: 42,
+			},
+			ExpectedOutput: map[string]interface{}{"foo": int64(23)},
+			ExpectErr:      false,
+			Schema:         `message test { required int64 foo; optional int32 baz; }`,
+		},
 		{
 			Input: struct {
 				Lunch Time
@@ -637,3 +663,39 @@ func (r *marshTestRecord) UnmarshalParquet(obj interfaces.UnmarshalObject) error
 
 	return nil
 }
+
+func TestWriterStructFieldCache(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required group address {
+			required binary city (STRING);
+		}
+	}`)
+	require.NoError(t, err)
+
+	type address struct{ City string }
+	type record struct {
+		ID      int64
+		Address address
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd)))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.Write(record{ID: int64(i), Address: address{City: "city"}}))
+	}
+	require.NoError(t, w.Close())
+	// the schema definition is parsed once, so there is one entry per struct type
+	require.Len(t, w.fields.fields, 2)
+
+	pr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(pr)
+	for i := 0; r.Next(); i++ {
+		var rec record
+		require.NoError(t, r.Scan(&rec))
+		require.Equal(t, record{ID: int64(i), Address: address{City: "city"}}, rec)
+	}
+	require.NoError(t, r.Err())
+	require.Len(t, r.fields.fields, 2)
+}
@@ -0,0 +1,379 @@
+package goparquet
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// mapConversion is how the values of MAP groups are returned in reading.
+type mapConversion int
+
+const (
+	// mapsAsGroups returns the map and its repeated group as they are stored.
+	mapsAsGroups mapConversion = iota
+	// mapsAsGoMaps returns a Go map of the keys and values.
+	mapsAsGoMaps
+	// mapsAsEntries returns a []MapEntry of the keys and values in their order.
+	mapsAsEntries
+)
+
+// groupConversion is the conversion of the values of LIST and MAP groups, and of INT96, DECIMAL and UUID columns, in
+// reading.
+type groupConversion struct {
+	listsAsSlices bool
+	maps          mapConversion
+	int96AsTime   bool
+	decimals      bool
+	uuids         uuidConversion
+}
+
+func (gc groupConversion) enabled() bool {
+	return gc.listsAsSlices || gc.maps != mapsAsGroups || gc.int96AsTime || gc.decimals || gc.uuids != uuidsAsBytes
+}
+
+// MapEntry is a key and its value of a MAP group, see WithMapsAsEntries.
+type MapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// isListGroup returns true if the schema element of a group has the LIST logical or converted type.
+func isListGroup(elem *parquet.SchemaElement) bool {
+	if elem == nil {
+		return false
+	}
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetLIST() {
+		return true
+	}
+	return elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_LIST
+}
+
+// isMapGroup returns true if the schema element of a group has the MAP logical or converted type, or the
+// MAP_KEY_VALUE converted type that older writers put on the map group.
+func isMapGroup(elem *parquet.SchemaElement) bool {
+	if elem == nil {
+		return false
+	}
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetMAP() {
+		return true
+	}
+	if elem.ConvertedType == nil {
+		return false
+	}
+	ct := elem.GetConvertedType()
+	return ct == parquet.ConvertedType_MAP || ct == parquet.ConvertedType_MAP_KEY_VALUE
+}
+
+// listElement returns the repeated column of the LIST group c and the column of its elements. The element column is
+// nil if the repeated column is the element itself. The rules of the parquet format for files of older writers are
+// followed: a repeated primitive column is the element, and so is a repeated group with more than one column or with
+// one column and the name "array" (parquet-avro) or the name of the list with "_tuple" appended (parquet-thrift).
+// Otherwise the one column of the repeated group is the element, whatever its name is, e.g. "element" or the
+// "array_element" of Hive in a group named "bag". ok is false if c doesn't have the structure of a list.
+func listElement(c *Column) (repeated, element *Column, ok bool) {
+	if len(c.children) != 1 || c.children[0].rep != parquet.FieldRepetitionType_REPEATED {
+		return nil, nil, false
+	}
+	repeated = c.children[0]
+	if repeated.data != nil || len(repeated.children) != 1 || repeated.name == "array" || repeated.name == c.name+"_tuple" {
+		return repeated, nil, true
+	}
+	return repeated, repeated.children[0], true
+}
+
+// mapKeyValue returns the repeated group of the MAP group c and the columns of its keys and values. The columns are
+// found by their position, the key is the first column and must be a primitive column, the value is the second
+// column if there is one. ok is false if c doesn't have the structure of a map.
+func mapKeyValue(c *Column) (repeated, key, value *Column, ok bool) {
+	if len(c.children) != 1 || c.children[0].rep != parquet.FieldRepetitionType_REPEATED {
+		return nil, nil, nil, false
+	}
+	repeated = c.children[0]
+	if n := len(repeated.children); n == 0 || n > 2 || repeated.children[0].data == nil {
+		return nil, nil, nil, false
+	}
+	key = repeated.children[0]
+	if len(repeated.children) == 2 {
+		value = repeated.children[1]
+	}
+	return repeated, key, value, true
+}
+
+// convert returns the value v of the column c with the values of its LIST and MAP groups converted.
+func (gc groupConversion) convert(c *Column, v interface{}) (interface{}, error) {
+	if v == nil {
+		return v, nil
+	}
+	if c.data != nil {
+		return gc.convertValue(c, v)
+	}
+
+	switch {
+	case c.parent == listParent && gc.listsAsSlices:
+		if repeated, element, ok := listElement(c); ok {
+			m, _ := v.(map[string]interface{})
+			return gc.convertList(repeated, element, m[repeated.name])
+		}
+	case c.parent == mapParent && gc.maps != mapsAsGroups:
+		if repeated, key, value, ok := mapKeyValue(c); ok {
+			m, _ := v.(map[string]interface{})
+			return gc.convertMap(key, value, m[repeated.name])
+		}
+	}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return gc.convertGroup(c, x)
+	case []map[string]interface{}:
+		ret := make([]map[string]interface{}, len(x))
+		for i := range x {
+			var err error
+			if ret[i], err = gc.convertGroup(c, x[i]); err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	}
+	return v, nil
+}
+
+// convertGroup converts the values of the columns of the group c.
+func (gc groupConversion) convertGroup(c *Column, m map[string]interface{}) (map[string]interface{}, error) {
+	if m == nil {
+		return nil, nil
+	}
+	ret := make(map[string]interface{}, len(m))
+	for _, child := range c.children {
+		v, ok := m[child.name]
+		if !ok {
+			continue
+		}
+		var err error
+		if ret[child.name], err = gc.convert(child, v); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// convertList returns the elements of a list as a []interface{} from the value v of its repeated column. A null
+// element is nil, a list without elements is an empty slice.
+func (gc groupConversion) convertList(repeated, element *Column, v interface{}) (interface{}, error) {
+	ret := []interface{}{}
+	if repeated.data != nil {
+		// the values of a repeated primitive column are typed slices
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice {
+			return ret, nil
+		}
+		for i := 0; i < rv.Len(); i++ {
+			ret = append(ret, rv.Index(i).Interface())
+		}
+		return ret, nil
+	}
+
+	items, _ := v.([]map[string]interface{})
+	for _, item := range items {
+		var (
+			value interface{}
+			err   error
+		)
+		if element == nil {
+			value, err = gc.convertGroup(repeated, item)
+		} else if item != nil {
+			value, err = gc.convert(element, item[element.name])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := value.(map[string]interface{}); ok && m == nil {
+			value = nil
+		}
+		ret = append(ret, value)
+	}
+	return ret, nil
+}
+
+// convertMap returns the keys and values of a map from the value v of its repeated group, as a Go map or a
+// []MapEntry. The keys of BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns are strings in a Go map, since a []byte can't be
+// a map key, and so are the keys of DECIMAL columns. A null value is nil, a null key is an error.
+func (gc groupConversion) convertMap(key, value *Column, v interface{}) (interface{}, error) {
+	items, _ := v.([]map[string]interface{})
+
+	var entries []MapEntry
+	for _, item := range items {
+		k, ok := item[key.name]
+		if !ok {
+			return nil, errors.Errorf("null key in the map column %q", key.flatName)
+		}
+		entry := MapEntry{}
+		var err error
+		if entry.Key, err = gc.convertValue(key, k); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			if entry.Value, err = gc.convert(value, item[value.name]); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	if gc.maps == mapsAsEntries {
+		if entries == nil {
+			entries = []MapEntry{}
+		}
+		return entries, nil
+	}
+
+	keyType := gc.mapKeyType(key.Element())
+	ret := reflect.MakeMapWithSize(reflect.MapOf(keyType, reflect.TypeOf((*interface{})(nil)).Elem()), len(entries))
+	for _, entry := range entries {
+		k := entry.Key
+		switch x := k.(type) {
+		case []byte:
+			k = string(x)
+		case Decimal:
+			k = x.String()
+		}
+		kv := reflect.ValueOf(k)
+		if !kv.Type().AssignableTo(keyType) {
+			return nil, errors.Errorf("the key %v of the map column %q is a %T, not a %s", k, key.flatName, k, keyType)
+		}
+		vv := reflect.Zero(ret.Type().Elem())
+		if entry.Value != nil {
+			vv = reflect.ValueOf(entry.Value)
+		}
+		ret.SetMapIndex(kv, vv)
+	}
+	return ret.Interface(), nil
+}
+
+// convertValue returns the value v of the primitive column c, converted if it is an INT96 column and int96AsTime is
+// set, a DECIMAL column and decimals is set, or a UUID column and uuids is set. The values of a repeated column are
+// converted to a slice of the converted values, e.g. a []time.Time.
+func (gc groupConversion) convertValue(c *Column, v interface{}) (interface{}, error) {
+	if gc.uuids != uuidsAsBytes && isUUID(c.Element()) {
+		return gc.convertUUIDs(c, v)
+	}
+	if gc.decimals {
+		if scale, ok := decimalScale(c.Element()); ok {
+			return convertDecimals(c, v, scale)
+		}
+	}
+	if !gc.int96AsTime || c.Type() == nil || *c.Type() != parquet.Type_INT96 {
+		return v, nil
+	}
+	switch x := v.(type) {
+	case [12]byte:
+		return Int96ToTime(x), nil
+	case [][12]byte:
+		ret := make([]time.Time, len(x))
+		for i := range x {
+			ret[i] = Int96ToTime(x[i])
+		}
+		return ret, nil
+	}
+	return v, nil
+}
+
+// convertDecimals returns the value v of the DECIMAL column c as a Decimal, or as a []Decimal if c is repeated.
+func convertDecimals(c *Column, v interface{}, scale int32) (interface{}, error) {
+	if _, ok := v.([]byte); ok || c.rep != parquet.FieldRepetitionType_REPEATED {
+		d, err := decodeDecimal(v, scale)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of the decimal column %q", c.flatName)
+		}
+		return d, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, errors.Errorf("invalid value of type %T of the repeated decimal column %q", v, c.flatName)
+	}
+	ret := make([]Decimal, rv.Len())
+	for i := range ret {
+		var err error
+		if ret[i], err = decodeDecimal(rv.Index(i).Interface(), scale); err != nil {
+			return nil, errors.Wrapf(err, "invalid value of the decimal column %q", c.flatName)
+		}
+	}
+	return ret, nil
+}
+
+// convertUUIDs returns the value v of the UUID column c as a [16]byte or a string, or as a slice of them if c is
+// repeated.
+func (gc groupConversion) convertUUIDs(c *Column, v interface{}) (interface{}, error) {
+	convert := func(b []byte) (interface{}, error) {
+		var u [16]byte
+		if len(b) != len(u) {
+			return nil, errors.Errorf("invalid value of %d bytes of the UUID column %q", len(b), c.flatName)
+		}
+		copy(u[:], b)
+		if gc.uuids == uuidsAsStrings {
+			return formatUUID(u), nil
+		}
+		return u, nil
+	}
+
+	switch x := v.(type) {
+	case []byte:
+		return convert(x)
+	case [][]byte:
+		var ret reflect.Value
+		if gc.uuids == uuidsAsStrings {
+			ret = reflect.ValueOf(make([]string, len(x)))
+		} else {
+			ret = reflect.ValueOf(make([][16]byte, len(x)))
+		}
+		for i := range x {
+			u, err := convert(x[i])
+			if err != nil {
+				return nil, err
+			}
+			ret.Index(i).Set(reflect.ValueOf(u))
+		}
+		return ret.Interface(), nil
+	}
+	return v, nil
+}
+
+// mapKeyType returns the type of the keys of a Go map for the values of the key column elem.
+func (gc groupConversion) mapKeyType(elem *parquet.SchemaElement) reflect.Type {
+	if _, ok := decimalScale(elem); ok && gc.decimals {
+		return reflect.TypeOf("")
+	}
+	if isUUID(elem) && gc.uuids == uuidsAsArrays {
+		return reflect.TypeOf([16]byte{})
+	}
+	var v interface{}
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		v = false
+	case parquet.Type_INT32:
+		v = int32(0)
+		if isUnsignedInt32(elem) {
+			v = uint32(0)
+		}
+	case parquet.Type_INT64:
+		v = int64(0)
+		if isUnsignedInt64(elem) {
+			v = uint64(0)
+		}
+	case parquet.Type_INT96:
+		v = [12]byte{}
+		if gc.int96AsTime {
+			v = time.Time{}
+		}
+	case parquet.Type_FLOAT:
+		v = float32(0)
+	case parquet.Type_DOUBLE:
+		v = float64(0)
+	default:
+		v = ""
+	}
+	return reflect.TypeOf(v)
+}
@@ -0,0 +1,257 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadListsAsSlices(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional group numbers (LIST) {
+				repeated group list {
+					optional int64 element;
+				}
+			}
+			optional group matrix (LIST) {
+				repeated group list {
+					required group element (LIST) {
+						repeated group list {
+							required int32 element;
+						}
+					}
+				}
+			}
+			optional group points (LIST) {
+				repeated group list {
+					optional group element {
+						required double x;
+						required double y;
+					}
+				}
+			}
+			optional group legacy (LIST) {
+				repeated binary array (STRING);
+			}
+			optional group avro (LIST) {
+				repeated group array {
+					required int32 value;
+				}
+			}
+			optional group thrift (LIST) {
+				repeated group thrift_tuple {
+					required int32 value;
+				}
+			}
+			optional group hive (LIST) {
+				repeated group bag {
+					optional int32 array_element;
+				}
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	written := []map[string]interface{}{
+		{"id": int64(0)},
+		{
+			"id":      int64(1),
+			"numbers": map[string]interface{}{},
+			"matrix":  map[string]interface{}{},
+			"points":  map[string]interface{}{},
+			"legacy":  map[string]interface{}{},
+			"hive":    map[string]interface{}{},
+		},
+		{
+			"id": int64(2),
+			"numbers": map[string]interface{}{"list": []map[string]interface{}{
+				{"element": int64(1)}, {}, {"element": int64(3)},
+			}},
+			"matrix": map[string]interface{}{"list": []map[string]interface{}{
+				{"element": map[string]interface{}{"list": []map[string]interface{}{{"element": int32(1)}, {"element": int32(2)}}}},
+				{"element": map[string]interface{}{}},
+				{"element": map[string]interface{}{"list": []map[string]interface{}{{"element": int32(3)}}}},
+			}},
+			"points": map[string]interface{}{"list": []map[string]interface{}{
+				{"element": map[string]interface{}{"x": float64(1), "y": float64(2)}}, {},
+			}},
+			"legacy": map[string]interface{}{"array": [][]byte{[]byte("a"), []byte("b")}},
+			"avro":   map[string]interface{}{"array": []map[string]interface{}{{"value": int32(1)}, {"value": int32(2)}}},
+			"thrift": map[string]interface{}{"thrift_tuple": []map[string]interface{}{{"value": int32(3)}}},
+			"hive":   map[string]interface{}{"bag": []map[string]interface{}{{"array_element": int32(4)}, {}}},
+		},
+	}
+	expected := []map[string]interface{}{
+		{"id": int64(0)},
+		{
+			"id":      int64(1),
+			"numbers": []interface{}{},
+			"matrix":  []interface{}{},
+			"points":  []interface{}{},
+			"legacy":  []interface{}{},
+			"hive":    []interface{}{},
+		},
+		{
+			"id":      int64(2),
+			"numbers": []interface{}{int64(1), nil, int64(3)},
+			"matrix":  []interface{}{[]interface{}{int32(1), int32(2)}, []interface{}{}, []interface{}{int32(3)}},
+			"points":  []interface{}{map[string]interface{}{"x": float64(1), "y": float64(2)}, nil},
+			"legacy":  []interface{}{[]byte("a"), []byte("b")},
+			"avro":    []interface{}{map[string]interface{}{"value": int32(1)}, map[string]interface{}{"value": int32(2)}},
+			"thrift":  []interface{}{map[string]interface{}{"value": int32(3)}},
+			"hive":    []interface{}{int32(4), nil},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range written {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithListsAsSlices())
+	require.NoError(t, err)
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row, "row %d", i)
+	}
+
+	rg, err := r.RowGroup(0)
+	require.NoError(t, err)
+	for i := range expected {
+		row, err := rg.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row, "row %d", i)
+	}
+
+	// without the option, the lists are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := range written {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, written[i], row, "row %d", i)
+	}
+}
+
+func TestReadMapsAsGoMaps(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional group tags (MAP) {
+				repeated group key_value {
+					required binary key (STRING);
+					optional group value (LIST) {
+						repeated group list {
+							required int32 element;
+						}
+					}
+				}
+			}
+			optional group counts (MAP) {
+				repeated group key_value {
+					required int64 key;
+					optional int32 value;
+				}
+			}
+			optional group legacy (MAP_KEY_VALUE) {
+				repeated group map {
+					required int32 key;
+					required binary value (STRING);
+				}
+			}
+			optional group names (MAP) {
+				repeated group key_value {
+					optional binary key (STRING);
+					optional binary value (STRING);
+				}
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	written := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1), "tags": map[string]interface{}{}, "counts": map[string]interface{}{}},
+		{
+			"id": int64(2),
+			"tags": map[string]interface{}{"key_value": []map[string]interface{}{
+				{"key": []byte("b"), "value": map[string]interface{}{"list": []map[string]interface{}{{"element": int32(1)}, {"element": int32(2)}}}},
+				{"key": []byte("a")},
+				{"key": []byte("c"), "value": map[string]interface{}{}},
+			}},
+			"counts": map[string]interface{}{"key_value": []map[string]interface{}{
+				{"key": int64(3), "value": int32(30)},
+				{"key": int64(1)},
+				{"key": int64(3), "value": int32(31)},
+			}},
+			"legacy": map[string]interface{}{"map": []map[string]interface{}{
+				{"key": int32(7), "value": []byte("seven")},
+			}},
+			"names": map[string]interface{}{"key_value": []map[string]interface{}{
+				{"key": []byte("x"), "value": []byte("y")},
+			}},
+		},
+		{"id": int64(3), "names": map[string]interface{}{"key_value": []map[string]interface{}{
+			{"value": []byte("no key")},
+		}}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range written {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	expected := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1), "tags": map[string]interface{}{}, "counts": map[int64]interface{}{}},
+		{
+			"id": int64(2),
+			"tags": map[string]interface{}{
+				"b": []interface{}{int32(1), int32(2)},
+				"a": nil,
+				"c": []interface{}{},
+			},
+			"counts": map[int64]interface{}{3: int32(31), 1: nil},
+			"legacy": map[int32]interface{}{7: []byte("seven")},
+			"names":  map[string]interface{}{"x": []byte("y")},
+		},
+	}
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMapsAsGoMaps(), WithListsAsSlices())
+	require.NoError(t, err)
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.EqualError(t, err, `reading row 3 failed: null key in the map column "names.key_value.key"`)
+
+	// the entries keep the order and the duplicate keys
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMapsAsEntries())
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, []MapEntry{
+		{Key: []byte("b"), Value: map[string]interface{}{"list": []map[string]interface{}{{"element": int32(1)}, {"element": int32(2)}}}},
+		{Key: []byte("a")},
+		{Key: []byte("c"), Value: map[string]interface{}{}},
+	}, row["tags"])
+	require.Equal(t, []MapEntry{
+		{Key: int64(3), Value: int32(30)},
+		{Key: int64(1)},
+		{Key: int64(3), Value: int32(31)},
+	}, row["counts"])
+}
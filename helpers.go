@@ -116,6 +116,26 @@ func writeThrift(tr thriftWriter, w io.Writer) error {
 	return tr.Write(proto)
 }
 
+// readFixedSize fills buf with values of size bytes from r, and returns the number of complete values read. The
+// error is io.EOF if the data ends at a value boundary and io.ErrUnexpectedEOF if the last value is partial.
+func readFixedSize(r io.Reader, buf []byte, size int) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.ErrUnexpectedEOF && n%size == 0 {
+		err = io.EOF
+	}
+
+	return n / size, err
+}
+
+// remainingBytes returns the number of unread bytes in r and true, if r knows it. The page data is always read into
+// memory before decoding, so the decoders can use it to reject lengths larger than the page before the allocation.
+func remainingBytes(r io.Reader) (int, bool) {
+	if l, ok := r.(interface{ Len() int }); ok {
+		return l.Len(), true
+	}
+	return 0, false
+}
+
 func decodeInt32(d decoder, data []int32) error {
 	for i := range data {
 		u, err := d.next()
@@ -128,6 +148,18 @@ func decodeInt32(d decoder, data []int32) error {
 	return nil
 }
 
+func decodeInt64(d decoder64, data []int64) error {
+	for i := range data {
+		u, err := d.next()
+		if err != nil {
+			return err
+		}
+		data[i] = u
+	}
+
+	return nil
+}
+
 func decodePackedArray(d levelDecoder, count int) (*packedArray, int, error) {
 	ret := &packedArray{}
 	ret.reset(bits.Len16(d.maxLevel()))
@@ -255,26 +287,68 @@ func encodeValue(w io.Writer, enc valuesEncoder, all []interface{}) error {
 	return enc.Close()
 }
 
+// encodeStoreValues encodes the values from to to of the store with enc, which must be initialized. Typed values
+// are passed to the typed method of the encoder, so they are never boxed. The other values are boxed into scratch,
+// which is returned for the next call.
+func encodeStoreValues(enc valuesEncoder, d *dictStore, from, to int, scratch []interface{}) ([]interface{}, error) {
+	if d.typed == nil {
+		scratch = scratch[:0]
+		for _, idx := range d.data[from:to] {
+			scratch = append(scratch, d.values[idx])
+		}
+		return scratch, enc.encodeValues(scratch)
+	}
+
+	switch values := d.typed.(type) {
+	case []int32:
+		e, ok := enc.(int32BatchEncoder)
+		if !ok {
+			return scratch, errors.Errorf("encoder %T does not support int32 values", enc)
+		}
+		return scratch, e.encodeInt32s(values[from:to])
+	case []int64:
+		e, ok := enc.(int64BatchEncoder)
+		if !ok {
+			return scratch, errors.Errorf("encoder %T does not support int64 values", enc)
+		}
+		return scratch, e.encodeInt64s(values[from:to])
+	case []float64:
+		e, ok := enc.(float64BatchEncoder)
+		if !ok {
+			return scratch, errors.Errorf("encoder %T does not support float64 values", enc)
+		}
+		return scratch, e.encodeFloat64s(values[from:to])
+	case [][]byte:
+		e, ok := enc.(byteArrayBatchEncoder)
+		if !ok {
+			return scratch, errors.Errorf("encoder %T does not support byte array values", enc)
+		}
+		return scratch, e.encodeByteArrays(values[from:to])
+	default:
+		return scratch, errors.Errorf("unsupported typed values %T", d.typed)
+	}
+}
+
 // In PageV1 the rle stream for rep/def level has the size in stream , but in V2 the size is inside the header not the
 // stream
-func encodeLevelsV1(w io.Writer, max uint16, values *packedArray) error {
+func encodeLevelsV1(w io.Writer, max uint16, values *packedArray, from, to int) error {
 	rle := newHybridEncoder(bits.Len16(max))
 	if err := rle.initSize(w); err != nil {
 		return errors.Wrap(err, "level writer initialize with size failed")
 	}
-	if err := rle.encodePacked(values); err != nil {
+	if err := rle.encodePacked(values, from, to); err != nil {
 		return errors.Wrap(err, "level writer encode values failed")
 	}
 
 	return errors.Wrap(rle.Close(), "level writer flush failed")
 }
 
-func encodeLevelsV2(w io.Writer, max uint16, values *packedArray) error {
+func encodeLevelsV2(w io.Writer, max uint16, values *packedArray, from, to int) error {
 	rle := newHybridEncoder(bits.Len16(max))
 	if err := rle.init(w); err != nil {
 		return errors.Wrap(err, "level writer initialize with size failed")
 	}
-	if err := rle.encodePacked(values); err != nil {
+	if err := rle.encodePacked(values, from, to); err != nil {
 		return errors.Wrap(err, "level writer encode values failed")
 	}
 
@@ -302,6 +376,8 @@ func fnvHashFunc(in []byte) interface{} {
 	return hash.Sum64()
 }
 
+// writePos is a writer that counts the bytes written to it. All offsets in the meta data of a file are positions of
+// the writer, as the file writer never seeks.
 type writePos interface {
 	io.Writer
 	Pos() int64
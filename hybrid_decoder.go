@@ -21,6 +21,13 @@ type decoder interface {
 	initSize(io.Reader) error
 }
 
+// decoder64 is the 64 bit counterpart of decoder, used for the INT64 delta encoding.
+type decoder64 interface {
+	next() (int64, error)
+
+	init(io.Reader) error
+}
+
 type levelDecoder interface {
 	decoder
 
@@ -115,12 +122,9 @@ func (hd *hybridDecoder) next() (next int32, err error) {
 
 func (hd *hybridDecoder) readRLERunValue() error {
 	v := make([]byte, hd.rleValueSize)
-	n, err := hd.r.Read(v)
-	if err != nil {
-		return err
-	}
-	if n != hd.rleValueSize {
-		return io.ErrUnexpectedEOF
+	if _, err := io.ReadFull(hd.r, v); err != nil {
+		// the run header is already read, so the run value must be there
+		return unexpectedEOF(err)
 	}
 
 	hd.rleValue = decodeRLEValue(v)
@@ -132,14 +136,22 @@ func (hd *hybridDecoder) readRLERunValue() error {
 
 func (hd *hybridDecoder) readBitPackedRun() error {
 	data := make([]byte, hd.bitWidth)
-	_, err := hd.r.Read(data)
-	if err != nil {
-		return err
+	if _, err := io.ReadFull(hd.r, data); err != nil {
+		// the run header promised at least one more group of 8 values
+		return unexpectedEOF(err)
 	}
 	hd.bpRun = hd.unpackerFn(data)
 	return nil
 }
 
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, to use in places where the end of stream is not acceptable.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
 func (hd *hybridDecoder) readRunHeader() error {
 	h, err := readUVariant32(hd.r)
 	if err != nil {
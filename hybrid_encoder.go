@@ -6,32 +6,49 @@ import (
 	"io"
 )
 
+// maxBitPackedGroups is the maximum number of 8-value groups in a single bit-packed run. Limiting the run to 63
+// groups keeps the run header in a single byte, which is exactly what parquet-mr is doing.
+const maxBitPackedGroups = 63
+
+// hybridEncoder is the RLE/bit-packed hybrid encoder. It follows the same logic as parquet-mr: values are buffered in
+// groups of 8, and as soon as a value is repeated at least 8 times, it switches to a RLE run. otherwise the groups are
+// written as bit-packed runs.
 type hybridEncoder struct {
 	w io.Writer
 
-	left       []int32
-	original   io.Writer
-	bitWidth   int
-	unpackerFn pack8int32Func
+	original io.Writer
+	bitWidth int
+	packerFn pack8int32Func
+
+	buffered      [8]int32
+	bufferedCount int
+
+	previousValue int32
+	repeatCount   int
 
-	data *packedArray
+	bpRun        []byte
+	bpGroupCount int
+
+	written int
 }
 
 func newHybridEncoder(bitWidth int) *hybridEncoder {
-	p := &packedArray{}
 	return &hybridEncoder{
-		bitWidth:   bitWidth,
-		unpackerFn: pack8Int32FuncByWidth[bitWidth],
-		data:       p,
+		bitWidth: bitWidth,
+		packerFn: pack8Int32FuncByWidth[bitWidth],
 	}
 }
 
 func (he *hybridEncoder) init(w io.Writer) error {
 	he.w = w
-	he.left = nil
 	he.original = nil
 
-	he.data.reset(he.bitWidth)
+	he.bufferedCount = 0
+	he.previousValue = 0
+	he.repeatCount = 0
+	he.bpRun = he.bpRun[:0]
+	he.bpGroupCount = 0
+	he.written = 0
 	return nil
 }
 
@@ -47,45 +64,170 @@ func (he *hybridEncoder) write(items ...[]byte) error {
 		if err := writeFull(he.w, items[i]); err != nil {
 			return err
 		}
+		he.written += len(items[i])
 	}
 
 	return nil
 }
 
-func (he *hybridEncoder) bpEncode() error {
-	// If the bit width is zero, no need to write any
-	if he.bitWidth == 0 {
+func (he *hybridEncoder) writeRunHeader(header int) error {
+	buf := make([]byte, binary.MaxVarintLen32)
+	cnt := binary.PutUvarint(buf, uint64(header))
+	return he.write(buf[:cnt])
+}
+
+// endBitPackedRun writes the pending bit-packed groups (if there is any) to the stream
+func (he *hybridEncoder) endBitPackedRun() error {
+	if he.bpGroupCount == 0 {
 		return nil
 	}
-	l := he.data.count
-	if x := l % 8; x != 0 {
-		l += 8 - x
+
+	if err := he.writeRunHeader(he.bpGroupCount<<1 | 1); err != nil {
+		return err
+	}
+	if err := he.write(he.bpRun); err != nil {
+		return err
 	}
 
-	header := ((l / 8) << 1) | 1
-	buf := make([]byte, 4) // big enough for int
-	cnt := binary.PutUvarint(buf, uint64(header))
+	he.bpRun = he.bpRun[:0]
+	he.bpGroupCount = 0
+	return nil
+}
+
+func (he *hybridEncoder) appendBitPackedGroup() error {
+	if he.bpGroupCount >= maxBitPackedGroups {
+		if err := he.endBitPackedRun(); err != nil {
+			return err
+		}
+	}
+
+	he.bpRun = append(he.bpRun, he.packerFn(he.buffered)...)
+	he.bpGroupCount++
+	he.bufferedCount = 0
+	// the repeated values are already in the bit-packed run
+	he.repeatCount = 0
+	return nil
+}
+
+func (he *hybridEncoder) writeRLERun() error {
+	if err := he.endBitPackedRun(); err != nil {
+		return err
+	}
+
+	if err := he.writeRunHeader(he.repeatCount << 1); err != nil {
+		return err
+	}
+
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, uint32(he.previousValue))
+	if err := he.write(value[:(he.bitWidth+7)/8]); err != nil {
+		return err
+	}
+
+	he.repeatCount = 0
+	he.bufferedCount = 0
+	return nil
+}
+
+func (he *hybridEncoder) encodeOne(v int32) error {
+	if v == he.previousValue && he.repeatCount > 0 {
+		he.repeatCount++
+		if he.repeatCount >= 8 {
+			// continue the RLE run, the value is already in the run
+			return nil
+		}
+	} else {
+		if he.repeatCount >= 8 {
+			if err := he.writeRLERun(); err != nil {
+				return err
+			}
+		}
+		he.repeatCount = 1
+		he.previousValue = v
+	}
+
+	he.buffered[he.bufferedCount] = v
+	he.bufferedCount++
+	if he.bufferedCount == 8 {
+		return he.appendBitPackedGroup()
+	}
 
-	return he.write(buf[:cnt], he.data.data)
+	return nil
 }
 
 func (he *hybridEncoder) encode(data []int32) error {
+	// If the bit width is zero, no need to write any
+	if he.bitWidth == 0 {
+		return nil
+	}
 	for i := range data {
-		he.data.appendSingle(data[i])
+		if err := he.encodeOne(data[i]); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (he *hybridEncoder) encodePacked(data *packedArray) error {
-	he.data.appendArray(data)
+// encodePacked encodes the values from to to of data.
+func (he *hybridEncoder) encodePacked(data *packedArray, from, to int) error {
+	if he.bitWidth == 0 || data == nil {
+		return nil
+	}
+	for i := from; i < to; i++ {
+		v, err := data.at(i)
+		if err != nil {
+			return err
+		}
+		if err := he.encodeOne(v); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (he *hybridEncoder) flush() error {
-	he.data.flush()
-	return he.bpEncode()
+	if he.repeatCount >= 8 {
+		return he.writeRLERun()
+	}
+
+	if he.bufferedCount > 0 {
+		// pad the last group with zeros
+		for i := he.bufferedCount; i < 8; i++ {
+			he.buffered[i] = 0
+		}
+		if err := he.appendBitPackedGroup(); err != nil {
+			return err
+		}
+	}
+
+	return he.endBitPackedRun()
+}
+
+// estimatedSize returns the size of the runs written so far, plus the pending bit-packed run and the buffered
+// values. The size prefix is included if the encoder writes it.
+func (he *hybridEncoder) estimatedSize() int {
+	if he.bitWidth == 0 {
+		return 0
+	}
+
+	size := he.written
+	if he.original != nil {
+		size += 4
+	}
+	if he.bpGroupCount > 0 {
+		// the header of a bit-packed run is always a single byte
+		size += 1 + len(he.bpRun)
+	}
+	// the buffered values end up either in a RLE run or in a bit-packed group
+	if he.repeatCount >= 8 {
+		size += binary.MaxVarintLen32 + (he.bitWidth+7)/8
+	} else if he.bufferedCount > 0 {
+		size += he.bitWidth
+	}
+
+	return size
 }
 
 func (he *hybridEncoder) Close() error {
@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"bytes"
+	"io"
 	"math"
 	"math/rand"
 	"testing"
@@ -69,10 +70,187 @@ func TestOnlyOne(t *testing.T) {
 	data.flush()
 
 	buf := &bytes.Buffer{}
-	require.NoError(t, encodeLevelsV1(buf, 1, data))
+	require.NoError(t, encodeLevelsV1(buf, 1, data, 0, data.count))
 	read := make([]int32, 1000)
 	dec := newHybridDecoder(1)
 	require.NoError(t, dec.initSize(bytes.NewReader(buf.Bytes())))
 	require.NoError(t, decodeInt32(dec, read))
 	require.Equal(t, data.toArray(), read)
 }
+
+func TestHybridDecoderZeroBitWidth(t *testing.T) {
+	dec := newHybridDecoder(0)
+	buf := bytes.NewReader([]byte{1, 2, 3})
+	require.NoError(t, dec.initSize(buf))
+	read := make([]int32, 100)
+	require.NoError(t, decodeInt32(dec, read))
+	require.Equal(t, make([]int32, 100), read)
+	// nothing should be consumed from the stream
+	require.Equal(t, 3, buf.Len())
+}
+
+func TestHybridDecoderRunsSpanningBatches(t *testing.T) {
+	// RLE run of 10 times 3, followed by a bit-packed run of 8 values, and another RLE run of 5 times 1
+	data := []byte{
+		10 << 1, 3,
+		1<<1 | 1, 0x88, 0xc6, 0xfa,
+		5 << 1, 1,
+	}
+	dec := newHybridDecoder(3)
+	require.NoError(t, dec.init(bytes.NewReader(data)))
+
+	var all []int32
+	for _, size := range []int{3, 7, 2, 9, 2} {
+		read := make([]int32, size)
+		require.NoError(t, decodeInt32(dec, read))
+		all = append(all, read...)
+	}
+	require.Equal(t, []int32{3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 0, 1, 2, 3, 4, 5, 6, 7, 1, 1, 1, 1, 1}, all)
+
+	_, err := dec.next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestHybridDecoderTruncatedInput(t *testing.T) {
+	data := []struct {
+		name     string
+		bitWidth int
+		data     []byte
+	}{
+		{name: "missing RLE value", bitWidth: 3, data: []byte{10 << 1}},
+		{name: "short RLE value", bitWidth: 12, data: []byte{10 << 1, 0xff}},
+		{name: "missing bit-packed run", bitWidth: 3, data: []byte{1<<1 | 1}},
+		{name: "short bit-packed run", bitWidth: 3, data: []byte{1<<1 | 1, 0x88, 0xc6}},
+		{name: "short second bit-packed group", bitWidth: 3, data: []byte{2<<1 | 1, 0x88, 0xc6, 0xfa, 0x88}},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			dec := newHybridDecoder(d.bitWidth)
+			require.NoError(t, dec.init(bytes.NewReader(d.data)))
+			read := make([]int32, 16)
+			require.Equal(t, io.ErrUnexpectedEOF, decodeInt32(dec, read))
+		})
+	}
+}
+
+func TestHybridEncoderGolden(t *testing.T) {
+	data := []struct {
+		name     string
+		bitWidth int
+		values   []int32
+		expected []byte
+	}{
+		{
+			name:     "bit-packed only",
+			bitWidth: 3,
+			values:   []int32{0, 1, 2, 3, 4, 5, 6, 7},
+			expected: []byte{1<<1 | 1, 0x88, 0xc6, 0xfa},
+		},
+		{
+			name:     "rle only",
+			bitWidth: 1,
+			values:   make([]int32, 100),
+			expected: []byte{0xc8, 0x01, 0x00},
+		},
+		{
+			name:     "rle followed by padded bit-packed",
+			bitWidth: 2,
+			values:   []int32{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 3},
+			expected: []byte{10 << 1, 0x01, 1<<1 | 1, 0x0e, 0x00},
+		},
+		{
+			name:     "bit-packed followed by rle",
+			bitWidth: 3,
+			values:   []int32{0, 1, 2, 3, 4, 5, 6, 7, 5, 5, 5, 5, 5, 5, 5, 5, 5},
+			expected: []byte{1<<1 | 1, 0x88, 0xc6, 0xfa, 9 << 1, 0x05},
+		},
+		{
+			name:     "two byte rle value",
+			bitWidth: 10,
+			values:   []int32{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000},
+			expected: []byte{8 << 1, 0xe8, 0x03},
+		},
+		{
+			name:     "empty",
+			bitWidth: 3,
+			values:   []int32{},
+			expected: nil,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			enc := newHybridEncoder(d.bitWidth)
+			require.NoError(t, enc.init(buf))
+			require.NoError(t, enc.encode(d.values))
+			require.NoError(t, enc.Close())
+			require.Equal(t, d.expected, buf.Bytes())
+
+			dec := newHybridDecoder(d.bitWidth)
+			require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+			read := make([]int32, len(d.values))
+			require.NoError(t, decodeInt32(dec, read))
+			require.Equal(t, d.values, read)
+		})
+	}
+}
+
+func TestHybridEncoderSizePrefix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := newHybridEncoder(1)
+	require.NoError(t, enc.initSize(buf))
+	require.NoError(t, enc.encode(make([]int32, 100)))
+	require.NoError(t, enc.Close())
+	require.Equal(t, []byte{3, 0, 0, 0, 0xc8, 0x01, 0x00}, buf.Bytes())
+}
+
+func TestHybridEncoderLongBitPackedRun(t *testing.T) {
+	// more than 63 groups without any repetition must be split into multiple bit-packed runs
+	values := make([]int32, 8*maxBitPackedGroups+16)
+	for i := range values {
+		values[i] = int32(i % 2)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := newHybridEncoder(1)
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encode(values))
+	require.NoError(t, enc.Close())
+
+	require.Equal(t, 1+maxBitPackedGroups+1+2, buf.Len())
+	require.Equal(t, byte(maxBitPackedGroups<<1|1), buf.Bytes()[0])
+	require.Equal(t, byte(2<<1|1), buf.Bytes()[1+maxBitPackedGroups])
+
+	dec := newHybridDecoder(1)
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	read := make([]int32, len(values))
+	require.NoError(t, decodeInt32(dec, read))
+	require.Equal(t, values, read)
+}
+
+func TestHybridRepeatedRoundTrip(t *testing.T) {
+	for w := 1; w <= 32; w++ {
+		var values []int32
+		for _, v := range buildData(w, 200) {
+			values = append(values, v)
+			// add runs of different lengths around the RLE threshold
+			for j := 0; j < rand.Intn(20); j++ {
+				values = append(values, v)
+			}
+		}
+
+		data := &bytes.Buffer{}
+		enc := newHybridEncoder(w)
+		require.NoError(t, enc.initSize(data))
+		require.NoError(t, enc.encode(values))
+		require.NoError(t, enc.Close())
+
+		dec := newHybridDecoder(w)
+		require.NoError(t, dec.initSize(bytes.NewReader(data.Bytes())))
+		read := make([]int32, len(values))
+		require.NoError(t, decodeInt32(dec, read))
+		require.Equal(t, values, read, "bit width %d", w)
+	}
+}
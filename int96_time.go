@@ -11,21 +11,27 @@ const (
 )
 
 func timeToJD(t time.Time) (uint32, uint64) {
-	days := t.Unix() / secPerDay
-	nSecs := t.UnixNano() - (days * secPerDay * int64(time.Second))
+	// the division rounds towards zero, the days before the epoch have to be rounded down
+	sec := t.Unix()
+	days := sec / secPerDay
+	if sec%secPerDay < 0 {
+		days--
+	}
+	nSecs := (sec-days*secPerDay)*int64(time.Second) + int64(t.Nanosecond())
 
 	// unix time starts from Jan 1, 1970 AC, this day is 2440588 day after the Jan 1, 4713 BC
-	return uint32(days) + jan011970, uint64(nSecs)
+	return uint32(days + jan011970), uint64(nSecs)
 }
 
 func jdToTime(jd uint32, nsec uint64) time.Time {
-	sec := int64(jd-jan011970) * secPerDay
-	return time.Unix(sec, int64(nsec))
+	sec := (int64(jd) - jan011970) * secPerDay
+	return time.Unix(sec, int64(nsec)).UTC()
 }
 
 // Int96ToTime is a utility function to convert a Int96 Julian Date timestamp (https://en.wikipedia.org/wiki/Julian_day) to a time.Time.
-// Please be aware that this function is limited to timestamps after the Unix epoch (Jan 01 1970 00:00:00 UTC) and cannot
-// convert timestamps before that. The returned time does not contain a monotonic clock reading and is in the machine's current time zone.
+// The low 8 bytes are the nanoseconds of the day, the high 4 bytes are the Julian day number, both little endian. The
+// timestamps are in UTC as Spark and Hive write them, so the returned time is in UTC. It doesn't contain a monotonic
+// clock reading, and dates before the Unix epoch (Jan 01 1970 00:00:00 UTC) are supported.
 func Int96ToTime(parquetDate [12]byte) time.Time {
 	nano := binary.LittleEndian.Uint64(parquetDate[:8])
 	dt := binary.LittleEndian.Uint32(parquetDate[8:])
@@ -34,8 +40,8 @@ func Int96ToTime(parquetDate [12]byte) time.Time {
 }
 
 // TimeToInt96 is a utility function to convert a time.Time to an Int96 Julian Date timestamp (https://en.wikipedia.org/wiki/Julian_day).
-// Please be aware that this function is limited to timestamps after the Unix epoch (Jan 01 1970 00:00:00 UTC) and cannot convert
-// timestamps before that.
+// The time is converted to UTC first, and its nanoseconds are kept. Dates before the Unix epoch (Jan 01 1970 00:00:00
+// UTC) are supported.
 func TimeToInt96(t time.Time) [12]byte {
 	var parquetDate [12]byte
 	days, nSecs := timeToJD(t)
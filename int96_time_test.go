@@ -1,15 +1,19 @@
 package goparquet
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
+	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/require"
 )
 
 func TestConvert(t *testing.T) {
 	// The round here is essential, since the time in parquet does not contains a monotonic clock reading
-	now := time.Now().Round(0)
+	now := time.Now().UTC().Round(0)
 	arr := []time.Time{
 		now,
 		now.Add(time.Hour),
@@ -17,6 +21,11 @@ func TestConvert(t *testing.T) {
 		now.Add(-240 * time.Hour),
 		now.Add(-2400 * time.Hour),
 		now.Add(-24000 * time.Hour),
+		time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(1900, 1, 1, 0, 0, 0, 1, time.UTC),
+		time.Date(1582, 10, 15, 12, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2262, 4, 12, 0, 0, 0, 1, time.UTC),
 	}
 
 	for i := range arr {
@@ -27,8 +36,116 @@ func TestConvert(t *testing.T) {
 	}
 
 	date := [12]byte{00, 0x60, 0xFD, 0x4B, 0x32, 0x29, 0x00, 0x00, 0x59, 0x68, 0x25, 0x00}
-	// the generated time is in the current timezone (depends on the machine)
 	ts := Int96ToTime(date)
 	expected := time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC)
-	require.Equal(t, expected, ts.UTC())
+	require.Equal(t, expected, ts)
+
+	// the time is converted to UTC
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	require.Equal(t, date, TimeToInt96(time.Date(2000, 1, 1, 14, 34, 56, 0, loc)))
+
+	// 1969-12-31 23:00:00 is the Julian day 2440587 and 23 hours
+	date = TimeToInt96(time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC))
+	require.Equal(t, [12]byte{0x00, 0x60, 0x96, 0x60, 0x4E, 0x4B, 0x00, 0x00, 0x8B, 0x3D, 0x25, 0x00}, date)
+}
+
+func TestReadInt96AsTime(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int96 ts;
+			optional int96 opt;
+			repeated int96 rep;
+			optional group m (MAP) {
+				repeated group key_value (MAP_KEY_VALUE) {
+					required int96 key;
+					optional int64 value;
+				}
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	times := []time.Time{
+		time.Date(2020, 2, 29, 1, 2, 3, 456789123, time.UTC),
+		time.Date(1960, 6, 1, 0, 0, 0, 1, time.UTC),
+	}
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"ts":  TimeToInt96(times[0]),
+		"opt": TimeToInt96(times[1]),
+		"rep": [][12]byte{TimeToInt96(times[1]), TimeToInt96(times[0])},
+		"m": map[string]interface{}{
+			"key_value": []map[string]interface{}{{"key": TimeToInt96(times[0]), "value": int64(1)}},
+		},
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"ts": TimeToInt96(times[1]),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithInt96AsTime(), WithMapsAsGoMaps())
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"ts":  times[0],
+		"opt": times[1],
+		"rep": []time.Time{times[1], times[0]},
+		"m":   map[time.Time]interface{}{times[0]: int64(1)},
+	}, row)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"ts": times[1]}, row)
+
+	// without the option the values are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "ts")
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"ts": TimeToInt96(times[0])}, row)
+}
+
+func TestInt96GoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library, in the layout Spark
+	// writes INT96 timestamps with parquet-mr: a PLAIN_DICTIONARY dictionary page and data page. The values have
+	// nanoseconds and are before and after 1970.
+	data, err := ioutil.ReadFile("testdata/int96.parquet")
+	require.NoError(t, err)
+
+	times := []time.Time{
+		time.Date(2021, 6, 15, 8, 0, 0, 1000, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		{},
+		time.Date(1900, 1, 1, 12, 34, 56, 123456789, time.UTC),
+		time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 6, 15, 8, 0, 0, 1000, time.UTC),
+		time.Date(1066, 10, 14, 9, 0, 0, 0, time.UTC),
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithInt96AsTime())
+	require.NoError(t, err)
+	for i, ts := range times {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int32(i)}
+		if !ts.IsZero() {
+			expected["ts"] = ts
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// without the option the values are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for i, ts := range times {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		if !ts.IsZero() {
+			require.Equal(t, TimeToInt96(ts), row["ts"], "row %d", i)
+		}
+	}
 }
@@ -13,22 +13,77 @@ type pageReader interface {
 
 	readValues([]interface{}) (n int, dLevel *packedArray, rLevel *packedArray, err error)
 
+	// notNullValues consumes the levels of the remaining values in the page, and returns the values decoder
+	// together with the number of not null values that are left to decode from it.
+	notNullValues() (valuesDecoder, int, error)
+
 	numValues() int32
+
+	// release returns the buffer of the page data to the pool, once all values of the page are read.
+	release()
 }
 
 // pageReader is an internal interface used only internally to read the pages
 type pageWriter interface {
-	init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error
+	init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec, compressor BlockCompressor, enableCRC bool, maxStatsSize int) error
+
+	// encode encodes a data page with the rows from the start of p, until the page reaches maxPageSize or has
+	// maxPageRows rows. The range of the page is in the result.
+	encode(p pageRange, maxPageSize int64, maxPageRows int) (*encodedPage, error)
+
+	// compress compresses an encoded page and completes its header. It only uses the encoded page, so it can run
+	// concurrently with the encoding of the next pages.
+	compress(ep *encodedPage) error
 
-	write(w io.Writer) (int, int, error)
+	// dictEncodings returns the encoding of the dictionary page and the encoding of the
+	// dictionary encoded data page for this page format.
+	dictEncodings() (dictPage parquet.Encoding, dataPage parquet.Encoding)
+
+	// dataPageType returns the type of the data pages of this page format.
+	dataPageType() parquet.PageType
 }
 
 type newDataPageFunc func(useDict bool) pageWriter
 
 type valuesDecoder interface {
 	init(io.Reader) error
-	// the error io.EOF with the less value is acceptable, any other error is not
-	decodeValues([]interface{}) (int, error)
+	// decodeValues decodes up to len(dst) values and returns the number of decoded values. If the data ends before
+	// dst is full, it returns the count with io.EOF, so the tail of a page can be read without knowing its size. A
+	// value that is cut in the middle is reported as io.ErrUnexpectedEOF, any error other than io.EOF is fatal.
+	decodeValues(dst []interface{}) (n int, err error)
+}
+
+// valuesCountLimiter is implemented by the values decoders that read the number of values from the stream. The page
+// readers call setMaxValues with the number of values in the page before init.
+type valuesCountLimiter interface {
+	setMaxValues(n int32)
+}
+
+// valuesCountDecoder is implemented by the values decoders that need the number of not null values in the page to
+// split its data. The page readers call initNumValues with it instead of init.
+type valuesCountDecoder interface {
+	valuesDecoder
+	initNumValues(r io.Reader, n int32) error
+}
+
+// int32BatchDecoder, int64BatchDecoder, float64BatchDecoder and byteArrayBatchDecoder are implemented by the
+// values decoders that can decode into a typed slice, without boxing every value in an interface{}. Same as
+// decodeValues, they return io.EOF with less values at the end. Unsigned values are returned as their signed
+// bit pattern.
+type int32BatchDecoder interface {
+	decodeInt32Batch([]int32) (int, error)
+}
+
+type int64BatchDecoder interface {
+	decodeInt64Batch([]int64) (int, error)
+}
+
+type float64BatchDecoder interface {
+	decodeFloat64Batch([]float64) (int, error)
+}
+
+type byteArrayBatchDecoder interface {
+	decodeByteArrayBatch([][]byte) (int, error)
 }
 
 type dictValuesDecoder interface {
@@ -40,10 +95,32 @@ type dictValuesDecoder interface {
 type valuesEncoder interface {
 	init(io.Writer) error
 	encodeValues([]interface{}) error
+	// estimatedSize returns the number of bytes the encoded values would take if the encoder was closed now,
+	// including everything that is still buffered in the encoder.
+	estimatedSize() int
 
 	io.Closer
 }
 
+// int32BatchEncoder, int64BatchEncoder, float64BatchEncoder and byteArrayBatchEncoder are implemented by the
+// values encoders that can encode a typed slice, without the values boxed in an interface{}. Unsigned values are
+// passed as their signed bit pattern.
+type int32BatchEncoder interface {
+	encodeInt32s([]int32) error
+}
+
+type int64BatchEncoder interface {
+	encodeInt64s([]int64) error
+}
+
+type float64BatchEncoder interface {
+	encodeFloat64s([]float64) error
+}
+
+type byteArrayBatchEncoder interface {
+	encodeByteArrays([][]byte) error
+}
+
 type dictValuesEncoder interface {
 	valuesEncoder
 
@@ -60,11 +137,7 @@ type parquetColumn interface {
 type typedColumnStore interface {
 	parquetColumn
 	reset(repetitionType parquet.FieldRepetitionType)
-	// Min and Max in parquet byte
-	maxValue() []byte
-	minValue() []byte
-
-	// Should extract the value, turn it into an array and check for min and max on all values in this
+	// Should extract the value, turn it into an array
 	getValues(v interface{}) ([]interface{}, error)
 	sizeOf(v interface{}) int
 	// the tricky append. this is a way of creating new "typed" array. the first interface is nil or an []T (T is the type,
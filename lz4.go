@@ -0,0 +1,307 @@
+package goparquet
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	lz4MinMatch  = 4
+	lz4HashLog   = 14
+	lz4MaxOffset = 65535
+	// the last match must start at least 12 byte before the end of the block, and the last 5 byte are always
+	// literals, see the lz4 block format.
+	lz4MFLimit      = 12
+	lz4LastLiterals = 5
+
+	// lz4HadoopBlockSize is the maximum uncompressed size of one block in the hadoop framing, it is the default buffer
+	// size of the hadoop lz4 codec, so a hadoop reader can decompress every block into its buffer.
+	lz4HadoopBlockSize = 256 * 1024
+)
+
+var errLZ4Corrupt = errors.New("lz4: corrupt block")
+
+// lz4RawCompressor is the LZ4_RAW codec, every page is a single lz4 block without any framing. It is not the same
+// as the LZ4 codec, see lz4HadoopCompressor.
+type lz4RawCompressor struct{}
+
+func (lz4RawCompressor) CompressBlock(block []byte) ([]byte, error) {
+	return lz4EncodeBlock(nil, block), nil
+}
+
+func (c lz4RawCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return c.DecompressBlockSize(block, -1)
+}
+
+func (lz4RawCompressor) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	var dst []byte
+	if size >= 0 {
+		dst = make([]byte, 0, size)
+	}
+
+	return lz4DecodeBlock(dst, block, size)
+}
+
+func (lz4RawCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	return lz4DecodeBlock(dst[:0], block, len(dst))
+}
+
+// lz4HadoopCompressor is the LZ4 codec, as it is written by parquet-mr with the hadoop codec. The data is in blocks,
+// every block starts with the uncompressed size as 4 byte big endian, followed by one or more chunks of the compressed
+// size as 4 byte big endian and the raw lz4 data. Some writers used raw lz4 data without the framing, the decompressor
+// falls back to it if the data is not framed.
+type lz4HadoopCompressor struct{}
+
+func (lz4HadoopCompressor) CompressBlock(block []byte) ([]byte, error) {
+	var (
+		dst []byte
+		buf []byte
+	)
+	for len(block) > 0 {
+		chunk := block
+		if len(chunk) > lz4HadoopBlockSize {
+			chunk = chunk[:lz4HadoopBlockSize]
+		}
+		block = block[len(chunk):]
+
+		buf = lz4EncodeBlock(buf[:0], chunk)
+		var header [8]byte
+		binary.BigEndian.PutUint32(header[:4], uint32(len(chunk)))
+		binary.BigEndian.PutUint32(header[4:], uint32(len(buf)))
+		dst = append(dst, header[:]...)
+		dst = append(dst, buf...)
+	}
+
+	return dst, nil
+}
+
+func (c lz4HadoopCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return c.DecompressBlockSize(block, -1)
+}
+
+func (c lz4HadoopCompressor) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	var dst []byte
+	if size >= 0 {
+		dst = make([]byte, 0, size)
+	}
+
+	return c.decode(dst, block, size)
+}
+
+func (c lz4HadoopCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	return c.decode(dst[:0], block, len(dst))
+}
+
+func (lz4HadoopCompressor) decode(dst, block []byte, size int) ([]byte, error) {
+	res, err := lz4DecodeHadoop(dst, block, size)
+	if err == nil {
+		return res, nil
+	}
+
+	// not framed, try the raw lz4 block
+	res, rawErr := lz4DecodeBlock(dst, block, size)
+	if rawErr != nil {
+		return nil, errors.Errorf("lz4: the data is not hadoop framed (%v) and not a raw lz4 block either (%v)", err, rawErr)
+	}
+
+	return res, nil
+}
+
+// lz4DecodeHadoop decodes the hadoop framed lz4 data in src and appends it to the empty dst. If size is not negative,
+// the result must be exactly size byte.
+func lz4DecodeHadoop(dst, src []byte, size int) ([]byte, error) {
+	for len(src) > 0 {
+		if len(src) < 4 {
+			return nil, errors.New("lz4: the block header is truncated")
+		}
+		rawLen := int(binary.BigEndian.Uint32(src))
+		src = src[4:]
+		if size >= 0 && rawLen > size-len(dst) {
+			return nil, errors.Errorf("lz4: the block has %d byte, but only %d byte are left in the page", rawLen, size-len(dst))
+		}
+
+		blockEnd := len(dst) + rawLen
+		for len(dst) < blockEnd {
+			if len(src) < 4 {
+				return nil, errors.New("lz4: the chunk header is truncated")
+			}
+			compLen := int(binary.BigEndian.Uint32(src))
+			src = src[4:]
+			if compLen <= 0 || compLen > len(src) {
+				return nil, errors.Errorf("lz4: invalid compressed chunk size %d, there are %d byte left", compLen, len(src))
+			}
+
+			var err error
+			if dst, err = lz4DecodeBlock(dst, src[:compLen], blockEnd-len(dst)); err != nil {
+				return nil, err
+			}
+			src = src[compLen:]
+		}
+	}
+
+	if size >= 0 && len(dst) != size {
+		return nil, errors.Errorf("lz4: the blocks have %d byte, but the page has %d byte", len(dst), size)
+	}
+
+	return dst, nil
+}
+
+// lz4DecodeBlock decodes the raw lz4 block src and appends the result to dst. If max is not negative, the block can't
+// decode to more than max byte.
+func lz4DecodeBlock(dst, src []byte, max int) ([]byte, error) {
+	start := len(dst)
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			l, n, err := lz4ReadLength(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			litLen += l
+			i += n
+		}
+		if litLen > len(src)-i {
+			return nil, errLZ4Corrupt
+		}
+		if max >= 0 && len(dst)-start+litLen > max {
+			return nil, errors.Errorf("lz4: the block is larger than %d byte", max)
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		// the last sequence has only literals
+		if i == len(src) {
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, errLZ4Corrupt
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst)-start {
+			return nil, errors.Errorf("lz4: invalid match offset %d", offset)
+		}
+
+		matchLen := int(token & 15)
+		if matchLen == 15 {
+			l, n, err := lz4ReadLength(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			matchLen += l
+			i += n
+		}
+		matchLen += lz4MinMatch
+		if max >= 0 && len(dst)-start+matchLen > max {
+			return nil, errors.Errorf("lz4: the block is larger than %d byte", max)
+		}
+
+		pos := len(dst) - offset
+		if offset >= matchLen {
+			dst = append(dst, dst[pos:pos+matchLen]...)
+			continue
+		}
+		// the match overlaps with itself, it repeats the last offset byte
+		for j := 0; j < matchLen; j++ {
+			dst = append(dst, dst[pos+j])
+		}
+	}
+
+	return dst, nil
+}
+
+// lz4ReadLength reads the extra bytes of a literal or match length, and returns the length and the number of bytes
+// read.
+func lz4ReadLength(src []byte) (int, int, error) {
+	var l int
+	for i := range src {
+		l += int(src[i])
+		if src[i] != 255 {
+			return l, i + 1, nil
+		}
+	}
+
+	return 0, 0, errLZ4Corrupt
+}
+
+// lz4EncodeBlock compresses src as a raw lz4 block and appends it to dst. It is a simple greedy compressor, it takes
+// the first match it finds in the hash table.
+func lz4EncodeBlock(dst, src []byte) []byte {
+	anchor := 0
+	if len(src) > lz4MFLimit {
+		// the positions are stored plus one, zero means empty
+		table := make([]int32, 1<<lz4HashLog)
+		limit := len(src) - lz4MFLimit
+		for i := 0; i < limit; {
+			v := binary.LittleEndian.Uint32(src[i:])
+			h := (v * 2654435761) >> (32 - lz4HashLog)
+			ref := int(table[h]) - 1
+			table[h] = int32(i + 1)
+			if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != v {
+				i++
+				continue
+			}
+
+			end := i + lz4MinMatch
+			for end < len(src)-lz4LastLiterals && src[end] == src[ref+end-i] {
+				end++
+			}
+			dst = lz4AppendSequence(dst, src[anchor:i], i-ref, end-i)
+			i = end
+			anchor = i
+		}
+	}
+
+	return lz4AppendSequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4AppendSequence appends the literals followed by the match to dst, a zero match length is the last sequence of
+// the block without a match.
+func lz4AppendSequence(dst, literals []byte, offset, matchLen int) []byte {
+	var token byte
+	litLen := len(literals)
+	if litLen >= 15 {
+		token = 15 << 4
+	} else {
+		token = byte(litLen) << 4
+	}
+
+	ml := matchLen - lz4MinMatch
+	if matchLen > 0 {
+		if ml >= 15 {
+			token |= 15
+		} else {
+			token |= byte(ml)
+		}
+	}
+
+	dst = append(dst, token)
+	if litLen >= 15 {
+		dst = lz4AppendLength(dst, litLen-15)
+	}
+	dst = append(dst, literals...)
+	if matchLen == 0 {
+		return dst
+	}
+
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if ml >= 15 {
+		dst = lz4AppendLength(dst, ml-15)
+	}
+
+	return dst
+}
+
+func lz4AppendLength(dst []byte, l int) []byte {
+	for l >= 255 {
+		dst = append(dst, 255)
+		l -= 255
+	}
+
+	return append(dst, byte(l))
+}
@@ -0,0 +1,85 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// readOffsetIndex reads the offset index of the column chunk, it returns nil if the chunk has no offset index.
+func readOffsetIndex(r io.ReadSeeker, chunk *parquet.ColumnChunk) (*parquet.OffsetIndex, error) {
+	if chunk.OffsetIndexOffset == nil || chunk.OffsetIndexLength == nil || chunk.MetaData == nil {
+		return nil, nil
+	}
+
+	size := chunk.GetOffsetIndexLength()
+	if size <= 0 {
+		return nil, errors.Errorf("invalid offset index length %d", size)
+	}
+	if _, err := r.Seek(chunk.GetOffsetIndexOffset(), io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.Wrap(err, "reading offset index failed")
+	}
+
+	index := &parquet.OffsetIndex{}
+	if err := readThrift(index, bytes.NewReader(buf)); err != nil {
+		return nil, errors.Wrap(err, "reading offset index failed")
+	}
+	if err := validateOffsetIndex(index, chunk.MetaData); err != nil {
+		return nil, errors.Wrap(err, "invalid offset index")
+	}
+
+	return index, nil
+}
+
+// validateOffsetIndex checks that the pages of the index are in order and within the column chunk.
+func validateOffsetIndex(index *parquet.OffsetIndex, meta *parquet.ColumnMetaData) error {
+	if len(index.PageLocations) == 0 {
+		return errors.New("no page locations")
+	}
+	if first := index.PageLocations[0].FirstRowIndex; first != 0 {
+		return errors.Errorf("the first page starts at row %d", first)
+	}
+
+	start := chunkStart(meta)
+	end := start + meta.TotalCompressedSize
+	for i, loc := range index.PageLocations {
+		if loc.Offset < start || loc.CompressedPageSize <= 0 || loc.Offset+int64(loc.CompressedPageSize) > end {
+			return errors.Errorf("page %d at offset %d with %d byte is outside of the column chunk", i, loc.Offset, loc.CompressedPageSize)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := index.PageLocations[i-1]
+		if loc.Offset < prev.Offset+int64(prev.CompressedPageSize) || loc.FirstRowIndex <= prev.FirstRowIndex {
+			return errors.Errorf("page %d is not after page %d", i, i-1)
+		}
+	}
+
+	return nil
+}
+
+// pagesForRows returns the locations of the pages that contain the rows from to to (exclusive), and the index of
+// the first row of the first of these pages.
+func pagesForRows(index *parquet.OffsetIndex, from, to int64) ([]*parquet.PageLocation, int64) {
+	locations := index.PageLocations
+	first, last := 0, 0
+	for i, loc := range locations {
+		if loc.FirstRowIndex <= from {
+			first = i
+		}
+		if loc.FirstRowIndex < to {
+			last = i
+		}
+	}
+	if last < first {
+		last = first
+	}
+
+	return locations[first : last+1], locations[first].FirstRowIndex
+}
@@ -0,0 +1,183 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkOffsetIndex returns the offset index of the column chunk md, from the pages in the file data.
+func chunkOffsetIndex(t *testing.T, data []byte, md *parquet.ColumnMetaData) *parquet.OffsetIndex {
+	start := chunkStart(md)
+	in := &offsetReader{inner: bytes.NewReader(data)}
+	_, err := in.Seek(start, io.SeekStart)
+	require.NoError(t, err)
+
+	index := &parquet.OffsetIndex{}
+	var row int64
+	for in.offset < start+md.TotalCompressedSize {
+		offset := in.offset
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, in))
+		_, err := in.Seek(int64(ph.CompressedPageSize), io.SeekCurrent)
+		require.NoError(t, err)
+		if ph.Type == parquet.PageType_DICTIONARY_PAGE {
+			continue
+		}
+
+		index.PageLocations = append(index.PageLocations, &parquet.PageLocation{
+			Offset:             offset,
+			CompressedPageSize: int32(in.offset - offset),
+			FirstRowIndex:      row,
+		})
+		if ph.DataPageHeaderV2 != nil {
+			row += int64(ph.DataPageHeaderV2.NumRows)
+		} else {
+			row += int64(ph.DataPageHeader.NumValues)
+		}
+	}
+	return index
+}
+
+// rewriteFooter replaces the footer of the file data with the file meta data changed by fn.
+func rewriteFooter(t *testing.T, data []byte, fn func(meta *parquet.FileMetaData)) []byte {
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	fn(r.meta)
+
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	out := bytes.NewBuffer(append([]byte(nil), data[:len(data)-8-footerSize]...))
+	footerStart := out.Len()
+	require.NoError(t, writeThrift(r.meta, out))
+	require.NoError(t, binary.Write(out, binary.LittleEndian, int32(out.Len()-footerStart)))
+	out.Write(magic)
+	return out.Bytes()
+}
+
+type countingReader struct {
+	io.ReadSeeker
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadSeeker.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestReadColumnRowsWithOffsetIndex(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			repeated binary tags (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageSize(1024), WithDataPageV2ForColumn("tags"))
+	expected := map[string][]interface{}{}
+	for i := 0; i < 2000; i++ {
+		row := map[string]interface{}{"id": int64(i)}
+		expected["id"] = append(expected["id"], int64(i))
+
+		var name interface{}
+		if i%3 != 0 {
+			name = []byte(fmt.Sprintf("name %d", i%17))
+			row["name"] = name
+		}
+		expected["name"] = append(expected["name"], name)
+
+		var tags []interface{}
+		if n := i % 4; n > 0 {
+			values := make([][]byte, n)
+			for j := range values {
+				values[j] = []byte(fmt.Sprintf("tag %d", i+j))
+				tags = append(tags, values[j])
+			}
+			row["tags"] = values
+		}
+		expected["tags"] = append(expected["tags"], tags)
+
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	indexed := buf.Bytes()
+	plain := rewriteFooter(t, indexed, func(meta *parquet.FileMetaData) {
+		for _, cc := range meta.RowGroups[0].Columns {
+			cc.OffsetIndexOffset, cc.OffsetIndexLength = nil, nil
+		}
+	})
+
+	readRows := func(data []byte, col string, from, to int64) ([]interface{}, int64) {
+		cr := &countingReader{ReadSeeker: bytes.NewReader(data)}
+		r, err := NewFileReader(cr)
+		require.NoError(t, err)
+		cr.n = 0
+		rows, err := r.ReadColumnRows(0, col, from, to)
+		require.NoError(t, err)
+		return rows, cr.n
+	}
+
+	for _, col := range []string{"id", "name", "tags"} {
+		for _, rng := range [][2]int64{{0, 1}, {0, 2000}, {500, 510}, {777, 1500}, {1999, 2000}, {1000, 1000}} {
+			from, to := rng[0], rng[1]
+			plainRows, plainRead := readRows(plain, col, from, to)
+			indexedRows, indexedRead := readRows(indexed, col, from, to)
+			if from == to {
+				require.Empty(t, plainRows)
+				require.Empty(t, indexedRows)
+				continue
+			}
+			require.Equal(t, expected[col][from:to], plainRows, "column %s rows %d to %d", col, from, to)
+			require.Equal(t, plainRows, indexedRows, "column %s rows %d to %d", col, from, to)
+			// the dictionary page of name is most of its column chunk
+			if col == "id" && to-from < 100 {
+				require.True(t, indexedRead < plainRead/2, "column %s rows %d to %d: read %d byte with the index, %d byte without", col, from, to, indexedRead, plainRead)
+			}
+		}
+	}
+
+	r, err := NewFileReader(bytes.NewReader(indexed))
+	require.NoError(t, err)
+	_, err = r.ReadColumnRows(0, "id", 10, 2001)
+	require.EqualError(t, err, "invalid rows 10 to 2001, the row group has 2000 rows")
+	_, err = r.ReadColumnRows(0, "id", 10, 9)
+	require.Error(t, err)
+}
+
+func TestPagesForRows(t *testing.T) {
+	index := &parquet.OffsetIndex{PageLocations: []*parquet.PageLocation{
+		{Offset: 4, CompressedPageSize: 10, FirstRowIndex: 0},
+		{Offset: 14, CompressedPageSize: 10, FirstRowIndex: 10},
+		{Offset: 24, CompressedPageSize: 10, FirstRowIndex: 20},
+	}}
+
+	locations, first := pagesForRows(index, 0, 10)
+	require.Equal(t, index.PageLocations[:1], locations)
+	require.Equal(t, int64(0), first)
+
+	locations, first = pagesForRows(index, 15, 21)
+	require.Equal(t, index.PageLocations[1:], locations)
+	require.Equal(t, int64(10), first)
+
+	locations, first = pagesForRows(index, 25, 30)
+	require.Equal(t, index.PageLocations[2:], locations)
+	require.Equal(t, int64(20), first)
+
+	meta := &parquet.ColumnMetaData{DataPageOffset: 4, TotalCompressedSize: 30}
+	require.NoError(t, validateOffsetIndex(index, meta))
+	meta.TotalCompressedSize = 29
+	require.EqualError(t, validateOffsetIndex(index, meta), "page 2 at offset 24 with 10 byte is outside of the column chunk")
+	index.PageLocations[1].FirstRowIndex = 0
+	meta.TotalCompressedSize = 30
+	require.EqualError(t, validateOffsetIndex(index, meta), "page 1 is not after page 0")
+}
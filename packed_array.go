@@ -79,6 +79,14 @@ func (pa *packedArray) at(pos int) (int32, error) {
 	return buf[idx], nil
 }
 
+// byteSize returns the size of the bit packed values of the array.
+func (pa *packedArray) byteSize() int64 {
+	if pa == nil {
+		return 0
+	}
+	return (int64(pa.count)*int64(pa.bw) + 7) / 8
+}
+
 func (pa *packedArray) appendArray(other *packedArray) {
 	if other == nil {
 		return
@@ -51,6 +51,8 @@ func (dp *dictPageReader) read(r io.Reader, ph *parquet.PageHeader, codec parque
 		dp.values = make([]interface{}, 0, dp.numValues)
 	}
 	dp.values = dp.values[:int(dp.numValues)]
+	defer reader.release()
+
 	if err := dp.enc.init(reader); err != nil {
 		return err
 	}
@@ -66,12 +68,19 @@ func (dp *dictPageReader) read(r io.Reader, ph *parquet.PageHeader, codec parque
 type dictPageWriter struct {
 	col *Column
 
-	codec parquet.CompressionCodec
+	codec      parquet.CompressionCodec
+	compressor BlockCompressor
+	enableCRC  bool
+	enc        parquet.Encoding
+	// values are the values of the dictionary, the first values of the dictionary of the column store
+	values []interface{}
 }
 
-func (dp *dictPageWriter) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
+func (dp *dictPageWriter) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec, compressor BlockCompressor, enableCRC bool, maxStatsSize int) error {
 	dp.col = col
+	dp.enableCRC = enableCRC
 	dp.codec = codec
+	dp.compressor = compressor
 	return nil
 }
 
@@ -82,8 +91,8 @@ func (dp *dictPageWriter) getHeader(comp, unComp int) *parquet.PageHeader {
 		CompressedPageSize:   int32(comp),
 		Crc:                  nil,
 		DictionaryPageHeader: &parquet.DictionaryPageHeader{
-			NumValues: dp.col.data.values.numDistinctValues(),
-			Encoding:  parquet.Encoding_PLAIN, // PLAIN_DICTIONARY is deprecated in the Parquet 2.0 specification
+			NumValues: int32(len(dp.values)),
+			Encoding:  dp.enc,
 			IsSorted:  nil,
 		},
 	}
@@ -99,18 +108,21 @@ func (dp *dictPageWriter) write(w io.Writer) (int, int, error) {
 		return 0, 0, err
 	}
 
-	err = encodeValue(dataBuf, encoder, dp.col.data.values.values)
+	err = encodeValue(dataBuf, encoder, dp.values)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	comp, err := compressBlock(dataBuf.Bytes(), dp.codec)
+	comp, err := dp.compressor.CompressBlock(dataBuf.Bytes())
 	if err != nil {
 		return 0, 0, errors.Wrapf(err, "compressing data failed with %s method", dp.codec)
 	}
 	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
 
 	header := dp.getHeader(compSize, unCompSize)
+	if dp.enableCRC {
+		header.Crc = pageChecksum(comp)
+	}
 	if err := writeThrift(header, w); err != nil {
 		return 0, 0, err
 	}
@@ -0,0 +1,149 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// pageIndex is the column index and the offset index of a column chunk. They are collected while the data pages are
+// written, and written after the row groups when the file is closed.
+type pageIndex struct {
+	chunk *parquet.ColumnChunk
+
+	// columnIndex is nil if a page with values that are not null has no min and max value, e.g. because the sort
+	// order of the column is undefined.
+	columnIndex *parquet.ColumnIndex
+	offsetIndex *parquet.OffsetIndex
+
+	nextRow int64
+}
+
+func newPageIndex() *pageIndex {
+	return &pageIndex{
+		columnIndex: &parquet.ColumnIndex{},
+		offsetIndex: &parquet.OffsetIndex{},
+	}
+}
+
+// addPage adds the data page with the range p that was written at offset with size bytes, including its header.
+func (pi *pageIndex) addPage(offset, size int64, p pageRange, stats *parquet.Statistics) {
+	pi.offsetIndex.PageLocations = append(pi.offsetIndex.PageLocations, &parquet.PageLocation{
+		Offset:             offset,
+		CompressedPageSize: int32(size),
+		FirstRowIndex:      pi.nextRow,
+	})
+	pi.nextRow += int64(p.rows)
+
+	ci := pi.columnIndex
+	if ci == nil {
+		return
+	}
+	nullPage := p.valueEnd == p.valueStart
+	if !nullPage && (stats.MinValue == nil || stats.MaxValue == nil) {
+		pi.columnIndex = nil
+		return
+	}
+
+	ci.NullPages = append(ci.NullPages, nullPage)
+	ci.NullCounts = append(ci.NullCounts, stats.GetNullCount())
+	if nullPage {
+		ci.MinValues = append(ci.MinValues, []byte{})
+		ci.MaxValues = append(ci.MaxValues, []byte{})
+	} else {
+		ci.MinValues = append(ci.MinValues, stats.MinValue)
+		ci.MaxValues = append(ci.MaxValues, stats.MaxValue)
+	}
+}
+
+// setBoundaryOrder sets the boundary order of the column index from the min and max values of the pages that are
+// not only null values. The order is ascending if there are less than two of these pages.
+func (pi *pageIndex) setBoundaryOrder(elem *parquet.SchemaElement) {
+	ci := pi.columnIndex
+	if ci == nil {
+		return
+	}
+
+	order := columnSortOrder(elem)
+	ascending, descending := true, true
+	prev := -1
+	for i, null := range ci.NullPages {
+		if null {
+			continue
+		}
+		if prev >= 0 {
+			cmpMin := comparePlainValues(elem.GetType(), order, ci.MinValues[prev], ci.MinValues[i])
+			cmpMax := comparePlainValues(elem.GetType(), order, ci.MaxValues[prev], ci.MaxValues[i])
+			if cmpMin > 0 || cmpMax > 0 {
+				ascending = false
+			}
+			if cmpMin < 0 || cmpMax < 0 {
+				descending = false
+			}
+		}
+		prev = i
+	}
+
+	switch {
+	case ascending:
+		ci.BoundaryOrder = parquet.BoundaryOrder_ASCENDING
+	case descending:
+		ci.BoundaryOrder = parquet.BoundaryOrder_DESCENDING
+	default:
+		ci.BoundaryOrder = parquet.BoundaryOrder_UNORDERED
+	}
+}
+
+// writePageIndexes writes the column indexes and then the offset indexes of the column chunks, and sets their
+// offsets and lengths in the column chunks.
+func writePageIndexes(w writePos, indexes []*pageIndex) error {
+	for _, pi := range indexes {
+		if pi.columnIndex == nil {
+			continue
+		}
+		offset := w.Pos()
+		if err := writeThrift(pi.columnIndex, w); err != nil {
+			return err
+		}
+		length := int32(w.Pos() - offset)
+		pi.chunk.ColumnIndexOffset, pi.chunk.ColumnIndexLength = &offset, &length
+	}
+
+	for _, pi := range indexes {
+		offset := w.Pos()
+		if err := writeThrift(pi.offsetIndex, w); err != nil {
+			return err
+		}
+		length := int32(w.Pos() - offset)
+		pi.chunk.OffsetIndexOffset, pi.chunk.OffsetIndexLength = &offset, &length
+	}
+
+	return nil
+}
+
+// comparePlainValues compares two PLAIN encoded values of the type typ in the sort order order, it returns -1, 0 or 1.
+func comparePlainValues(typ parquet.Type, order sortOrder, a, b []byte) int {
+	switch {
+	case typ == parquet.Type_INT32 && len(a) == 4 && len(b) == 4:
+		x, y := binary.LittleEndian.Uint32(a), binary.LittleEndian.Uint32(b)
+		if order == sortOrderUnsigned {
+			return compareUint64(uint64(x), uint64(y))
+		}
+		return compareInt64(int64(int32(x)), int64(int32(y)))
+	case typ == parquet.Type_INT64 && len(a) == 8 && len(b) == 8:
+		x, y := binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b)
+		if order == sortOrderUnsigned {
+			return compareUint64(x, y)
+		}
+		return compareInt64(int64(x), int64(y))
+	case typ == parquet.Type_FLOAT && len(a) == 4 && len(b) == 4:
+		x, y := math.Float32frombits(binary.LittleEndian.Uint32(a)), math.Float32frombits(binary.LittleEndian.Uint32(b))
+		return compareFloat64(float64(x), float64(y))
+	case typ == parquet.Type_DOUBLE && len(a) == 8 && len(b) == 8:
+		x, y := math.Float64frombits(binary.LittleEndian.Uint64(a)), math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return compareFloat64(x, y)
+	}
+	return bytes.Compare(a, b)
+}
@@ -0,0 +1,141 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePageIndex(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 asc;
+			required int32 desc;
+			optional double random;
+			required int96 ts;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageSize(512))
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 2000; i++ {
+		row := map[string]interface{}{
+			"asc":  int64(i),
+			"desc": int32(-i),
+			"ts":   [12]byte{byte(i)},
+		}
+		// the second row group has only null values
+		if i < 1000 {
+			row["random"] = rnd.Float64()
+		}
+		require.NoError(t, w.AddData(row))
+		if i == 999 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, r.meta.RowGroups, 2)
+
+	lastChunk := r.meta.RowGroups[1].Columns[3].MetaData
+	chunksEnd := chunkStart(lastChunk) + lastChunk.TotalCompressedSize
+
+	for rg := range r.meta.RowGroups {
+		for _, cc := range r.meta.RowGroups[rg].Columns {
+			col := cc.MetaData.PathInSchema[0]
+
+			// the indexes are written after the row groups
+			require.True(t, cc.GetOffsetIndexOffset() >= chunksEnd, "offset index of column %s", col)
+			offsetIndex, err := readOffsetIndex(bytes.NewReader(data), cc)
+			require.NoError(t, err)
+			require.Equal(t, chunkOffsetIndex(t, data, cc.MetaData), offsetIndex, "offset index of column %s", col)
+			if col != "random" || rg == 0 {
+				require.True(t, len(offsetIndex.PageLocations) > 1, "column %s has %d pages", col, len(offsetIndex.PageLocations))
+			}
+
+			if col == "ts" {
+				require.Nil(t, cc.ColumnIndexOffset)
+				require.Nil(t, cc.ColumnIndexLength)
+				continue
+			}
+
+			columnIndex, err := readColumnIndex(bytes.NewReader(data), cc)
+			require.NoError(t, err)
+			require.True(t, cc.GetColumnIndexOffset() >= chunksEnd, "column index of column %s", col)
+
+			// the column index has the statistics of the page headers
+			it, err := r.Pages(rg, col)
+			require.NoError(t, err)
+			var page int
+			for {
+				ph, _, err := it.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				stats := ph.DataPageHeader.Statistics
+				nullPage := ph.DataPageHeader.NumValues == int32(stats.GetNullCount())
+				require.Equal(t, nullPage, columnIndex.NullPages[page], "column %s page %d", col, page)
+				require.Equal(t, stats.GetNullCount(), columnIndex.NullCounts[page], "column %s page %d", col, page)
+				if nullPage {
+					require.Empty(t, columnIndex.MinValues[page])
+					require.Empty(t, columnIndex.MaxValues[page])
+				} else {
+					require.Equal(t, stats.MinValue, columnIndex.MinValues[page], "column %s page %d", col, page)
+					require.Equal(t, stats.MaxValue, columnIndex.MaxValues[page], "column %s page %d", col, page)
+				}
+				page++
+			}
+			require.Equal(t, len(offsetIndex.PageLocations), page)
+
+			switch col {
+			case "asc":
+				require.Equal(t, parquet.BoundaryOrder_ASCENDING, columnIndex.BoundaryOrder)
+			case "desc":
+				require.Equal(t, parquet.BoundaryOrder_DESCENDING, columnIndex.BoundaryOrder)
+			case "random":
+				if rg == 0 {
+					require.Equal(t, parquet.BoundaryOrder_UNORDERED, columnIndex.BoundaryOrder)
+				} else {
+					require.Equal(t, []bool{true}, columnIndex.NullPages)
+					require.Equal(t, []int64{1000}, columnIndex.NullCounts)
+				}
+			}
+		}
+	}
+}
+
+func TestPageIndexBoundaryOrder(t *testing.T) {
+	elem := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32)}
+	testFunc := func(nullPages []bool, minMax ...int32) parquet.BoundaryOrder {
+		pi := newPageIndex()
+		for i, null := range nullPages {
+			pi.columnIndex.NullPages = append(pi.columnIndex.NullPages, null)
+			pi.columnIndex.MinValues = append(pi.columnIndex.MinValues, plainUint32(uint32(minMax[2*i])))
+			pi.columnIndex.MaxValues = append(pi.columnIndex.MaxValues, plainUint32(uint32(minMax[2*i+1])))
+		}
+		pi.setBoundaryOrder(elem)
+		return pi.columnIndex.BoundaryOrder
+	}
+
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, testFunc(nil))
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, testFunc([]bool{false}, 3, 5))
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, testFunc([]bool{false, false, false}, -3, 5, -3, 6, 2, 6))
+	require.Equal(t, parquet.BoundaryOrder_DESCENDING, testFunc([]bool{false, false}, 3, 5, -10, -2))
+	require.Equal(t, parquet.BoundaryOrder_UNORDERED, testFunc([]bool{false, false}, 3, 5, 2, 6))
+	// null pages are ignored
+	require.Equal(t, parquet.BoundaryOrder_DESCENDING, testFunc([]bool{false, true, false}, 3, 5, 100, 100, 1, 2))
+
+	elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_32)
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, testFunc([]bool{false, false}, 3, 5, 6, -1))
+}
@@ -0,0 +1,75 @@
+package goparquet
+
+import (
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// PageIterator iterates the raw pages of a column chunk. It is the escape hatch underneath the value readers of the
+// FileReader: the pages are neither decompressed nor decoded, and their checksums are not validated, so it can be
+// used to inspect the pages of a file or to copy them into another file as they are.
+type PageIterator struct {
+	r      io.ReadSeeker
+	offset int64
+	end    int64
+}
+
+// Pages returns an iterator over the pages of the column chunk of the column colName in the row group with the
+// index rowGroup, starting with the dictionary page if the chunk has one. It does not affect the row based reading
+// with NextRow. If the FileReader reads from an io.ReaderAt, the iterator has its own position in the file and can be
+// used concurrently with other reads, otherwise it must not be.
+func (f *FileReader) Pages(rowGroup int, colName string) (*PageIterator, error) {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := rg.Columns[col.Index()]
+	if chunk.FilePath != nil {
+		return nil, errors.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
+	}
+	if chunk.MetaData == nil {
+		return nil, errors.Errorf("missing meta data for column %q", colName)
+	}
+
+	start := chunkStart(chunk.MetaData)
+	return &PageIterator{
+		r:      f.sectionReader(),
+		offset: start,
+		end:    start + chunk.MetaData.TotalCompressedSize,
+	}, nil
+}
+
+// Next returns the header and the data of the next page. The data is exactly as it is stored in the file, i.e.
+// compressed with the codec of the column chunk, and for DATA_PAGE_V2 pages it starts with the uncompressed
+// repetition and definition levels. The data is not reused by later calls. Next returns io.EOF after the last page.
+func (it *PageIterator) Next() (*parquet.PageHeader, []byte, error) {
+	if it.offset >= it.end {
+		return nil, nil, io.EOF
+	}
+
+	if _, err := it.r.Seek(it.offset, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	r := &offsetReader{inner: it.r, offset: it.offset}
+	ph := &parquet.PageHeader{}
+	if err := readThrift(ph, r); err != nil {
+		return nil, nil, errors.Wrap(err, "reading page header failed")
+	}
+
+	size := int64(ph.GetCompressedPageSize())
+	if size < 0 || r.offset+size > it.end {
+		return nil, nil, errors.Errorf("invalid compressed page size %d", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, nil, errors.Wrap(err, "reading page data failed")
+	}
+
+	it.offset = r.offset
+	return ph, data, nil
+}
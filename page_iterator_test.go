@@ -0,0 +1,80 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageIterator(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithDataPageV2ForColumn("b"))
+	sa, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(sa, parquet.FieldRepetitionType_REQUIRED)))
+	sb, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("b", NewDataColumn(sb, parquet.FieldRepetitionType_OPTIONAL)))
+	for i := 0; i < 1000; i++ {
+		data := map[string]interface{}{"a": []byte(fmt.Sprintf("value %d", i%10))}
+		if i%2 == 0 {
+			data["b"] = int64(i)
+		}
+		require.NoError(t, w.AddData(data))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	readPages := func(col string) []*parquet.PageHeader {
+		it, err := r.Pages(0, col)
+		require.NoError(t, err)
+
+		var headers []*parquet.PageHeader
+		for {
+			ph, data, err := it.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			require.Len(t, data, int(ph.CompressedPageSize))
+			require.Equal(t, pageChecksum(data), ph.Crc)
+
+			// the data is still compressed
+			if ph.Type != parquet.PageType_DATA_PAGE_V2 {
+				block, err := decompressBlock(data, parquet.CompressionCodec_SNAPPY, int(ph.UncompressedPageSize))
+				require.NoError(t, err)
+				require.Len(t, block, int(ph.UncompressedPageSize))
+			}
+			headers = append(headers, ph)
+		}
+		return headers
+	}
+
+	headers := readPages("a")
+	require.Len(t, headers, 2)
+	require.Equal(t, parquet.PageType_DICTIONARY_PAGE, headers[0].Type)
+	require.Equal(t, int32(10), headers[0].DictionaryPageHeader.NumValues)
+	require.Equal(t, parquet.PageType_DATA_PAGE, headers[1].Type)
+	require.Equal(t, int32(1000), headers[1].DataPageHeader.NumValues)
+
+	headers = readPages("b")
+	require.Len(t, headers, 1)
+	require.Equal(t, parquet.PageType_DATA_PAGE_V2, headers[0].Type)
+	require.Equal(t, int32(500), headers[0].DataPageHeaderV2.NumNulls)
+
+	// the iterator doesn't affect the row based reading
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value 0"), row["a"])
+
+	_, err = r.Pages(1, "a")
+	require.EqualError(t, err, "row group 1 is out of range, the file has 1 row groups")
+	_, err = r.Pages(0, "c")
+	require.EqualError(t, err, `column "c" not found`)
+}
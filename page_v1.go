@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -14,10 +15,17 @@ type dataPageReaderV1 struct {
 	valuesCount        int32
 	encoding           parquet.Encoding
 	dDecoder, rDecoder levelDecoder
+	dFn                getLevelDecoder
 	valuesDecoder      valuesDecoder
 	fn                 getValueDecoderFn
 
 	position int
+
+	data *blockReader
+}
+
+func (dp *dataPageReaderV1) release() {
+	dp.data.release()
 }
 
 func (dp *dataPageReaderV1) numValues() int32 {
@@ -47,6 +55,10 @@ func (dp *dataPageReaderV1) readValues(val []interface{}) (n int, dLevel *packed
 
 	if notNull != 0 {
 		if n, err := dp.valuesDecoder.decodeValues(val[:notNull]); err != nil {
+			if err == io.EOF {
+				// the levels say there are more values, so the end of values is not acceptable here
+				err = io.ErrUnexpectedEOF
+			}
 			return 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d value read %d", notNull, n)
 		}
 	}
@@ -54,6 +66,21 @@ func (dp *dataPageReaderV1) readValues(val []interface{}) (n int, dLevel *packed
 	return size, dLevel, rLevel, nil
 }
 
+func (dp *dataPageReaderV1) notNullValues() (valuesDecoder, int, error) {
+	size := int(dp.valuesCount) - dp.position
+	if size <= 0 {
+		return dp.valuesDecoder, 0, nil
+	}
+
+	_, notNull, err := decodePackedArray(dp.dDecoder, size)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "read definition levels failed")
+	}
+
+	dp.position += size
+	return dp.valuesDecoder, notNull, nil
+}
+
 func (dp *dataPageReaderV1) init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error {
 	if dp.ph.DataPageHeader == nil {
 		return errors.New("page header is missing data page header")
@@ -70,6 +97,7 @@ func (dp *dataPageReaderV1) init(dDecoder, rDecoder getLevelDecoder, values getV
 		return err
 	}
 
+	dp.dFn = dDecoder
 	dp.fn = values
 	dp.position = 0
 
@@ -89,102 +117,160 @@ func (dp *dataPageReaderV1) read(r io.Reader, ph *parquet.PageHeader, codec parq
 		return err
 	}
 
+	dp.data = reader
 	dp.encoding = ph.DataPageHeader.Encoding
 	dp.ph = ph
 
 	if dp.valuesDecoder, err = dp.fn(dp.encoding); err != nil {
 		return err
 	}
+	if l, ok := dp.valuesDecoder.(valuesCountLimiter); ok {
+		l.setMaxValues(dp.valuesCount)
+	}
 
 	if err := dp.rDecoder.initSize(reader); err != nil {
 		return err
 	}
 
+	if c, ok := dp.valuesDecoder.(valuesCountDecoder); ok {
+		notNull, err := dp.initDefinitionLevels(reader)
+		if err != nil {
+			return err
+		}
+		dp.valuesDecoder, err = initValuesCountDecoder(c, reader, notNull)
+		return err
+	}
+
 	if err := dp.dDecoder.initSize(reader); err != nil {
 		return err
 	}
 
-	return dp.valuesDecoder.init(reader)
+	dp.valuesDecoder, err = initValuesDecoder(dp.valuesDecoder, reader)
+	return err
+}
+
+// initDefinitionLevels initializes the definition level decoder and returns the number of not null values in the
+// page, for the values decoders that need it to init. The definition levels are decoded twice in that case.
+func (dp *dataPageReaderV1) initDefinitionLevels(r *blockReader) (int32, error) {
+	if dp.dDecoder.maxLevel() == 0 {
+		return dp.valuesCount, dp.dDecoder.initSize(r)
+	}
+
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return 0, err
+	}
+	if int64(size) > int64(r.Len()) {
+		return 0, errors.Errorf("the definition levels of %d bytes don't fit into the page", size)
+	}
+	levels := make([]byte, size)
+	if _, err := io.ReadFull(r, levels); err != nil {
+		return 0, err
+	}
+
+	counter, err := dp.dFn(dp.ph.DataPageHeader.DefinitionLevelEncoding)
+	if err != nil {
+		return 0, err
+	}
+	if err := counter.init(bytes.NewReader(levels)); err != nil {
+		return 0, err
+	}
+	_, notNull, err := decodePackedArray(counter, int(dp.valuesCount))
+	if err != nil {
+		return 0, errors.Wrap(err, "read definition levels failed")
+	}
+
+	return int32(notNull), dp.dDecoder.init(bytes.NewReader(levels))
 }
 
 type dataPageWriterV1 struct {
 	col *Column
 
-	codec      parquet.CompressionCodec
-	dictionary bool
+	codec        parquet.CompressionCodec
+	compressor   BlockCompressor
+	enableCRC    bool
+	maxStatsSize int
+	dictionary   bool
 }
 
-func (dp *dataPageWriterV1) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
+func (dp *dataPageWriterV1) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec, compressor BlockCompressor, enableCRC bool, maxStatsSize int) error {
 	dp.col = col
+	dp.enableCRC = enableCRC
+	dp.maxStatsSize = maxStatsSize
 	dp.codec = codec
+	dp.compressor = compressor
 	return nil
 }
 
-func (dp *dataPageWriterV1) getHeader(comp, unComp int) *parquet.PageHeader {
+// dictEncodings for data page v1 is PLAIN_DICTIONARY for both pages. It is deprecated in the
+// 2.0 format, but this is what parquet-mr writes in v1 files and what older readers expect.
+func (dp *dataPageWriterV1) dictEncodings() (parquet.Encoding, parquet.Encoding) {
+	return parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_PLAIN_DICTIONARY
+}
+
+func (dp *dataPageWriterV1) dataPageType() parquet.PageType {
+	return parquet.PageType_DATA_PAGE
+}
+
+func (dp *dataPageWriterV1) getHeader(p pageRange, stats *parquet.Statistics) *parquet.PageHeader {
 	enc := dp.col.data.encoding()
 	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
+		_, enc = dp.dictEncodings()
 	}
 	ph := &parquet.PageHeader{
-		Type:                 parquet.PageType_DATA_PAGE,
-		UncompressedPageSize: int32(unComp),
-		CompressedPageSize:   int32(comp),
-		Crc:                  nil,
+		Type: parquet.PageType_DATA_PAGE,
+		Crc:  nil,
 		DataPageHeader: &parquet.DataPageHeader{
-			NumValues: dp.col.data.values.numValues() + dp.col.data.values.nullValueCount(),
+			NumValues: p.numValues(),
 			Encoding:  enc,
 			// Only RLE supported for now, not sure if we need support for more encoding
 			DefinitionLevelEncoding: parquet.Encoding_RLE,
 			RepetitionLevelEncoding: parquet.Encoding_RLE,
-			Statistics:              nil,
+			Statistics:              stats,
 		},
 	}
 	return ph
 }
 
-func (dp *dataPageWriterV1) write(w io.Writer) (int, int, error) {
-	dataBuf := &bytes.Buffer{}
-	// Only write repetition value higher than zero
-	if dp.col.MaxRepetitionLevel() > 0 {
-		if err := encodeLevelsV1(dataBuf, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels); err != nil {
-			return 0, 0, err
-		}
-	}
-
-	// Only write definition value higher than zero
-	if dp.col.MaxDefinitionLevel() > 0 {
-		if err := encodeLevelsV1(dataBuf, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels); err != nil {
-			return 0, 0, err
-		}
-	}
-
+func (dp *dataPageWriterV1) encode(p pageRange, maxPageSize int64, maxPageRows int) (*encodedPage, error) {
 	enc := dp.col.data.encoding()
 	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
+		_, enc = dp.dictEncodings()
 	}
 
-	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data.values)
+	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 
-	err = encodeValue(dataBuf, encoder, dp.col.data.values.assemble())
-	if err != nil {
-		return 0, 0, err
+	// the values are encoded first, they decide which levels are part of the page
+	valuesBuf := &bytes.Buffer{}
+	if p, err = encodePageValues(valuesBuf, encoder, dp.col, p, maxPageSize, maxPageRows); err != nil {
+		return nil, err
 	}
 
-	comp, err := compressBlock(dataBuf.Bytes(), dp.codec)
-	if err != nil {
-		return 0, 0, errors.Wrapf(err, "compressing data failed with %s method", dp.codec)
+	dataBuf := &bytes.Buffer{}
+	// Only write repetition value higher than zero
+	if dp.col.MaxRepetitionLevel() > 0 {
+		if err := encodeLevelsV1(dataBuf, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels, p.levelStart, p.levelEnd); err != nil {
+			return nil, err
+		}
 	}
-	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
 
-	header := dp.getHeader(compSize, unCompSize)
-	if err := writeThrift(header, w); err != nil {
-		return 0, 0, err
+	// Only write definition value higher than zero
+	if dp.col.MaxDefinitionLevel() > 0 {
+		if err := encodeLevelsV1(dataBuf, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels, p.levelStart, p.levelEnd); err != nil {
+			return nil, err
+		}
 	}
+	dataBuf.Write(valuesBuf.Bytes())
+
+	stats := pageStatistics(dp.col, p, dp.maxStatsSize)
+	return newEncodedPage(p, dp.getHeader(p, stats), stats, nil, dataBuf.Bytes()), nil
+}
 
-	return compSize, unCompSize, writeFull(w, comp)
+func (dp *dataPageWriterV1) compress(ep *encodedPage) error {
+	return ep.compress(dp.compressor, dp.codec, dp.enableCRC)
 }
 
 func newDataPageV1Writer(useDict bool) pageWriter {
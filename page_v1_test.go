@@ -1,6 +1,14 @@
 package goparquet
 
-import "testing"
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
 
 func TestDataPageReaderV1InitCrash(t *testing.T) {
 	data := []byte("PAR1\x15\x00\x15\x06\x15\x1c6\x01(\x03:\x00\x00\x00\x96a" +
@@ -511,3 +519,128 @@ func TestDataPageReaderV1InitCrash(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func TestDataPageReaderV1BooleanRLE(t *testing.T) {
+	// a length prefixed RLE run of 8 true values
+	data := []byte{0x02, 0x00, 0x00, 0x00, 0x10, 0x01}
+
+	readPage := func(numValues int32) *dataPageReaderV1 {
+		ph := &parquet.PageHeader{
+			Type:                 parquet.PageType_DATA_PAGE,
+			UncompressedPageSize: int32(len(data)),
+			CompressedPageSize:   int32(len(data)),
+			DataPageHeader: &parquet.DataPageHeader{
+				NumValues:               numValues,
+				Encoding:                parquet.Encoding_RLE,
+				DefinitionLevelEncoding: parquet.Encoding_RLE,
+				RepetitionLevelEncoding: parquet.Encoding_RLE,
+			},
+		}
+		levels := func(parquet.Encoding) (levelDecoder, error) {
+			return &levelDecoderWrapper{decoder: constDecoder(0), max: 0}, nil
+		}
+		values := func(enc parquet.Encoding) (valuesDecoder, error) {
+			return getValuesDecoder(enc, &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BOOLEAN)}, nil)
+		}
+
+		p := &dataPageReaderV1{ph: ph}
+		require.NoError(t, p.init(levels, levels, values))
+		require.NoError(t, p.read(bytes.NewReader(data), ph, parquet.CompressionCodec_UNCOMPRESSED))
+		return p
+	}
+
+	// the stream contains more values than the page, the rest is ignored
+	p := readPage(5)
+	val := make([]interface{}, 10)
+	n, _, _, err := p.readValues(val)
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, []interface{}{true, true, true, true, true}, val[:n])
+	n, _, _, err = p.readValues(val)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	// the stream contains less values than the page
+	p = readPage(10)
+	_, _, _, err = p.readValues(val)
+	require.Error(t, err)
+	require.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+}
+
+func readTestPageV1(t *testing.T, data []byte, numValues int32, enc parquet.Encoding, typ parquet.Type, maxDef uint16) *dataPageReaderV1 {
+	ph := &parquet.PageHeader{
+		Type:                 parquet.PageType_DATA_PAGE,
+		UncompressedPageSize: int32(len(data)),
+		CompressedPageSize:   int32(len(data)),
+		DataPageHeader: &parquet.DataPageHeader{
+			NumValues:               numValues,
+			Encoding:                enc,
+			DefinitionLevelEncoding: parquet.Encoding_RLE,
+			RepetitionLevelEncoding: parquet.Encoding_RLE,
+		},
+	}
+	rLevels := func(parquet.Encoding) (levelDecoder, error) {
+		return &levelDecoderWrapper{decoder: constDecoder(0), max: 0}, nil
+	}
+	dLevels := rLevels
+	if maxDef > 0 {
+		dLevels = func(parquet.Encoding) (levelDecoder, error) {
+			dec := newHybridDecoder(1)
+			dec.buffered = true
+			return &levelDecoderWrapper{decoder: dec, max: maxDef}, nil
+		}
+	}
+	values := func(enc parquet.Encoding) (valuesDecoder, error) {
+		return getValuesDecoder(enc, &parquet.SchemaElement{Type: parquet.TypePtr(typ)}, []interface{}{int64(42)})
+	}
+
+	p := &dataPageReaderV1{ph: ph}
+	require.NoError(t, p.init(dLevels, rLevels, values))
+	require.NoError(t, p.read(bytes.NewReader(data), ph, parquet.CompressionCodec_UNCOMPRESSED))
+	return p
+}
+
+func TestDataPageReaderV1RequiredColumn(t *testing.T) {
+	// a required top-level column has neither repetition nor definition levels in the page
+	data := []byte{0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00}
+	p := readTestPageV1(t, data, 3, parquet.Encoding_PLAIN, parquet.Type_INT32, 0)
+
+	val := make([]interface{}, 3)
+	n, dLevel, rLevel, err := p.readValues(val)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []interface{}{int32(1), int32(2), int32(3)}, val)
+	require.Equal(t, []int32{0, 0, 0}, dLevel.toArray())
+	require.Equal(t, []int32{0, 0, 0}, rLevel.toArray())
+}
+
+func TestDataPageReaderV1AllNulls(t *testing.T) {
+	// the length prefixed definition levels, a RLE run of 4 null values, and no value bytes at all
+	data := []byte{0x02, 0x00, 0x00, 0x00, 0x08, 0x00}
+
+	for _, enc := range []parquet.Encoding{
+		parquet.Encoding_PLAIN,
+		parquet.Encoding_PLAIN_DICTIONARY,
+		parquet.Encoding_RLE_DICTIONARY,
+		parquet.Encoding_DELTA_BINARY_PACKED,
+	} {
+		p := readTestPageV1(t, data, 4, enc, parquet.Type_INT64, 1)
+		val := make([]interface{}, 4)
+		n, dLevel, _, err := p.readValues(val)
+		require.NoError(t, err, enc.String())
+		require.Equal(t, 4, n)
+		require.Equal(t, []int32{0, 0, 0, 0}, dLevel.toArray())
+
+		p = readTestPageV1(t, data, 4, enc, parquet.Type_INT64, 1)
+		_, notNull, err := p.notNullValues()
+		require.NoError(t, err)
+		require.Equal(t, 0, notNull)
+	}
+
+	// the levels have a value, but the page has no value bytes
+	data = []byte{0x02, 0x00, 0x00, 0x00, 0x08, 0x01}
+	p := readTestPageV1(t, data, 4, parquet.Encoding_RLE_DICTIONARY, parquet.Type_INT64, 1)
+	_, _, _, err := p.readValues(make([]interface{}, 4))
+	require.Error(t, err)
+	require.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+}
@@ -17,6 +17,12 @@ type dataPageReaderV2 struct {
 	dDecoder, rDecoder levelDecoder
 	fn                 getValueDecoderFn
 	position           int
+
+	data *blockReader
+}
+
+func (dp *dataPageReaderV2) release() {
+	dp.data.release()
 }
 
 func (dp *dataPageReaderV2) numValues() int32 {
@@ -46,6 +52,10 @@ func (dp *dataPageReaderV2) readValues(val []interface{}) (n int, dLevel *packed
 
 	if notNull != 0 {
 		if n, err := dp.valuesDecoder.decodeValues(val[:notNull]); err != nil {
+			if err == io.EOF {
+				// the levels say there are more values, so the end of values is not acceptable here
+				err = io.ErrUnexpectedEOF
+			}
 			return 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d values but read %d", notNull, n)
 		}
 	}
@@ -53,6 +63,21 @@ func (dp *dataPageReaderV2) readValues(val []interface{}) (n int, dLevel *packed
 	return size, dLevel, rLevel, nil
 }
 
+func (dp *dataPageReaderV2) notNullValues() (valuesDecoder, int, error) {
+	size := int(dp.valuesCount) - dp.position
+	if size <= 0 {
+		return dp.valuesDecoder, 0, nil
+	}
+
+	_, notNull, err := decodePackedArray(dp.dDecoder, size)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "read definition levels failed")
+	}
+
+	dp.position += size
+	return dp.valuesDecoder, notNull, nil
+}
+
 func (dp *dataPageReaderV2) init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error {
 	var err error
 	// Page v2 dose not have any encoding for the levels
@@ -87,6 +112,12 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 	if ph.DataPageHeaderV2.DefinitionLevelsByteLength < 0 {
 		return errors.Errorf("invalid DefinitionLevelsByteLength")
 	}
+	if nulls := ph.DataPageHeaderV2.NumNulls; nulls < 0 || nulls > dp.valuesCount {
+		return errors.Errorf("invalid NumNulls %d in DATA_PAGE_V2 with %d values", nulls, dp.valuesCount)
+	}
+	if rows := ph.DataPageHeaderV2.NumRows; rows < 0 || rows > dp.valuesCount {
+		return errors.Errorf("invalid NumRows %d in DATA_PAGE_V2 with %d values", rows, dp.valuesCount)
+	}
 	dp.encoding = ph.DataPageHeaderV2.Encoding
 	dp.ph = ph
 
@@ -96,131 +127,143 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 			return err
 		}
 	}
+	if l, ok := dp.valuesDecoder.(valuesCountLimiter); ok {
+		l.setMaxValues(dp.valuesCount)
+	}
 
-	// Its safe to call this {r,d}Decoder later, since the stream they operate on are in memory
+	// The levels are stored uncompressed before the values, without the length prefix of the v1 pages. Its safe to
+	// call this {r,d}Decoder later, since the stream they operate on are in memory. The decoders are initialized even
+	// without level bytes, so a page that lacks the levels fails on read instead of using an uninitialized decoder.
 	levelsSize := ph.DataPageHeaderV2.RepetitionLevelsByteLength + ph.DataPageHeaderV2.DefinitionLevelsByteLength
-	// read both level size
-	if levelsSize > 0 {
-		data := make([]byte, levelsSize)
-		n, err := io.ReadFull(r, data)
-		if err != nil {
-			return errors.Wrapf(err, "need to read %d byte but there was only %d byte", levelsSize, n)
-		}
-
-		if ph.DataPageHeaderV2.RepetitionLevelsByteLength > 0 {
-			if err := dp.rDecoder.init(bytes.NewReader(data[:int(ph.DataPageHeaderV2.RepetitionLevelsByteLength)])); err != nil {
-				return errors.Wrapf(err, "read repetition level failed")
-			}
-		}
+	if levelsSize < 0 || levelsSize > ph.GetCompressedPageSize() {
+		return errors.Errorf("the levels of %d byte don't fit into the page of %d byte", levelsSize, ph.GetCompressedPageSize())
+	}
+	data := make([]byte, levelsSize)
+	if n, err := io.ReadFull(r, data); err != nil {
+		return errors.Wrapf(err, "need to read %d byte but there was only %d byte", levelsSize, n)
+	}
 
-		if ph.DataPageHeaderV2.DefinitionLevelsByteLength > 0 {
-			if err := dp.dDecoder.init(bytes.NewReader(data[int(ph.DataPageHeaderV2.RepetitionLevelsByteLength):])); err != nil {
-				return errors.Wrapf(err, "read definition level failed")
-			}
-		}
+	if err := dp.rDecoder.init(bytes.NewReader(data[:int(ph.DataPageHeaderV2.RepetitionLevelsByteLength)])); err != nil {
+		return errors.Wrapf(err, "read repetition level failed")
+	}
+	if err := dp.dDecoder.init(bytes.NewReader(data[int(ph.DataPageHeaderV2.RepetitionLevelsByteLength):])); err != nil {
+		return errors.Wrapf(err, "read definition level failed")
 	}
 
+	// only the values are compressed, and not even those if is_compressed is false.
+	if !ph.DataPageHeaderV2.GetIsCompressed() {
+		codec = parquet.CompressionCodec_UNCOMPRESSED
+	}
 	reader, err := createDataReader(r, codec, ph.GetCompressedPageSize()-levelsSize, ph.GetUncompressedPageSize()-levelsSize)
 	if err != nil {
 		return err
 	}
+	dp.data = reader
 
-	return dp.valuesDecoder.init(reader)
+	if c, ok := dp.valuesDecoder.(valuesCountDecoder); ok {
+		dp.valuesDecoder, err = initValuesCountDecoder(c, reader, dp.valuesCount-ph.DataPageHeaderV2.NumNulls)
+		return err
+	}
+	dp.valuesDecoder, err = initValuesDecoder(dp.valuesDecoder, reader)
+	return err
 }
 
 type dataPageWriterV2 struct {
 	col    *Column
 	schema SchemaWriter
 
-	codec      parquet.CompressionCodec
-	dictionary bool
+	codec        parquet.CompressionCodec
+	compressor   BlockCompressor
+	enableCRC    bool
+	maxStatsSize int
+	dictionary   bool
 }
 
-func (dp *dataPageWriterV2) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
+func (dp *dataPageWriterV2) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec, compressor BlockCompressor, enableCRC bool, maxStatsSize int) error {
 	dp.col = col
+	dp.enableCRC = enableCRC
+	dp.maxStatsSize = maxStatsSize
 	dp.codec = codec
+	dp.compressor = compressor
 	dp.schema = schema
 	return nil
 }
 
-func (dp *dataPageWriterV2) getHeader(comp, unComp, defSize, repSize int, isCompressed bool) *parquet.PageHeader {
+// dictEncodings for data page v2 is PLAIN for the dictionary page and RLE_DICTIONARY for
+// the data pages, as the 2.0 format requires.
+func (dp *dataPageWriterV2) dictEncodings() (parquet.Encoding, parquet.Encoding) {
+	return parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY
+}
+
+func (dp *dataPageWriterV2) dataPageType() parquet.PageType {
+	return parquet.PageType_DATA_PAGE_V2
+}
+
+func (dp *dataPageWriterV2) getHeader(defSize, repSize int, isCompressed bool, p pageRange, stats *parquet.Statistics) *parquet.PageHeader {
 	enc := dp.col.data.encoding()
 	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
+		_, enc = dp.dictEncodings()
 	}
 	ph := &parquet.PageHeader{
-		Type:                 parquet.PageType_DATA_PAGE_V2,
-		UncompressedPageSize: int32(unComp + defSize + repSize),
-		CompressedPageSize:   int32(comp + defSize + repSize),
-		Crc:                  nil,
+		Type: parquet.PageType_DATA_PAGE_V2,
+		Crc:  nil,
 		DataPageHeaderV2: &parquet.DataPageHeaderV2{
-			NumValues:                  dp.col.data.values.numValues() + dp.col.data.values.nullValueCount(),
-			NumNulls:                   dp.col.data.values.nullValueCount(),
-			NumRows:                    int32(dp.schema.rowGroupNumRecords()),
+			NumValues:                  p.numValues(),
+			NumNulls:                   p.numNulls(),
+			NumRows:                    int32(p.rows),
 			Encoding:                   enc,
 			DefinitionLevelsByteLength: int32(defSize),
 			RepetitionLevelsByteLength: int32(repSize),
 			IsCompressed:               isCompressed,
-			Statistics:                 nil,
+			Statistics:                 stats,
 		},
 	}
 	return ph
 }
 
-func (dp *dataPageWriterV2) write(w io.Writer) (int, int, error) {
-	rep := &bytes.Buffer{}
-
-	// Only write repetition value higher than zero
-	if dp.col.MaxRepetitionLevel() > 0 {
-		if err := encodeLevelsV2(rep, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels); err != nil {
-			return 0, 0, err
-		}
-	}
-
-	def := &bytes.Buffer{}
-
-	// Only write definition level higher than zero
-	if dp.col.MaxDefinitionLevel() > 0 {
-		if err := encodeLevelsV2(def, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels); err != nil {
-			return 0, 0, err
-		}
-	}
-
+func (dp *dataPageWriterV2) encode(p pageRange, maxPageSize int64, maxPageRows int) (*encodedPage, error) {
 	dataBuf := &bytes.Buffer{}
 	enc := dp.col.data.encoding()
 	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
+		_, enc = dp.dictEncodings()
 	}
 
-	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data.values)
+	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data)
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 
-	if err = encodeValue(dataBuf, encoder, dp.col.data.values.assemble()); err != nil {
-		return 0, 0, err
+	// the values are encoded first, they decide which levels are part of the page
+	if p, err = encodePageValues(dataBuf, encoder, dp.col, p, maxPageSize, maxPageRows); err != nil {
+		return nil, err
 	}
 
-	comp, err := compressBlock(dataBuf.Bytes(), dp.codec)
-	if err != nil {
-		return 0, 0, errors.Wrapf(err, "compressing data failed with %s method", dp.codec)
-	}
-	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
-	defLen, repLen := def.Len(), rep.Len()
-	header := dp.getHeader(compSize, unCompSize, defLen, repLen, dp.codec != parquet.CompressionCodec_UNCOMPRESSED)
-	if err := writeThrift(header, w); err != nil {
-		return 0, 0, err
-	}
+	// the levels are not compressed, the repetition levels come first
+	levels := &bytes.Buffer{}
 
-	if err := writeFull(w, rep.Bytes()); err != nil {
-		return 0, 0, err
+	// Only write repetition value higher than zero
+	if dp.col.MaxRepetitionLevel() > 0 {
+		if err := encodeLevelsV2(levels, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels, p.levelStart, p.levelEnd); err != nil {
+			return nil, err
+		}
 	}
+	repLen := levels.Len()
 
-	if err := writeFull(w, def.Bytes()); err != nil {
-		return 0, 0, err
+	// Only write definition level higher than zero
+	if dp.col.MaxDefinitionLevel() > 0 {
+		if err := encodeLevelsV2(levels, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels, p.levelStart, p.levelEnd); err != nil {
+			return nil, err
+		}
 	}
+	defLen := levels.Len() - repLen
+
+	stats := pageStatistics(dp.col, p, dp.maxStatsSize)
+	header := dp.getHeader(defLen, repLen, dp.codec != parquet.CompressionCodec_UNCOMPRESSED, p, stats)
+	return newEncodedPage(p, header, stats, levels.Bytes(), dataBuf.Bytes()), nil
+}
 
-	return compSize + defLen + repLen, unCompSize + defLen + repLen, writeFull(w, comp)
+func (dp *dataPageWriterV2) compress(ep *encodedPage) error {
+	return ep.compress(dp.compressor, dp.codec, dp.enableCRC)
 }
 
 func newDataPageV2Writer(useDict bool) pageWriter {
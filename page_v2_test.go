@@ -0,0 +1,153 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// the definition levels 1, 0, 1, 1 (one null) as a bit-packed run of width 1, without the length prefix of v1 pages.
+var testPageV2DefLevels = []byte{0x03, 0x0d}
+
+func readTestPageV2(t *testing.T, levels, values []byte, compressed []byte, header *parquet.DataPageHeaderV2, codec parquet.CompressionCodec) (*dataPageReaderV2, error) {
+	body := compressed
+	if body == nil {
+		body = values
+	}
+	data := append(append([]byte{}, levels...), body...)
+	ph := &parquet.PageHeader{
+		Type:                 parquet.PageType_DATA_PAGE_V2,
+		UncompressedPageSize: int32(len(levels) + len(values)),
+		CompressedPageSize:   int32(len(data)),
+		DataPageHeaderV2:     header,
+	}
+
+	rLevels := func(parquet.Encoding) (levelDecoder, error) {
+		return &levelDecoderWrapper{decoder: constDecoder(0), max: 0}, nil
+	}
+	dLevels := func(parquet.Encoding) (levelDecoder, error) {
+		dec := newHybridDecoder(1)
+		dec.buffered = true
+		return &levelDecoderWrapper{decoder: dec, max: 1}, nil
+	}
+	valuesFn := func(enc parquet.Encoding) (valuesDecoder, error) {
+		return getValuesDecoder(enc, &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32)}, nil)
+	}
+
+	p := &dataPageReaderV2{ph: ph}
+	require.NoError(t, p.init(dLevels, rLevels, valuesFn))
+	return p, p.read(bytes.NewReader(data), ph, codec)
+}
+
+func TestDataPageReaderV2(t *testing.T) {
+	values := []byte{0x01, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00}
+	newHeader := func(isCompressed bool) *parquet.DataPageHeaderV2 {
+		return &parquet.DataPageHeaderV2{
+			NumValues:                  4,
+			NumNulls:                   1,
+			NumRows:                    4,
+			Encoding:                   parquet.Encoding_PLAIN,
+			DefinitionLevelsByteLength: int32(len(testPageV2DefLevels)),
+			RepetitionLevelsByteLength: 0,
+			IsCompressed:               isCompressed,
+		}
+	}
+
+	tests := map[string]struct {
+		compressed   []byte
+		isCompressed bool
+	}{
+		// only the values are compressed, the levels are stored as they are
+		"snappy values": {compressed: snappy.Encode(nil, values), isCompressed: true},
+		// the column chunk is compressed, but this page is not, e.g. because compression didn't pay off
+		"uncompressed page": {isCompressed: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := readTestPageV2(t, testPageV2DefLevels, values, tt.compressed, newHeader(tt.isCompressed), parquet.CompressionCodec_SNAPPY)
+			require.NoError(t, err)
+
+			val := make([]interface{}, 4)
+			n, dLevel, _, err := p.readValues(val)
+			require.NoError(t, err)
+			require.Equal(t, 4, n)
+			require.Equal(t, []int32{1, 0, 1, 1}, dLevel.toArray())
+			require.Equal(t, []interface{}{int32(1), int32(3), int32(4)}, val[:3])
+		})
+	}
+}
+
+func TestDataPageReaderV2InvalidHeader(t *testing.T) {
+	values := []byte{0x01, 0x00, 0x00, 0x00}
+
+	_, err := readTestPageV2(t, testPageV2DefLevels, values, nil, &parquet.DataPageHeaderV2{
+		NumValues:                  4,
+		NumNulls:                   5,
+		NumRows:                    4,
+		DefinitionLevelsByteLength: int32(len(testPageV2DefLevels)),
+	}, parquet.CompressionCodec_UNCOMPRESSED)
+	require.EqualError(t, err, "invalid NumNulls 5 in DATA_PAGE_V2 with 4 values")
+
+	_, err = readTestPageV2(t, testPageV2DefLevels, values, nil, &parquet.DataPageHeaderV2{
+		NumValues:                  4,
+		NumRows:                    4,
+		DefinitionLevelsByteLength: 100,
+	}, parquet.CompressionCodec_UNCOMPRESSED)
+	require.EqualError(t, err, "the levels of 100 byte don't fit into the page of 6 byte")
+
+	// the page has no definition levels, even though the column has them
+	p, err := readTestPageV2(t, nil, values, nil, &parquet.DataPageHeaderV2{
+		NumValues: 1,
+		NumRows:   1,
+	}, parquet.CompressionCodec_UNCOMPRESSED)
+	require.NoError(t, err)
+	_, _, _, err = p.readValues(make([]interface{}, 1))
+	require.Error(t, err)
+	require.Equal(t, io.EOF, errors.Cause(err))
+}
+
+func TestDataPageV2GoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library, in the layout parquet-mr
+	// uses with writer.version v2: GZIP compressed DATA_PAGE_V2 pages, DELTA_BINARY_PACKED for the INT64 column, a
+	// PLAIN dictionary page with RLE_DICTIONARY data pages for the strings, and a repeated column. The second page of
+	// the id column has is_compressed set to false.
+	data, err := ioutil.ReadFile("testdata/data_page_v2.parquet")
+	require.NoError(t, err)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, r.meta.RowGroups[0].Columns, 3)
+	for _, col := range r.meta.RowGroups[0].Columns {
+		require.Equal(t, parquet.CompressionCodec_GZIP, col.MetaData.Codec)
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, bytes.NewReader(data[col.MetaData.DataPageOffset:])))
+		require.Equal(t, parquet.PageType_DATA_PAGE_V2, ph.Type)
+	}
+
+	require.Equal(t, int64(300), r.NumRows())
+	for i := 0; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int64(i*i - 50*i)}
+		if i%5 != 0 {
+			expected["name"] = []byte(fmt.Sprintf("name %d", i%17))
+		}
+		if i%4 != 0 {
+			nums := make([]int32, i%4)
+			for j := range nums {
+				nums[j] = int32(i + j)
+			}
+			expected["nums"] = nums
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
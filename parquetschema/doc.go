@@ -8,6 +8,9 @@
 // Users have the possibility to manually assemble their own SchemaDefinition
 // object manually and programmatically.
 //
+// A SchemaBuilder from NewSchemaBuilder assembles a SchemaDefinition column by
+// column, and validates it when it is built.
+//
 // To construct a schema definition, start with a SchemaDefinition object and
 // set its RootDocument field to a ColumnDefinition. This "root column" describes
 // the whole message. The root column doesn't have a type on its own, so the
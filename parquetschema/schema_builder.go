@@ -0,0 +1,206 @@
+package parquetschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// SchemaBuilder builds a SchemaDefinition column by column, as an alternative to parsing a textual schema
+// definition or assembling the ColumnDefinitions by hand. Always use NewSchemaBuilder to create such an object.
+//
+//	sd, err := parquetschema.NewSchemaBuilder("msg").
+//		Required("id", parquet.Type_INT64).
+//		Optional("name", parquet.Type_BYTE_ARRAY, parquetschema.WithConvertedType(parquet.ConvertedType_UTF8)).
+//		Group("address", parquet.FieldRepetitionType_OPTIONAL, func(b *parquetschema.GroupBuilder) {
+//			b.Required("city", parquet.Type_BYTE_ARRAY, parquetschema.WithConvertedType(parquet.ConvertedType_UTF8))
+//		}).
+//		Build()
+type SchemaBuilder struct {
+	root *GroupBuilder
+	name string
+}
+
+// GroupBuilder adds the columns of a group to a SchemaBuilder.
+type GroupBuilder struct {
+	cols []*ColumnDefinition
+}
+
+// ColumnOption sets an attribute of a column or group of a SchemaBuilder.
+type ColumnOption func(elem *parquet.SchemaElement)
+
+// NewSchemaBuilder returns a SchemaBuilder for a message with the name name.
+func NewSchemaBuilder(name string) *SchemaBuilder {
+	return &SchemaBuilder{root: &GroupBuilder{}, name: name}
+}
+
+// WithConvertedType sets the converted type of a column or group, e.g. UTF8 or LIST.
+func WithConvertedType(ct parquet.ConvertedType) ColumnOption {
+	return func(elem *parquet.SchemaElement) {
+		elem.ConvertedType = parquet.ConvertedTypePtr(ct)
+	}
+}
+
+// WithLogicalType sets the logical type of a column or group, and the equivalent converted type for readers that
+// don't know logical types, if there is one. The precision and scale of a DECIMAL logical type are set in the
+// column as well.
+func WithLogicalType(lt *parquet.LogicalType) ColumnOption {
+	return func(elem *parquet.SchemaElement) {
+		elem.LogicalType = lt
+		elem.ConvertedType = ConvertedTypeOf(lt)
+		if lt != nil && lt.IsSetDECIMAL() {
+			elem.Precision = &lt.DECIMAL.Precision
+			elem.Scale = &lt.DECIMAL.Scale
+		}
+	}
+}
+
+// WithTypeLength sets the length of a FIXED_LEN_BYTE_ARRAY column.
+func WithTypeLength(n int32) ColumnOption {
+	return func(elem *parquet.SchemaElement) {
+		elem.TypeLength = &n
+	}
+}
+
+// WithFieldID sets the field ID of a column or group.
+func WithFieldID(id int32) ColumnOption {
+	return func(elem *parquet.SchemaElement) {
+		elem.FieldID = &id
+	}
+}
+
+// Required adds a required column with the type typ.
+func (b *SchemaBuilder) Required(name string, typ parquet.Type, opts ...ColumnOption) *SchemaBuilder {
+	b.root.Required(name, typ, opts...)
+	return b
+}
+
+// Optional adds an optional column with the type typ.
+func (b *SchemaBuilder) Optional(name string, typ parquet.Type, opts ...ColumnOption) *SchemaBuilder {
+	b.root.Optional(name, typ, opts...)
+	return b
+}
+
+// Repeated adds a repeated column with the type typ.
+func (b *SchemaBuilder) Repeated(name string, typ parquet.Type, opts ...ColumnOption) *SchemaBuilder {
+	b.root.Repeated(name, typ, opts...)
+	return b
+}
+
+// Group adds a group with the repetition type rep, fn adds its columns.
+func (b *SchemaBuilder) Group(name string, rep parquet.FieldRepetitionType, fn func(g *GroupBuilder), opts ...ColumnOption) *SchemaBuilder {
+	b.root.Group(name, rep, fn, opts...)
+	return b
+}
+
+// Columns calls fn to add columns, like the columns of a group are added by Group.
+func (b *SchemaBuilder) Columns(fn func(g *GroupBuilder)) *SchemaBuilder {
+	fn(b.root)
+	return b
+}
+
+// Build validates the columns and returns the schema definition. The error has all problems of the columns, like
+// columns with the same name in a group, FIXED_LEN_BYTE_ARRAY columns without a type length and groups without
+// columns. Only if there are none, the schema definition is validated like by its Validate method.
+func (b *SchemaBuilder) Build() (*SchemaDefinition, error) {
+	root := &ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: b.name},
+		Children:      b.root.cols,
+	}
+
+	var problems []string
+	if b.name == "" {
+		problems = append(problems, "the message has no name")
+	}
+	if len(root.Children) == 0 {
+		problems = append(problems, "the message has no columns")
+	}
+	problems = checkBuiltColumns(root.Children, "", problems)
+	if len(problems) > 0 {
+		return nil, errors.New("invalid schema: " + strings.Join(problems, "; "))
+	}
+
+	for _, c := range root.Children {
+		recursiveFix(c)
+	}
+	sd := &SchemaDefinition{RootColumn: root}
+	if err := sd.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return sd, nil
+}
+
+// Required adds a required column with the type typ.
+func (b *GroupBuilder) Required(name string, typ parquet.Type, opts ...ColumnOption) *GroupBuilder {
+	return b.column(name, parquet.FieldRepetitionType_REQUIRED, typ, opts)
+}
+
+// Optional adds an optional column with the type typ.
+func (b *GroupBuilder) Optional(name string, typ parquet.Type, opts ...ColumnOption) *GroupBuilder {
+	return b.column(name, parquet.FieldRepetitionType_OPTIONAL, typ, opts)
+}
+
+// Repeated adds a repeated column with the type typ.
+func (b *GroupBuilder) Repeated(name string, typ parquet.Type, opts ...ColumnOption) *GroupBuilder {
+	return b.column(name, parquet.FieldRepetitionType_REPEATED, typ, opts)
+}
+
+// Group adds a group with the repetition type rep, fn adds its columns.
+func (b *GroupBuilder) Group(name string, rep parquet.FieldRepetitionType, fn func(g *GroupBuilder), opts ...ColumnOption) *GroupBuilder {
+	elem := &parquet.SchemaElement{
+		Name:           name,
+		RepetitionType: parquet.FieldRepetitionTypePtr(rep),
+	}
+	for _, opt := range opts {
+		opt(elem)
+	}
+	group := &GroupBuilder{}
+	if fn != nil {
+		fn(group)
+	}
+	b.cols = append(b.cols, &ColumnDefinition{SchemaElement: elem, Children: group.cols})
+	return b
+}
+
+func (b *GroupBuilder) column(name string, rep parquet.FieldRepetitionType, typ parquet.Type, opts []ColumnOption) *GroupBuilder {
+	elem := &parquet.SchemaElement{
+		Name:           name,
+		Type:           parquet.TypePtr(typ),
+		RepetitionType: parquet.FieldRepetitionTypePtr(rep),
+	}
+	for _, opt := range opts {
+		opt(elem)
+	}
+	b.cols = append(b.cols, &ColumnDefinition{SchemaElement: elem})
+	return b
+}
+
+// checkBuiltColumns appends the problems of the columns cols of the group with the path prefix to problems.
+func checkBuiltColumns(cols []*ColumnDefinition, prefix string, problems []string) []string {
+	names := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		elem := col.SchemaElement
+		path := prefix + elem.Name
+		switch {
+		case elem.Name == "":
+			problems = append(problems, fmt.Sprintf("a column of %q has no name", strings.TrimSuffix(prefix, ".")))
+		case names[elem.Name]:
+			problems = append(problems, fmt.Sprintf("the name of column %q is not unique", path))
+		}
+		names[elem.Name] = true
+
+		if elem.Type == nil {
+			if len(col.Children) == 0 {
+				problems = append(problems, fmt.Sprintf("group %q has no columns", path))
+			}
+			problems = checkBuiltColumns(col.Children, path+".", problems)
+			continue
+		}
+		if elem.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY && elem.GetTypeLength() <= 0 {
+			problems = append(problems, fmt.Sprintf("column %q is a FIXED_LEN_BYTE_ARRAY without a type length", path))
+		}
+	}
+	return problems
+}
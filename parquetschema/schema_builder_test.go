@@ -0,0 +1,79 @@
+package parquetschema
+
+import (
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	utf8 := WithConvertedType(parquet.ConvertedType_UTF8)
+	sd, err := NewSchemaBuilder("msg").
+		Required("id", parquet.Type_INT64, WithFieldID(1)).
+		Optional("name", parquet.Type_BYTE_ARRAY, utf8).
+		Optional("price", parquet.Type_INT64, WithLogicalType(&parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: 18, Scale: 4}})).
+		Group("address", parquet.FieldRepetitionType_OPTIONAL, func(b *GroupBuilder) {
+			b.Required("city", parquet.Type_BYTE_ARRAY, utf8).
+				Optional("zip", parquet.Type_FIXED_LEN_BYTE_ARRAY, WithTypeLength(5))
+		}).
+		Group("tags", parquet.FieldRepetitionType_OPTIONAL, func(b *GroupBuilder) {
+			b.Group("list", parquet.FieldRepetitionType_REPEATED, func(b *GroupBuilder) {
+				b.Required("element", parquet.Type_BYTE_ARRAY, utf8)
+			})
+		}, WithConvertedType(parquet.ConvertedType_LIST), WithFieldID(7)).
+		Repeated("scores", parquet.Type_DOUBLE).
+		Build()
+	require.NoError(t, err)
+
+	text := `message msg {
+  required int64 id = 1;
+  optional binary name (UTF8);
+  optional int64 price (DECIMAL(18, 4));
+  optional group address {
+    required binary city (UTF8);
+    optional fixed_len_byte_array(5) zip;
+  }
+  optional group tags (LIST) = 7 {
+    repeated group list {
+      required binary element (UTF8);
+    }
+  }
+  repeated double scores;
+}
+`
+	require.Equal(t, text, sd.String())
+
+	parsed, err := ParseSchemaDefinition(text)
+	require.NoError(t, err)
+	require.Equal(t, parsed, sd)
+	require.Equal(t, int32(2), sd.SubSchema("address").SchemaElement().GetNumChildren())
+	// the logical type comes with the equivalent converted type
+	require.Equal(t, parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL), sd.SubSchema("price").SchemaElement().ConvertedType)
+}
+
+func TestSchemaBuilderErrors(t *testing.T) {
+	_, err := NewSchemaBuilder("msg").
+		Required("id", parquet.Type_INT64).
+		Optional("id", parquet.Type_INT32).
+		Required("hash", parquet.Type_FIXED_LEN_BYTE_ARRAY).
+		Group("empty", parquet.FieldRepetitionType_REQUIRED, nil).
+		Group("a", parquet.FieldRepetitionType_OPTIONAL, func(b *GroupBuilder) {
+			b.Required("b", parquet.Type_INT32).
+				Required("b", parquet.Type_INT32).
+				Required("", parquet.Type_INT32)
+		}).
+		Build()
+	require.EqualError(t, err, `invalid schema: the name of column "id" is not unique; `+
+		`column "hash" is a FIXED_LEN_BYTE_ARRAY without a type length; group "empty" has no columns; `+
+		`the name of column "a.b" is not unique; a column of "a" has no name`)
+
+	_, err = NewSchemaBuilder("").Build()
+	require.EqualError(t, err, "invalid schema: the message has no name; the message has no columns")
+
+	// the columns are validated like by Validate once they have no problems
+	_, err = NewSchemaBuilder("msg").
+		Required("day", parquet.Type_INT64, WithConvertedType(parquet.ConvertedType_DATE)).
+		Build()
+	require.EqualError(t, err, "invalid schema: field day is annotated as DATE but is not an int32")
+}
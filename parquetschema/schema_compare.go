@@ -0,0 +1,173 @@
+package parquetschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// maxCompatibilityProblems is the number of incompatible columns that CompatibleWith reports in its error.
+const maxCompatibilityProblems = 5
+
+// Equal returns true if the schema definitions describe the same columns: the same paths in the same order, with
+// the same physical types, logical or converted types and repetition types. The name of the message and the field
+// IDs are not compared.
+func (sd *SchemaDefinition) Equal(other *SchemaDefinition) bool {
+	return sd.CompatibleWith(other) == nil && other.CompatibleWith(sd) == nil
+}
+
+// CompatibleWith checks whether data written with the schema definition can be read with the schema definition
+// other, e.g. to read a directory of files as one dataset. Both need to have the same columns with the same physical
+// and logical types, but other may relax REQUIRED columns and groups to OPTIONAL and add OPTIONAL columns and groups
+// after the existing ones of a group. The error has the paths of the first incompatible columns, like
+// "column ts: INT64 vs INT96".
+func (sd *SchemaDefinition) CompatibleWith(other *SchemaDefinition) error {
+	if sd == nil || sd.RootColumn == nil || other == nil || other.RootColumn == nil {
+		return errors.New("schema definition is nil")
+	}
+
+	c := &compatibilityChecker{}
+	c.group(sd.RootColumn.Children, other.RootColumn.Children, "")
+	if len(c.problems) == 0 {
+		return nil
+	}
+
+	problems := c.problems
+	if len(problems) > maxCompatibilityProblems {
+		problems = problems[:maxCompatibilityProblems]
+	}
+	msg := "incompatible schemas: " + strings.Join(problems, "; ")
+	if n := len(c.problems) - len(problems); n > 0 {
+		msg += fmt.Sprintf(" and %d more", n)
+	}
+	return errors.New(msg)
+}
+
+// compatibilityChecker collects the problems of CompatibleWith.
+type compatibilityChecker struct {
+	problems []string
+}
+
+func (c *compatibilityChecker) problemf(format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+// group compares the columns cols of a group with the columns others of the same group in the other schema.
+func (c *compatibilityChecker) group(cols, others []*ColumnDefinition, prefix string) {
+	positions := make(map[string]int, len(others))
+	for i, o := range others {
+		positions[o.SchemaElement.GetName()] = i
+	}
+
+	names := make(map[string]bool, len(cols))
+	for i, col := range cols {
+		name := col.SchemaElement.GetName()
+		names[name] = true
+		path := prefix + name
+
+		j, ok := positions[name]
+		if !ok {
+			c.problemf("column %s: missing", path)
+			continue
+		}
+		if i != j {
+			c.problemf("column %s: position %d vs %d", path, i, j)
+		}
+		c.column(col, others[j], path)
+	}
+
+	for _, o := range others {
+		name := o.SchemaElement.GetName()
+		if !names[name] && o.SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL {
+			c.problemf("column %s: new column is %s, not OPTIONAL", prefix+name, o.SchemaElement.GetRepetitionType())
+		}
+	}
+}
+
+// column compares the column or group col with the column or group o of the other schema.
+func (c *compatibilityChecker) column(col, o *ColumnDefinition, path string) {
+	elem, other := col.SchemaElement, o.SchemaElement
+
+	if rep, otherRep := elem.GetRepetitionType(), other.GetRepetitionType(); rep != otherRep &&
+		(rep != parquet.FieldRepetitionType_REQUIRED || otherRep != parquet.FieldRepetitionType_OPTIONAL) {
+		c.problemf("column %s: %s vs %s", path, rep, otherRep)
+	}
+
+	if typ, otherTyp := physicalTypeName(elem), physicalTypeName(other); typ != otherTyp {
+		c.problemf("column %s: %s vs %s", path, typ, otherTyp)
+		return
+	}
+
+	if !annotationsMatch(elem, other) {
+		c.problemf("column %s: %s vs %s", path, annotationName(elem), annotationName(other))
+	}
+
+	if elem.Type == nil {
+		c.group(col.Children, o.Children, path+".")
+	}
+}
+
+// physicalTypeName returns the physical type of elem as it is reported by CompatibleWith, or "group".
+func physicalTypeName(elem *parquet.SchemaElement) string {
+	switch {
+	case elem.Type == nil:
+		return "group"
+	case elem.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return fmt.Sprintf("%s(%d)", elem.GetType(), elem.GetTypeLength())
+	}
+	return elem.GetType().String()
+}
+
+// annotationName returns the logical or converted type of elem as it is reported by CompatibleWith.
+func annotationName(elem *parquet.SchemaElement) string {
+	switch {
+	case elem.LogicalType != nil:
+		return getSchemaLogicalType(elem.LogicalType)
+	case elem.GetConvertedType() == parquet.ConvertedType_DECIMAL:
+		return fmt.Sprintf("DECIMAL(%d, %d)", elem.GetPrecision(), elem.GetScale())
+	case elem.ConvertedType != nil:
+		return elem.GetConvertedType().String()
+	}
+	return "no logical type"
+}
+
+// annotationsMatch returns true if elem and other have the same logical type. If only one of them has a logical
+// type, like in files of older writers, it is compared by its equivalent converted type.
+func annotationsMatch(elem, other *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && other.LogicalType != nil {
+		return getSchemaLogicalType(elem.LogicalType) == getSchemaLogicalType(other.LogicalType)
+	}
+
+	ct, otherCT := effectiveConvertedType(elem), effectiveConvertedType(other)
+	if ct == nil || otherCT == nil {
+		// a logical type without a converted type only matches itself
+		return ct == nil && otherCT == nil && elem.LogicalType == nil && other.LogicalType == nil
+	}
+	if *ct != *otherCT {
+		return false
+	}
+	if *ct == parquet.ConvertedType_DECIMAL {
+		p, s := decimalParams(elem)
+		otherP, otherS := decimalParams(other)
+		return p == otherP && s == otherS
+	}
+	return true
+}
+
+// effectiveConvertedType returns the converted type of elem, or the one that is equivalent to its logical type.
+func effectiveConvertedType(elem *parquet.SchemaElement) *parquet.ConvertedType {
+	if elem.ConvertedType != nil {
+		return elem.ConvertedType
+	}
+	return ConvertedTypeOf(elem.LogicalType)
+}
+
+// decimalParams returns the precision and scale of the DECIMAL column elem.
+func decimalParams(elem *parquet.SchemaElement) (int32, int32) {
+	if elem.LogicalType != nil && elem.LogicalType.IsSetDECIMAL() {
+		return elem.LogicalType.DECIMAL.Precision, elem.LogicalType.DECIMAL.Scale
+	}
+	return elem.GetPrecision(), elem.GetScale()
+}
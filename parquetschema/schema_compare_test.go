@@ -0,0 +1,190 @@
+package parquetschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDefinitionCompatibleWith(t *testing.T) {
+	base := `message base {
+		required int64 id;
+		required int64 ts;
+		optional binary name (STRING);
+		required group address {
+			required binary city (STRING);
+		}
+	}`
+
+	for _, tt := range []struct {
+		name  string
+		other string
+		err   string
+	}{
+		{"same", base, ""},
+		{"other message name and field IDs", `message other {
+			required int64 id = 1;
+			required int64 ts = 2;
+			optional binary name (STRING);
+			required group address {
+				required binary city (STRING);
+			}
+		}`, ""},
+		{"relaxed to optional", `message base {
+			optional int64 id;
+			required int64 ts;
+			optional binary name (STRING);
+			optional group address {
+				optional binary city (STRING);
+			}
+		}`, ""},
+		{"new optional trailing columns", `message base {
+			required int64 id;
+			required int64 ts;
+			optional binary name (STRING);
+			required group address {
+				required binary city (STRING);
+				optional binary street (STRING);
+			}
+			optional double score;
+		}`, ""},
+		{"converted type instead of logical type", `message base {
+			required int64 id;
+			required int64 ts;
+			optional binary name (UTF8);
+			required group address {
+				required binary city (UTF8);
+			}
+		}`, ""},
+		{"physical type", `message base {
+			required int64 id;
+			required int96 ts;
+			optional binary name (STRING);
+			required group address {
+				required binary city (STRING);
+			}
+		}`, "incompatible schemas: column ts: INT64 vs INT96"},
+		{"logical type and repetition", `message base {
+			repeated int64 id;
+			required int64 ts (TIMESTAMP(MILLIS, true));
+			optional binary name (ENUM);
+			required group address {
+				optional binary city;
+			}
+		}`, "incompatible schemas: column id: REQUIRED vs REPEATED; column ts: no logical type vs TIMESTAMP(MILLIS, true); column name: STRING vs ENUM; column address.city: STRING vs no logical type"},
+		{"missing and new required columns", `message base {
+			required int64 id;
+			required int64 ts;
+			required group address {
+				required binary city (STRING);
+				required binary street (STRING);
+			}
+		}`, "incompatible schemas: column name: missing; column address: position 3 vs 2; column address.street: new column is REQUIRED, not OPTIONAL"},
+		{"new column in the middle", `message base {
+			required int64 id;
+			optional int64 extra;
+			required int64 ts;
+			optional binary name (STRING);
+			required group address {
+				required binary city (STRING);
+			}
+		}`, "incompatible schemas: column ts: position 1 vs 2; column name: position 2 vs 3; column address: position 3 vs 4"},
+		{"group instead of column", `message base {
+			required int64 id;
+			required int64 ts;
+			optional binary name (STRING);
+			required binary address;
+		}`, "incompatible schemas: column address: group vs BYTE_ARRAY"},
+		{"too many problems", `message base {
+			required int32 id;
+			required int32 ts;
+			optional int32 name;
+			required group address {
+				required int32 city;
+			}
+			required int32 a;
+			required int32 b;
+		}`, "incompatible schemas: column id: INT64 vs INT32; column ts: INT64 vs INT32; column name: BYTE_ARRAY vs INT32; column address.city: BYTE_ARRAY vs INT32; column a: new column is REQUIRED, not OPTIONAL and 1 more"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sd, err := ParseSchemaDefinition(base)
+			require.NoError(t, err)
+			other, err := ParseSchemaDefinition(tt.other)
+			require.NoError(t, err)
+
+			err = sd.CompatibleWith(other)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchemaDefinitionCompatibleWithDecimal(t *testing.T) {
+	sd, err := ParseSchemaDefinition(`message m { required int64 price (DECIMAL(18, 4)); }`)
+	require.NoError(t, err)
+
+	other, err := ParseSchemaDefinition(`message m { required int64 price (DECIMAL(18, 2)); }`)
+	require.NoError(t, err)
+	require.EqualError(t, sd.CompatibleWith(other), "incompatible schemas: column price: DECIMAL(18, 4) vs DECIMAL(18, 2)")
+
+	// a column with only the converted type has the same decimal
+	other.RootColumn.Children[0].SchemaElement.LogicalType = nil
+	other.RootColumn.Children[0].SchemaElement.Scale = sd.RootColumn.Children[0].SchemaElement.Scale
+	require.NoError(t, sd.CompatibleWith(other))
+}
+
+func TestSchemaDefinitionEqual(t *testing.T) {
+	sd, err := ParseSchemaDefinition(`message m {
+		required int64 id;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	same, err := ParseSchemaDefinition(`message other {
+		required int64 id = 1;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	require.True(t, sd.Equal(same))
+	require.True(t, same.Equal(sd))
+
+	// relaxed and new columns are compatible, but not equal
+	relaxed, err := ParseSchemaDefinition(`message m {
+		optional int64 id;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, sd.CompatibleWith(relaxed))
+	require.False(t, sd.Equal(relaxed))
+
+	added, err := ParseSchemaDefinition(`message m {
+		required int64 id;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+		optional int64 extra;
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, sd.CompatibleWith(added))
+	require.False(t, sd.Equal(added))
+
+	require.False(t, sd.Equal(nil))
+	require.EqualError(t, sd.CompatibleWith(nil), "schema definition is nil")
+}
@@ -37,7 +37,7 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //	column-definition ::= <repetition-type> <column-type-definition>
 //	repetition-type ::= 'required' | 'repeated' | 'optional'
 //	column-type-definition ::= <group-definition> | <field-definition>
-//	group-definition ::= 'group' <identifier> <converted-type-annotation>? '{' <message-body> '}'
+//	group-definition ::= 'group' <identifier> <converted-type-annotation>? <field-id-definition>? '{' <message-body> '}'
 //	field-definition ::= <type> <identifier> <logical-type-annotation>? <field-id-definition>? ';'
 //	type ::= 'binary'
 //		| 'float'
@@ -80,6 +80,7 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //		| 'BSON'
 //		| 'INT' '(' <bit-width> ',' <boolean> ')'
 //		| 'DECIMAL' '(' <precision> ',' <scale> ')'
+//		| 'UNKNOWN'
 //	field-id-definition ::= '=' <number>
 //	number ::= <digit>+
 //	digit ::= '0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9'
@@ -92,6 +93,7 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //	precision := <number>
 //	scale := <number>
 // For examples of textual schema definitions, please take a look at schema-files/*.schema.
+// The errors have the line and column of the token where parsing failed.
 func ParseSchemaDefinition(schemaText string) (*SchemaDefinition, error) {
 	p := newSchemaParser(schemaText)
 	if err := p.parse(); err != nil {
@@ -106,7 +108,9 @@ func ParseSchemaDefinition(schemaText string) (*SchemaDefinition, error) {
 // String returns a textual representation of the schema definition. This textual representation
 // adheres to the format accepted by the ParseSchemaDefinition function. A textual schema definition
 // parsed by ParseSchemaDefinition and turned back into a string by this method repeatedly will
-// always remain the same, save for differences in the emitted whitespaces.
+// always remain the same, save for differences in the emitted whitespaces, and parsing the string
+// returns the same schema definition. The columns are indented by two spaces per level, with the
+// logical type of a column or its converted type if it has none, and its field ID.
 func (sd *SchemaDefinition) String() string {
 	if sd == nil || sd.RootColumn == nil {
 		return "message empty {\n}\n"
@@ -169,8 +173,16 @@ func printCols(w io.Writer, cols []*ColumnDefinition, indent int) {
 
 		if elem.Type == nil {
 			fmt.Fprintf(w, "group %s", elem.GetName())
-			if elem.ConvertedType != nil {
+			switch {
+			case elem.ConvertedType != nil:
 				fmt.Fprintf(w, " (%s)", elem.GetConvertedType().String())
+			case elem.LogicalType != nil && elem.LogicalType.IsSetLIST():
+				fmt.Fprintf(w, " (%s)", parquet.ConvertedType_LIST)
+			case elem.LogicalType != nil && elem.LogicalType.IsSetMAP():
+				fmt.Fprintf(w, " (%s)", parquet.ConvertedType_MAP)
+			}
+			if elem.FieldID != nil {
+				fmt.Fprintf(w, " = %d", elem.GetFieldID())
 			}
 			fmt.Fprintf(w, " {\n")
 			printCols(w, col.Children, indent+2)
@@ -180,9 +192,13 @@ func printCols(w io.Writer, cols []*ColumnDefinition, indent int) {
 		} else {
 			typ := getSchemaType(elem)
 			fmt.Fprintf(w, "%s %s", typ, elem.GetName())
-			if elem.LogicalType != nil {
+			switch {
+			case elem.LogicalType != nil:
 				fmt.Fprintf(w, " (%s)", getSchemaLogicalType(elem.GetLogicalType()))
-			} else if elem.ConvertedType != nil {
+			case elem.GetConvertedType() == parquet.ConvertedType_DECIMAL:
+				// the parser only accepts decimals with their precision and scale
+				fmt.Fprintf(w, " (DECIMAL(%d, %d))", elem.GetPrecision(), elem.GetScale())
+			case elem.ConvertedType != nil:
 				fmt.Fprintf(w, " (%s)", elem.GetConvertedType().String())
 			}
 			if elem.FieldID != nil {
@@ -273,7 +289,65 @@ func getSchemaLogicalType(t *parquet.LogicalType) string {
 		return fmt.Sprintf("DECIMAL(%d, %d)", t.DECIMAL.Precision, t.DECIMAL.Scale)
 	case t.IsSetINTEGER():
 		return fmt.Sprintf("INT(%d, %t)", t.INTEGER.BitWidth, t.INTEGER.IsSigned)
+	case t.IsSetUNKNOWN():
+		return "UNKNOWN"
+	case t.IsSetLIST():
+		return "LIST"
+	case t.IsSetMAP():
+		return "MAP"
 	default:
 		return "BUG(UNKNOWN)"
 	}
 }
+
+// ConvertedTypeOf returns the legacy converted type that is equivalent to the logical type lt, for readers that
+// don't know logical types, or nil if there is none, like for UUID, UNKNOWN and the NANOS unit of TIME and
+// TIMESTAMP. The converted types of TIME and TIMESTAMP are adjusted to UTC, so there is none for the TIME and
+// TIMESTAMP columns that are not.
+func ConvertedTypeOf(lt *parquet.LogicalType) *parquet.ConvertedType {
+	if lt == nil {
+		return nil
+	}
+
+	var ct parquet.ConvertedType
+	switch {
+	case lt.IsSetSTRING():
+		ct = parquet.ConvertedType_UTF8
+	case lt.IsSetMAP():
+		ct = parquet.ConvertedType_MAP
+	case lt.IsSetLIST():
+		ct = parquet.ConvertedType_LIST
+	case lt.IsSetENUM():
+		ct = parquet.ConvertedType_ENUM
+	case lt.IsSetDECIMAL():
+		ct = parquet.ConvertedType_DECIMAL
+	case lt.IsSetDATE():
+		ct = parquet.ConvertedType_DATE
+	case lt.IsSetTIME() && !lt.TIME.IsAdjustedToUTC, lt.IsSetTIMESTAMP() && !lt.TIMESTAMP.IsAdjustedToUTC:
+		return nil
+	case lt.IsSetTIME() && lt.TIME.Unit.IsSetMILLIS():
+		ct = parquet.ConvertedType_TIME_MILLIS
+	case lt.IsSetTIME() && lt.TIME.Unit.IsSetMICROS():
+		ct = parquet.ConvertedType_TIME_MICROS
+	case lt.IsSetTIMESTAMP() && lt.TIMESTAMP.Unit.IsSetMILLIS():
+		ct = parquet.ConvertedType_TIMESTAMP_MILLIS
+	case lt.IsSetTIMESTAMP() && lt.TIMESTAMP.Unit.IsSetMICROS():
+		ct = parquet.ConvertedType_TIMESTAMP_MICROS
+	case lt.IsSetINTEGER():
+		name := fmt.Sprintf("INT_%d", lt.INTEGER.BitWidth)
+		if !lt.INTEGER.IsSigned {
+			name = "U" + name
+		}
+		var err error
+		if ct, err = parquet.ConvertedTypeFromString(name); err != nil {
+			return nil
+		}
+	case lt.IsSetJSON():
+		ct = parquet.ConvertedType_JSON
+	case lt.IsSetBSON():
+		ct = parquet.ConvertedType_BSON
+	default:
+		return nil
+	}
+	return &ct
+}
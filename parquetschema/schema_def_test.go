@@ -1,9 +1,12 @@
 package parquetschema
 
 import (
+	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -123,3 +126,263 @@ func TestSubSchema(t *testing.T) {
 
 	require.Nil(t, schemaDef.SubSchema("does-not-exist"))
 }
+
+// randomSchemaPrimitives are column types with the annotations that are valid for them.
+var randomSchemaPrimitives = []struct {
+	typ         string
+	annotations []string
+}{
+	{"boolean", []string{""}},
+	{"int32", []string{"", "INT(8, true)", "INT(16, false)", "DATE", "DECIMAL(9, 2)", "TIME(MILLIS, true)", "UINT_32", "INT_16", "TIME_MILLIS"}},
+	{"int64", []string{"", "INT(64, false)", "TIMESTAMP(MILLIS, true)", "TIMESTAMP(NANOS, false)", "TIME(MICROS, false)", "DECIMAL(18, 4)", "TIMESTAMP_MICROS"}},
+	{"int96", []string{""}},
+	{"float", []string{""}},
+	{"double", []string{""}},
+	{"binary", []string{"", "STRING", "ENUM", "JSON", "BSON", "UTF8", "DECIMAL(30, 5)", "UNKNOWN"}},
+	{"fixed_len_byte_array(16)", []string{"", "UUID"}},
+	{"fixed_len_byte_array(12)", []string{"INTERVAL"}},
+	{"fixed_len_byte_array(8)", []string{"DECIMAL(18, 3)"}},
+}
+
+// randomSchema writes the columns of a random schema definition in the format of SchemaDefinition.String.
+type randomSchema struct {
+	rnd   *rand.Rand
+	buf   strings.Builder
+	names int
+}
+
+func (g *randomSchema) line(indent int, format string, args ...interface{}) {
+	g.buf.WriteString(strings.Repeat(" ", indent))
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteString("\n")
+}
+
+func (g *randomSchema) fieldID() string {
+	if g.rnd.Intn(2) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" = %d", g.rnd.Intn(1000))
+}
+
+func (g *randomSchema) name() string {
+	g.names++
+	return fmt.Sprintf("c%d", g.names)
+}
+
+func (g *randomSchema) column(indent, depth int, rep, name string) {
+	if rep == "" {
+		rep = []string{"required", "optional", "repeated"}[g.rnd.Intn(3)]
+	}
+	if name == "" {
+		name = g.name()
+	}
+
+	kind := g.rnd.Intn(6)
+	if depth >= 3 {
+		kind = 0
+	}
+	switch {
+	case kind == 3 && rep != "repeated":
+		g.line(indent, "%s group %s (LIST)%s {", rep, name, g.fieldID())
+		g.line(indent+2, "repeated group list {")
+		g.column(indent+4, depth+1, []string{"required", "optional"}[g.rnd.Intn(2)], "element")
+		g.line(indent+2, "}")
+		g.line(indent, "}")
+	case kind == 4 && rep != "repeated":
+		g.line(indent, "%s group %s (MAP)%s {", rep, name, g.fieldID())
+		g.line(indent+2, "repeated group key_value {")
+		g.line(indent+4, "required binary key (STRING);")
+		g.column(indent+4, depth+1, "", "value")
+		g.line(indent+2, "}")
+		g.line(indent, "}")
+	case kind == 5:
+		g.line(indent, "%s group %s%s {", rep, name, g.fieldID())
+		for i := g.rnd.Intn(3); i >= 0; i-- {
+			g.column(indent+2, depth+1, "", "")
+		}
+		g.line(indent, "}")
+	default:
+		p := randomSchemaPrimitives[g.rnd.Intn(len(randomSchemaPrimitives))]
+		annotation := p.annotations[g.rnd.Intn(len(p.annotations))]
+		if annotation != "" {
+			annotation = " (" + annotation + ")"
+		}
+		g.line(indent, "%s %s %s%s%s;", rep, p.typ, name, annotation, g.fieldID())
+	}
+}
+
+func TestSchemaDefinitionStringRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 1000; i++ {
+		g := &randomSchema{rnd: rnd}
+		g.line(0, "message m%d {", i)
+		for n := rnd.Intn(5); n >= 0; n-- {
+			g.column(2, 0, "", "")
+		}
+		g.line(0, "}")
+		text := g.buf.String()
+
+		sd, err := ParseSchemaDefinition(text)
+		require.NoError(t, err, text)
+		require.Equal(t, text, sd.String())
+
+		parsed, err := ParseSchemaDefinition(sd.String())
+		require.NoError(t, err, text)
+		require.Equal(t, sd, parsed, text)
+	}
+}
+
+func TestSchemaDefinitionStringWithoutParser(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	sd := SchemaDefinitionFromColumnDefinition(&ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: "msg"},
+		Children: []*ColumnDefinition{
+			{
+				SchemaElement: &parquet.SchemaElement{
+					Name:           "price",
+					Type:           parquet.TypePtr(parquet.Type_INT64),
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+					ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL),
+					Precision:      int32Ptr(18),
+					Scale:          int32Ptr(4),
+				},
+			},
+			{
+				SchemaElement: &parquet.SchemaElement{
+					Name:           "tags",
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+					LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					FieldID:        int32Ptr(3),
+				},
+				Children: []*ColumnDefinition{
+					{
+						SchemaElement: &parquet.SchemaElement{
+							Name:           "list",
+							RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						},
+						Children: []*ColumnDefinition{
+							{
+								SchemaElement: &parquet.SchemaElement{
+									Name:           "element",
+									Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+									RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+									LogicalType:    &parquet.LogicalType{UNKNOWN: parquet.NewNullType()},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	expected := `message msg {
+  required int64 price (DECIMAL(18, 4));
+  optional group tags (LIST) = 3 {
+    repeated group list {
+      optional binary element (UNKNOWN);
+    }
+  }
+}
+`
+	require.Equal(t, expected, sd.String())
+
+	parsed, err := ParseSchemaDefinition(sd.String())
+	require.NoError(t, err)
+	require.Equal(t, expected, parsed.String())
+	price := parsed.SubSchema("price").SchemaElement()
+	require.Equal(t, int32(18), price.GetPrecision())
+	require.Equal(t, int32(4), price.GetScale())
+}
+
+func TestConvertedTypeOf(t *testing.T) {
+	for _, tt := range []struct {
+		lt       *parquet.LogicalType
+		expected *parquet.ConvertedType
+	}{
+		{nil, nil},
+		{&parquet.LogicalType{STRING: parquet.NewStringType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)},
+		{&parquet.LogicalType{LIST: parquet.NewListType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_LIST)},
+		{&parquet.LogicalType{MAP: parquet.NewMapType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_MAP)},
+		{&parquet.LogicalType{ENUM: parquet.NewEnumType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)},
+		{&parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: 9, Scale: 2}}, parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)},
+		{&parquet.LogicalType{DATE: parquet.NewDateType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)},
+		{&parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}}, parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS)},
+		{&parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}}, parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)},
+		{&parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}}}, nil},
+		{&parquet.LogicalType{TIME: &parquet.TimeType{Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}}, nil},
+		{&parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}}, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)},
+		{&parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}}, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)},
+		{&parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{Unit: &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}}}, nil},
+		{&parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}}, nil},
+		{&parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 8, IsSigned: true}}, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_8)},
+		{&parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 64, IsSigned: false}}, parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_64)},
+		{&parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 12, IsSigned: true}}, nil},
+		{&parquet.LogicalType{JSON: parquet.NewJsonType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)},
+		{&parquet.LogicalType{BSON: parquet.NewBsonType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)},
+		{&parquet.LogicalType{UUID: parquet.NewUUIDType()}, nil},
+		{&parquet.LogicalType{UNKNOWN: parquet.NewNullType()}, nil},
+	} {
+		require.Equal(t, tt.expected, ConvertedTypeOf(tt.lt), "%v", tt.lt)
+	}
+}
+
+func TestParseTimeConvertedType(t *testing.T) {
+	sd, err := ParseSchemaDefinition(`message msg {
+		required int64 utc (TIMESTAMP(MILLIS, true));
+		required int64 local (TIMESTAMP(MILLIS, false));
+		required int32 utc_time (TIME(MILLIS, true));
+		required int32 local_time (TIME(MILLIS, false));
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS), sd.SubSchema("utc").SchemaElement().ConvertedType)
+	require.Nil(t, sd.SubSchema("local").SchemaElement().ConvertedType)
+	require.Equal(t, parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS), sd.SubSchema("utc_time").SchemaElement().ConvertedType)
+	require.Nil(t, sd.SubSchema("local_time").SchemaElement().ConvertedType)
+}
+
+func TestValidateLogicalType(t *testing.T) {
+	column := func(typ parquet.Type, lt *parquet.LogicalType, ct *parquet.ConvertedType) *SchemaDefinition {
+		return &SchemaDefinition{RootColumn: &ColumnDefinition{
+			SchemaElement: &parquet.SchemaElement{Name: "msg"},
+			Children: []*ColumnDefinition{{SchemaElement: &parquet.SchemaElement{
+				Name:           "foo",
+				Type:           parquet.TypePtr(typ),
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+				LogicalType:    lt,
+				ConvertedType:  ct,
+			}}},
+		}}
+	}
+	str := &parquet.LogicalType{STRING: parquet.NewStringType()}
+
+	require.NoError(t, column(parquet.Type_BYTE_ARRAY, str, nil).Validate())
+	require.NoError(t, column(parquet.Type_BYTE_ARRAY, str, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)).Validate())
+	require.EqualError(t, column(parquet.Type_INT32, str, nil).Validate(), "field foo is annotated as STRING but is not a binary")
+	require.EqualError(t, column(parquet.Type_BYTE_ARRAY, str, parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)).Validate(),
+		"field foo is annotated as STRING but has the converted type ENUM instead of UTF8")
+	require.EqualError(t, column(parquet.Type_INT64, &parquet.LogicalType{DATE: parquet.NewDateType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)).Validate(),
+		"field foo is annotated as DATE but is not an int32")
+	// logical types without an equivalent converted type don't restrict it
+	require.NoError(t, column(parquet.Type_INT64, &parquet.LogicalType{UNKNOWN: parquet.NewNullType()}, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64)).Validate())
+	// older writers wrote the converted type of the unit for timestamps that are not adjusted to UTC
+	local := &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}}
+	require.NoError(t, column(parquet.Type_INT64, local, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)).Validate())
+	require.EqualError(t, column(parquet.Type_INT64, local, parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)).Validate(),
+		"field foo is annotated as TIMESTAMP(MILLIS, false) but has the converted type TIMESTAMP_MICROS instead of TIMESTAMP_MILLIS")
+
+	sd, err := ParseSchemaDefinition(`message msg {
+		optional group m (MAP_KEY_VALUE) {
+			repeated group key_value {
+				required binary key (STRING);
+				optional int32 value;
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	// older writers annotate MAP groups as MAP_KEY_VALUE
+	sd.SubSchema("m").SchemaElement().LogicalType = &parquet.LogicalType{MAP: parquet.NewMapType()}
+	require.NoError(t, sd.Validate())
+	sd.SubSchema("m").SchemaElement().ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_LIST)
+	require.EqualError(t, sd.Validate(), "field m is annotated as MAP but has the converted type LIST instead of MAP")
+}
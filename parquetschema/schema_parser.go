@@ -146,6 +146,12 @@ func (l *schemaLexer) emit(t itemType) {
 	l.startLine = l.line
 }
 
+// column returns the column of the position p in its line, counted in runes from 1.
+func (l *schemaLexer) column(p pos) int {
+	lineStart := strings.LastIndexByte(l.input[:p], '\n') + 1
+	return utf8.RuneCountInString(l.input[lineStart:p]) + 1
+}
+
 func (l *schemaLexer) acceptRun(valid string) {
 	for strings.ContainsRune(valid, l.next()) {
 	}
@@ -304,7 +310,7 @@ func (p *schemaParser) recover(errp *error) {
 }
 
 func (p *schemaParser) errorf(msg string, args ...interface{}) {
-	msg = fmt.Sprintf("line %d: %s", p.token.line, msg)
+	msg = fmt.Sprintf("line %d, column %d: %s", p.token.line, p.l.column(p.token.pos), msg)
 	panic(fmt.Errorf(msg, args...))
 }
 
@@ -394,6 +400,11 @@ func (p *schemaParser) parseColumnDefinition() *ColumnDefinition {
 			p.next()
 		}
 
+		if p.token.typ == itemEqual {
+			col.SchemaElement.FieldID = p.parseFieldID()
+			p.next()
+		}
+
 		col.Children = p.parseMessageBody()
 
 		p.expect(itemRightBrace)
@@ -516,6 +527,9 @@ func (p *schemaParser) parseLogicalOrConvertedType() (*parquet.LogicalType, *par
 		ct = parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)
 	case "DECIMAL":
 		p.parseDecimalLogicalType(lt)
+		ct = parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)
+	case "UNKNOWN":
+		lt.UNKNOWN = parquet.NewNullType()
 	default:
 		convertedType, err := parquet.ConvertedTypeFromString(strings.ToUpper(typStr))
 		if err != nil {
@@ -569,6 +583,10 @@ func (p *schemaParser) parseTimestampLogicalType(lt *parquet.LogicalType) (ct *p
 	p.next()
 	p.expect(itemRightParen)
 
+	// the converted types are adjusted to UTC
+	if !lt.TIMESTAMP.IsAdjustedToUTC {
+		return nil
+	}
 	return ct
 }
 
@@ -610,6 +628,10 @@ func (p *schemaParser) parseTimeLogicalType(lt *parquet.LogicalType) (ct *parque
 	p.next()
 	p.expect(itemRightParen)
 
+	// the converted types are adjusted to UTC
+	if !lt.TIME.IsAdjustedToUTC {
+		return nil
+	}
 	return ct
 }
 
@@ -761,6 +783,10 @@ func (col *ColumnDefinition) validateColumn(isRoot, strictMode bool) error {
 		return fmt.Errorf("field %s has a type but also children", col.SchemaElement.Name)
 	}
 
+	if col.SchemaElement.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY && col.SchemaElement.Type != nil && col.SchemaElement.GetTypeLength() <= 0 {
+		return fmt.Errorf("field %s is a fixed_len_byte_array without a type length", col.SchemaElement.Name)
+	}
+
 	return nil
 }
 
@@ -900,7 +926,16 @@ func (col *ColumnDefinition) validateTimeLogicalType() error {
 }
 
 func (col *ColumnDefinition) validateDecimalLogicalType() error {
-	dec := col.SchemaElement.GetLogicalType().DECIMAL
+	dec := &parquet.DecimalType{Precision: col.SchemaElement.GetPrecision(), Scale: col.SchemaElement.GetScale()}
+	if col.SchemaElement.LogicalType != nil && col.SchemaElement.LogicalType.IsSetDECIMAL() {
+		dec = col.SchemaElement.LogicalType.DECIMAL
+	} else if col.SchemaElement.Precision == nil {
+		// the DECIMAL converted type without the logical type
+		return fmt.Errorf("field %s is annotated as DECIMAL but has no precision", col.SchemaElement.Name)
+	}
+	if dec.Scale < 0 || dec.Scale > dec.Precision {
+		return fmt.Errorf("field %s is annotated as DECIMAL but scale %d is out of bounds; needs to be 0 <= scale <= precision %d", col.SchemaElement.Name, dec.Scale, dec.Precision)
+	}
 	switch col.SchemaElement.GetType() {
 	case parquet.Type_INT32:
 		if dec.Precision < 1 || dec.Precision > 9 {
@@ -912,7 +947,8 @@ func (col *ColumnDefinition) validateDecimalLogicalType() error {
 		}
 	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
 		n := *col.SchemaElement.TypeLength
-		maxDigits := int32(math.Floor(math.Log10(math.Exp2(8*float64(n)-1)) - 1))
+		// the two's complement of n bytes has up to floor(log10(2^(8n-1) - 1)) digits
+		maxDigits := int32(math.Floor(math.Log10(math.Exp2(8*float64(n) - 1))))
 		if dec.Precision < 1 || dec.Precision > maxDigits {
 			return fmt.Errorf("field %s is fixed_len_byte_array(%d) and annotated as DECIMAL but precision %d is out of bounds; needs to be 1 <= precision <= %d", col.SchemaElement.Name, n, dec.Precision, maxDigits)
 		}
@@ -944,11 +980,42 @@ func (col *ColumnDefinition) validateIntegerLogicalType() error {
 	return nil
 }
 
+// validateConvertedType checks that a column with both a logical type and a converted type has the converted type
+// that is equivalent to the logical type. MAP groups may have the converted type MAP_KEY_VALUE of older writers, and
+// TIME and TIMESTAMP columns that are not adjusted to UTC the converted type of their unit, which older writers wrote
+// for them as well.
+func (col *ColumnDefinition) validateConvertedType() error {
+	elem := col.SchemaElement
+	if elem.LogicalType == nil || elem.ConvertedType == nil {
+		return nil
+	}
+	ct := ConvertedTypeOf(utcLogicalType(elem.LogicalType))
+	if ct == nil || *ct == elem.GetConvertedType() || elem.LogicalType.IsSetMAP() && elem.GetConvertedType() == parquet.ConvertedType_MAP_KEY_VALUE {
+		return nil
+	}
+	return fmt.Errorf("field %s is annotated as %s but has the converted type %s instead of %s", elem.Name, getSchemaLogicalType(elem.LogicalType), elem.GetConvertedType(), ct)
+}
+
+// utcLogicalType returns the TIME or TIMESTAMP logical type lt adjusted to UTC, or lt for the other logical types.
+func utcLogicalType(lt *parquet.LogicalType) *parquet.LogicalType {
+	switch {
+	case lt.IsSetTIME() && !lt.TIME.IsAdjustedToUTC:
+		return &parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: lt.TIME.Unit}}
+	case lt.IsSetTIMESTAMP() && !lt.TIMESTAMP.IsAdjustedToUTC:
+		return &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: lt.TIMESTAMP.Unit}}
+	}
+	return lt
+}
+
 func (col *ColumnDefinition) validate(isRoot bool, strictMode bool) error {
 	if err := col.validateColumn(isRoot, strictMode); err != nil {
 		return err
 	}
 
+	if err := col.validateConvertedType(); err != nil {
+		return err
+	}
+
 	switch {
 	case (col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetLIST()) || col.SchemaElement.GetConvertedType() == parquet.ConvertedType_LIST:
 		if err := col.validateListLogicalType(strictMode); err != nil {
@@ -974,6 +1041,10 @@ func (col *ColumnDefinition) validate(isRoot bool, strictMode bool) error {
 		if col.SchemaElement.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY || col.SchemaElement.GetTypeLength() != 16 {
 			return fmt.Errorf("field %s is annotated as UUID but is not a fixed_len_byte_array(16)", col.SchemaElement.Name)
 		}
+	case col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetSTRING():
+		if col.SchemaElement.GetType() != parquet.Type_BYTE_ARRAY {
+			return fmt.Errorf("field %s is annotated as STRING but is not a binary", col.SchemaElement.Name)
+		}
 	case col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetENUM():
 		if col.SchemaElement.GetType() != parquet.Type_BYTE_ARRAY {
 			return fmt.Errorf("field %s is annotated as ENUM but is not a binary", col.SchemaElement.Name)
@@ -986,7 +1057,7 @@ func (col *ColumnDefinition) validate(isRoot bool, strictMode bool) error {
 		if col.SchemaElement.GetType() != parquet.Type_BYTE_ARRAY {
 			return fmt.Errorf("field %s is annotated as BSON but is not a binary", col.SchemaElement.Name)
 		}
-	case col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetDECIMAL():
+	case (col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetDECIMAL()) || (col.SchemaElement.ConvertedType != nil && col.SchemaElement.GetConvertedType() == parquet.ConvertedType_DECIMAL):
 		if err := col.validateDecimalLogicalType(); err != nil {
 			return err
 		}
@@ -302,8 +302,8 @@ func TestSchemaParser(t *testing.T) {
 		}`, false, false},
 		// 70.
 		{`message foo {
-			required fixed_len_byte_array(10) foo (DECIMAL(23,10));
-		}`, true, false}, // 23 is out of bounds; maximum for 10 is 22.
+			required fixed_len_byte_array(10) foo (DECIMAL(24,10));
+		}`, true, false}, // 24 is out of bounds; maximum for 10 is 23.
 		{`message foo {
 			required binary foo (DECIMAL(100,10));
 		}`, false, false},
@@ -482,6 +482,9 @@ message foo { }`, false, false}, // this is necessary because we once had a pars
 				}
 			}
 		}`, true, false}, // type and logical type don't match for element.
+		{`message foo {
+			required int64 foo (DECIMAL(5, 6));
+		}`, true, false}, // scale is greater than the precision.
 		{`message foo {
 			optional group bar (INVALID) {
 
@@ -527,7 +530,43 @@ func TestLineNumber(t *testing.T) {
 	err := p.parse()
 	assert.Error(t, err)
 
-	assert.Contains(t, err.Error(), "line 13:")
+	assert.Contains(t, err.Error(), "line 13, column 6:")
+}
+
+func TestErrorPosition(t *testing.T) {
+	testData := []struct {
+		Msg string
+		Err string
+	}{
+		{"message foo {\n  required int33 bar;\n}", `line 2, column 12: invalid type "int33"`},
+		{"message foo {\n  required int64 bar (DECIMAL(x, 2));\n}", `line 2, column 31: expected number, got "x" instead`},
+		{"message foo {\n  required int64 a;\n  optional int32 b (INT(8, maybe));\n}", `line 3, column 28: invalid isSigned annotation "maybe" for INT`},
+		{"message foo { required group bar = 99999999999 { required int64 baz; } }", `line 1, column 36: couldn't parse field ID "99999999999": strconv.ParseInt: parsing "99999999999": value out of range`},
+		{"message foo {\n  required binary ä;\n  required binary b\n}", `line 4, column 1: expected ;, got "}" instead`},
+	}
+
+	for idx, tt := range testData {
+		_, err := ParseSchemaDefinition(tt.Msg)
+		assert.EqualError(t, err, tt.Err, "%d. error doesn't match", idx)
+	}
+}
+
+func TestGroupFieldID(t *testing.T) {
+	msg := `message foo {
+  optional group tags (LIST) = 3 {
+    repeated group list = 4 {
+      required binary element (STRING) = 5;
+    }
+  }
+}
+`
+	sd, err := ParseSchemaDefinition(msg)
+	assert.NoError(t, err)
+	tags := sd.SubSchema("tags")
+	assert.Equal(t, int32(3), tags.SchemaElement().GetFieldID())
+	assert.Equal(t, int32(4), tags.SubSchema("list").SchemaElement().GetFieldID())
+	assert.Equal(t, int32(5), tags.SubSchema("list").SubSchema("element").SchemaElement().GetFieldID())
+	assert.Equal(t, msg, sd.String())
 }
 
 func TestValidate(t *testing.T) {
@@ -2,9 +2,14 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"io/ioutil"
+	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,6 +80,12 @@ func TestWriteThenReadFile(t *testing.T) {
 
 	testFunc(WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithCreator("parquet-go-unittest"))
 	testFunc(WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithCreator("parquet-go-unittest"), WithDataPageV2())
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_GZIP), WithCreator("parquet-go-unittest"))
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_GZIP), WithCreator("parquet-go-unittest"), WithDataPageV2())
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_LZ4), WithCreator("parquet-go-unittest"))
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_LZ4_RAW), WithCreator("parquet-go-unittest"), WithDataPageV2())
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_BROTLI), WithCreator("parquet-go-unittest"))
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_ZSTD), WithCreator("parquet-go-unittest"), WithDataPageV2())
 }
 
 func TestWriteThenReadFileRepeated(t *testing.T) {
@@ -638,8 +649,19 @@ func TestWriteNoRecords(t *testing.T) {
 
 	w := NewFileWriter(wf, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_GZIP))
 
-	require.Error(t, w.Close())
+	// a file without rows has no row groups
+	require.NoError(t, w.Close())
 	require.NoError(t, wf.Close())
+
+	rf, err := os.Open("files/test10.parquet")
+	require.NoError(t, err)
+	defer rf.Close()
+	r, err := NewFileReader(rf)
+	require.NoError(t, err)
+	require.Equal(t, 0, r.RowGroupCount())
+	require.Equal(t, int64(0), r.NumRows())
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
 }
 
 func TestReadWriteMultiLevel(t *testing.T) {
@@ -863,6 +885,2026 @@ func TestReadWriteColumeEncodings(t *testing.T) {
 	require.Equal(t, io.EOF, err)
 }
 
-func strPtr(s string) *string {
-	return &s
+func TestWriteDictionaryFallback(t *testing.T) {
+	writeFile := func(opts ...FileWriterOption) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+
+		s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+
+		// 1000 distinct values of 10 bytes each, every one of them repeated 10 times.
+		for i := 0; i < 10000; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprintf("value%05d", i%1000))}))
+		}
+
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	readFile := func(data []byte) *parquet.ColumnMetaData {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, 1, r.RowGroupCount())
+		md := r.meta.RowGroups[0].Columns[0].MetaData
+
+		for i := 0; i < 10000; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, []byte(fmt.Sprintf("value%05d", i%1000)), row["a"])
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+
+		return md
+	}
+
+	md := readFile(writeFile())
+	require.NotNil(t, md.DictionaryPageOffset)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY}, md.Encodings)
+
+	md = readFile(writeFile(WithMaxDictionarySize(5000)))
+	require.Nil(t, md.DictionaryPageOffset)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN}, md.Encodings)
+}
+
+func TestWriteDictionaryFallbackMidChunk(t *testing.T) {
+	// the first half of the values repeats 10 values, the second half has distinct values that don't fit into the
+	// dictionary
+	value := func(i int) []byte {
+		if i < 5000 {
+			i %= 10
+		}
+		return []byte(fmt.Sprintf("value%05d", i))
+	}
+
+	testFunc := func(dictPageEnc, dataPageEnc parquet.Encoding, encodings []parquet.Encoding, opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithMaxDictionarySize(2000), WithMaxPageSize(1024)}, opts...)...)
+
+		s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+
+		for i := 0; i < 10000; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": value(i)}))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		md := r.meta.RowGroups[0].Columns[0].MetaData
+		require.Equal(t, encodings, md.Encodings)
+		require.NotNil(t, md.DictionaryPageOffset)
+
+		// the dictionary page has only the values of the dictionary encoded pages
+		dictHeader := &parquet.PageHeader{}
+		require.NoError(t, readThrift(dictHeader, bytes.NewReader(buf.Bytes()[*md.DictionaryPageOffset:])))
+		require.Equal(t, dictPageEnc, dictHeader.DictionaryPageHeader.Encoding)
+		numValues := dictHeader.DictionaryPageHeader.NumValues
+		require.True(t, numValues >= 10 && numValues*10 <= 2000, "dictionary has %d values", numValues)
+
+		dataPageType := parquet.PageType_DATA_PAGE
+		if dataPageEnc == parquet.Encoding_RLE_DICTIONARY {
+			dataPageType = parquet.PageType_DATA_PAGE_V2
+		}
+		stats := md.EncodingStats
+		require.Len(t, stats, 3)
+		require.Equal(t, &parquet.PageEncodingStats{PageType: parquet.PageType_DICTIONARY_PAGE, Encoding: dictPageEnc, Count: 1}, stats[0])
+		require.Equal(t, dataPageType, stats[1].PageType)
+		require.Equal(t, dataPageEnc, stats[1].Encoding)
+		require.True(t, stats[1].Count > 1)
+		require.Equal(t, dataPageType, stats[2].PageType)
+		require.Equal(t, parquet.Encoding_PLAIN, stats[2].Encoding)
+		require.True(t, stats[2].Count > 1)
+
+		for i := 0; i < 10000; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, value(i), row["a"], "row %d", i)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+	}
+
+	testFunc(parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_PLAIN_DICTIONARY,
+		[]parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_PLAIN})
+	testFunc(parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY,
+		[]parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY}, WithDataPageV2())
+}
+
+func TestWriteDictionaryEncodings(t *testing.T) {
+	testFunc := func(dictPageEnc, dataPageEnc parquet.Encoding, encodings []parquet.Encoding, opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+
+		s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprint(i % 3))}))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		md := r.meta.RowGroups[0].Columns[0].MetaData
+		require.Equal(t, encodings, md.Encodings)
+		require.NotNil(t, md.DictionaryPageOffset)
+
+		pr := bytes.NewReader(buf.Bytes()[*md.DictionaryPageOffset:])
+		dictHeader := &parquet.PageHeader{}
+		require.NoError(t, readThrift(dictHeader, pr))
+		require.Equal(t, dictPageEnc, dictHeader.DictionaryPageHeader.Encoding)
+
+		pr = bytes.NewReader(buf.Bytes()[md.DataPageOffset:])
+		dataHeader := &parquet.PageHeader{}
+		require.NoError(t, readThrift(dataHeader, pr))
+		if dataHeader.DataPageHeader != nil {
+			require.Equal(t, dataPageEnc, dataHeader.DataPageHeader.Encoding)
+		} else {
+			require.Equal(t, dataPageEnc, dataHeader.DataPageHeaderV2.Encoding)
+		}
+
+		for i := 0; i < 100; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, []byte(fmt.Sprint(i%3)), row["a"])
+		}
+	}
+
+	testFunc(parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_PLAIN_DICTIONARY,
+		[]parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY})
+	testFunc(parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY,
+		[]parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY}, WithDataPageV2())
+}
+
+func TestWriteColumnChunkEncodings(t *testing.T) {
+	writeFile := func(opts ...FileWriterOption) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithMaxPageSize(256), WithDataPageV2ForColumn("v2")}, opts...)...)
+
+		for _, name := range []string{"v1", "v2"} {
+			s, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+			require.NoError(t, err)
+			require.NoError(t, w.AddColumn(name, NewDataColumn(s, parquet.FieldRepetitionType_OPTIONAL)))
+		}
+		s, err := NewBooleanStore(parquet.Encoding_RLE, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("flag", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+
+		// the first half of the values repeats 50 values, the values of the second half are distinct
+		for i := 0; i < 5000; i++ {
+			n := i
+			if i < 2500 {
+				n %= 50
+			}
+			value := []byte(fmt.Sprintf("value %d", n))
+			require.NoError(t, w.AddData(map[string]interface{}{"v1": value, "v2": value, "flag": i%7 == 0}))
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	// checkChunks compares the meta data of the column chunks with the pages in the file, and returns the encodings
+	// of the column chunks.
+	checkChunks := func(data []byte) map[string][]parquet.Encoding {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		encodings := map[string][]parquet.Encoding{}
+		for _, cc := range r.meta.RowGroups[0].Columns {
+			md := cc.MetaData
+			col := md.PathInSchema[0]
+			encodings[col] = md.Encodings
+
+			in := &offsetReader{inner: bytes.NewReader(data)}
+			_, err := in.Seek(chunkStart(md), io.SeekStart)
+			require.NoError(t, err)
+
+			var (
+				dataPageOffset int64 = -1
+				stats          []*parquet.PageEncodingStats
+			)
+			for in.offset < chunkStart(md)+md.TotalCompressedSize {
+				offset := in.offset
+				ph := &parquet.PageHeader{}
+				require.NoError(t, readThrift(ph, in))
+				_, err := in.Seek(int64(ph.CompressedPageSize), io.SeekCurrent)
+				require.NoError(t, err)
+
+				var enc parquet.Encoding
+				switch ph.Type {
+				case parquet.PageType_DICTIONARY_PAGE:
+					require.Equal(t, md.DictionaryPageOffset, &offset, "column %s", col)
+					enc = ph.DictionaryPageHeader.Encoding
+				case parquet.PageType_DATA_PAGE:
+					enc = ph.DataPageHeader.Encoding
+				case parquet.PageType_DATA_PAGE_V2:
+					enc = ph.DataPageHeaderV2.Encoding
+				}
+				if ph.Type != parquet.PageType_DICTIONARY_PAGE && dataPageOffset < 0 {
+					dataPageOffset = offset
+				}
+				require.Contains(t, md.Encodings, enc, "column %s", col)
+
+				if len(stats) == 0 || stats[len(stats)-1].PageType != ph.Type || stats[len(stats)-1].Encoding != enc {
+					stats = append(stats, &parquet.PageEncodingStats{PageType: ph.Type, Encoding: enc})
+				}
+				stats[len(stats)-1].Count++
+			}
+			require.Equal(t, dataPageOffset, md.DataPageOffset, "column %s", col)
+			require.Equal(t, stats, md.EncodingStats, "column %s", col)
+			require.True(t, stats[len(stats)-1].Count > 1, "column %s has %d data pages", col, stats[len(stats)-1].Count)
+		}
+		return encodings
+	}
+
+	encodings := checkChunks(writeFile())
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY}, encodings["v1"])
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY}, encodings["v2"])
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE}, encodings["flag"])
+
+	// the values of the first page are too large for the dictionary, the chunks use the column encoding only
+	encodings = checkChunks(writeFile(WithMaxDictionarySize(100)))
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN}, encodings["v1"])
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN}, encodings["v2"])
+
+	// the distinct values of the second half don't fit into the dictionary, the chunks fall back to the column
+	// encoding after the dictionary encoded pages
+	data := writeFile(WithMaxDictionarySize(1000))
+	encodings = checkChunks(data)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_PLAIN}, encodings["v1"])
+	// the PLAIN encoding of the v2 chunk is listed for the dictionary page already, the fallback is in the stats
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY}, encodings["v2"])
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	stats := r.meta.RowGroups[0].Columns[1].MetaData.EncodingStats
+	require.Len(t, stats, 3)
+	require.Equal(t, parquet.PageType_DATA_PAGE_V2, stats[1].PageType)
+	require.Equal(t, parquet.Encoding_RLE_DICTIONARY, stats[1].Encoding)
+	require.Equal(t, parquet.PageType_DATA_PAGE_V2, stats[2].PageType)
+	require.Equal(t, parquet.Encoding_PLAIN, stats[2].Encoding)
+}
+
+func TestWriteDataPageV2ForColumn(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated int32 nums;
+			optional binary name (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithDataPageV2ForColumn("nums"), WithDataPageV2ForColumn("name"))
+
+	var expected []map[string]interface{}
+	for i := 0; i < 100; i++ {
+		row := map[string]interface{}{"id": int64(i)}
+		if i%4 != 0 {
+			nums := make([]int32, i%4)
+			for j := range nums {
+				nums[j] = int32(i + j)
+			}
+			row["nums"] = nums
+		}
+		if i%5 != 0 {
+			row["name"] = []byte(fmt.Sprintf("name %d", i))
+		}
+		expected = append(expected, row)
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	headers := make(map[string]*parquet.PageHeader)
+	for _, col := range r.meta.RowGroups[0].Columns {
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, bytes.NewReader(buf.Bytes()[col.MetaData.DataPageOffset:])))
+		headers[strings.Join(col.MetaData.PathInSchema, ".")] = ph
+	}
+
+	require.Equal(t, parquet.PageType_DATA_PAGE, headers["id"].Type)
+
+	nums := headers["nums"]
+	require.Equal(t, parquet.PageType_DATA_PAGE_V2, nums.Type)
+	// the 25 rows without numbers still have a (null) value with a repetition level of 0
+	require.Equal(t, int32(100), nums.DataPageHeaderV2.NumRows)
+	require.Equal(t, int32(25+25*1+25*2+25*3), nums.DataPageHeaderV2.NumValues)
+	require.Equal(t, int32(25), nums.DataPageHeaderV2.NumNulls)
+	require.True(t, nums.DataPageHeaderV2.IsCompressed)
+	require.Equal(t, int64(25), nums.DataPageHeaderV2.Statistics.GetNullCount())
+	require.Equal(t, []byte{1, 0, 0, 0}, nums.DataPageHeaderV2.Statistics.MinValue)
+	require.Equal(t, []byte{101, 0, 0, 0}, nums.DataPageHeaderV2.Statistics.MaxValue)
+
+	name := headers["name"]
+	require.Equal(t, parquet.PageType_DATA_PAGE_V2, name.Type)
+	require.Equal(t, int32(100), name.DataPageHeaderV2.NumRows)
+	require.Equal(t, int32(20), name.DataPageHeaderV2.NumNulls)
+	require.Equal(t, int32(0), name.DataPageHeaderV2.RepetitionLevelsByteLength)
+	require.NotZero(t, name.DataPageHeaderV2.DefinitionLevelsByteLength)
+
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// a column keeps the V1 format in a file with V2 pages
+	buf.Reset()
+	w = NewFileWriter(buf, WithSchemaDefinition(sd), WithDataPageV2(), WithDataPageV1ForColumn("name"))
+	for i := range expected {
+		require.NoError(t, w.AddData(expected[i]))
+	}
+	require.NoError(t, w.Close())
+
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for _, col := range r.meta.RowGroups[0].Columns {
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, bytes.NewReader(buf.Bytes()[col.MetaData.DataPageOffset:])))
+		expectedType := parquet.PageType_DATA_PAGE_V2
+		if col.MetaData.PathInSchema[0] == "name" {
+			expectedType = parquet.PageType_DATA_PAGE
+		}
+		require.Equal(t, expectedType, ph.Type, "column %s", col.MetaData.PathInSchema[0])
+	}
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row)
+	}
+
+	// unknown columns are an error
+	for _, opt := range []struct {
+		opt  FileWriterOption
+		name string
+	}{{WithDataPageV2ForColumn("nmes"), "WithDataPageV2ForColumn"}, {WithDataPageV1ForColumn("nmes"), "WithDataPageV1ForColumn"}} {
+		w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), opt.opt)
+		require.NoError(t, w.AddData(expected[0]))
+		require.EqualError(t, w.Close(), `column "nmes" of `+opt.name+` not found`)
+	}
+}
+
+func TestWriteColumnEncoding(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required group event {
+				required binary payload (STRING);
+				required int64 seq;
+			}
+			required int32 count;
+			required double score;
+			required boolean flag;
+		}`)
+	require.NoError(t, err)
+
+	writeFile := func(opts ...FileWriterOption) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 1000; i++ {
+			err := w.AddData(map[string]interface{}{
+				"event": map[string]interface{}{
+					"payload": []byte(fmt.Sprintf("payload %d", i)),
+					"seq":     int64(i * 3),
+				},
+				"count": int32(i % 10),
+				"score": float64(i) / 4,
+				"flag":  i%3 == 0,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	readFile := func(data []byte) map[string][]parquet.Encoding {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		encodings := map[string][]parquet.Encoding{}
+		for _, cc := range r.meta.RowGroups[0].Columns {
+			encodings[strings.Join(cc.MetaData.PathInSchema, ".")] = cc.MetaData.Encodings
+		}
+
+		for i := 0; i < 1000; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{
+				"event": map[string]interface{}{
+					"payload": []byte(fmt.Sprintf("payload %d", i)),
+					"seq":     int64(i * 3),
+				},
+				"count": int32(i % 10),
+				"score": float64(i) / 4,
+				"flag":  i%3 == 0,
+			}, row)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+		return encodings
+	}
+
+	data, err := writeFile(
+		WithColumnEncoding("event.payload", parquet.Encoding_DELTA_BYTE_ARRAY),
+		WithColumnEncoding("score", parquet.Encoding_BYTE_STREAM_SPLIT),
+		WithColumnEncoding("count", parquet.Encoding_RLE_DICTIONARY),
+		WithDeltaEncodingForIntegers(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]parquet.Encoding{
+		"event.payload": {parquet.Encoding_RLE, parquet.Encoding_DELTA_BYTE_ARRAY},
+		"event.seq":     {parquet.Encoding_RLE, parquet.Encoding_DELTA_BINARY_PACKED},
+		"count":         {parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY},
+		"score":         {parquet.Encoding_RLE, parquet.Encoding_BYTE_STREAM_SPLIT},
+		"flag":          {parquet.Encoding_RLE, parquet.Encoding_PLAIN},
+	}, readFile(data))
+
+	// the DOUBLE column has no dictionary by default
+	data, err = writeFile()
+	require.NoError(t, err)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN}, readFile(data)["score"])
+
+	_, err = writeFile(WithColumnEncoding("event.payload", parquet.Encoding_DELTA_BINARY_PACKED))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid encoding DELTA_BINARY_PACKED of column "event.payload"`)
+
+	_, err = writeFile(WithColumnEncoding("flag", parquet.Encoding_RLE_DICTIONARY))
+	require.EqualError(t, err, `invalid encoding RLE_DICTIONARY of column "flag": BOOLEAN columns can't use a dictionary`)
+
+	_, err = writeFile(WithColumnEncoding("event.missing", parquet.Encoding_PLAIN))
+	require.EqualError(t, err, `column "event.missing" of WithColumnEncoding not found`)
+}
+
+func TestWriteColumnEncodingDefaults(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 n;
+			required double score;
+			required float ratio;
+		}`)
+	require.NoError(t, err)
+
+	// writeFile returns whether the column chunks have a dictionary
+	writeFile := func(opts ...FileWriterOption) map[string]bool {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"n": int64(i % 5), "score": float64(i % 5), "ratio": float32(i % 5)}))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		dicts := map[string]bool{}
+		for _, cc := range r.meta.RowGroups[0].Columns {
+			dicts[cc.MetaData.PathInSchema[0]] = cc.MetaData.DictionaryPageOffset != nil
+		}
+		return dicts
+	}
+
+	require.Equal(t, map[string]bool{"n": true, "score": false, "ratio": false}, writeFile())
+	require.Equal(t, map[string]bool{"n": true, "score": true, "ratio": false}, writeFile(WithDictionaryEncoding("score", true)))
+	require.Equal(t, map[string]bool{"n": true, "score": true, "ratio": true}, writeFile(WithDictionaryEncodingDefault(true)))
+}
+
+func TestWriteColumnEncodingInvalidOption(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 n;
+			required boolean flag;
+		}`)
+	require.NoError(t, err)
+
+	w := NewFileWriter(&bytes.Buffer{}, WithColumnEncoding("n", parquet.Encoding_DELTA_BINARY_PACKED), WithColumnEncoding("flag", parquet.Encoding_RLE_DICTIONARY))
+	require.EqualError(t, w.SetSchemaDefinition(sd), `invalid encoding RLE_DICTIONARY of column "flag": BOOLEAN columns can't use a dictionary`)
+
+	// the invalid option is reported again, and no column got its encoding
+	for i := 0; i < 2; i++ {
+		err := w.AddData(map[string]interface{}{"n": int64(i), "flag": true})
+		require.EqualError(t, err, `invalid encoding RLE_DICTIONARY of column "flag": BOOLEAN columns can't use a dictionary`)
+		require.Equal(t, parquet.Encoding_PLAIN, w.SchemaWriter.GetColumnByName("n").data.enc)
+	}
+	require.Error(t, w.WriteColumns(map[string]interface{}{"n": []int64{1}, "flag": []interface{}{true}}))
+	require.Error(t, w.Close())
+
+	w = NewFileWriter(&bytes.Buffer{}, WithDictionaryEncoding("missing", false))
+	require.EqualError(t, w.SetSchemaDefinition(sd), `column "missing" of WithDictionaryEncoding not found`)
+	require.EqualError(t, w.FlushRowGroup(), `column "missing" of WithDictionaryEncoding not found`)
+	require.EqualError(t, w.FlushRowGroup(), `column "missing" of WithDictionaryEncoding not found`)
+}
+
+func TestWriteDictionaryEncodingOption(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required binary id (STRING);
+			required binary tag (STRING);
+			required int64 n;
+			required boolean flag;
+		}`)
+	require.NoError(t, err)
+
+	// writeFile returns the encodings of the column chunks and whether they have a dictionary
+	writeFile := func(opts ...FileWriterOption) (map[string][]parquet.Encoding, map[string]bool, error) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 1000; i++ {
+			err := w.AddData(map[string]interface{}{
+				"id":   []byte(fmt.Sprintf("id %d", i)),
+				"tag":  []byte(fmt.Sprintf("tag %d", i%3)),
+				"n":    int64(i % 5),
+				"flag": i%2 == 0,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		// the values of the columns without a dictionary are not hashed
+		for _, col := range w.SchemaWriter.Columns() {
+			require.Equal(t, !col.data.allowDict, len(col.data.values.indices) == 0, "column %s", col.FlatName())
+		}
+		if err := w.Close(); err != nil {
+			return nil, nil, err
+		}
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		encodings, dicts := map[string][]parquet.Encoding{}, map[string]bool{}
+		for _, cc := range r.meta.RowGroups[0].Columns {
+			md := cc.MetaData
+			encodings[md.PathInSchema[0]] = md.Encodings
+			dicts[md.PathInSchema[0]] = md.DictionaryPageOffset != nil
+			// the distinct values are unknown if they were not hashed
+			if md.Statistics.DistinctCount == nil {
+				require.Nil(t, md.DictionaryPageOffset)
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, []byte(fmt.Sprintf("id %d", i)), row["id"])
+			require.Equal(t, []byte(fmt.Sprintf("tag %d", i%3)), row["tag"])
+		}
+		return encodings, dicts, nil
+	}
+
+	encodings, dicts, err := writeFile()
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"id": false, "tag": true, "n": true, "flag": false}, dicts)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY}, encodings["tag"])
+
+	encodings, dicts, err = writeFile(WithDictionaryEncoding("tag", false), WithDictionaryEncoding("id", false))
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"id": false, "tag": false, "n": true, "flag": false}, dicts)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN}, encodings["tag"])
+
+	// the disabled dictionary falls back to the encoding of the column
+	encodings, dicts, err = writeFile(
+		WithDictionaryEncodingDefault(false),
+		WithDictionaryEncoding("tag", true),
+		WithColumnEncoding("id", parquet.Encoding_DELTA_BYTE_ARRAY),
+		WithDeltaEncodingForIntegers(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"id": false, "tag": true, "n": false, "flag": false}, dicts)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_DELTA_BYTE_ARRAY}, encodings["id"])
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_DELTA_BINARY_PACKED}, encodings["n"])
+
+	// WithDictionaryEncoding takes precedence over the encoding of the column
+	_, dicts, err = writeFile(WithColumnEncoding("tag", parquet.Encoding_DELTA_BYTE_ARRAY), WithDictionaryEncoding("tag", true))
+	require.NoError(t, err)
+	require.True(t, dicts["tag"])
+
+	_, _, err = writeFile(WithDictionaryEncoding("flag", true))
+	require.EqualError(t, err, `invalid dictionary encoding of column "flag": BOOLEAN columns can't use a dictionary`)
+	_, _, err = writeFile(WithDictionaryEncoding("name", false))
+	require.EqualError(t, err, `column "name" of WithDictionaryEncoding not found`)
+}
+
+func TestWritePageCRC(t *testing.T) {
+	testFunc := func(withCRC bool, opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append(opts, WithCRC(withCRC))...)
+		as, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(as, parquet.FieldRepetitionType_OPTIONAL)))
+		bs, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("b", NewDataColumn(bs, parquet.FieldRepetitionType_REPEATED)))
+		for i := 0; i < 100; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": []byte(fmt.Sprint(i % 7)), "b": []int64{int64(i), int64(i)}}))
+		}
+		require.NoError(t, w.Close())
+
+		pages := 0
+		rewritePages(t, buf.Bytes(), func(col string, ordinal int, ph *parquet.PageHeader, body []byte) {
+			pages++
+			if !withCRC {
+				require.Nil(t, ph.Crc, "page %d of column %s", ordinal, col)
+				return
+			}
+			require.NotNil(t, ph.Crc, "page %d of column %s", ordinal, col)
+			require.Equal(t, int32(crc32.ChecksumIEEE(body)), *ph.Crc, "page %d of column %s", ordinal, col)
+		})
+		// the dictionary page and the data page of a, the data page of b
+		require.Equal(t, 3, pages)
+
+		r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithCRC32Validation(true))
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"a": []byte(fmt.Sprint(i % 7)), "b": []int64{int64(i), int64(i)}}, row)
+		}
+	}
+
+	testFunc(true)
+	testFunc(true, WithDataPageV2(), WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+	testFunc(false)
+	testFunc(false, WithDataPageV2())
+}
+
+func TestWriteMaxPageSize(t *testing.T) {
+	// 10 MiB of int64 values
+	values := make([]int64, 10*1024*1024/8)
+	for i := range values {
+		values[i] = int64(i)
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithMaxPageRows(0))
+	s, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+	require.NoError(t, w.WriteColumns(map[string]interface{}{"a": values}))
+	require.NoError(t, w.Close())
+
+	var numValues []int32
+	rewritePages(t, buf.Bytes(), func(col string, ordinal int, ph *parquet.PageHeader, body []byte) {
+		require.True(t, ph.UncompressedPageSize >= defaultMaxPageSize || len(numValues) == 9, "page %d has %d byte", ordinal, ph.UncompressedPageSize)
+		require.Equal(t, ph.UncompressedPageSize/8, ph.DataPageHeader.NumValues)
+		numValues = append(numValues, ph.DataPageHeader.NumValues)
+	})
+	require.Len(t, numValues, 10)
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	read, err := r.ReadInt64Values(0, "a", nil)
+	require.NoError(t, err)
+	require.Equal(t, values, read)
+}
+
+func TestWriteMaxPageSizeNested(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated binary tags (STRING);
+			optional double score;
+		}
+	`)
+	require.NoError(t, err)
+
+	testFunc := func(opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append(opts, WithSchemaDefinition(sd), WithMaxPageSize(512))...)
+
+		var expected []map[string]interface{}
+		for i := 0; i < 1000; i++ {
+			row := map[string]interface{}{"id": int64(i)}
+			if n := i % 5; n > 0 {
+				tags := make([][]byte, n)
+				for j := range tags {
+					tags[j] = []byte(fmt.Sprintf("tag %d", (i+j)%13))
+				}
+				row["tags"] = tags
+			}
+			if i%3 != 0 {
+				row["score"] = float64(i) / 4
+			}
+			expected = append(expected, row)
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		pages := make(map[string]int)
+		values := make(map[string]int32)
+		rows := make(map[string]int32)
+		rewritePages(t, buf.Bytes(), func(col string, ordinal int, ph *parquet.PageHeader, body []byte) {
+			switch ph.Type {
+			case parquet.PageType_DATA_PAGE:
+				values[col] += ph.DataPageHeader.NumValues
+			case parquet.PageType_DATA_PAGE_V2:
+				values[col] += ph.DataPageHeaderV2.NumValues
+				rows[col] += ph.DataPageHeaderV2.NumRows
+				require.True(t, ph.DataPageHeaderV2.NumNulls <= ph.DataPageHeaderV2.NumValues)
+			default:
+				return
+			}
+			pages[col]++
+		})
+		for _, col := range []string{"id", "tags", "score"} {
+			require.True(t, pages[col] > 1, "column %s has %d pages", col, pages[col])
+			if len(rows) > 0 {
+				require.Equal(t, int32(1000), rows[col], "rows of column %s", col)
+			}
+		}
+		require.Equal(t, map[string]int32{"id": 1000, "tags": 200 + 200*(1+2+3+4), "score": 1000}, values)
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		for i := range expected {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, expected[i], row)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+	}
+
+	testFunc()
+	testFunc(WithDataPageV2())
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithDataPageV2())
+}
+
+func TestWriteMaxPageRows(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated binary tags (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	testFunc := func(opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append(opts, WithSchemaDefinition(sd), WithMaxPageRows(100))...)
+
+		var expected []map[string]interface{}
+		for i := 0; i < 1050; i++ {
+			row := map[string]interface{}{"id": int64(i)}
+			if n := i % 4; n > 0 {
+				tags := make([][]byte, n)
+				for j := range tags {
+					tags[j] = []byte(fmt.Sprintf("tag %d", i+j))
+				}
+				row["tags"] = tags
+			}
+			expected = append(expected, row)
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		for _, cc := range r.meta.RowGroups[0].Columns {
+			index, err := readOffsetIndex(r.reader, cc)
+			require.NoError(t, err)
+			var firstRows []int64
+			for _, loc := range index.PageLocations {
+				firstRows = append(firstRows, loc.FirstRowIndex)
+			}
+			require.Equal(t, []int64{0, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}, firstRows, "column %s", cc.MetaData.PathInSchema[0])
+		}
+
+		for i := range expected {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, expected[i], row)
+		}
+	}
+
+	testFunc()
+	testFunc(WithDataPageV2())
+}
+
+func TestWriteRowGroupTargetSize(t *testing.T) {
+	require.Equal(t, int64(128*1024*1024), NewFileWriter(&bytes.Buffer{}).rowGroupFlushSize)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithRowGroupTargetSize(1024*1024))
+	ids, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("id", NewDataColumn(ids, parquet.FieldRepetitionType_REQUIRED)))
+	names, err := NewByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("name", NewDataColumn(names, parquet.FieldRepetitionType_REQUIRED)))
+
+	// every row has 8 bytes of id and 4+120 bytes of name, so a row group is full after 7944 rows
+	for i := 0; i < 30000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"id":   int64(i),
+			"name": []byte(fmt.Sprintf("%0120d", i)),
+		}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	var numRows []int64
+	for _, rg := range r.meta.RowGroups {
+		numRows = append(numRows, rg.NumRows)
+	}
+	require.Equal(t, []int64{7944, 7944, 7944, 6168}, numRows)
+
+	var first int64
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		values, err := r.ReadInt64Values(rg, "id", nil)
+		require.NoError(t, err)
+		require.Len(t, values, int(numRows[rg]))
+		require.Equal(t, first, values[0])
+		require.Equal(t, first+numRows[rg]-1, values[len(values)-1])
+
+		rows, err := r.ReadColumnRows(rg, "name", 0, 1)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("%0120d", first)), rows[0])
+		first += numRows[rg]
+	}
+}
+
+func TestWriteRowGroupRowLimit(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated binary tags (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	testFunc := func(expected []int64, opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append(opts, WithSchemaDefinition(sd))...)
+		for i := 0; i < 2500; i++ {
+			tags := make([][]byte, i%5)
+			for j := range tags {
+				tags[j] = []byte(fmt.Sprintf("%050d", i+j))
+			}
+			require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "tags": tags}))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		var numRows []int64
+		for _, rg := range r.meta.RowGroups {
+			numRows = append(numRows, rg.NumRows)
+		}
+		require.Equal(t, expected, numRows)
+
+		for i := 0; i < 2500; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, int64(i), row["id"])
+		}
+	}
+
+	// the repeated values don't count as rows
+	testFunc([]int64{1000, 1000, 500}, WithRowGroupRowLimit(1000))
+	// the size limit is reached before the row limit
+	testFunc([]int64{880, 880, 740}, WithRowGroupRowLimit(1000), WithRowGroupTargetSize(100*1024))
+	testFunc([]int64{600, 600, 600, 600, 100}, WithRowGroupRowLimit(600), WithRowGroupTargetSize(100*1024))
+}
+
+func TestWriteThenReadDeltaBinaryPacked(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+
+	s32, err := NewInt32Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("a", NewDataColumn(s32, parquet.FieldRepetitionType_REQUIRED)))
+
+	s64, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("b", NewDataColumn(s64, parquet.FieldRepetitionType_OPTIONAL)))
+
+	values := []int64{math.MinInt64, math.MaxInt64, 0, -1, math.MaxInt64 - 1, math.MinInt64 + 1}
+	for i := 0; i < 1000; i++ {
+		row := map[string]interface{}{"a": int32(i * 1000 * -1)}
+		if i%7 != 0 {
+			row["b"] = values[i%len(values)] - int64(i)
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"a": int32(i * 1000 * -1)}
+		if i%7 != 0 {
+			expected["b"] = values[i%len(values)] - int64(i)
+		}
+		require.Equal(t, expected, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteThenReadByteStreamSplit(t *testing.T) {
+	testFunc := func(opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+
+		fs, err := NewFloatStore(parquet.Encoding_BYTE_STREAM_SPLIT, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("f", NewDataColumn(fs, parquet.FieldRepetitionType_REQUIRED)))
+
+		ds, err := NewDoubleStore(parquet.Encoding_BYTE_STREAM_SPLIT, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("d", NewDataColumn(ds, parquet.FieldRepetitionType_OPTIONAL)))
+
+		var expected []map[string]interface{}
+		for i := 0; i < 500; i++ {
+			row := map[string]interface{}{"f": float32(i) / 3}
+			if i%3 != 0 {
+				row["d"] = float64(i) * -1.5
+			}
+			expected = append(expected, row)
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		for _, col := range r.meta.RowGroups[0].Columns {
+			require.Contains(t, col.MetaData.Encodings, parquet.Encoding_BYTE_STREAM_SPLIT)
+		}
+
+		for i := range expected {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, expected[i], row)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+	}
+
+	testFunc()
+	testFunc(WithDataPageV2())
+	testFunc(WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestReadTypedValues(t *testing.T) {
+	testFunc := func(opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+
+		s32, err := NewInt32Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s32, parquet.FieldRepetitionType_REQUIRED)))
+
+		s64, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("b", NewDataColumn(s64, parquet.FieldRepetitionType_OPTIONAL)))
+
+		sd, err := NewDoubleStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("c", NewDataColumn(sd, parquet.FieldRepetitionType_REQUIRED)))
+
+		sb, err := NewByteArrayStore(parquet.Encoding_DELTA_BYTE_ARRAY, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("d", NewDataColumn(sb, parquet.FieldRepetitionType_OPTIONAL)))
+
+		var (
+			as []int32
+			bs []int64
+			cs []float64
+			ds [][]byte
+		)
+		for rg := 0; rg < 2; rg++ {
+			for i := 0; i < 300; i++ {
+				row := map[string]interface{}{
+					"a": int32(i - rg*1000),
+					"c": float64(i%5) / 2,
+				}
+				as = append(as, row["a"].(int32))
+				cs = append(cs, row["c"].(float64))
+				if i%3 != 0 {
+					row["b"] = int64(i) * math.MaxInt32
+					bs = append(bs, row["b"].(int64))
+				}
+				if i%4 != 0 {
+					row["d"] = []byte(fmt.Sprintf("value %d", i))
+					ds = append(ds, row["d"].([]byte))
+				}
+				require.NoError(t, w.AddData(row))
+			}
+			require.NoError(t, w.FlushRowGroup())
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, 2, r.RowGroupCount())
+
+		var (
+			a []int32
+			b []int64
+			c []float64
+			d [][]byte
+		)
+		for rg := 0; rg < r.RowGroupCount(); rg++ {
+			a, err = r.ReadInt32Values(rg, "a", a)
+			require.NoError(t, err)
+			b, err = r.ReadInt64Values(rg, "b", b)
+			require.NoError(t, err)
+			c, err = r.ReadFloat64Values(rg, "c", c)
+			require.NoError(t, err)
+			d, err = r.ReadByteArrayValues(rg, "d", d)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, as, a)
+		assert.Equal(t, bs, b)
+		assert.Equal(t, cs, c)
+		assert.Equal(t, ds, d)
+
+		_, err = r.ReadInt64Values(0, "a", nil)
+		assert.EqualError(t, err, `column "a" is not of type INT64`)
+		_, err = r.ReadInt32Values(0, "x", nil)
+		assert.EqualError(t, err, `column "x" not found`)
+		_, err = r.ReadInt32Values(2, "a", nil)
+		assert.EqualError(t, err, "row group 2 is out of range, the file has 2 row groups")
+
+		// the typed reads don't interfere with reading the rows
+		for i := 0; i < 600; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, as[i], row["a"])
+		}
+	}
+
+	testFunc()
+	testFunc(WithDataPageV2())
+}
+
+func BenchmarkReadInt64Values(b *testing.B) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	s64, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(b, err)
+	require.NoError(b, w.AddColumn("a", NewDataColumn(s64, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 100000; i++ {
+		require.NoError(b, w.AddData(map[string]interface{}{"a": int64(i)}))
+	}
+	require.NoError(b, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(b, err)
+
+	var values []int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		values, err = r.ReadInt64Values(0, "a", values[:0])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestWriteColumns(t *testing.T) {
+	testFunc := func(opts ...FileWriterOption) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, opts...)
+
+		s32, err := NewInt32Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("a", NewDataColumn(s32, parquet.FieldRepetitionType_REQUIRED)))
+
+		s64, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("b", NewDataColumn(s64, parquet.FieldRepetitionType_REQUIRED)))
+
+		sd, err := NewDoubleStore(parquet.Encoding_BYTE_STREAM_SPLIT, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("c", NewDataColumn(sd, parquet.FieldRepetitionType_REQUIRED)))
+
+		sb, err := NewByteArrayStore(parquet.Encoding_DELTA_BYTE_ARRAY, false, &ColumnParameters{})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("d", NewDataColumn(sb, parquet.FieldRepetitionType_REQUIRED)))
+
+		typeLen := int32(2)
+		sf, err := NewFixedByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{TypeLength: &typeLen})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("e", NewDataColumn(sf, parquet.FieldRepetitionType_REQUIRED)))
+
+		// a row added the usual way ends up in its own row group
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"a": int32(-1), "b": int64(-1), "c": float64(-1), "d": []byte("row"), "e": []byte("ro"),
+		}))
+
+		const n = 1000
+		a, b, c, d, e := make([]int32, n), make([]int64, n), make([]float64, n), make([][]byte, n), make([][]byte, n)
+		for i := 0; i < n; i++ {
+			a[i] = int32(i % 10)
+			b[i] = int64(i) * math.MaxInt32
+			c[i] = float64(i) / 3
+			d[i] = []byte(fmt.Sprintf("value %d", i))
+			e[i] = []byte{byte(i), byte(i >> 8)}
+		}
+		require.NoError(t, w.WriteColumns(map[string]interface{}{"a": a, "b": b, "c": c, "d": d, "e": e}))
+
+		err = w.WriteColumns(map[string]interface{}{"a": a, "b": b, "c": c, "d": d})
+		assert.EqualError(t, err, `no values for column "e"`)
+		err = w.WriteColumns(map[string]interface{}{"a": a, "b": b, "c": c, "d": d, "e": e, "f": a})
+		assert.EqualError(t, err, `column "f" not found`)
+		err = w.WriteColumns(map[string]interface{}{"a": b, "b": b, "c": c, "d": d, "e": e})
+		assert.EqualError(t, err, `column "a": []int64 values are not supported for INT32 columns`)
+		err = w.WriteColumns(map[string]interface{}{"a": a[:10], "b": b, "c": c, "d": d, "e": e})
+		assert.EqualError(t, err, `column "b" has 1000 values, but the other columns have 10 values`)
+
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, 2, r.RowGroupCount())
+		require.Equal(t, int64(n+1), r.NumRows())
+
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		assert.Equal(t, int32(-1), row["a"])
+		for i := 0; i < n; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"a": a[i], "b": b[i], "c": c[i], "d": d[i], "e": e[i]}, row)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+
+		stats := r.meta.RowGroups[1].Columns[0].MetaData.Statistics
+		assert.Equal(t, []byte{0, 0, 0, 0}, stats.MinValue)
+		assert.Equal(t, []byte{9, 0, 0, 0}, stats.MaxValue)
+		assert.Nil(t, stats.DistinctCount)
+	}
+
+	testFunc()
+	testFunc(WithDataPageV2())
+}
+
+func TestWriteColumnsNulls(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			optional double score;
+			required group g {
+				optional int32 x;
+			}
+		}`)
+	require.NoError(t, err)
+
+	const n = 100
+	ids, names, scores, xs := make([]int64, n), make([]interface{}, n), make([]interface{}, n), make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		ids[i] = int64(i)
+		if i%3 != 0 {
+			names[i] = []byte(fmt.Sprintf("name %d", i))
+		}
+		if i%2 == 0 {
+			xs[i] = int32(i)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	columns := map[string]interface{}{"id": []interface{}{int64(0), nil}, "name": names, "score": scores, "g.x": xs}
+	err = w.WriteColumns(columns)
+	require.EqualError(t, err, `column "id": the value of row 1 is nil, but the column is required`)
+
+	columns["id"] = ids
+	columns["name"] = []interface{}{nil, "name"}
+	err = w.WriteColumns(columns)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `column "name": row 1: `)
+
+	columns["name"] = names
+	require.NoError(t, w.WriteColumns(columns))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(n), r.NumRows())
+
+	for name, nulls := range map[string]int64{"id": 0, "name": 34, "score": n, "g.x": 50} {
+		stats, err := r.ColumnStatistics(0, name)
+		require.NoError(t, err)
+		require.Equal(t, nulls, stats.NullCount, "null count of %s", name)
+	}
+
+	for i := 0; i < n; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int64(i), "g": map[string]interface{}{}}
+		if names[i] != nil {
+			expected["name"] = names[i]
+		}
+		if xs[i] != nil {
+			expected["g"] = map[string]interface{}{"x": xs[i]}
+		}
+		require.Equal(t, expected, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// a null in a column of an optional group could be a null of the group or of the column
+	sd, err = parquetschema.ParseSchemaDefinition(`
+		message test {
+			optional group o {
+				optional int32 y;
+			}
+		}`)
+	require.NoError(t, err)
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	err = w.WriteColumns(map[string]interface{}{"o.y": []interface{}{nil}})
+	require.EqualError(t, err, `column "o.y" is repeated or in an optional group, it can't be written by WriteColumns`)
+	err = w.WriteColumns(map[string]interface{}{"o.y": []int32{1}})
+	require.EqualError(t, err, `column "o.y" is optional or repeated, only required columns can be written as typed values`)
+}
+
+func BenchmarkWriteColumnsInt32(b *testing.B) {
+	values := make([]int32, 10000000)
+	for i := range values {
+		values[i] = int32(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := NewFileWriter(ioutil.Discard)
+		s32, err := NewInt32Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := w.AddColumn("a", NewDataColumn(s32, parquet.FieldRepetitionType_REQUIRED)); err != nil {
+			b.Fatal(err)
+		}
+		if err := w.WriteColumns(map[string]interface{}{"a": values}); err != nil {
+			b.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// dremelDocument returns the records of the Document example of the Dremel paper,
+// https://research.google/pubs/pub36632/
+func dremelDocument() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"DocId": int64(10),
+			"Links": map[string]interface{}{
+				"Forward": []int64{20, 40, 60},
+			},
+			"Name": []map[string]interface{}{
+				{
+					"Language": []map[string]interface{}{
+						{"Code": []byte("en-us"), "Country": []byte("us")},
+						{"Code": []byte("en")},
+					},
+					"Url": []byte("http://A"),
+				},
+				{
+					"Url": []byte("http://B"),
+				},
+				{
+					"Language": []map[string]interface{}{
+						{"Code": []byte("en-gb"), "Country": []byte("gb")},
+					},
+				},
+			},
+		},
+		{
+			"DocId": int64(20),
+			"Links": map[string]interface{}{
+				"Backward": []int64{10, 30},
+				"Forward":  []int64{80},
+			},
+			"Name": []map[string]interface{}{
+				{"Url": []byte("http://C")},
+			},
+		},
+	}
+}
+
+func TestWriteThenReadDremelDocument(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message Document {
+			required int64 DocId;
+			optional group Links {
+				repeated int64 Backward;
+				repeated int64 Forward;
+			}
+			repeated group Name {
+				repeated group Language {
+					required binary Code (STRING);
+					optional binary Country (STRING);
+				}
+				optional binary Url (STRING);
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range dremelDocument() {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	requireDremelDocument(t, r)
+}
+
+// requireDremelDocument checks that r has the column stripes of the Document example of the Dremel paper, and
+// that its rows are the records of the example.
+func requireDremelDocument(t *testing.T, r *FileReader) {
+	// the levels of the paper
+	expectedLevels := map[string][2][]int32{
+		"Name.Url":              {{0, 1, 1, 0}, {2, 2, 1, 2}},
+		"Links.Forward":         {{0, 1, 1, 0}, {2, 2, 2, 2}},
+		"Links.Backward":        {{0, 0, 1}, {1, 2, 2}},
+		"Name.Language.Code":    {{0, 2, 1, 1, 0}, {2, 2, 1, 2, 1}},
+		"Name.Language.Country": {{0, 2, 1, 1, 0}, {3, 2, 1, 3, 1}},
+	}
+	require.NoError(t, r.PreLoad())
+	for name, levels := range expectedLevels {
+		d := r.GetColumnByName(name)
+		require.Equal(t, levels[0], d.data.rLevels.toArray(), "repetition levels of %s", name)
+		require.Equal(t, levels[1], d.data.dLevels.toArray(), "definition levels of %s", name)
+	}
+
+	data := dremelDocument()
+	for i := range data {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, data[i], row)
+	}
+	_, err := r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestDremelDocumentGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. Its pages have the column
+	// stripes of the Document example in the Dremel paper, in the layout of parquet-mr with writer.version v1.
+	data, err := ioutil.ReadFile("testdata/dremel_document.parquet")
+	require.NoError(t, err)
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	requireDremelDocument(t, r)
+}
+
+func TestSparkNestedGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. It has the schema Spark
+	// writes for id long not null, a struct<b: array<struct<c: int, d: array<string>>>>, with the three-level
+	// lists of Spark, and null and empty lists and structs at every level.
+	data, err := ioutil.ReadFile("testdata/spark_nested.parquet")
+	require.NoError(t, err)
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithListsAsSlices())
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{"id": int64(1), "a": map[string]interface{}{"b": []interface{}{
+			map[string]interface{}{"c": int32(1), "d": []interface{}{[]byte("x"), []byte("y")}},
+			map[string]interface{}{"d": []interface{}{}},
+			nil,
+		}}},
+		{"id": int64(2)},
+		{"id": int64(3), "a": map[string]interface{}{}},
+		{"id": int64(4), "a": map[string]interface{}{"b": []interface{}{}}},
+		{"id": int64(5), "a": map[string]interface{}{"b": []interface{}{
+			map[string]interface{}{"c": int32(5), "d": []interface{}{[]byte("z"), nil}},
+		}}},
+	}
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteThenReadStructOfListOfStruct(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			optional group s {
+				optional group items (LIST) {
+					repeated group list {
+						optional group element {
+							optional int64 a;
+							optional binary b (STRING);
+						}
+					}
+				}
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	list := func(elements ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"s": map[string]interface{}{"items": map[string]interface{}{"list": elements}}}
+	}
+	data := []map[string]interface{}{
+		{},
+		{"s": map[string]interface{}{}},
+		{"s": map[string]interface{}{"items": map[string]interface{}{}}},
+		list(),
+		list(map[string]interface{}{}),
+		list(map[string]interface{}{"element": map[string]interface{}{}}),
+		list(
+			map[string]interface{}{"element": map[string]interface{}{"a": int64(1)}},
+			map[string]interface{}{"element": map[string]interface{}{"b": []byte("x")}},
+		),
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// a null group ends the levels at its parent, and an empty list has the level of the list group
+	expectedLevels := map[string][2][]int32{
+		"s.items.list.element.a": {{0, 0, 0, 0, 0, 0, 0, 1}, {0, 1, 2, 2, 3, 4, 5, 4}},
+		"s.items.list.element.b": {{0, 0, 0, 0, 0, 0, 0, 1}, {0, 1, 2, 2, 3, 4, 4, 5}},
+	}
+	require.NoError(t, r.PreLoad())
+	for name, levels := range expectedLevels {
+		d := r.GetColumnByName(name)
+		require.Equal(t, levels[0], d.data.rLevels.toArray(), "repetition levels of %s", name)
+		require.Equal(t, levels[1], d.data.dLevels.toArray(), "definition levels of %s", name)
+	}
+
+	// the empty list is read as a list group without elements
+	data[3] = data[2]
+	for i := range data {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, data[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteThenReadRepeatedLeaf(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated int64 nums;
+			repeated binary tags (STRING);
+		}
+	`)
+	require.NoError(t, err)
+
+	many := make([]interface{}, 1000)
+	for i := range many {
+		many[i] = int64(i)
+	}
+	data := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1), "nums": []int64{}, "tags": []interface{}{}},
+		{"id": int64(2), "nums": []int64{7}, "tags": []interface{}{[]byte("a")}},
+		{"id": int64(3), "nums": many, "tags": [][]byte{[]byte("b"), []byte("c")}},
+		{"id": int64(4), "nums": []interface{}{int64(-1)}, "tags": []byte("d")},
+		{"id": int64(5), "nums": int64(8)},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageRows(2))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	err = w.AddData(map[string]interface{}{"id": int64(6), "nums": []interface{}{int64(1), nil}})
+	require.EqualError(t, err, `invalid value of column "nums": the element 1 of the repeated value is nil`)
+	err = w.AddData(map[string]interface{}{"id": int64(6), "nums": []interface{}{int64(1), "2"}})
+	require.EqualError(t, err, `invalid value of column "nums": element 1: unsupported type for storing in int64 column: string => 2`)
+	err = w.AddData(map[string]interface{}{"id": int64(6), "nums": []interface{}{[]int64{1, 2}}})
+	require.EqualError(t, err, `invalid value of column "nums": the element 0 of the repeated value is not a single value, but a []int64`)
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), r.NumRows())
+
+	// the pages have two records each, however many values they have
+	ci, err := r.ColumnIndex(0, "nums")
+	require.NoError(t, err)
+	require.Equal(t, []int64{0, 2, 4}, ci.FirstRows)
+	require.Equal(t, []bool{true, false, false}, ci.NullPages)
+
+	manyValues := make([]int64, len(many))
+	for i := range manyValues {
+		manyValues[i] = int64(i)
+	}
+	expected := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1)},
+		{"id": int64(2), "nums": []int64{7}, "tags": [][]byte{[]byte("a")}},
+		{"id": int64(3), "nums": manyValues, "tags": [][]byte{[]byte("b"), []byte("c")}},
+		{"id": int64(4), "nums": []int64{-1}, "tags": [][]byte{[]byte("d")}},
+		{"id": int64(5), "nums": []int64{8}},
+	}
+	for i := range expected {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// the first value of a record has the repetition level 0, the others 1, and empty lists have only a definition level
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, r.PreLoad())
+	d := r.GetColumnByName("tags")
+	require.Equal(t, []int32{0, 0, 0, 0, 1, 0, 0}, d.data.rLevels.toArray())
+	require.Equal(t, []int32{0, 0, 1, 1, 1, 1, 0}, d.data.dLevels.toArray())
+}
+
+func TestWriteThenReadDeeplyNested(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional group a {
+				optional group b {
+					repeated group c {
+						optional group d {
+							repeated int32 values;
+							optional binary label (STRING);
+						}
+						required boolean flag;
+					}
+				}
+				optional double score;
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	data := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1), "a": map[string]interface{}{"score": float64(1.5)}},
+		{"id": int64(2), "a": map[string]interface{}{"b": map[string]interface{}{}}},
+		{"id": int64(3), "a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": []map[string]interface{}{
+					{"flag": true},
+					{"flag": false, "d": map[string]interface{}{"label": []byte("x")}},
+					{"flag": true, "d": map[string]interface{}{"values": []int32{1, 2, 3}}},
+				},
+			},
+			"score": float64(3),
+		}},
+		{"id": int64(4), "a": map[string]interface{}{
+			"b": map[string]interface{}{
+				"c": []map[string]interface{}{
+					{"flag": false, "d": map[string]interface{}{"values": []int32{4}, "label": []byte("y")}},
+				},
+			},
+		}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := range data {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, data[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteThenReadNestedOptionals(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			optional int64 top;
+			optional group a {
+				optional int64 av;
+				optional group b {
+					optional int64 bv;
+					optional group c {
+						optional int64 cv;
+					}
+				}
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	// a null field has no key in its group, a null group has no key in its parent
+	data := []map[string]interface{}{
+		{},
+		{"top": int64(1)},
+		{"a": map[string]interface{}{}},
+		{"a": map[string]interface{}{"av": int64(2)}},
+		{"a": map[string]interface{}{"b": map[string]interface{}{}}},
+		{"a": map[string]interface{}{"b": map[string]interface{}{"bv": int64(3)}}},
+		{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{}}}},
+		{"a": map[string]interface{}{"b": map[string]interface{}{"c": map[string]interface{}{"cv": int64(4)}}}},
+		{"top": int64(5), "a": map[string]interface{}{
+			"av": int64(6),
+			"b":  map[string]interface{}{"bv": int64(7), "c": map[string]interface{}{"cv": int64(8)}},
+		}},
+		{},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, r.PreLoad())
+
+	// the definition level tells which of the ancestors of the leaf is null
+	require.Equal(t, []int32{0, 0, 1, 1, 2, 2, 3, 4, 4, 0}, r.GetColumnByName("a.b.c.cv").data.dLevels.toArray())
+
+	for i := range data {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, data[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteThenReadRepeatedFields(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			repeated int32 values;
+			optional binary name (STRING);
+			optional group list (LIST) {
+				repeated group list {
+					optional int64 element;
+				}
+			}
+			repeated group items {
+				optional int64 a;
+				optional int64 b;
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	data := []map[string]interface{}{
+		{"id": int64(0)},
+		{"id": int64(1), "values": []int32{1}, "name": []byte("one")},
+		{"id": int64(2), "values": []int32{1, 2, 3, 4}},
+		{"id": int64(3), "list": map[string]interface{}{}},
+		{"id": int64(4), "list": map[string]interface{}{"list": []map[string]interface{}{
+			{"element": int64(1)}, {}, {"element": int64(3)},
+		}}},
+		{"id": int64(5), "list": map[string]interface{}{"list": []map[string]interface{}{{}, {}}}, "values": []int32{5, 6}},
+		{"id": int64(6), "items": []map[string]interface{}{{"a": int64(1)}, {"b": int64(2)}, {}, {"a": int64(3), "b": int64(4)}}},
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	// an empty array is written like a missing one
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":     int64(7),
+		"values": []int32{},
+		"list":   map[string]interface{}{"list": []map[string]interface{}{}},
+		"items":  []map[string]interface{}{},
+	}))
+	data = append(data, map[string]interface{}{"id": int64(7), "list": map[string]interface{}{}})
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, r.PreLoad())
+
+	// a missing list and an empty list have different definition levels
+	require.Equal(t, []int32{0, 0, 0, 1, 3, 2, 3, 2, 2, 0, 1}, r.GetColumnByName("list.list.element").data.dLevels.toArray())
+
+	for i := range data {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, data[i], row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// the elements of a repeated group are found without its first column
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "id", "items.b")
+	require.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":    int64(6),
+		"items": []map[string]interface{}{{}, {"b": int64(2)}, {}, {"b": int64(4)}},
+	}, row)
+}
+
+func TestWriteFileLayout(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithMaxPageSize(1024))
+
+	fooStore, err := NewInt64Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	barStore, err := NewByteArrayStore(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("foo", NewDataColumn(fooStore, parquet.FieldRepetitionType_REQUIRED)))
+	require.NoError(t, w.AddColumn("bar", NewDataColumn(barStore, parquet.FieldRepetitionType_OPTIONAL)))
+
+	for i := 0; i < 3000; i++ {
+		if i > 0 && i%1000 == 0 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+		row := map[string]interface{}{"foo": int64(i)}
+		if i%3 != 0 {
+			row["bar"] = []byte(fmt.Sprintf("value %d", i))
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	// the file starts and ends with the magic, the footer is before its length and the trailing magic
+	require.Equal(t, "PAR1", string(data[:4]))
+	require.Equal(t, "PAR1", string(data[len(data)-4:]))
+	footerLen := int64(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := int64(len(data)) - 8 - footerLen
+	meta, n, err := ReadFileMetaData(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, footerLen+8, n)
+	require.Equal(t, int64(3000), meta.NumRows)
+	require.Len(t, meta.RowGroups, 3)
+
+	// the column chunks follow each other, their pages fill them and have all their values
+	offset := int64(4)
+	for _, rg := range meta.RowGroups {
+		require.Equal(t, int64(1000), rg.NumRows)
+		require.Len(t, rg.Columns, 2)
+		for _, chunk := range rg.Columns {
+			md := chunk.MetaData
+			start := md.DataPageOffset
+			if md.DictionaryPageOffset != nil {
+				require.True(t, *md.DictionaryPageOffset < start)
+				start = *md.DictionaryPageOffset
+			}
+			require.Equal(t, offset, start)
+			require.Equal(t, int64(1000), md.NumValues)
+
+			r := bytes.NewReader(data[start : start+md.TotalCompressedSize])
+			var values int32
+			for r.Len() > 0 {
+				ph := &parquet.PageHeader{}
+				require.NoError(t, readThrift(ph, r))
+				if ph.DataPageHeader != nil {
+					values += ph.DataPageHeader.NumValues
+				}
+				_, err := r.Seek(int64(ph.CompressedPageSize), io.SeekCurrent)
+				require.NoError(t, err)
+			}
+			require.Equal(t, int32(1000), values)
+			offset = start + md.TotalCompressedSize
+		}
+	}
+	require.True(t, offset <= footerStart)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for i := 0; i < 3000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["foo"])
+		if i%3 == 0 {
+			require.NotContains(t, row, "bar")
+		} else {
+			require.Equal(t, []byte(fmt.Sprintf("value %d", i)), row["bar"])
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestAddDataErrors(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			optional group address {
+				required binary city (STRING);
+				optional int32 zip;
+			}
+			repeated group phones {
+				required binary number (STRING);
+			}
+			repeated int32 scores;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	tests := []struct {
+		row map[string]interface{}
+		err string
+	}{
+		{map[string]interface{}{"name": []byte("a")}, `the value "id" is required`},
+		{map[string]interface{}{"id": 1}, `invalid value of column "id": unsupported type for storing in int64 column: int => 1`},
+		{map[string]interface{}{"id": int64(1), "name": "a"}, `invalid value of column "name": unsupported type for storing in []byte column string => a`},
+		{map[string]interface{}{"id": int64(1), "address": map[string]interface{}{"zip": int32(1)}}, `the value "address.city" is required`},
+		{map[string]interface{}{"id": int64(1), "address": map[string]interface{}{"city": []byte("x"), "zip": int64(1)}}, `invalid value of column "address.zip": unsupported type for storing in int32 column: int64 => 1`},
+		{map[string]interface{}{"id": int64(1), "address": []map[string]interface{}{}}, `the value of the group "address" must be a map[string]interface{}, not a []map[string]interface {}`},
+		{map[string]interface{}{"id": int64(1), "address": map[string]string{}}, `the value of the group "address" must be a map[string]interface{}, not a map[string]string`},
+		{map[string]interface{}{"id": int64(1), "phones": map[string]interface{}{"number": []byte("1")}}, `the value of the repeated group "phones" must be a []map[string]interface{}, not a map[string]interface {}`},
+		{map[string]interface{}{"id": int64(1), "phones": "1"}, `the value of the group "phones" must be a []map[string]interface{}, not a string`},
+		{map[string]interface{}{"id": int64(1), "phones": []map[string]interface{}{{"number": []byte("1")}, {}}}, `the value "phones.number" is required`},
+		{map[string]interface{}{"id": int64(1), "scores": []int64{1}}, `invalid value of column "scores": unsupported type for storing in int32 column: []int64 => [1]`},
+		{map[string]interface{}{"id": []int64{1}}, `invalid value of column "id": the value is not repeated but it is an array`},
+	}
+
+	// the rows with errors are not added partially, the file has only the valid rows
+	valid := []map[string]interface{}{
+		{"id": int64(1)},
+		{"id": int64(2), "name": []byte("b"), "address": map[string]interface{}{"city": []byte("x")}, "phones": []map[string]interface{}{{"number": []byte("1")}}, "scores": []int32{1, 2}},
+	}
+	require.NoError(t, w.AddData(valid[0]))
+	for _, tt := range tests {
+		require.EqualError(t, w.AddData(tt.row), tt.err)
+	}
+	require.NoError(t, w.AddData(valid[1]))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumRows())
+	for _, expected := range valid {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected, row)
+	}
+}
+
+func TestWriteFlushRowGroupAndClose(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	// flushing without rows doesn't write an empty row group
+	require.NoError(t, w.FlushRowGroup())
+	require.Equal(t, int64(0), w.CurrentFileSize())
+
+	var id int64
+	for _, batch := range []int{3, 1, 5} {
+		for i := 0; i < batch; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"id": id}))
+			id++
+		}
+		require.NoError(t, w.FlushRowGroup())
+		require.NoError(t, w.FlushRowGroup())
+	}
+	require.NoError(t, w.AddData(map[string]interface{}{"id": id}))
+	require.NoError(t, w.Close())
+	size := buf.Len()
+
+	require.Equal(t, ErrWriterClosed, w.Close())
+	require.Equal(t, ErrWriterClosed, w.AddData(map[string]interface{}{"id": int64(0)}))
+	require.Equal(t, ErrWriterClosed, w.FlushRowGroup())
+	require.Equal(t, ErrWriterClosed, w.WriteColumns(map[string]interface{}{"id": []int64{0}}))
+	require.Equal(t, size, buf.Len())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, ErrWriterClosed, w.AppendRowGroupFrom(r, 0))
+	require.Equal(t, int64(10), r.NumRows())
+	var numRows []int64
+	for i := 0; i < r.RowGroupCount(); i++ {
+		n, err := r.RowGroupNumRowsAt(i)
+		require.NoError(t, err)
+		numRows = append(numRows, n)
+	}
+	require.Equal(t, []int64{3, 1, 5, 1}, numRows)
+}
+
+// streamWriter is an io.Writer that panics if it is used as an io.Seeker.
+type streamWriter struct {
+	bytes.Buffer
+}
+
+func (w *streamWriter) Seek(offset int64, whence int) (int64, error) {
+	panic("Seek called on a stream")
+}
+
+func TestWriteToStream(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			repeated int32 values;
+		}
+	`)
+	require.NoError(t, err)
+
+	writeFile := func(w io.Writer, opts ...FileWriterOption) {
+		fw := NewFileWriter(w, append([]FileWriterOption{WithSchemaDefinition(sd), WithMaxPageRows(50)}, opts...)...)
+		fw.SetKeyValueMetaData("key", "value")
+		for i := 0; i < 300; i++ {
+			row := map[string]interface{}{
+				"id":     int64(i),
+				"values": []int32{int32(i), int32(i % 3)},
+			}
+			if i%4 != 0 {
+				row["name"] = []byte(fmt.Sprintf("name %d", i%10))
+			}
+			require.NoError(t, fw.AddData(row))
+			if i%120 == 119 {
+				require.NoError(t, fw.FlushRowGroup())
+			}
+		}
+		require.NoError(t, fw.Close())
+	}
+
+	src := &bytes.Buffer{}
+	writeFile(src)
+	w := &streamWriter{}
+	writeFile(w, WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithCompressionConcurrency(4), WithDataPageV2())
+	require.Equal(t, "PAR1", string(w.Bytes()[:4]))
+
+	r, err := NewFileReader(bytes.NewReader(w.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(300), r.NumRows())
+	require.Equal(t, 3, r.RowGroupCount())
+	require.Equal(t, map[string]string{"key": "value"}, r.MetaData())
+	for i := 0; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+		require.Equal(t, []int32{int32(i), int32(i % 3)}, row["values"])
+	}
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		ci, err := r.ColumnIndex(rg, "id")
+		require.NoError(t, err)
+		require.True(t, len(ci.FirstRows) > 1)
+	}
+
+	// the row groups of another file are copied to the stream as well
+	sr, err := NewFileReader(bytes.NewReader(src.Bytes()))
+	require.NoError(t, err)
+	w = &streamWriter{}
+	fw := NewFileWriter(w, WithSchemaDefinition(sd))
+	for rg := 0; rg < sr.RowGroupCount(); rg++ {
+		require.NoError(t, fw.AppendRowGroupFrom(sr, rg))
+	}
+	require.NoError(t, fw.Close())
+	r, err = NewFileReader(bytes.NewReader(w.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(300), r.NumRows())
+	values, err := r.ReadColumnRows(2, "id", 55, 57)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(295), int64(296)}, values)
 }
@@ -0,0 +1,98 @@
+package goparquet
+
+import (
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// RowGroupInfo describes the physical layout of a row group, as it is recorded in the footer of the file.
+type RowGroupInfo struct {
+	// Index is the index of the row group in the file.
+	Index int
+	// NumRows is the number of rows in the row group.
+	NumRows int64
+	// Offset is the offset of the first page of the row group in the file.
+	Offset int64
+	// TotalByteSize is the uncompressed size of the column chunks, as written by the writer of the file.
+	TotalByteSize int64
+	// TotalCompressedSize is the size of the column chunks in the file, including the page headers.
+	TotalCompressedSize int64
+	// Columns are the column chunks of the row group, in the order of the columns in the schema.
+	Columns []*ColumnChunkInfo
+}
+
+// ColumnChunkInfo describes the physical layout of a column chunk, as it is recorded in the footer of the file.
+type ColumnChunkInfo struct {
+	// Path is the flat name of the column in dotted notation.
+	Path      string
+	Type      parquet.Type
+	Codec     parquet.CompressionCodec
+	Encodings []parquet.Encoding
+	// DataPageOffset is the offset of the first data page in the file.
+	DataPageOffset int64
+	// DictionaryPageOffset is the offset of the dictionary page in the file, or -1 if the chunk has no dictionary
+	// page.
+	DictionaryPageOffset int64
+	// TotalCompressedSize and TotalUncompressedSize are the sizes of the pages of the column chunk, including their
+	// headers.
+	TotalCompressedSize   int64
+	TotalUncompressedSize int64
+	// NumValues is the number of values of the column chunk, including the null values.
+	NumValues int64
+}
+
+// RowGroupInfo returns the layout of the row group with the index rowGroup. It is taken from the footer of the file,
+// no pages are read.
+func (f *FileReader) RowGroupInfo(rowGroup int) (*RowGroupInfo, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group %d is out of range, the file has %d row groups", rowGroup, len(f.meta.RowGroups))
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	info := &RowGroupInfo{
+		Index:         rowGroup,
+		NumRows:       rg.NumRows,
+		Offset:        -1,
+		TotalByteSize: rg.TotalByteSize,
+	}
+	var compressedSize int64
+	for i, cc := range rg.Columns {
+		meta := cc.MetaData
+		if meta == nil {
+			return nil, errors.Errorf("missing meta data of column chunk %d", i)
+		}
+
+		chunk := &ColumnChunkInfo{
+			Path:                  strings.Join(meta.PathInSchema, "."),
+			Type:                  meta.Type,
+			Codec:                 meta.Codec,
+			Encodings:             meta.Encodings,
+			DataPageOffset:        meta.DataPageOffset,
+			DictionaryPageOffset:  -1,
+			TotalCompressedSize:   meta.TotalCompressedSize,
+			TotalUncompressedSize: meta.TotalUncompressedSize,
+			NumValues:             meta.NumValues,
+		}
+		if meta.DictionaryPageOffset != nil {
+			chunk.DictionaryPageOffset = *meta.DictionaryPageOffset
+		}
+		info.Columns = append(info.Columns, chunk)
+
+		if start := chunkStart(meta); info.Offset < 0 || start < info.Offset {
+			info.Offset = start
+		}
+		compressedSize += meta.TotalCompressedSize
+	}
+
+	info.TotalCompressedSize = compressedSize
+	if rg.TotalCompressedSize != nil {
+		info.TotalCompressedSize = *rg.TotalCompressedSize
+	}
+	if rg.FileOffset != nil {
+		info.Offset = *rg.FileOffset
+	}
+
+	return info, nil
+}
@@ -0,0 +1,87 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowGroupInfo(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			required group meta {
+				optional binary kind (STRING);
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithRowGroupRowLimit(600))
+	for i := 0; i < 1000; i++ {
+		row := map[string]interface{}{"id": int64(i), "meta": map[string]interface{}{}}
+		if i%4 != 0 {
+			row["meta"] = map[string]interface{}{"kind": []byte(fmt.Sprintf("kind %d", i%3))}
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+	data := buf.Bytes()
+
+	// the reader only reads the footer
+	rr := &readRecorder{Reader: bytes.NewReader(data)}
+	r, err := NewFileReader(rr)
+	require.NoError(t, err)
+	footerReads := len(rr.ranges)
+
+	var offset int64 = 4
+	for rg := 0; rg < 2; rg++ {
+		info, err := r.RowGroupInfo(rg)
+		require.NoError(t, err)
+		require.Equal(t, rg, info.Index)
+		require.Equal(t, []int64{600, 400}[rg], info.NumRows)
+		require.Equal(t, offset, info.Offset)
+		require.Len(t, info.Columns, 2)
+
+		id, kind := info.Columns[0], info.Columns[1]
+		require.Equal(t, "id", id.Path)
+		require.Equal(t, parquet.Type_INT64, id.Type)
+		require.Equal(t, parquet.CompressionCodec_SNAPPY, id.Codec)
+		require.Equal(t, int64(-1), id.DictionaryPageOffset)
+		require.Equal(t, offset, id.DataPageOffset)
+		require.Equal(t, info.NumRows, id.NumValues)
+
+		require.Equal(t, "meta.kind", kind.Path)
+		require.Equal(t, parquet.Type_BYTE_ARRAY, kind.Type)
+		require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN_DICTIONARY}, kind.Encodings)
+		require.Equal(t, offset+id.TotalCompressedSize, kind.DictionaryPageOffset)
+		require.True(t, kind.DataPageOffset > kind.DictionaryPageOffset)
+		require.Equal(t, info.NumRows, kind.NumValues)
+
+		require.Equal(t, id.TotalCompressedSize+kind.TotalCompressedSize, info.TotalCompressedSize)
+		require.Equal(t, id.TotalUncompressedSize+kind.TotalUncompressedSize, info.TotalByteSize)
+		offset += info.TotalCompressedSize
+	}
+	require.Equal(t, footerReads, len(rr.ranges))
+
+	_, err = r.RowGroupInfo(2)
+	require.EqualError(t, err, "row group 2 is out of range, the file has 2 row groups")
+
+	// files without the optional fields of the row group get them from the column chunks
+	data = rewriteFooter(t, data, func(meta *parquet.FileMetaData) {
+		for _, rg := range meta.RowGroups {
+			rg.FileOffset, rg.TotalCompressedSize = nil, nil
+		}
+	})
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	info, err := r.RowGroupInfo(1)
+	require.NoError(t, err)
+	require.Equal(t, r.meta.RowGroups[1].Columns[0].MetaData.DataPageOffset, info.Offset)
+	require.Equal(t, r.meta.RowGroups[1].Columns[0].MetaData.TotalCompressedSize+r.meta.RowGroups[1].Columns[1].MetaData.TotalCompressedSize, info.TotalCompressedSize)
+}
@@ -0,0 +1,101 @@
+package goparquet
+
+import (
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// RowGroupReader reads the rows of a single row group of a parquet file. It has its own schema with its own column
+// data and its own row cursor, so it is independent of the row based reading of the FileReader and of other
+// RowGroupReaders. Always use FileReader.RowGroup to create such an object.
+type RowGroupReader struct {
+	SchemaReader
+	reader   io.ReadSeeker
+	rowGroup *parquet.RowGroup
+	index    int
+	firstRow int64
+
+	loaded        bool
+	currentRecord int64
+
+	validateCRC     bool
+	readConcurrency int
+}
+
+// RowGroup returns a reader for the row group with the index i, with the same selected columns as the FileReader.
+// The row group is read into memory with the first call of NextRow or PreLoad. If the FileReader was created with
+// an io.ReadSeeker that is also an io.ReaderAt, e.g. an *os.File or a *bytes.Reader, the RowGroupReader reads
+// through its own io.SectionReader, and RowGroupReaders of different row groups can be used concurrently, e.g. to
+// distribute the row groups across goroutines. Otherwise they share the reader of the FileReader and must not be
+// used concurrently with each other or with the FileReader.
+func (f *FileReader) RowGroup(i int) (*RowGroupReader, error) {
+	if i < 0 || i >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group %d is out of range, the file has %d row groups", i, len(f.meta.RowGroups))
+	}
+
+	schema, err := makeSchema(f.meta)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating schema failed")
+	}
+	schema.setSelectedColumns(f.columns...)
+	schema.setGroupConversion(f.conversion)
+
+	return &RowGroupReader{
+		SchemaReader:    schema,
+		reader:          f.sectionReader(),
+		rowGroup:        f.meta.RowGroups[i],
+		index:           i,
+		firstRow:        f.firstRow(i),
+		validateCRC:     f.validateCRC,
+		readConcurrency: f.readConcurrency,
+	}, nil
+}
+
+// Index returns the index of the row group in the file.
+func (rg *RowGroupReader) Index() int {
+	return rg.index
+}
+
+// MetaData returns the meta data of the row group.
+func (rg *RowGroupReader) MetaData() *parquet.RowGroup {
+	return rg.rowGroup
+}
+
+// NumRows returns the number of rows in the row group. This information is directly taken from the file's meta
+// data.
+func (rg *RowGroupReader) NumRows() int64 {
+	return rg.rowGroup.NumRows
+}
+
+// PreLoad reads the row group into memory. It does nothing if the row group is already loaded.
+func (rg *RowGroupReader) PreLoad() error {
+	if rg.loaded {
+		return nil
+	}
+	if err := readRowGroupWithConcurrency(rg.reader, rg.SchemaReader, rg.rowGroup, rg.validateCRC, 0, rg.readConcurrency); err != nil {
+		return errors.Wrapf(err, "reading row group %d failed", rg.index)
+	}
+	rg.loaded = true
+	return nil
+}
+
+// NextRow reads the next row of the row group, like FileReader.NextRow. It returns io.EOF after the last row, other
+// errors contain the index of the row in the file.
+func (rg *RowGroupReader) NextRow() (map[string]interface{}, error) {
+	if err := rg.PreLoad(); err != nil {
+		return nil, err
+	}
+	if rg.currentRecord >= rg.SchemaReader.rowGroupNumRecords() {
+		return nil, io.EOF
+	}
+
+	row := rg.firstRow + rg.currentRecord
+	rg.currentRecord++
+	data, err := rg.SchemaReader.getData()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading row %d failed", row)
+	}
+	return data, nil
+}
@@ -0,0 +1,105 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowGroupReader(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			repeated int32 nums;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(300))
+	var expected []map[string]interface{}
+	for i := 0; i < 1000; i++ {
+		row := map[string]interface{}{"id": int64(i)}
+		if i%3 != 0 {
+			row["name"] = []byte(fmt.Sprintf("name %d", i))
+		}
+		if n := i % 4; n > 0 {
+			nums := make([]int32, n)
+			for j := range nums {
+				nums[j] = int32(i + j)
+			}
+			row["nums"] = nums
+		}
+		require.NoError(t, w.AddData(row))
+		expected = append(expected, row)
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 4, r.RowGroupCount())
+
+	// the whole file iteration is not affected by the row group readers
+	first, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, expected[0], first)
+
+	rows := make([][]map[string]interface{}, r.RowGroupCount())
+	errs := make([]error, r.RowGroupCount())
+	var wg sync.WaitGroup
+	for i := range rows {
+		rg, err := r.RowGroup(i)
+		require.NoError(t, err)
+		require.Equal(t, i, rg.Index())
+		wg.Add(1)
+		go func(i int, rg *RowGroupReader) {
+			defer wg.Done()
+			for {
+				row, err := rg.NextRow()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				rows[i] = append(rows[i], row)
+			}
+		}(i, rg)
+	}
+	wg.Wait()
+
+	var start int64
+	for i := range rows {
+		require.NoError(t, errs[i])
+		numRows := r.meta.RowGroups[i].NumRows
+		require.Equal(t, expected[start:start+numRows], rows[i], "row group %d", i)
+		start += numRows
+	}
+	require.Equal(t, int64(1000), start)
+
+	second, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, expected[1], second)
+
+	_, err = r.RowGroup(4)
+	require.EqualError(t, err, "row group 4 is out of range, the file has 4 row groups")
+	_, err = r.RowGroup(-1)
+	require.Error(t, err)
+
+	// the row group readers have the selected columns of the file reader
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "id")
+	require.NoError(t, err)
+	rg, err := r.RowGroup(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), rg.NumRows())
+	row, err := rg.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": int64(900)}, row)
+}
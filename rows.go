@@ -0,0 +1,293 @@
+package goparquet
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// Rows is a cursor over the rows of a file, like the rows of database/sql. Always use FileReader.Rows to create such
+// an object.
+//
+//	rows, err := r.Rows("id", "name")
+//	...
+//	for rows.Next() {
+//		var id int64
+//		var name sql.NullString
+//		if err := rows.Scan(&id, &name); err != nil {
+//			...
+//		}
+//	}
+//	if err := rows.Err(); err != nil {
+//		...
+//	}
+type Rows struct {
+	r       *FileReader
+	columns []*Column
+	row     map[string]interface{}
+	err     error
+}
+
+// Rows returns a cursor over the rows of the file, starting with the next row of NextRow, which it uses to read
+// them. Scan reads the values of the columns, the columns with values in schema order if no columns are given. The
+// columns are given by their flat names in dotted notation, they must be selected in the FileReader and must not
+// be in a repeated group.
+func (f *FileReader) Rows(columns ...string) (*Rows, error) {
+	rows := &Rows{r: f}
+	if len(columns) == 0 {
+		// the columns themselves, their flat names are ambiguous if names contain dots
+		for _, col := range f.SchemaReader.Columns() {
+			if f.SchemaReader.isSelected(col.FlatName()) {
+				rows.columns = append(rows.columns, col)
+			}
+		}
+		return rows, nil
+	}
+
+	for _, name := range columns {
+		col := f.SchemaReader.GetColumnByName(name)
+		if col == nil {
+			return nil, errors.Errorf("column %q not found", name)
+		}
+		if !f.SchemaReader.isSelected(name) {
+			return nil, errors.Errorf("column %q is not selected", name)
+		}
+		rows.columns = append(rows.columns, col)
+	}
+
+	return rows, nil
+}
+
+// Columns returns the flat names of the columns of Scan.
+func (rows *Rows) Columns() []string {
+	names := make([]string, len(rows.columns))
+	for i, col := range rows.columns {
+		names[i] = col.FlatName()
+	}
+	return names
+}
+
+// Next reads the next row for Scan. It returns false after the last row or if reading the row failed, Err returns
+// the error in that case.
+func (rows *Rows) Next() bool {
+	if rows.err != nil {
+		return false
+	}
+
+	row, err := rows.r.NextRow()
+	if err != nil {
+		rows.row = nil
+		if err != io.EOF {
+			rows.err = err
+		}
+		return false
+	}
+	rows.row = row
+	return true
+}
+
+// Err returns the error that ended the iteration, or nil if it ended after the last row.
+func (rows *Rows) Err() error {
+	return rows.err
+}
+
+// Scan copies the values of the columns of the current row into the values pointed at by dest, in the order of the
+// columns. A value can be scanned into:
+//   - a *interface{}, which gets the value as NextRow returns it
+//   - a sql.Scanner like sql.NullString or sql.NullInt64, which gets the value as a driver.Value
+//   - a *string or *[]byte for BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns, the []byte is a copy
+//   - a *string for a Decimal of WithDecimalsAsDecimal, which gets its String
+//   - a *time.Time for INT32 columns with the DATE type, INT64 columns with the TIMESTAMP type and INT96 columns
+//   - a pointer to a Go type that the value is assignable to, e.g. *int64 for INT64 columns, or a wider type of the
+//     same kind, e.g. *int64 for INT32 and *float64 for FLOAT columns
+//
+// Null values can only be scanned into a *interface{}, a sql.Scanner or a pointer to a pointer like **string, which
+// is set to nil.
+func (rows *Rows) Scan(dest ...interface{}) error {
+	if rows.row == nil {
+		return errors.New("Scan called without a successful call of Next")
+	}
+	if len(dest) != len(rows.columns) {
+		return errors.Errorf("expected %d destination arguments in Scan, not %d", len(rows.columns), len(dest))
+	}
+
+	for i, col := range rows.columns {
+		v, err := columnValue(rows.row, col.pathArray())
+		if err != nil {
+			return err
+		}
+		if err := scanValue(dest[i], v, col.Element()); err != nil {
+			return errors.Wrapf(err, "scanning column %q failed", col.FlatName())
+		}
+	}
+	return nil
+}
+
+// columnValue returns the value of the column path in the row, or nil if it is null.
+func columnValue(row map[string]interface{}, path []string) (interface{}, error) {
+	var v interface{} = row
+	for i, name := range path {
+		group, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("column %q is in the repeated group %q", strings.Join(path, "."), strings.Join(path[:i], "."))
+		}
+		if v, ok = group[name]; !ok {
+			return nil, nil
+		}
+	}
+	return v, nil
+}
+
+func scanValue(dest, v interface{}, elem *parquet.SchemaElement) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = v
+		return nil
+	case sql.Scanner:
+		return d.Scan(driverValue(v, elem))
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return errors.Errorf("destination %T is not a pointer", dest)
+	}
+	target := dv.Elem()
+	if target.Kind() == reflect.Ptr {
+		if v == nil {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		value := reflect.New(target.Type().Elem())
+		if err := assignValue(value, v, elem); err != nil {
+			return err
+		}
+		target.Set(value)
+		return nil
+	}
+
+	if v == nil {
+		return errors.Errorf("the value is null, but %T can't be null", dest)
+	}
+	return assignValue(dv, v, elem)
+}
+
+// assignValue assigns the value v of a column elem to the value the pointer dest points at.
+func assignValue(dest reflect.Value, v interface{}, elem *parquet.SchemaElement) error {
+	target := dest.Elem()
+	switch d := dest.Interface().(type) {
+	case *string:
+		switch x := v.(type) {
+		case []byte:
+			*d = string(x)
+			return nil
+		case Decimal:
+			*d = x.String()
+			return nil
+		}
+	case *[]byte:
+		if b, ok := v.([]byte); ok {
+			*d = append([]byte(nil), b...)
+			return nil
+		}
+	case *time.Time:
+		if t, ok := timeValue(v, elem); ok {
+			*d = t
+			return nil
+		}
+	}
+
+	value := reflect.ValueOf(v)
+	switch {
+	case value.Type().AssignableTo(target.Type()):
+		target.Set(value)
+		return nil
+	case isIntKind(value.Kind()) && isIntKind(target.Kind()) && value.Type().Bits() <= target.Type().Bits():
+		target.SetInt(value.Int())
+		return nil
+	case isUintKind(value.Kind()) && isUintKind(target.Kind()) && value.Type().Bits() <= target.Type().Bits():
+		target.SetUint(value.Uint())
+		return nil
+	case value.Kind() == reflect.Float32 && target.Kind() == reflect.Float64:
+		target.SetFloat(value.Float())
+		return nil
+	}
+	return errors.Errorf("can't scan a value of type %T into %s", v, dest.Type())
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k == reflect.Int || k == reflect.Int8 || k == reflect.Int16 || k == reflect.Int32 || k == reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k == reflect.Uint || k == reflect.Uint8 || k == reflect.Uint16 || k == reflect.Uint32 || k == reflect.Uint64
+}
+
+// driverValue converts the value v of a column elem to the types of driver.Value. A Decimal is converted to its
+// string, as database/sql drivers return decimals, and so is a uint64 that doesn't fit into an int64.
+func driverValue(v interface{}, elem *parquet.SchemaElement) driver.Value {
+	if t, ok := timeValue(v, elem); ok {
+		return t
+	}
+	switch x := v.(type) {
+	case Decimal:
+		return x.String()
+	case int32:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case uint64:
+		if x > math.MaxInt64 {
+			return strconv.FormatUint(x, 10)
+		}
+		return int64(x)
+	case float32:
+		return float64(x)
+	}
+	return v
+}
+
+// timeValue converts the value v of an INT32 DATE, INT64 TIMESTAMP or INT96 column elem to a time.Time. The time is
+// always in UTC: a timestamp that is not adjusted to UTC stores the local wall clock time as if it was UTC, so it
+// keeps its wall clock time that way.
+func timeValue(v interface{}, elem *parquet.SchemaElement) (time.Time, bool) {
+	lt := elem.GetLogicalType()
+	switch x := v.(type) {
+	case int32:
+		if (lt != nil && lt.IsSetDATE()) || (elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_DATE) {
+			return time.Unix(0, 0).UTC().AddDate(0, 0, int(x)), true
+		}
+	case int64:
+		var unit time.Duration
+		switch {
+		case lt != nil && lt.IsSetTIMESTAMP():
+			switch {
+			case lt.TIMESTAMP.Unit.IsSetMILLIS():
+				unit = time.Millisecond
+			case lt.TIMESTAMP.Unit.IsSetMICROS():
+				unit = time.Microsecond
+			case lt.TIMESTAMP.Unit.IsSetNANOS():
+				unit = time.Nanosecond
+			}
+		case elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_TIMESTAMP_MILLIS:
+			unit = time.Millisecond
+		case elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_TIMESTAMP_MICROS:
+			unit = time.Microsecond
+		}
+		if unit == 0 {
+			return time.Time{}, false
+		}
+		perSecond := int64(time.Second / unit)
+		return time.Unix(x/perSecond, (x%perSecond)*int64(unit)).UTC(), true
+	case [12]byte:
+		return Int96ToTime(x), true
+	}
+	return time.Time{}, false
+}
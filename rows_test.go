@@ -0,0 +1,192 @@
+package goparquet
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowsScan(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			required int32 day (DATE);
+			optional int64 ts (TIMESTAMP(MICROS, true));
+			required float score;
+			optional group meta {
+				required int32 version;
+				repeated binary tags (STRING);
+			}
+			repeated group items {
+				required int32 n;
+			}
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithRowGroupRowLimit(3))
+	base := time.Date(2021, 3, 4, 5, 6, 7, 8000, time.UTC)
+	for i := 0; i < 10; i++ {
+		row := map[string]interface{}{
+			"id":    int64(i),
+			"day":   int32(18000 + i),
+			"score": float32(i) / 2,
+		}
+		if i%2 == 0 {
+			row["name"] = []byte(fmt.Sprintf("name %d", i))
+			row["ts"] = base.Add(time.Duration(i)*time.Hour).UnixNano() / 1000
+			row["meta"] = map[string]interface{}{"version": int32(i), "tags": [][]byte{[]byte("a")}}
+		}
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rows, err := r.Rows("id", "name", "day", "ts", "score", "meta.version")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "day", "ts", "score", "meta.version"}, rows.Columns())
+	require.EqualError(t, rows.Scan(), "Scan called without a successful call of Next")
+
+	var i int
+	for ; rows.Next(); i++ {
+		var (
+			id      int64
+			name    sql.NullString
+			day     time.Time
+			ts      *time.Time
+			score   float64
+			version *int
+		)
+		require.NoError(t, rows.Scan(&id, &name, &day, &ts, &score, &version))
+		require.Equal(t, int64(i), id)
+		require.Equal(t, float64(i)/2, score)
+		require.Equal(t, time.Date(2019, 4, 14, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i), day)
+		if i%2 == 0 {
+			require.Equal(t, sql.NullString{String: fmt.Sprintf("name %d", i), Valid: true}, name)
+			require.Equal(t, base.Add(time.Duration(i)*time.Hour), *ts)
+			require.Equal(t, i, *version)
+		} else {
+			require.False(t, name.Valid)
+			require.Nil(t, ts)
+			require.Nil(t, version)
+
+			var s string
+			err := rows.Scan(&id, &s, &day, &ts, &score, &version)
+			require.EqualError(t, err, `scanning column "name" failed: the value is null, but *string can't be null`)
+		}
+
+		var (
+			raw     interface{}
+			nullDay sql.NullTime
+			nullID  sql.NullInt64
+			small   int32
+		)
+		require.NoError(t, rows.Scan(&nullID, &raw, &nullDay, &raw, &raw, &raw))
+		require.Equal(t, sql.NullInt64{Int64: int64(i), Valid: true}, nullID)
+		require.Equal(t, sql.NullTime{Time: day, Valid: true}, nullDay)
+		err := rows.Scan(&small, &raw, &raw, &raw, &raw, &raw)
+		require.EqualError(t, err, `scanning column "id" failed: can't scan a value of type int64 into *int32`)
+		require.EqualError(t, rows.Scan(&id), "expected 6 destination arguments in Scan, not 1")
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, 10, i)
+	require.False(t, rows.Next())
+
+	// all columns in schema order
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "id", "name", "meta")
+	require.NoError(t, err)
+	rows, err = r.Rows()
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "meta.version", "meta.tags"}, rows.Columns())
+	require.True(t, rows.Next())
+	var (
+		id      int64
+		name    []byte
+		version int32
+		tags    interface{}
+	)
+	require.NoError(t, rows.Scan(&id, &name, &version, &tags))
+	require.Equal(t, []byte("name 0"), name)
+	require.Equal(t, [][]byte{[]byte("a")}, tags)
+
+	_, err = r.Rows("score")
+	require.EqualError(t, err, `column "score" is not selected`)
+	_, err = r.Rows("missing")
+	require.EqualError(t, err, `column "missing" not found`)
+
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rows, err = r.Rows("items.n")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.True(t, rows.Next())
+	var n interface{}
+	require.NoError(t, rows.Scan(&n))
+	require.Nil(t, n)
+}
+
+func TestRowsScanLocalTimestamp(t *testing.T) {
+	// the wall clock time of a timestamp that is not adjusted to UTC doesn't depend on the local time zone
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("UTC+5", 5*3600)
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 local (TIMESTAMP(MILLIS, false));
+		required int64 utc (TIMESTAMP(MILLIS, true));
+	}`)
+	require.NoError(t, err)
+
+	wallClock := time.Date(2021, 3, 4, 5, 6, 7, 8000000, time.UTC)
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	ms := wallClock.UnixNano() / int64(time.Millisecond)
+	require.NoError(t, w.AddData(map[string]interface{}{"local": ms, "utc": ms}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rows, err := r.Rows("local", "utc")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	var (
+		local, utc         time.Time
+		nullLocal, nullUTC sql.NullTime
+	)
+	require.NoError(t, rows.Scan(&local, &utc))
+	require.Equal(t, wallClock, local)
+	require.Equal(t, wallClock, utc)
+	require.NoError(t, rows.Scan(&nullLocal, &nullUTC))
+	require.Equal(t, sql.NullTime{Time: wallClock, Valid: true}, nullLocal)
+	require.Equal(t, sql.NullTime{Time: wallClock, Valid: true}, nullUTC)
+}
+
+func TestDriverValue(t *testing.T) {
+	elem := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64)}
+	for _, tt := range []struct {
+		v        interface{}
+		expected driver.Value
+	}{
+		{int32(-1), int64(-1)},
+		{uint32(math.MaxUint32), int64(math.MaxUint32)},
+		{uint64(42), int64(42)},
+		{uint64(math.MaxInt64), int64(math.MaxInt64)},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{float32(0.5), float64(0.5)},
+		{[]byte("a"), []byte("a")},
+	} {
+		v := driverValue(tt.v, elem)
+		require.Equal(t, tt.expected, v, "%T %v", tt.v, tt.v)
+		require.True(t, driver.IsValue(v), "%T is not a driver.Value", v)
+	}
+}
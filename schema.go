@@ -40,19 +40,23 @@ type Column struct {
 	params *ColumnParameters
 }
 
-// Children returns the column's child columns.
+// Children returns the column's child columns and groups, in schema order, if the column is a group.
 func (c *Column) Children() []*Column {
 	return c.children
 }
 
 func (c *Column) pathArray() []string {
-	if c.nameArray == nil {
-		c.nameArray = strings.Split(c.flatName, ".")
-	}
-
 	return c.nameArray
 }
 
+// childPath returns the path of the child name of the column with the path parent.
+func childPath(parent []string, name string) []string {
+	path := make([]string, len(parent)+1)
+	copy(path, parent)
+	path[len(parent)] = name
+	return path
+}
+
 func (c *Column) getSchemaArray() []*parquet.SchemaElement {
 	ret := []*parquet.SchemaElement{c.Element()}
 	if c.data != nil {
@@ -81,12 +85,20 @@ func (c *Column) FlatName() string {
 	return c.flatName
 }
 
+// Path returns the names of the column and its parents. Unlike the FlatName, it is unambiguous for names that
+// contain dots.
+func (c *Column) Path() []string {
+	return append([]string(nil), c.nameArray...)
+}
+
 // Name returns the column name.
 func (c *Column) Name() string {
 	return c.name
 }
 
-// Index returns the index of the column in schema, zero based.
+// Index returns the index of the column in schema, zero based. Only data columns have an index,
+// which is their position in the depth-first order of the schema, and so of the column chunks
+// in the row groups. GetColumnByIndex returns the column with an index.
 func (c *Column) Index() int {
 	return c.index
 }
@@ -110,6 +122,12 @@ func (c *Column) Type() *parquet.Type {
 	return parquet.TypePtr(c.data.parquetType())
 }
 
+// LogicalType returns the logical type of the column or group, or nil if it has none. The converted type of its
+// Element is the equivalent legacy annotation, if there is one.
+func (c *Column) LogicalType() *parquet.LogicalType {
+	return c.Element().LogicalType
+}
+
 // RepetitionType returns the repetition type for the current column.
 func (c *Column) RepetitionType() *parquet.FieldRepetitionType {
 	return &c.rep
@@ -145,6 +163,10 @@ func (c *Column) buildElement() *parquet.SchemaElement {
 		elem.FieldID = c.params.FieldID
 		elem.ConvertedType = c.params.ConvertedType
 		elem.LogicalType = c.params.LogicalType
+		if elem.ConvertedType == nil {
+			// readers that don't know logical types need the equivalent converted type
+			elem.ConvertedType = parquetschema.ConvertedTypeOf(elem.LogicalType)
+		}
 	}
 
 	if c.data != nil {
@@ -152,6 +174,14 @@ func (c *Column) buildElement() *parquet.SchemaElement {
 		elem.TypeLength = c.params.TypeLength
 		elem.Scale = c.params.Scale
 		elem.Precision = c.params.Precision
+		if lt := elem.LogicalType; lt != nil && lt.IsSetDECIMAL() {
+			if elem.Scale == nil {
+				elem.Scale = &lt.DECIMAL.Scale
+			}
+			if elem.Precision == nil {
+				elem.Precision = &lt.DECIMAL.Precision
+			}
+		}
 	} else {
 		nc := int32(len(c.children))
 		elem.NumChildren = &nc
@@ -160,12 +190,21 @@ func (c *Column) buildElement() *parquet.SchemaElement {
 	return elem
 }
 
+// getDataSize returns the estimated size of the column data when it is PLAIN encoded, including the repetition and
+// definition levels.
 func (c *Column) getDataSize() int64 {
-	if _, ok := c.data.typedColumnStore.(*booleanStore); ok {
+	size := c.data.values.size
+	switch s := c.data.typedColumnStore.(type) {
+	case *booleanStore:
 		// Booleans are stored in one bit, so the result is the number of items / 8
-		return int64(c.data.values.numValues())/8 + 1
+		size = int64(c.data.values.numValues())/8 + 1
+	case *byteArrayStore:
+		// every value that is not fixed size is prefixed by its length
+		if s.TypeLength == nil || *s.TypeLength <= 0 {
+			size += 4 * int64(c.data.values.numValues())
+		}
 	}
-	return c.data.values.size
+	return size + c.data.rLevels.byteSize() + c.data.dLevels.byteSize()
 }
 
 func (c *Column) getNextData() (map[string]interface{}, int32, error) {
@@ -232,6 +271,26 @@ func (c *Column) getFirstRDLevel() (int32, int32, bool) {
 	return -1, -1, false
 }
 
+// getNextRLevel returns the rLevel of the next value of the group. All the columns of the group are at the same
+// position, so the first selected column tells it. It returns true if there are no values left.
+func (c *Column) getNextRLevel() (int32, bool) {
+	if c.data != nil {
+		// a column that is not selected has no levels
+		if c.data.skipped {
+			return -1, false
+		}
+		rl, _, last := c.data.getRDLevelAt(-1)
+		return rl, last
+	}
+
+	for i := range c.children {
+		if rl, last := c.children[i].getNextRLevel(); last || rl >= 0 {
+			return rl, last
+		}
+	}
+	return -1, false
+}
+
 func (c *Column) getData() (interface{}, int32, error) {
 	if c.children != nil {
 		data, maxD, err := c.getNextData()
@@ -245,7 +304,7 @@ func (c *Column) getData() (interface{}, int32, error) {
 
 		ret := []map[string]interface{}{data}
 		for {
-			rl, _, last := c.getFirstRDLevel()
+			rl, last := c.getNextRLevel()
 			if last || rl < int32(c.maxR) || rl == 0 {
 				// end of this object
 				return ret, maxD, nil
@@ -271,21 +330,50 @@ type schema struct {
 
 	// selected columns in reading. if the size is zero, it means all the columns
 	selectedColumn []string
+	// conversion of the values of LIST and MAP groups and INT96 columns in reading
+	conversion groupConversion
+	// row has the converted values of the row that AddData adds, it is reused for the next row
+	row rowValues
+}
+
+// rowValues are the values of the data columns of a row as checkColumnData converted them, in the order in which
+// recursiveAddColumnData adds them, so that they are only converted once.
+type rowValues struct {
+	values [][]interface{}
+	pos    int
+}
+
+func (rv *rowValues) reset() {
+	for i := range rv.values {
+		rv.values[i] = nil
+	}
+	rv.values = rv.values[:0]
+	rv.pos = 0
+}
+
+func (rv *rowValues) next() []interface{} {
+	vals := rv.values[rv.pos]
+	rv.pos++
+	return vals
 }
 
 func (r *schema) ensureRoot() {
 	if r.root == nil {
-		r.root = &Column{
-			index:    0,
-			name:     "msg",
-			flatName: "", // the flat name for root element is empty
-			data:     nil,
-			children: []*Column{},
-			rep:      0,
-			maxR:     0,
-			maxD:     0,
-			element:  nil,
-		}
+		r.root = newRootColumn()
+	}
+}
+
+func newRootColumn() *Column {
+	return &Column{
+		index:    0,
+		name:     "msg",
+		flatName: "", // the flat name for root element is empty
+		data:     nil,
+		children: []*Column{},
+		rep:      0,
+		maxR:     0,
+		maxD:     0,
+		element:  nil,
 	}
 }
 
@@ -293,6 +381,10 @@ func (r *schema) setSelectedColumns(selected ...string) {
 	r.selectedColumn = selected
 }
 
+func (r *schema) setGroupConversion(gc groupConversion) {
+	r.conversion = gc
+}
+
 func (r *schema) isSelected(path string) bool {
 	if len(r.selectedColumn) == 0 {
 		return true
@@ -319,6 +411,14 @@ func (r *schema) getSchemaArray() []*parquet.SchemaElement {
 	return elem
 }
 
+func (r *schema) Root() *Column {
+	if r.root == nil {
+		// the root of an empty schema is only created by the first column
+		return newRootColumn()
+	}
+	return r.root
+}
+
 func (r *schema) Columns() []*Column {
 	var ret []*Column
 	var fn func([]*Column)
@@ -348,6 +448,50 @@ func (r *schema) GetColumnByName(path string) *Column {
 	return nil
 }
 
+func (r *schema) NumColumns() int {
+	return len(r.Columns())
+}
+
+func (r *schema) GetColumnByIndex(i int) *Column {
+	data := r.Columns()
+	if i < 0 || i >= len(data) {
+		return nil
+	}
+	return data[i]
+}
+
+func (r *schema) GetColumnByPath(path ...string) *Column {
+	data := r.Columns()
+	for i := range data {
+		if pathEqual(data[i].nameArray, path) {
+			return data[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *schema) ColumnPaths() [][]string {
+	data := r.Columns()
+	ret := make([][]string, 0, len(data))
+	for i := range data {
+		ret = append(ret, data[i].Path())
+	}
+	return ret
+}
+
+func pathEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // resetData is useful for resetting data after writing a chunk, to collect data for the next chunk
 func (r *schema) resetData() {
 	data := r.Columns()
@@ -396,8 +540,9 @@ func (r *schema) SetSchemaDefinition(sd *parquetschema.SchemaDefinition) error {
 	r.root = root
 
 	for _, c := range r.root.children {
-		recursiveFix(c, "", 0, 0)
+		recursiveFix(c, nil, 0, 0)
 	}
+	r.sortIndex()
 
 	return nil
 }
@@ -439,35 +584,42 @@ func createColumnFromColumnDefinition(root *parquetschema.ColumnDefinition) (*Co
 	return col, nil
 }
 
+// getColumnStore creates the column store of a column of a schema definition. The values of FLOAT and DOUBLE columns
+// are rarely repeated, so they are written PLAIN right away instead of being hashed for a dictionary.
 func getColumnStore(elem *parquet.SchemaElement, params *ColumnParameters) (*ColumnStore, error) {
 	if elem.Type == nil {
 		return nil, nil
 	}
 
+	allowDict := elem.GetType() != parquet.Type_FLOAT && elem.GetType() != parquet.Type_DOUBLE
+	return newColumnStore(elem.GetType(), parquet.Encoding_PLAIN, allowDict, params)
+}
+
+// newColumnStore creates a column store of the type typ with the value encoding enc. allowDict is ignored for
+// BOOLEAN columns, which are never dictionary encoded.
+func newColumnStore(typ parquet.Type, enc parquet.Encoding, allowDict bool, params *ColumnParameters) (*ColumnStore, error) {
 	var (
 		colStore *ColumnStore
 		err      error
 	)
 
-	typ := elem.GetType()
-
 	switch typ {
 	case parquet.Type_BYTE_ARRAY:
-		colStore, err = NewByteArrayStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewByteArrayStore(enc, allowDict, params)
 	case parquet.Type_FLOAT:
-		colStore, err = NewFloatStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewFloatStore(enc, allowDict, params)
 	case parquet.Type_DOUBLE:
-		colStore, err = NewDoubleStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewDoubleStore(enc, allowDict, params)
 	case parquet.Type_BOOLEAN:
-		colStore, err = NewBooleanStore(parquet.Encoding_PLAIN, params)
+		colStore, err = NewBooleanStore(enc, params)
 	case parquet.Type_INT32:
-		colStore, err = NewInt32Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt32Store(enc, allowDict, params)
 	case parquet.Type_INT64:
-		colStore, err = NewInt64Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt64Store(enc, allowDict, params)
 	case parquet.Type_INT96:
-		colStore, err = NewInt96Store(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewInt96Store(enc, allowDict, params)
 	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
-		colStore, err = NewFixedByteArrayStore(parquet.Encoding_PLAIN, true, params)
+		colStore, err = NewFixedByteArrayStore(enc, allowDict, params)
 	default:
 		return nil, fmt.Errorf("unsupported type %q when creating Column store", typ.String())
 	}
@@ -582,7 +734,7 @@ func (r *schema) AddColumn(path string, col *Column) error {
 	return r.addColumnOrGroup(path, col)
 }
 
-func recursiveFix(col *Column, path string, maxR, maxD uint16) {
+func recursiveFix(col *Column, parent []string, maxR, maxD uint16) {
 	if col.rep != parquet.FieldRepetitionType_REQUIRED {
 		maxD++
 	}
@@ -592,17 +744,15 @@ func recursiveFix(col *Column, path string, maxR, maxD uint16) {
 
 	col.maxR = maxR
 	col.maxD = maxD
-	col.flatName = path + "." + col.name
-	if path == "" {
-		col.flatName = col.name
-	}
+	col.nameArray = childPath(parent, col.name)
+	col.flatName = strings.Join(col.nameArray, ".")
 	if col.data != nil {
 		col.data.reset(col.rep, col.maxR, col.maxD)
 		return
 	}
 
 	for i := range col.children {
-		recursiveFix(col.children[i], col.flatName, maxR, maxD)
+		recursiveFix(col.children[i], col.nameArray, maxR, maxD)
 	}
 }
 
@@ -651,7 +801,7 @@ func (r *schema) addColumnOrGroup(path string, col *Column) error {
 		return errors.New("the children are nil")
 	}
 
-	recursiveFix(col, c.flatName, c.maxR, c.maxD)
+	recursiveFix(col, c.nameArray, c.maxR, c.maxD)
 
 	c.children = append(c.children, col)
 	r.sortIndex()
@@ -692,7 +842,11 @@ func (r *schema) findDataColumn(path string) (*Column, error) {
 func (r *schema) AddData(m map[string]interface{}) error {
 	r.readOnly = 1
 	r.ensureRoot()
-	err := recursiveAddColumnData(r.root.children, m, 0, 0, 0)
+	r.row.reset()
+	if err := checkColumnData(r.root.children, m, true, &r.row); err != nil {
+		return err
+	}
+	err := recursiveAddColumnData(r.root.children, m, 0, 0, 0, &r.row)
 	if err == nil {
 		r.numRecords++
 	}
@@ -707,6 +861,9 @@ func (r *schema) getData() (map[string]interface{}, error) {
 	if d.(map[string]interface{}) == nil {
 		d = make(map[string]interface{}) // just non nil root doc
 	}
+	if r.conversion.enabled() {
+		return r.conversion.convertGroup(r.root, d.(map[string]interface{}))
+	}
 
 	return d.(map[string]interface{}), nil
 }
@@ -730,12 +887,71 @@ func recursiveAddColumnNil(c []*Column, defLvl, maxRepLvl uint16, repLvl uint16)
 	return nil
 }
 
-func recursiveAddColumnData(c []*Column, m interface{}, defLvl uint16, maxRepLvl uint16, repLvl uint16) error {
+// checkColumnData checks the values of the row m for the columns c before they are added, so that a row with an
+// invalid value is not added partially. defined is true if the columns must have a value if they are required, i.e.
+// their parent group is not null. The converted values that are not null are appended to row.
+func checkColumnData(c []*Column, m map[string]interface{}, defined bool, row *rowValues) error {
+	for _, col := range c {
+		v := m[col.name]
+		if col.data != nil {
+			if v == nil {
+				if defined && col.rep == parquet.FieldRepetitionType_REQUIRED {
+					return errors.Errorf("the value %q is required", col.flatName)
+				}
+				continue
+			}
+			vals, err := col.data.getValues(v)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value of column %q", col.flatName)
+			}
+			row.values = append(row.values, vals)
+			continue
+		}
+
+		switch x := v.(type) {
+		case nil:
+			if err := checkColumnData(col.children, nil, defined && col.rep == parquet.FieldRepetitionType_REQUIRED, row); err != nil {
+				return err
+			}
+		case map[string]interface{}:
+			if col.rep == parquet.FieldRepetitionType_REPEATED {
+				return errors.Errorf("the value of the repeated group %q must be a []map[string]interface{}, not a %T", col.flatName, v)
+			}
+			if err := checkColumnData(col.children, x, true, row); err != nil {
+				return err
+			}
+		case []map[string]interface{}:
+			if col.rep != parquet.FieldRepetitionType_REPEATED {
+				return errors.Errorf("the value of the group %q must be a map[string]interface{}, not a %T", col.flatName, v)
+			}
+			for _, item := range x {
+				if err := checkColumnData(col.children, item, true, row); err != nil {
+					return err
+				}
+			}
+		default:
+			want := "map[string]interface{}"
+			if col.rep == parquet.FieldRepetitionType_REPEATED {
+				want = "[]map[string]interface{}"
+			}
+			return errors.Errorf("the value of the group %q must be a %s, not a %T", col.flatName, want, v)
+		}
+	}
+	return nil
+}
+
+// recursiveAddColumnData adds the row m to the columns c, with the values of the data columns that checkColumnData
+// converted in row.
+func recursiveAddColumnData(c []*Column, m interface{}, defLvl uint16, maxRepLvl uint16, repLvl uint16, row *rowValues) error {
 	var data = m.(map[string]interface{})
 	for i := range c {
 		d := data[c[i].name]
 		if c[i].data != nil {
-			if err := c[i].data.add(d, defLvl, maxRepLvl, repLvl); err != nil {
+			var vals []interface{}
+			if d != nil {
+				vals = row.next()
+			}
+			if err := c[i].data.addConverted(vals, defLvl, maxRepLvl, repLvl); err != nil {
 				return err
 			}
 		}
@@ -756,23 +972,27 @@ func recursiveAddColumnData(c []*Column, m interface{}, defLvl uint16, maxRepLvl
 				if c[i].rep == parquet.FieldRepetitionType_REPEATED {
 					return errors.Errorf("repeated group should be array")
 				}
-				if err := recursiveAddColumnData(c[i].children, v, l, maxRepLvl, repLvl); err != nil {
+				if err := recursiveAddColumnData(c[i].children, v, l, maxRepLvl, repLvl, row); err != nil {
 					return err
 				}
 			case []map[string]interface{}:
 				if c[i].rep != parquet.FieldRepetitionType_REPEATED {
 					return errors.Errorf("no repeated group should not be array")
 				}
-				m := maxRepLvl + 1
-				rL := repLvl
+				// an empty array has the definition level of its parent, like a nil array
 				if len(v) == 0 {
-					return recursiveAddColumnNil(c[i].children, l, m, rL)
+					if err := recursiveAddColumnNil(c[i].children, defLvl, maxRepLvl, repLvl); err != nil {
+						return err
+					}
+					continue
 				}
+				m := maxRepLvl + 1
+				rL := repLvl
 				for vi := range v {
 					if vi > 0 {
 						rL = m
 					}
-					if err := recursiveAddColumnData(c[i].children, v[vi], l, m, rL); err != nil {
+					if err := recursiveAddColumnData(c[i].children, v[vi], l, m, rL, row); err != nil {
 						return err
 					}
 				}
@@ -786,7 +1006,7 @@ func recursiveAddColumnData(c []*Column, m interface{}, defLvl uint16, maxRepLvl
 	return nil
 }
 
-func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel uint16) (int, error) {
+func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, parent []string, idx int, dLevel, rLevel uint16) (int, error) {
 	s := schema[idx]
 
 	if s.Name == "" {
@@ -814,15 +1034,13 @@ func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string,
 	}
 	c.rep = *s.RepetitionType
 	c.data = data
-	c.flatName = name + "." + s.Name
+	c.nameArray = childPath(parent, s.Name)
+	c.flatName = strings.Join(c.nameArray, ".")
 	c.name = s.Name
-	if name == "" {
-		c.flatName = s.Name
-	}
 	return idx + 1, nil
 }
 
-func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel uint16) (int, error) {
+func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, parent []string, idx int, dLevel, rLevel uint16) (int, error) {
 	if len(schema) <= idx {
 		return 0, errors.New("schema index out of bound")
 	}
@@ -855,16 +1073,18 @@ func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, i
 	c.maxD = dLevel
 	c.maxR = rLevel
 
-	if name == "" {
-		name = s.Name
-	} else {
-		name += "." + s.Name
-	}
-	c.flatName = name
+	c.nameArray = childPath(parent, s.Name)
+	c.flatName = strings.Join(c.nameArray, ".")
 	c.name = s.Name
 	c.element = s
 	c.children = make([]*Column, 0, l)
 	c.rep = s.GetRepetitionType()
+	switch {
+	case isListGroup(s):
+		c.parent = listParent
+	case isMapGroup(s):
+		c.parent = mapParent
+	}
 
 	var err error
 	idx++ // move idx from this group to next
@@ -875,14 +1095,14 @@ func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, i
 		if schema[idx].Type == nil {
 			// another group
 			child := &Column{}
-			idx, err = child.readGroupSchema(schema, name, idx, dLevel, rLevel)
+			idx, err = child.readGroupSchema(schema, c.nameArray, idx, dLevel, rLevel)
 			if err != nil {
 				return 0, err
 			}
 			c.children = append(c.children, child)
 		} else {
 			child := &Column{}
-			idx, err = child.readColumnSchema(schema, name, idx, dLevel, rLevel)
+			idx, err = child.readColumnSchema(schema, c.nameArray, idx, dLevel, rLevel)
 			if err != nil {
 				return 0, err
 			}
@@ -899,14 +1119,14 @@ func (r *schema) readSchema(schema []*parquet.SchemaElement) error {
 	for idx := 0; idx < len(schema); {
 		if schema[idx].Type == nil {
 			c := &Column{}
-			idx, err = c.readGroupSchema(schema, "", idx, 0, 0)
+			idx, err = c.readGroupSchema(schema, nil, idx, 0, 0)
 			if err != nil {
 				return err
 			}
 			r.root.children = append(r.root.children, c)
 		} else {
 			c := &Column{}
-			idx, err = c.readColumnSchema(schema, "", idx, 0, 0)
+			idx, err = c.readColumnSchema(schema, nil, idx, 0, 0)
 			if err != nil {
 				return err
 			}
@@ -958,10 +1178,28 @@ func (r *schema) rowGroupNumRecords() int64 {
 // to retrieve and set information related to the parquet schema and
 // columns that are used by the reader resp. writer.
 type SchemaCommon interface {
+	// Root returns the root of the schema tree, a group with the top-level columns and groups
+	// as its children. Groups and data columns are both a Column, DataColumn tells them apart.
+	// The root is the tree of the schema itself and must not be modified. SetSchemaDefinition
+	// replaces the tree, and so does the first column of an empty schema, so a root returned
+	// before is stale then and Root must be called again.
+	Root() *Column
 	// Columns return only data columns, not all columns
 	Columns() []*Column
-	// Return a column by its name
+	// ColumnPaths returns the paths of the data columns, in the same order as Columns.
+	ColumnPaths() [][]string
+	// Return a column by its name in dotted notation. If names contain dots, the dotted
+	// notation is ambiguous, e.g. "a.b" is both the column a.b and the column b in the
+	// group a, and the first of them is returned. Use GetColumnByPath for such columns.
 	GetColumnByName(path string) *Column
+	// GetColumnByPath returns the data column with the names of its path, e.g. "a", "b"
+	// for the column b in the group a, or nil if there is none.
+	GetColumnByPath(path ...string) *Column
+	// NumColumns returns the number of data columns.
+	NumColumns() int
+	// GetColumnByIndex returns the data column with the index i, which is the position of its
+	// chunks in the row groups, or nil if i is out of range.
+	GetColumnByIndex(i int) *Column
 
 	// GetSchemaDefinition returns the schema definition.
 	GetSchemaDefinition() *parquetschema.SchemaDefinition
@@ -969,6 +1207,7 @@ type SchemaCommon interface {
 
 	// Internal functions
 	rowGroupNumRecords() int64
+	setNumRecords(int64)
 	resetData()
 	getSchemaArray() []*parquet.SchemaElement
 }
@@ -976,9 +1215,9 @@ type SchemaCommon interface {
 // SchemaReader is an interface with methods necessary in the FileReader.
 type SchemaReader interface {
 	SchemaCommon
-	setNumRecords(int64)
 	getData() (map[string]interface{}, error)
 	setSelectedColumns(selected ...string)
+	setGroupConversion(gc groupConversion)
 	isSelected(string) bool
 }
 
@@ -994,8 +1233,8 @@ type SchemaWriter interface {
 }
 
 func makeSchema(meta *parquet.FileMetaData) (SchemaReader, error) {
-	if len(meta.Schema) < 1 {
-		return nil, errors.New("no schema element found")
+	if err := validateSchemaElements(meta.Schema); err != nil {
+		return nil, err
 	}
 	s := &schema{
 		root: &Column{
@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"math/rand"
 	"testing"
@@ -379,3 +380,330 @@ func readAllData(t *testing.T, data []byte) {
 		}
 	}
 }
+
+func TestWriteSchemaFromBuilder(t *testing.T) {
+	sd, err := parquetschema.NewSchemaBuilder("msg").
+		Required("id", parquet.Type_INT64).
+		Group("address", parquet.FieldRepetitionType_OPTIONAL, func(b *parquetschema.GroupBuilder) {
+			b.Repeated("lines", parquet.Type_BYTE_ARRAY, parquetschema.WithConvertedType(parquet.ConvertedType_UTF8))
+		}).
+		Build()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	col := w.GetColumnByName("address.lines")
+	require.NotNil(t, col)
+	require.Equal(t, uint16(2), col.MaxDefinitionLevel())
+	require.Equal(t, uint16(1), col.MaxRepetitionLevel())
+
+	row := map[string]interface{}{
+		"id":      int64(1),
+		"address": map[string]interface{}{"lines": [][]byte{[]byte("a"), []byte("b")}},
+	}
+	require.NoError(t, w.AddData(row))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	got, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, row, got)
+}
+
+func TestWriteLogicalTypes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	for _, c := range []struct {
+		name  string
+		store func(params *ColumnParameters) (*ColumnStore, error)
+		lt    *parquet.LogicalType
+	}{
+		{"id", func(params *ColumnParameters) (*ColumnStore, error) {
+			return NewInt64Store(parquet.Encoding_PLAIN, true, params)
+		}, nil},
+		{"name", func(params *ColumnParameters) (*ColumnStore, error) {
+			return NewByteArrayStore(parquet.Encoding_PLAIN, true, params)
+		}, &parquet.LogicalType{STRING: parquet.NewStringType()}},
+		{"price", func(params *ColumnParameters) (*ColumnStore, error) {
+			return NewInt64Store(parquet.Encoding_PLAIN, true, params)
+		}, &parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: 18, Scale: 4}}},
+		{"ts", func(params *ColumnParameters) (*ColumnStore, error) {
+			return NewInt64Store(parquet.Encoding_PLAIN, true, params)
+		}, &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{Unit: &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}}}},
+	} {
+		store, err := c.store(&ColumnParameters{LogicalType: c.lt})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn(c.name, NewDataColumn(store, parquet.FieldRepetitionType_REQUIRED)))
+		require.Equal(t, c.lt, w.GetColumnByName(c.name).LogicalType())
+	}
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":    int64(1),
+		"name":  []byte("name"),
+		"price": int64(12345),
+		"ts":    int64(1e18),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// the file has the logical types and, for old readers, the equivalent converted types
+	require.Nil(t, r.GetColumnByName("id").LogicalType())
+	require.Nil(t, r.GetColumnByName("id").Element().ConvertedType)
+
+	name := r.GetColumnByName("name")
+	require.True(t, name.LogicalType().IsSetSTRING())
+	require.Equal(t, parquet.ConvertedType_UTF8, name.Element().GetConvertedType())
+
+	price := r.GetColumnByName("price").Element()
+	require.True(t, price.LogicalType.IsSetDECIMAL())
+	require.Equal(t, parquet.ConvertedType_DECIMAL, price.GetConvertedType())
+	require.Equal(t, int32(18), price.GetPrecision())
+	require.Equal(t, int32(4), price.GetScale())
+
+	ts := r.GetColumnByName("ts")
+	require.True(t, ts.LogicalType().IsSetTIMESTAMP())
+	require.Nil(t, ts.Element().ConvertedType)
+
+	require.Equal(t, "message msg {\n  required int64 id;\n  required binary name (STRING);\n  required int64 price (DECIMAL(18, 4));\n  required int64 ts (TIMESTAMP(NANOS, false));\n}\n",
+		r.GetSchemaDefinition().String())
+}
+
+func TestDottedColumnNames(t *testing.T) {
+	sd, err := parquetschema.NewSchemaBuilder("msg").
+		Required("a.b", parquet.Type_INT64).
+		Group("a", parquet.FieldRepetitionType_REQUIRED, func(b *parquetschema.GroupBuilder) {
+			b.Required("b", parquet.Type_INT64)
+		}).
+		Build()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.Equal(t, [][]string{{"a.b"}, {"a", "b"}}, w.ColumnPaths())
+	require.Equal(t, []string{"a.b"}, w.GetColumnByPath("a.b").Path())
+	require.Equal(t, []string{"a", "b"}, w.GetColumnByPath("a", "b").Path())
+	require.Nil(t, w.GetColumnByPath("a"))
+	require.Nil(t, w.GetColumnByPath("b"))
+
+	row := map[string]interface{}{
+		"a.b": int64(1),
+		"a":   map[string]interface{}{"b": int64(2)},
+	}
+	require.NoError(t, w.AddData(row))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a.b"}, {"a", "b"}}, r.ColumnPaths())
+	// the chunks have the names of the columns in their path
+	for i, cc := range r.meta.RowGroups[0].Columns {
+		require.Equal(t, r.Columns()[i].Path(), cc.MetaData.PathInSchema)
+	}
+	require.Equal(t, 0, r.GetColumnByPath("a.b").Index())
+	require.Equal(t, 1, r.GetColumnByPath("a", "b").Index())
+
+	got, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, row, got)
+
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rows, err := r.Rows()
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	var ab, b int64
+	require.NoError(t, rows.Scan(&ab, &b))
+	require.Equal(t, int64(1), ab)
+	require.Equal(t, int64(2), b)
+}
+
+func TestGetColumnByIndex(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required int64 z;
+		optional group y {
+			required binary x (STRING);
+			optional group w (MAP) {
+				repeated group key_value {
+					required binary key (STRING);
+					optional int32 value;
+				}
+			}
+		}
+		optional group v (LIST) {
+			repeated group list {
+				required group element {
+					required int32 u;
+					required int32 a;
+				}
+			}
+		}
+		required boolean b;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"z": int64(1),
+		"y": map[string]interface{}{
+			"x": []byte("x"),
+			"w": map[string]interface{}{
+				"key_value": []map[string]interface{}{{"key": []byte("k"), "value": int32(2)}},
+			},
+		},
+		"v": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": map[string]interface{}{"u": int32(3), "a": int32(4)}}},
+		},
+		"b": true,
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// the columns are in schema order, which is the order of the column chunks
+	expected := []string{"z", "y.x", "y.w.key_value.key", "y.w.key_value.value", "v.list.element.u", "v.list.element.a", "b"}
+	for _, s := range []SchemaCommon{w, r} {
+		require.Equal(t, len(expected), s.NumColumns())
+		for i, name := range expected {
+			col := s.GetColumnByIndex(i)
+			require.Equal(t, name, col.FlatName())
+			require.Equal(t, i, col.Index())
+			require.Equal(t, col, s.GetColumnByName(name))
+		}
+		require.Nil(t, s.GetColumnByIndex(-1))
+		require.Nil(t, s.GetColumnByIndex(len(expected)))
+	}
+	chunks := r.meta.RowGroups[0].Columns
+	require.Len(t, chunks, r.NumColumns())
+	for i, cc := range chunks {
+		require.Equal(t, cc.MetaData.PathInSchema, r.GetColumnByIndex(i).Path())
+	}
+}
+
+func TestSchemaRoot(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required int64 id;
+		optional group address {
+			required binary city (STRING);
+			optional group tags (LIST) {
+				repeated group list {
+					required binary element (STRING);
+				}
+			}
+		}
+		required boolean valid;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1), "valid": true}))
+	require.NoError(t, w.Close())
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for _, s := range []SchemaCommon{w, r} {
+		root := s.Root()
+		require.Equal(t, "msg", root.Name())
+		require.False(t, root.DataColumn())
+		require.Nil(t, root.Path())
+
+		// walking the tree finds the groups and the data columns in schema order
+		var nodes []string
+		var walk func(c *Column)
+		walk = func(c *Column) {
+			for _, child := range c.Children() {
+				if child.DataColumn() {
+					require.Equal(t, child, s.GetColumnByIndex(child.Index()))
+					nodes = append(nodes, fmt.Sprintf("%s %s", child.FlatName(), child.RepetitionType()))
+					continue
+				}
+				nodes = append(nodes, fmt.Sprintf("%s %s group", child.FlatName(), child.RepetitionType()))
+				require.Equal(t, child.Name(), child.Element().GetName())
+				walk(child)
+			}
+		}
+		walk(root)
+		require.Equal(t, []string{
+			"id REQUIRED",
+			"address OPTIONAL group",
+			"address.city REQUIRED",
+			"address.tags OPTIONAL group",
+			"address.tags.list REPEATED group",
+			"address.tags.list.element REQUIRED",
+			"valid REQUIRED",
+		}, nodes)
+		require.True(t, s.GetColumnByName("address.tags.list.element").Element().GetLogicalType().IsSetSTRING())
+	}
+
+	// a root that was returned before SetSchemaDefinition is stale after it
+	w = NewFileWriter(&bytes.Buffer{})
+	empty := w.Root()
+	require.Empty(t, empty.Children())
+	require.NoError(t, w.SetSchemaDefinition(sd))
+	require.Empty(t, empty.Children())
+	require.Len(t, w.Root().Children(), 3)
+}
+
+// countingStore counts the values that are converted by getValues.
+type countingStore struct {
+	typedColumnStore
+	calls int
+}
+
+func (s *countingStore) getValues(v interface{}) ([]interface{}, error) {
+	s.calls++
+	return s.typedColumnStore.getValues(v)
+}
+
+func TestAddDataConvertsValuesOnce(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		repeated group items {
+			optional int64 n;
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	var stores []*countingStore
+	for _, name := range []string{"id", "items.n"} {
+		col := w.GetColumnByName(name)
+		require.NotNil(t, col)
+		s := &countingStore{typedColumnStore: col.data.typedColumnStore}
+		col.data.typedColumnStore = s
+		stores = append(stores, s)
+	}
+
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":    int64(1),
+		"items": []map[string]interface{}{{"n": int64(2)}, {}, {"n": int64(3)}},
+	}))
+	require.Equal(t, 1, stores[0].calls)
+	require.Equal(t, 2, stores[1].calls)
+
+	// a row with an invalid value is not added
+	err = w.AddData(map[string]interface{}{
+		"id":    int64(4),
+		"items": []map[string]interface{}{{"n": "five"}},
+	})
+	require.Error(t, err)
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(6)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":    int64(1),
+		"items": []map[string]interface{}{{"n": int64(2)}, {}, {"n": int64(3)}},
+	}, row)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": int64(6)}, row)
+}
@@ -0,0 +1,143 @@
+package goparquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// maxSchemaDepth is the maximum number of nested groups in the schema of a file.
+const maxSchemaDepth = 100
+
+// validateSchemaElements validates the flattened schema elements of a file, the first one being the root of the
+// schema. Instead of failing on the first problem, it returns all problems with the paths of their elements in one
+// error, so that a broken schema can be fixed at once.
+func validateSchemaElements(elems []*parquet.SchemaElement) error {
+	if len(elems) == 0 {
+		return errors.New("invalid schema: no schema element found")
+	}
+
+	v := &schemaValidator{elems: elems, names: []map[string]bool{{}}}
+	root := elems[0]
+	if root.Type != nil {
+		v.problemf("the root element %q has a type", root.Name)
+	}
+	if root.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED && root.RepetitionType != nil {
+		v.problemf("the root element %q is repeated", root.Name)
+	}
+
+	idx, n := 1, 0
+	for ; idx < len(elems) && (root.NumChildren == nil || n < int(root.GetNumChildren())); n++ {
+		idx = v.element(idx, "", 1)
+		if idx < 0 {
+			break
+		}
+	}
+	if idx >= 0 {
+		switch {
+		case root.NumChildren != nil && n != int(root.GetNumChildren()):
+			v.problemf("the root element %q has %d children, but there are only %d", root.Name, root.GetNumChildren(), n)
+		case idx < len(elems):
+			v.problemf("there are %d elements after the children of the root element %q", len(elems)-idx, root.Name)
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return errors.New("invalid schema: " + strings.Join(v.problems, "; "))
+	}
+	return nil
+}
+
+// schemaValidator collects the problems of flattened schema elements.
+type schemaValidator struct {
+	elems    []*parquet.SchemaElement
+	problems []string
+	// names are the names of the children of the root and the groups that are validated, to find duplicates
+	names []map[string]bool
+}
+
+func (v *schemaValidator) problemf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// element validates the element at idx, with its children if it is a group, and returns the index of the next
+// element. It returns -1 if the elements can't be validated any further because the group structure is broken.
+func (v *schemaValidator) element(idx int, prefix string, depth int) int {
+	elem := v.elems[idx]
+	path := prefix + elem.Name
+	if elem.Name == "" {
+		path = fmt.Sprintf("%s<element %d>", prefix, idx)
+		if prefix == "" {
+			v.problemf("element %d has no name", idx)
+		} else {
+			v.problemf("element %d in %q has no name", idx, strings.TrimSuffix(prefix, "."))
+		}
+	} else {
+		siblings := v.names[len(v.names)-1]
+		if siblings[elem.Name] {
+			v.problemf("the name of %q is not unique", path)
+		}
+		siblings[elem.Name] = true
+	}
+
+	if elem.Type != nil {
+		if elem.GetNumChildren() > 0 {
+			v.problemf("column %q has a type but also %d children", path, elem.GetNumChildren())
+			return -1
+		}
+		v.column(elem, path)
+		return idx + 1
+	}
+
+	if depth > maxSchemaDepth {
+		v.problemf("group %q is nested deeper than %d groups", path, maxSchemaDepth)
+		return -1
+	}
+	if elem.GetNumChildren() <= 0 {
+		v.problemf("group %q has no children", path)
+		return -1
+	}
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_LIST, parquet.ConvertedType_MAP, parquet.ConvertedType_MAP_KEY_VALUE:
+	default:
+		if elem.ConvertedType != nil {
+			v.problemf("group %q is annotated as %s, which is only valid for columns", path, elem.GetConvertedType())
+		}
+	}
+
+	v.names = append(v.names, make(map[string]bool))
+	defer func() { v.names = v.names[:len(v.names)-1] }()
+
+	next := idx + 1
+	for i := 0; i < int(elem.GetNumChildren()); i++ {
+		if next >= len(v.elems) {
+			v.problemf("group %q has %d children, but there are only %d elements after it", path, elem.GetNumChildren(), len(v.elems)-idx-1)
+			return -1
+		}
+		if next = v.element(next, path+".", depth+1); next < 0 {
+			return -1
+		}
+	}
+	return next
+}
+
+// column validates the column elem, whose path is path, like the columns of a schema definition are validated.
+func (v *schemaValidator) column(elem *parquet.SchemaElement, path string) {
+	if elem.RepetitionType == nil {
+		v.problemf("column %q has no repetition type", path)
+	}
+	if elem.Name == "" {
+		return
+	}
+
+	sd := &parquetschema.SchemaDefinition{RootColumn: &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: "root"},
+		Children:      []*parquetschema.ColumnDefinition{{SchemaElement: elem}},
+	}}
+	if err := sd.Validate(); err != nil {
+		v.problemf("column %q: %v", path, err)
+	}
+}
@@ -0,0 +1,108 @@
+package goparquet
+
+import (
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchemaElements(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message msg {
+		required int64 id;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+		optional group address {
+			required binary city (STRING);
+			optional fixed_len_byte_array(5) zip;
+		}
+		optional fixed_len_byte_array(8) price (DECIMAL(18, 2));
+	}`)
+	require.NoError(t, err)
+	w := NewFileWriter(nil, WithSchemaDefinition(sd))
+	elems := w.getSchemaArray()
+	require.NoError(t, validateSchemaElements(elems))
+
+	group := func(name string, numChildren int32, ct *parquet.ConvertedType) *parquet.SchemaElement {
+		return &parquet.SchemaElement{
+			Name:           name,
+			RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+			NumChildren:    &numChildren,
+			ConvertedType:  ct,
+		}
+	}
+	column := func(name string, typ parquet.Type, ct *parquet.ConvertedType) *parquet.SchemaElement {
+		return &parquet.SchemaElement{
+			Name:           name,
+			Type:           parquet.TypePtr(typ),
+			RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+			ConvertedType:  ct,
+		}
+	}
+	decimal := column("price", parquet.Type_INT64, parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL))
+	precision, scale, one := int32(4), int32(6), int32(1)
+	decimal.Precision, decimal.Scale = &precision, &scale
+	noRep := column("flag", parquet.Type_BOOLEAN, nil)
+	noRep.RepetitionType = nil
+
+	// all problems are returned at once
+	err = validateSchemaElements([]*parquet.SchemaElement{
+		group("msg", 7, nil),
+		column("id", parquet.Type_INT64, nil),
+		column("id", parquet.Type_INT32, nil),
+		column("", parquet.Type_INT32, nil),
+		column("hash", parquet.Type_FIXED_LEN_BYTE_ARRAY, nil),
+		group("a", 2, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)),
+		column("day", parquet.Type_INT64, parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)),
+		decimal,
+		noRep,
+		column("name", parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)),
+	})
+	require.EqualError(t, err, `invalid schema: the name of "id" is not unique; element 3 has no name; `+
+		`column "hash": field hash is a fixed_len_byte_array without a type length; group "a" is annotated as UTF8, which is only valid for columns; `+
+		`column "a.day": field day is annotated as DATE but is not an int32; `+
+		`column "a.price": field price is annotated as DECIMAL but scale 6 is out of bounds; needs to be 0 <= scale <= precision 4; `+
+		`column "flag" has no repetition type`)
+
+	for _, tt := range []struct {
+		elems    []*parquet.SchemaElement
+		expected string
+	}{
+		{nil, "invalid schema: no schema element found"},
+		{[]*parquet.SchemaElement{group("msg", 2, nil), column("a", parquet.Type_INT32, nil)},
+			`invalid schema: the root element "msg" has 2 children, but there are only 1`},
+		{[]*parquet.SchemaElement{group("msg", 1, nil), column("a", parquet.Type_INT32, nil), column("b", parquet.Type_INT32, nil)},
+			`invalid schema: there are 1 elements after the children of the root element "msg"`},
+		{[]*parquet.SchemaElement{{Name: "msg", RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED)}, column("a", parquet.Type_INT32, nil)},
+			`invalid schema: the root element "msg" is repeated`},
+		{[]*parquet.SchemaElement{group("msg", 1, nil), group("a", 2, nil), column("b", parquet.Type_INT32, nil)},
+			`invalid schema: group "a" has 2 children, but there are only 1 elements after it`},
+		{[]*parquet.SchemaElement{group("msg", 2, nil), group("a", 0, nil), column("b", parquet.Type_INT32, nil)},
+			`invalid schema: group "a" has no children`},
+		{[]*parquet.SchemaElement{group("msg", 1, nil), column("price", parquet.Type_INT64, parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL))},
+			`invalid schema: column "price": field price is annotated as DECIMAL but has no precision`},
+		{[]*parquet.SchemaElement{group("msg", 1, nil), {Name: "a", Type: parquet.TypePtr(parquet.Type_INT32), NumChildren: &one}, column("b", parquet.Type_INT32, nil)},
+			`invalid schema: column "a" has a type but also 1 children`},
+	} {
+		require.EqualError(t, validateSchemaElements(tt.elems), tt.expected)
+	}
+
+	// the groups can't be nested arbitrarily deep
+	deep := []*parquet.SchemaElement{group("msg", 1, nil)}
+	for i := 0; i <= maxSchemaDepth; i++ {
+		deep = append(deep, group("g", 1, nil))
+	}
+	deep = append(deep, column("leaf", parquet.Type_INT32, nil))
+	err = validateSchemaElements(deep)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is nested deeper than 100 groups")
+	require.NoError(t, validateSchemaElements(append(deep[:1:1], deep[2:]...)))
+
+	// files with invalid schemas can't be read
+	_, err = makeSchema(&parquet.FileMetaData{Schema: []*parquet.SchemaElement{group("msg", 1, nil), column("", parquet.Type_INT32, nil)}})
+	require.EqualError(t, err, "invalid schema: element 1 has no name")
+}
@@ -0,0 +1,64 @@
+package goparquet
+
+import (
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// SortingColumn is a column by which the rows of a row group are sorted.
+type SortingColumn struct {
+	// Path is the flat name of the column in dotted notation. It must be a column with values, not a group.
+	Path string
+	// Descending is true if the rows are sorted in descending order of the column values.
+	Descending bool
+	// NullsFirst is true if the null values come before the other values.
+	NullsFirst bool
+}
+
+// SetSortingColumns declares that the rows are sorted by the columns, the first column is the primary sort key. The
+// columns are recorded in the sorting columns of every row group that is flushed afterwards. The writer doesn't check
+// that the rows are actually sorted. It returns an error if a column doesn't exist in the schema, in that case the
+// sorting columns are not changed. Calling it without columns removes them.
+func (fw *FileWriter) SetSortingColumns(columns []SortingColumn) error {
+	sorting, err := sortingColumnsToParquet(fw.SchemaWriter, columns)
+	if err != nil {
+		return err
+	}
+	fw.sortingColumns = sorting
+	return nil
+}
+
+// sortingColumnsToParquet maps the paths of the sorting columns to the indexes of their columns in the schema.
+func sortingColumnsToParquet(schema SchemaCommon, columns []SortingColumn) ([]*parquet.SortingColumn, error) {
+	var sorting []*parquet.SortingColumn
+	for _, sc := range columns {
+		col := schema.GetColumnByName(sc.Path)
+		if col == nil {
+			return nil, errors.Errorf("sorting column %q not found", sc.Path)
+		}
+		sorting = append(sorting, &parquet.SortingColumn{
+			ColumnIdx:  int32(col.Index()),
+			Descending: sc.Descending,
+			NullsFirst: sc.NullsFirst,
+		})
+	}
+	return sorting, nil
+}
+
+// SortingColumns returns the columns by which the rows of the row group are sorted, the first column is the primary
+// sort key. It returns nil if the writer didn't declare any sorting columns.
+func (rg *RowGroupReader) SortingColumns() ([]SortingColumn, error) {
+	cols := rg.SchemaReader.Columns()
+	var columns []SortingColumn
+	for _, sc := range rg.rowGroup.SortingColumns {
+		if sc.ColumnIdx < 0 || int(sc.ColumnIdx) >= len(cols) {
+			return nil, errors.Errorf("sorting column index %d is out of bounds, the schema has %d columns", sc.ColumnIdx, len(cols))
+		}
+		columns = append(columns, SortingColumn{
+			Path:       cols[sc.ColumnIdx].FlatName(),
+			Descending: sc.Descending,
+			NullsFirst: sc.NullsFirst,
+		})
+	}
+	return columns, nil
+}
@@ -0,0 +1,360 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// sortOrder is the order in which the min and max statistics of a column are compared.
+type sortOrder int
+
+const (
+	sortOrderUndefined sortOrder = iota
+	sortOrderSigned
+	sortOrderUnsigned
+)
+
+// columnSortOrder returns the sort order of the column elem. INT96 values and intervals have no defined order, and
+// byte array decimals would need a signed comparison of their two's complement, so they have no min and max.
+func columnSortOrder(elem *parquet.SchemaElement) sortOrder {
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return sortOrderUnsigned
+	case parquet.Type_INT32:
+		if isUnsignedInt32(elem) {
+			return sortOrderUnsigned
+		}
+		return sortOrderSigned
+	case parquet.Type_INT64:
+		if isUnsignedInt64(elem) {
+			return sortOrderUnsigned
+		}
+		return sortOrderSigned
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return sortOrderSigned
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if elem.ConvertedType != nil {
+			switch *elem.ConvertedType {
+			case parquet.ConvertedType_INTERVAL, parquet.ConvertedType_DECIMAL:
+				return sortOrderUndefined
+			}
+		}
+		if elem.LogicalType != nil && elem.LogicalType.DECIMAL != nil {
+			return sortOrderUndefined
+		}
+		return sortOrderUnsigned
+	}
+	return sortOrderUndefined
+}
+
+// pageStatistics returns the statistics of the values in the page p of col. The min and max values are left out if
+// the sort order of the column is undefined, if the page has only null values, or if one of them is longer than
+// maxSize bytes. A maxSize of 0 or less means there is no limit.
+func pageStatistics(col *Column, p pageRange, maxSize int) *parquet.Statistics {
+	nullCount := int64(p.numNulls())
+	stats := &parquet.Statistics{NullCount: &nullCount}
+
+	order := columnSortOrder(col.Element())
+	if order == sortOrderUndefined {
+		return stats
+	}
+
+	min, max := storeMinMax(col.data.values, p.valueStart, p.valueEnd, order)
+	if min == nil || max == nil {
+		return stats
+	}
+	if maxSize > 0 && (len(min) > maxSize || len(max) > maxSize) {
+		return stats
+	}
+
+	stats.MinValue, stats.MaxValue = min, max
+	return stats
+}
+
+// chunkStatistics folds the statistics of the data pages of a column chunk into the statistics of the chunk.
+type chunkStatistics struct {
+	typ   parquet.Type
+	order sortOrder
+
+	nullCount int64
+	min, max  []byte
+	// noMinMax is set once a page with values that are not null has no min and max value, e.g. because they are too
+	// long, then the min and max value of the chunk are unknown.
+	noMinMax bool
+}
+
+func newChunkStatistics(col *Column) *chunkStatistics {
+	return &chunkStatistics{typ: col.Element().GetType(), order: columnSortOrder(col.Element())}
+}
+
+// add adds the statistics of the data page with the range p.
+func (cs *chunkStatistics) add(p pageRange, stats *parquet.Statistics) {
+	cs.nullCount += stats.GetNullCount()
+	if p.valueEnd == p.valueStart || cs.noMinMax {
+		return
+	}
+	if stats.MinValue == nil || stats.MaxValue == nil {
+		cs.noMinMax = true
+		cs.min, cs.max = nil, nil
+		return
+	}
+
+	if cs.min == nil || comparePlainValues(cs.typ, cs.order, stats.MinValue, cs.min) < 0 {
+		cs.min = stats.MinValue
+	}
+	if cs.max == nil || comparePlainValues(cs.typ, cs.order, stats.MaxValue, cs.max) > 0 {
+		cs.max = stats.MaxValue
+	}
+}
+
+// statistics returns the statistics of the column chunk. The min and max value are left out if the chunk has only
+// null values, or if a page has no min and max value.
+func (cs *chunkStatistics) statistics() *parquet.Statistics {
+	nullCount := cs.nullCount
+	return &parquet.Statistics{
+		MinValue:  cs.min,
+		MaxValue:  cs.max,
+		NullCount: &nullCount,
+	}
+}
+
+// storeMinMax returns the plain encoded min and max value of the values from to to of the store. NaN values are
+// ignored, the result is nil if there is no value to compare.
+func storeMinMax(d *dictStore, from, to int, order sortOrder) ([]byte, []byte) {
+	var min, max interface{}
+	update := func(v interface{}) {
+		if isNaN(v) {
+			return
+		}
+		if min == nil || compareValues(v, min, order) < 0 {
+			min = v
+		}
+		if max == nil || compareValues(v, max, order) > 0 {
+			max = v
+		}
+	}
+
+	switch values := d.typed.(type) {
+	case nil:
+		for _, idx := range d.data[from:to] {
+			update(d.values[idx])
+		}
+	case []int32:
+		for _, v := range values[from:to] {
+			update(v)
+		}
+	case []int64:
+		for _, v := range values[from:to] {
+			update(v)
+		}
+	case []float64:
+		for _, v := range values[from:to] {
+			update(v)
+		}
+	case [][]byte:
+		for _, v := range values[from:to] {
+			update(v)
+		}
+	}
+
+	if min == nil || max == nil {
+		return nil, nil
+	}
+	return plainValue(min), plainValue(max)
+}
+
+func isNaN(v interface{}) bool {
+	switch t := v.(type) {
+	case float32:
+		return math.IsNaN(float64(t))
+	case float64:
+		return math.IsNaN(t)
+	}
+	return false
+}
+
+// compareValues compares two values of the same type in the sort order of their column, it returns -1, 0 or 1.
+func compareValues(a, b interface{}, order sortOrder) int {
+	switch at := a.(type) {
+	case bool:
+		return compareUint64(boolToUint64(at), boolToUint64(b.(bool)))
+	case int32:
+		if order == sortOrderUnsigned {
+			return compareUint64(uint64(uint32(at)), uint64(uint32(b.(int32))))
+		}
+		return compareInt64(int64(at), int64(b.(int32)))
+	case uint32:
+		return compareUint64(uint64(at), uint64(b.(uint32)))
+	case int64:
+		if order == sortOrderUnsigned {
+			return compareUint64(uint64(at), uint64(b.(int64)))
+		}
+		return compareInt64(at, b.(int64))
+	case uint64:
+		return compareUint64(at, b.(uint64))
+	case float32:
+		return compareFloat64(float64(at), float64(b.(float32)))
+	case float64:
+		return compareFloat64(at, b.(float64))
+	case []byte:
+		return bytes.Compare(at, b.([]byte))
+	}
+	return 0
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// plainValue returns the PLAIN encoding of a single value, as it is used in the statistics.
+func plainValue(v interface{}) []byte {
+	switch t := v.(type) {
+	case bool:
+		return []byte{byte(boolToUint64(t))}
+	case int32:
+		return plainUint32(uint32(t))
+	case uint32:
+		return plainUint32(t)
+	case int64:
+		return plainUint64(uint64(t))
+	case uint64:
+		return plainUint64(t)
+	case float32:
+		return plainUint32(math.Float32bits(t))
+	case float64:
+		return plainUint64(math.Float64bits(t))
+	case []byte:
+		return append([]byte(nil), t...)
+	}
+	return nil
+}
+
+func plainUint32(v uint32) []byte {
+	ret := make([]byte, 4)
+	binary.LittleEndian.PutUint32(ret, v)
+	return ret
+}
+
+func plainUint64(v uint64) []byte {
+	ret := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ret, v)
+	return ret
+}
+
+// ColumnStatistics are the statistics of a column chunk, with the min and max value decoded to the Go type of the
+// column values. They allow to skip the row groups that can't contain the values that are searched for.
+type ColumnStatistics struct {
+	// MinValue and MaxValue have the same Go types as the values that are read from the column, e.g. int64 for
+	// INT64 and []byte for BYTE_ARRAY columns. They are nil if they are unknown, e.g. if the column chunk has only
+	// null values. Like the values of NextRow, the values of DATE and TIMESTAMP columns are the int32 days and the
+	// int64 time units since the Unix epoch, and not a time.Time like Rows.Scan returns. A time must be converted
+	// the same way to compare it with them, e.g. int32(t.Unix() / 86400) for a DATE column.
+	MinValue interface{}
+	MaxValue interface{}
+	// NullCount and DistinctCount are -1 if they are unknown.
+	NullCount     int64
+	DistinctCount int64
+	// NumValues is the number of values of the column chunk, including the null values.
+	NumValues int64
+
+	order sortOrder
+}
+
+// ColumnStatistics returns the statistics of the column chunk of the column colName in the row group with the index
+// rowGroup. It returns nil if the column chunk has no statistics. The deprecated min and max fields that older
+// writers wrote are only used for columns with a signed sort order, as their order is undefined otherwise.
+func (f *FileReader) ColumnStatistics(rowGroup int, colName string) (*ColumnStatistics, error) {
+	col, rg, err := f.columnChunk(rowGroup, colName)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := rg.Columns[col.Index()].MetaData
+	if meta == nil {
+		return nil, errors.Errorf("missing meta data for column %q", colName)
+	}
+	stats := meta.Statistics
+	if stats == nil {
+		return nil, nil
+	}
+
+	cs := &ColumnStatistics{
+		NullCount:     -1,
+		DistinctCount: -1,
+		NumValues:     meta.NumValues,
+		order:         columnSortOrder(col.Element()),
+	}
+	if stats.NullCount != nil {
+		cs.NullCount = *stats.NullCount
+	}
+	if stats.DistinctCount != nil {
+		cs.DistinctCount = *stats.DistinctCount
+	}
+
+	min, max := stats.MinValue, stats.MaxValue
+	if min == nil && max == nil && cs.order == sortOrderSigned {
+		min, max = stats.Min, stats.Max
+	}
+	if min == nil || max == nil || cs.order == sortOrderUndefined {
+		return cs, nil
+	}
+	if cs.MinValue, err = decodeStatValue(col.Element(), min); err != nil {
+		return nil, errors.Wrapf(err, "invalid min value of column %q", colName)
+	}
+	if cs.MaxValue, err = decodeStatValue(col.Element(), max); err != nil {
+		return nil, errors.Wrapf(err, "invalid max value of column %q", colName)
+	}
+
+	return cs, nil
+}
+
+// NotBetween returns true if the column chunk has no value between min and max (inclusive), so its row group can be
+// skipped when searching for these values. min and max must have the Go type of the column values. If the min and
+// max value of the column chunk are unknown, it returns true only if all values are null.
+func (cs *ColumnStatistics) NotBetween(min, max interface{}) (bool, error) {
+	if cs.MinValue == nil || cs.MaxValue == nil {
+		return cs.NumValues > 0 && cs.NullCount == cs.NumValues, nil
+	}
+	if err := checkValueTypes(cs.MinValue, min, max); err != nil {
+		return false, err
+	}
+
+	return compareValues(cs.MaxValue, min, cs.order) < 0 || compareValues(cs.MinValue, max, cs.order) > 0, nil
+}
@@ -0,0 +1,431 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnSortOrder(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required boolean b;
+			required int32 i32;
+			required int32 u32 (UINT_32);
+			required int64 i64;
+			required int64 u64 (UINT_64);
+			required int96 i96;
+			required double d;
+			required binary s (STRING);
+			required binary dec (DECIMAL(10, 2));
+			required fixed_len_byte_array(12) iv (INTERVAL);
+		}
+	`)
+	require.NoError(t, err)
+
+	expected := map[string]sortOrder{
+		"b":   sortOrderUnsigned,
+		"i32": sortOrderSigned,
+		"u32": sortOrderUnsigned,
+		"i64": sortOrderSigned,
+		"u64": sortOrderUnsigned,
+		"i96": sortOrderUndefined,
+		"d":   sortOrderSigned,
+		"s":   sortOrderUnsigned,
+		"dec": sortOrderUndefined,
+		"iv":  sortOrderUndefined,
+	}
+	for _, col := range sd.RootColumn.Children {
+		require.Equal(t, expected[col.SchemaElement.Name], columnSortOrder(col.SchemaElement), "column %s", col.SchemaElement.Name)
+	}
+}
+
+func TestStoreMinMax(t *testing.T) {
+	d := &dictStore{}
+	d.init()
+	for _, v := range []interface{}{int32(-1), int32(5), int32(0)} {
+		d.addValue(v, 4)
+	}
+	min, max := storeMinMax(d, 0, 3, sortOrderSigned)
+	require.Equal(t, plainValue(int32(-1)), min)
+	require.Equal(t, plainValue(int32(5)), max)
+
+	// -1 is the largest unsigned value
+	min, max = storeMinMax(d, 0, 3, sortOrderUnsigned)
+	require.Equal(t, plainValue(int32(0)), min)
+	require.Equal(t, plainValue(int32(-1)), max)
+
+	min, max = storeMinMax(d, 1, 2, sortOrderSigned)
+	require.Equal(t, plainValue(int32(5)), min)
+	require.Equal(t, plainValue(int32(5)), max)
+
+	min, max = storeMinMax(d, 1, 1, sortOrderSigned)
+	require.Nil(t, min)
+	require.Nil(t, max)
+
+	d = &dictStore{typed: []float64{math.NaN(), 2.5, -1.5, math.NaN()}}
+	min, max = storeMinMax(d, 0, 4, sortOrderSigned)
+	require.Equal(t, plainValue(-1.5), min)
+	require.Equal(t, plainValue(2.5), max)
+
+	min, max = storeMinMax(d, 0, 1, sortOrderSigned)
+	require.Nil(t, min)
+	require.Nil(t, max)
+
+	d = &dictStore{typed: [][]byte{[]byte("b"), []byte("\xff"), []byte("a")}}
+	min, max = storeMinMax(d, 0, 3, sortOrderUnsigned)
+	require.Equal(t, []byte("a"), min)
+	require.Equal(t, []byte("\xff"), max)
+}
+
+func TestWritePageStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional binary name (STRING);
+			optional int32 n;
+			optional int96 ts;
+		}
+	`)
+	require.NoError(t, err)
+
+	type page struct {
+		numValues int32
+		stats     *parquet.Statistics
+	}
+
+	writeFile := func(maxStatsSize int, opts ...FileWriterOption) map[string][]page {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append(opts, WithSchemaDefinition(sd), WithMaxPageSize(256), WithMaxStatisticsSize(maxStatsSize))...)
+		for i := 0; i < 200; i++ {
+			row := map[string]interface{}{"id": int64(i - 100), "ts": [12]byte{byte(i)}}
+			// the names are between 1 and 10 bytes long, and null in the second row group
+			if i == 100 || i == 150 {
+				require.NoError(t, w.FlushRowGroup())
+			}
+			if i < 100 {
+				row["name"] = []byte(strings.Repeat("x", i%10+1))
+			} else if i >= 150 {
+				row["name"] = []byte(strings.Repeat("y", i%10+1))
+			}
+			if i%2 == 0 {
+				row["n"] = int32(i % 7)
+			}
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		pages := make(map[string][]page)
+		rewritePages(t, buf.Bytes(), func(col string, ordinal int, ph *parquet.PageHeader, body []byte) {
+			switch ph.Type {
+			case parquet.PageType_DATA_PAGE:
+				pages[col] = append(pages[col], page{ph.DataPageHeader.NumValues, ph.DataPageHeader.Statistics})
+			case parquet.PageType_DATA_PAGE_V2:
+				pages[col] = append(pages[col], page{ph.DataPageHeaderV2.NumValues, ph.DataPageHeaderV2.Statistics})
+			}
+		})
+		return pages
+	}
+
+	for _, opts := range [][]FileWriterOption{nil, {WithDataPageV2()}} {
+		pages := writeFile(0, opts...)
+
+		// the ids increase, so the pages follow each other
+		ids := pages["id"]
+		require.True(t, len(ids) > 1)
+		require.Equal(t, plainValue(int64(-100)), ids[0].stats.MinValue)
+		require.Equal(t, plainValue(int64(99)), ids[len(ids)-1].stats.MaxValue)
+		for i, p := range ids {
+			require.Equal(t, int64(0), p.stats.GetNullCount())
+			min, max := int64(binary.LittleEndian.Uint64(p.stats.MinValue)), int64(binary.LittleEndian.Uint64(p.stats.MaxValue))
+			require.Equal(t, int64(p.numValues), max-min+1)
+			if i > 0 {
+				require.Equal(t, int64(binary.LittleEndian.Uint64(ids[i-1].stats.MaxValue))+1, min)
+			}
+		}
+
+		var nulls int64
+		allNull := 0
+		for _, p := range pages["name"] {
+			nulls += p.stats.GetNullCount()
+			if p.stats.GetNullCount() == int64(p.numValues) {
+				allNull++
+				require.Nil(t, p.stats.MinValue)
+				require.Nil(t, p.stats.MaxValue)
+				continue
+			}
+			require.Contains(t, []string{"x", "y"}, string(p.stats.MinValue[:1]))
+			require.True(t, bytes.Compare(p.stats.MinValue, p.stats.MaxValue) <= 0)
+		}
+		require.Equal(t, int64(50), nulls)
+		require.True(t, allNull > 0)
+
+		nulls = 0
+		for _, p := range pages["n"] {
+			nulls += p.stats.GetNullCount()
+			require.NotNil(t, p.stats.MinValue)
+			require.True(t, int32(binary.LittleEndian.Uint32(p.stats.MinValue)) >= 0)
+			require.True(t, int32(binary.LittleEndian.Uint32(p.stats.MaxValue)) <= 6)
+		}
+		require.Equal(t, int64(100), nulls)
+
+		// INT96 has no sort order
+		for _, p := range pages["ts"] {
+			require.Nil(t, p.stats.MinValue)
+			require.Nil(t, p.stats.MaxValue)
+			require.Equal(t, int64(0), p.stats.GetNullCount())
+		}
+
+		// every page of names has a value of 9 or 10 bytes
+		for _, p := range writeFile(8, opts...)["name"] {
+			require.Nil(t, p.stats.MinValue)
+			require.Nil(t, p.stats.MaxValue)
+			require.NotNil(t, p.stats.NullCount)
+		}
+	}
+}
+
+func TestColumnChunkStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int64 id;
+			optional double score;
+			optional binary name (STRING);
+			optional int32 none;
+			required int96 ts;
+		}
+	`)
+	require.NoError(t, err)
+
+	writeFile := func(maxStatsSize int) *FileReader {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageSize(256), WithMaxStatisticsSize(maxStatsSize))
+		for i := 0; i < 1000; i++ {
+			// the ids decrease and then increase, so the min and max are in different pages
+			row := map[string]interface{}{"id": int64(i), "ts": [12]byte{byte(i)}}
+			if i < 500 {
+				row["id"] = int64(-i)
+			}
+			switch {
+			case i%10 == 0:
+			case i%3 == 0:
+				row["score"] = math.NaN()
+			default:
+				row["score"] = float64(i) / 4
+			}
+			if i%5 != 0 {
+				row["name"] = []byte(strings.Repeat("n", i%20+1))
+			}
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+
+	r := writeFile(0)
+
+	stats, err := r.ColumnStatistics(0, "id")
+	require.NoError(t, err)
+	require.Equal(t, int64(-499), stats.MinValue)
+	require.Equal(t, int64(999), stats.MaxValue)
+	require.Equal(t, int64(0), stats.NullCount)
+	require.Equal(t, int64(1000), stats.NumValues)
+	skip, err := stats.NotBetween(int64(1000), int64(2000))
+	require.NoError(t, err)
+	require.True(t, skip)
+	skip, err = stats.NotBetween(int64(-1000), int64(-499))
+	require.NoError(t, err)
+	require.False(t, skip)
+	_, err = stats.NotBetween(int32(1), int32(2))
+	require.EqualError(t, err, "the value 1 is of type int32, but the column values are of type int64")
+
+	// NaN values are not part of the min and max
+	stats, err = r.ColumnStatistics(0, "score")
+	require.NoError(t, err)
+	require.Equal(t, float64(1)/4, stats.MinValue)
+	require.Equal(t, float64(998)/4, stats.MaxValue)
+	require.Equal(t, int64(100), stats.NullCount)
+
+	stats, err = r.ColumnStatistics(0, "name")
+	require.NoError(t, err)
+	require.Equal(t, []byte("nn"), stats.MinValue)
+	require.Equal(t, []byte(strings.Repeat("n", 20)), stats.MaxValue)
+	require.Equal(t, int64(200), stats.NullCount)
+	require.Equal(t, int64(16), stats.DistinctCount)
+
+	stats, err = r.ColumnStatistics(0, "none")
+	require.NoError(t, err)
+	require.Nil(t, stats.MinValue)
+	require.Nil(t, stats.MaxValue)
+	require.Equal(t, int64(1000), stats.NullCount)
+	skip, err = stats.NotBetween(int32(1), int32(2))
+	require.NoError(t, err)
+	require.True(t, skip)
+	meta := r.meta.RowGroups[0].Columns[3].MetaData.Statistics
+	require.Nil(t, meta.MinValue)
+	require.Nil(t, meta.MaxValue)
+	require.Equal(t, int64(1000), meta.GetNullCount())
+
+	stats, err = r.ColumnStatistics(0, "ts")
+	require.NoError(t, err)
+	require.Nil(t, stats.MinValue)
+	skip, err = stats.NotBetween([12]byte{}, [12]byte{})
+	require.NoError(t, err)
+	require.False(t, skip)
+
+	// some pages have names that are longer than 10 bytes
+	stats, err = writeFile(10).ColumnStatistics(0, "name")
+	require.NoError(t, err)
+	require.Nil(t, stats.MinValue)
+	require.Nil(t, stats.MaxValue)
+	require.Equal(t, int64(200), stats.NullCount)
+}
+
+func TestWriteStatisticsSortOrder(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required int32 i32;
+			required int32 u32 (UINT_32);
+			required int64 i64;
+			required int64 u64 (UINT_64);
+			required binary b;
+			required fixed_len_byte_array(2) f;
+		}
+	`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxPageSize(256))
+	for i := 0; i < 1000; i++ {
+		// the ints are between -500 and 499, -1 is the largest unsigned value, the leading bytes have the high bit set
+		// in every other page
+		n := i - 500
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"i32": int32(n),
+			"u32": uint32(int32(n)),
+			"i64": int64(n),
+			"u64": uint64(int64(n)),
+			"b":   []byte{byte(i), 'x'},
+			"f":   []byte{byte(i), 'y'},
+		}))
+	}
+	require.NoError(t, w.Close())
+
+	var pageStats = map[string][]*parquet.Statistics{}
+	rewritePages(t, buf.Bytes(), func(col string, ordinal int, ph *parquet.PageHeader, body []byte) {
+		if ph.Type == parquet.PageType_DATA_PAGE {
+			pageStats[col] = append(pageStats[col], ph.DataPageHeader.Statistics)
+		}
+	})
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	expected := map[string][2]interface{}{
+		"i32": {int32(-500), int32(499)},
+		"u32": {uint32(0), uint32(math.MaxUint32)},
+		"i64": {int64(-500), int64(499)},
+		"u64": {uint64(0), uint64(math.MaxUint64)},
+		"b":   {[]byte{0x00, 'x'}, []byte{0xff, 'x'}},
+		"f":   {[]byte{0x00, 'y'}, []byte{0xff, 'y'}},
+	}
+	for col, minMax := range expected {
+		stats, err := r.ColumnStatistics(0, col)
+		require.NoError(t, err)
+		require.Equal(t, minMax[0], stats.MinValue, "min of column %s", col)
+		require.Equal(t, minMax[1], stats.MaxValue, "max of column %s", col)
+
+		// the column index has the min and max values of the page headers, and the chunk has their min and max
+		ci, err := r.ColumnIndex(0, col)
+		require.NoError(t, err)
+		require.True(t, len(ci.MinValues) > 1, "column %s", col)
+		require.Len(t, pageStats[col], len(ci.MinValues), "column %s", col)
+		min, max := ci.MinValues[0], ci.MaxValues[0]
+		for i, ps := range pageStats[col] {
+			elem := r.SchemaReader.GetColumnByName(col).Element()
+			pageMin, err := decodeStatValue(elem, ps.MinValue)
+			require.NoError(t, err)
+			pageMax, err := decodeStatValue(elem, ps.MaxValue)
+			require.NoError(t, err)
+			require.Equal(t, pageMin, ci.MinValues[i], "min of page %d of column %s", i, col)
+			require.Equal(t, pageMax, ci.MaxValues[i], "max of page %d of column %s", i, col)
+			require.True(t, compareValues(pageMin, pageMax, ci.order) <= 0, "page %d of column %s", i, col)
+			if compareValues(pageMin, min, ci.order) < 0 {
+				min = pageMin
+			}
+			if compareValues(pageMax, max, ci.order) > 0 {
+				max = pageMax
+			}
+		}
+		require.Equal(t, stats.MinValue, min, "min of column %s", col)
+		require.Equal(t, stats.MaxValue, max, "max of column %s", col)
+	}
+
+	// the pages of the signed columns are in ascending order, the unsigned ones wrap around at -1
+	for col, order := range map[string]parquet.BoundaryOrder{
+		"i32": parquet.BoundaryOrder_ASCENDING,
+		"u32": parquet.BoundaryOrder_UNORDERED,
+		"i64": parquet.BoundaryOrder_ASCENDING,
+		"u64": parquet.BoundaryOrder_UNORDERED,
+	} {
+		ci, err := r.ColumnIndex(0, col)
+		require.NoError(t, err)
+		require.Equal(t, order, ci.BoundaryOrder, "column %s", col)
+	}
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, uint32(math.MaxUint32-499), row["u32"])
+	require.Equal(t, uint64(math.MaxUint64-499), row["u64"])
+
+	// unsigned values can't be written to signed columns
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	err = w.AddData(map[string]interface{}{"i32": uint32(1), "u32": uint32(1), "i64": int64(1), "u64": uint64(1), "b": []byte{}, "f": []byte{0, 0}})
+	require.EqualError(t, err, `invalid value of column "i32": uint32 values are only supported for unsigned int32 columns`)
+}
+
+func TestColumnStatisticsDeprecatedMinMax(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	ids, err := NewInt64Store(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("id", NewDataColumn(ids, parquet.FieldRepetitionType_REQUIRED)))
+	names, err := NewByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("name", NewDataColumn(names, parquet.FieldRepetitionType_REQUIRED)))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "name": []byte{byte('a' + i)}}))
+	}
+	require.NoError(t, w.Close())
+
+	// older writers only wrote the deprecated min and max fields
+	data := rewriteFooter(t, buf.Bytes(), func(meta *parquet.FileMetaData) {
+		for _, cc := range meta.RowGroups[0].Columns {
+			stats := cc.MetaData.Statistics
+			stats.Min, stats.Max = stats.MinValue, stats.MaxValue
+			stats.MinValue, stats.MaxValue = nil, nil
+		}
+	})
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	stats, err := r.ColumnStatistics(0, "id")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), stats.MinValue)
+	require.Equal(t, int64(9), stats.MaxValue)
+
+	// the order of the deprecated min and max of byte arrays is undefined
+	stats, err = r.ColumnStatistics(0, "name")
+	require.NoError(t, err)
+	require.Nil(t, stats.MinValue)
+	require.Nil(t, stats.MaxValue)
+}
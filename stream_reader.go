@@ -0,0 +1,101 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// NewStreamReader creates a FileReader that reads the rows of a file from r, which must be at the start of the file
+// and is only read forwards, e.g. a pipe or the body of an HTTP response. Since the footer is at the end of the file,
+// its meta data must be known, e.g. from the writer of the file or from ReadFileMetaData. The column chunks must be
+// in the file in the order of the row groups and columns of the meta data, NewStreamReader fails otherwise.
+//
+// Only NextRow and the methods that don't read from the file, like NumRows and MetaData, work with a stream.
+// SeekToRow, RowGroup, ReadColumnRows, ColumnIndex, Pages and the Read*Values methods need to seek back in the file
+// and fail. The pages of the columns that are not selected are read and discarded.
+func NewStreamReader(r io.Reader, meta *parquet.FileMetaData, options ...FileReaderOption) (*FileReader, error) {
+	opts := &fileReaderOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if err := checkStreamLayout(meta); err != nil {
+		return nil, err
+	}
+
+	var rs io.ReadSeeker = &forwardReader{r: r}
+	if opts.ctx != nil {
+		rs = newContextReader(opts.ctx, rs)
+	}
+
+	buf := make([]byte, len(magic))
+	if _, err := io.ReadFull(rs, buf); err != nil {
+		return nil, errors.Wrap(err, "read the file magic header failed")
+	}
+	if !bytes.Equal(buf, magic) {
+		return nil, errors.Errorf("invalid parquet file header %q", buf)
+	}
+
+	return newFileReader(rs, meta, opts)
+}
+
+// checkStreamLayout checks that the column chunks of the meta data are in the order of the row groups and columns,
+// so that they can be read without seeking backwards.
+func checkStreamLayout(meta *parquet.FileMetaData) error {
+	if meta == nil {
+		return errors.New("no file meta data")
+	}
+
+	end := int64(len(magic))
+	for i, rg := range meta.RowGroups {
+		for j, cc := range rg.Columns {
+			if cc.MetaData == nil {
+				return errors.Errorf("missing meta data of column chunk %d in row group %d", j, i)
+			}
+			start := chunkStart(cc.MetaData)
+			if start < end {
+				return errors.Errorf("column chunk %q of row group %d starts at offset %d before the end of the previous column chunk at offset %d, the file can't be read without seeking",
+					strings.Join(cc.MetaData.PathInSchema, "."), i, start, end)
+			}
+			end = start + cc.MetaData.TotalCompressedSize
+		}
+	}
+	return nil
+}
+
+// forwardReader is an io.ReadSeeker of a stream that can only seek forwards, which discards the bytes up to the new
+// offset.
+type forwardReader struct {
+	r      io.Reader
+	offset int64
+}
+
+func (f *forwardReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *forwardReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	default:
+		return f.offset, errors.New("can't seek relative to the end of a stream")
+	}
+
+	if offset < f.offset {
+		return f.offset, errors.Errorf("can't seek back from offset %d to offset %d in a stream", f.offset, offset)
+	}
+	n, err := io.CopyN(ioutil.Discard, f.r, offset-f.offset)
+	f.offset += n
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return f.offset, err
+}
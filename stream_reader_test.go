@@ -0,0 +1,160 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readerOnly hides all methods of the reader but Read.
+type readerOnly struct {
+	r io.Reader
+}
+
+func (r readerOnly) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func TestStreamReader(t *testing.T) {
+	data := writeWideFile(t, 25000)
+	meta, _, err := ReadFileMetaData(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, meta.RowGroups, 3)
+
+	readAll := func(r *FileReader) []map[string]interface{} {
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				return rows
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+	}
+
+	fr, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	expected := readAll(fr)
+
+	// the file is read through a pipe
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := pw.Write(data)
+		pw.CloseWithError(err)
+	}()
+	sr, err := NewStreamReader(pr, meta)
+	require.NoError(t, err)
+	require.Equal(t, int64(25000), sr.NumRows())
+	require.Equal(t, expected, readAll(sr))
+
+	// the chunks of the columns that are not selected are skipped
+	fr, err = NewFileReader(bytes.NewReader(data), "c3", "c10")
+	require.NoError(t, err)
+	expected = readAll(fr)
+	sr, err = NewStreamReader(readerOnly{bytes.NewReader(data)}, meta, WithColumns("c3", "c10"))
+	require.NoError(t, err)
+	require.Equal(t, expected, readAll(sr))
+
+	// reading the pages of a column chunk needs seeking back
+	sr, err = NewStreamReader(readerOnly{bytes.NewReader(data)}, meta)
+	require.NoError(t, err)
+	_, err = sr.NextRow()
+	require.NoError(t, err)
+	_, err = sr.ReadColumnRows(0, "c0", 0, 10)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can't seek back")
+
+	_, err = NewStreamReader(readerOnly{bytes.NewReader(data[4:])}, meta)
+	require.EqualError(t, err, fmt.Sprintf("invalid parquet file header %q", data[4:8]))
+	_, err = NewStreamReader(readerOnly{bytes.NewReader(data)}, nil)
+	require.EqualError(t, err, "no file meta data")
+
+	// a truncated stream fails
+	sr, err = NewStreamReader(readerOnly{bytes.NewReader(data[:len(data)/2])}, meta)
+	require.NoError(t, err)
+	var readErr error
+	for readErr == nil {
+		_, readErr = sr.NextRow()
+	}
+	require.NotEqual(t, io.EOF, readErr)
+
+	// the column chunks must be in the order of the meta data
+	columns := meta.RowGroups[1].Columns
+	columns[3], columns[4] = columns[4], columns[3]
+	_, err = NewStreamReader(readerOnly{bytes.NewReader(data)}, meta)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `column chunk "c3" of row group 1 starts at offset`)
+	require.Contains(t, err.Error(), "the file can't be read without seeking")
+
+	columns[3], columns[4] = columns[4], columns[3]
+	columns[3].MetaData = nil
+	_, err = NewStreamReader(readerOnly{bytes.NewReader(data)}, meta)
+	require.EqualError(t, err, "missing meta data of column chunk 3 in row group 1")
+}
+
+func TestForwardReader(t *testing.T) {
+	r := &forwardReader{r: bytes.NewReader([]byte("0123456789"))}
+	pos, err := r.Seek(2, io.SeekStart)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), pos)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	require.Equal(t, "234", string(buf))
+
+	pos, err = r.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), pos)
+	pos, err = r.Seek(2, io.SeekCurrent)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), pos)
+
+	_, err = r.Seek(6, io.SeekStart)
+	require.EqualError(t, err, "can't seek back from offset 7 to offset 6 in a stream")
+	_, err = r.Seek(-1, io.SeekEnd)
+	require.EqualError(t, err, "can't seek relative to the end of a stream")
+	pos, err = r.Seek(20, io.SeekStart)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, int64(10), pos)
+}
+
+func TestStreamReaderGoldenFiles(t *testing.T) {
+	// the files were assembled from the format spec, in the layouts of other writers, with dictionary pages, data
+	// page v2, several row groups, and page indexes after the row groups
+	for _, file := range []string{
+		"testdata/gzip.parquet",
+		"testdata/data_page_v2.parquet",
+		"testdata/sorting_columns.parquet",
+		"testdata/column_index.parquet",
+		"testdata/dremel_document.parquet",
+	} {
+		t.Run(file, func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			meta, _, err := ReadFileMetaData(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			fr, err := NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			sr, err := NewStreamReader(readerOnly{bytes.NewReader(data)}, meta)
+			require.NoError(t, err)
+			for {
+				expected, err := fr.NextRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				row, err := sr.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, expected, row)
+			}
+			_, err = sr.NextRow()
+			require.Equal(t, io.EOF, err)
+		})
+	}
+}
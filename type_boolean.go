@@ -7,62 +7,37 @@ import (
 	"github.com/pkg/errors"
 )
 
+// booleanPlainDecoder decodes the bit packed booleans (LSB first). One byte holds 8 values, so the decoder keeps
+// the current byte between decodeValues calls to continue in the middle of it.
 type booleanPlainDecoder struct {
-	r    io.Reader
-	left []bool
-}
-
-// copy the left overs from the previous call. instead of returning an empty subset of the old slice,
-// it delete the slice (by returning nil) so there is no memory leak because of the underlying array
-// the return value is the new left over and the number of read message
-func copyLeftOvers(dst []interface{}, src []bool) ([]bool, int) {
-	size := len(dst)
-	var clean bool
-	if len(src) <= size {
-		size = len(src)
-		clean = true
-	}
-
-	for i := 0; i < size; i++ {
-		dst[i] = src[i]
-	}
-	if clean {
-		return nil, size
-	}
+	r io.Reader
 
-	return src[size:], size
+	buf       [1]byte
+	current   byte
+	remaining int // number of values left in the current byte
 }
 
 func (b *booleanPlainDecoder) init(r io.Reader) error {
 	b.r = r
-	b.left = nil
+	b.current = 0
+	b.remaining = 0
 
 	return nil
 }
 
 func (b *booleanPlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var start int
-	if len(b.left) > 0 {
-		// there is a leftover from the last run
-		b.left, start = copyLeftOvers(dst, b.left)
-		if b.left != nil {
-			return len(dst), nil
-		}
-	}
-
-	buf := make([]byte, 1)
-	for i := start; i < len(dst); i += 8 {
-		if _, err := io.ReadFull(b.r, buf); err != nil {
-			return i, err
-		}
-		d := unpack8int32_1(buf)
-		for j := 0; j < 8; j++ {
-			if i+j < len(dst) {
-				dst[i+j] = d[j] == 1
-			} else {
-				b.left = append(b.left, d[j] == 1)
+	for i := range dst {
+		if b.remaining == 0 {
+			if _, err := io.ReadFull(b.r, b.buf[:]); err != nil {
+				return i, err
 			}
+			b.current = b.buf[0]
+			b.remaining = 8
 		}
+
+		dst[i] = b.current&1 == 1
+		b.current >>= 1
+		b.remaining--
 	}
 
 	return len(dst), nil
@@ -74,10 +49,20 @@ type booleanPlainEncoder struct {
 }
 
 func (b *booleanPlainEncoder) Close() error {
-	b.data.flush()
+	// the last byte is padded with zeros
+	if b.data.bufPos > 0 {
+		b.data.flush()
+	}
 	return writeFull(b.w, b.data.data)
 }
 
+func (b *booleanPlainEncoder) estimatedSize() int {
+	if b.data.bufPos > 0 {
+		return len(b.data.data) + 1
+	}
+	return len(b.data.data)
+}
+
 func (b *booleanPlainEncoder) init(w io.Writer) error {
 	b.w = w
 	b.data = &packedArray{}
@@ -127,6 +112,10 @@ func (b *booleanRLEEncoder) Close() error {
 	return b.encoder.Close()
 }
 
+func (b *booleanRLEEncoder) estimatedSize() int {
+	return b.encoder.estimatedSize()
+}
+
 func (b *booleanRLEEncoder) init(w io.Writer) error {
 	b.encoder = newHybridEncoder(1)
 	return b.encoder.initSize(w)
@@ -175,14 +164,6 @@ func (b *booleanStore) reset(repetitionType parquet.FieldRepetitionType) {
 	b.repTyp = repetitionType
 }
 
-func (b *booleanStore) maxValue() []byte {
-	return nil
-}
-
-func (b *booleanStore) minValue() []byte {
-	return nil
-}
-
 func (b *booleanStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"math/bits"
+	"reflect"
 
 	"github.com/fraugster/parquet-go/parquet"
 
@@ -14,19 +16,92 @@ type byteArrayPlainDecoder struct {
 	r io.Reader
 	// if the length is set, then this is a fix size array decoder, unless it reads the len first
 	length int
+	// column is the fixed length column of the decoder, if it is known the length is validated against it in init
+	column *parquet.SchemaElement
+
+	lenBuf [4]byte
+	values [][]byte
+
+	// when useScratch is set, the values are read into the scratch arena instead of a new slice for each value
+	useScratch bool
+	scratch    []byte
 }
 
 func (b *byteArrayPlainDecoder) init(r io.Reader) error {
 	b.r = r
+	if err := checkFixedLength(b.column, b.length); err != nil {
+		return err
+	}
+
+	// there is no length before the values, so the page must be an exact number of values
+	if remaining, ok := remainingBytes(r); ok && b.length > 0 && remaining%b.length != 0 {
+		return wrapColumn(b.column, errors.Errorf("bytearray/plain: the page has %d byte, which is not a multiple of the value length %d", remaining, b.length))
+	}
+	return nil
+}
+
+// checkFixedLength validates the length of a fixed length byte array decoder or encoder against the type length of
+// its column. There is nothing to check if the column is not known.
+func checkFixedLength(column *parquet.SchemaElement, length int) error {
+	if column == nil || column.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY {
+		return nil
+	}
+
+	if column.TypeLength == nil {
+		return errors.Errorf("column %q: FIXED_LEN_BYTE_ARRAY without type length", column.Name)
+	}
+	if typeLen := int(*column.TypeLength); typeLen <= 0 {
+		return errors.Errorf("column %q: the type length must be positive, it is %d", column.Name, typeLen)
+	} else if typeLen != length {
+		return errors.Errorf("column %q: the byte array length is %d, but the type length is %d", column.Name, length, typeLen)
+	}
+
 	return nil
 }
 
+// wrapColumn adds the column name to err, if the column is known.
+func wrapColumn(column *parquet.SchemaElement, err error) error {
+	if column == nil {
+		return err
+	}
+	return errors.Wrapf(err, "column %q", column.Name)
+}
+
+// setScratch makes the decoder read the values into buf instead of allocating every value on its own. The returned
+// values share the arena and are only valid until the next decode call, the caller must copy them to keep them. The
+// arena grows if buf is too small for a batch. A nil buf restores the default, where every value is a new slice.
+func (b *byteArrayPlainDecoder) setScratch(buf []byte) {
+	b.useScratch = buf != nil
+	b.scratch = buf[:0]
+}
+
+// alloc returns a slice of size l, from the scratch arena if it is enabled
+func (b *byteArrayPlainDecoder) alloc(l int) []byte {
+	if !b.useScratch {
+		return make([]byte, l)
+	}
+
+	if cap(b.scratch)-len(b.scratch) < l {
+		// the values in the old arena stay valid, so there is no need to copy them
+		size := 2 * cap(b.scratch)
+		if size < l {
+			size = l
+		}
+		b.scratch = make([]byte, 0, size)
+	}
+
+	start := len(b.scratch)
+	b.scratch = b.scratch[:start+l]
+	return b.scratch[start : start+l : start+l]
+}
+
 func (b *byteArrayPlainDecoder) next() ([]byte, error) {
 	var l = int32(b.length)
 	if l == 0 {
-		if err := binary.Read(b.r, binary.LittleEndian, &l); err != nil {
+		if _, err := io.ReadFull(b.r, b.lenBuf[:]); err != nil {
 			return nil, err
 		}
+		l = int32(binary.LittleEndian.Uint32(b.lenBuf[:]))
 
 		if l < 0 {
 			return nil, errors.New("bytearray/plain: len is negative")
@@ -35,16 +110,30 @@ func (b *byteArrayPlainDecoder) next() ([]byte, error) {
 		return nil, errors.New("bytearray/plain: len is negative")
 	}
 
-	buf := make([]byte, l)
+	if remaining, ok := remainingBytes(b.r); ok && int(l) > remaining {
+		if b.length > 0 && remaining == 0 {
+			return nil, io.EOF
+		}
+		return nil, errors.Wrapf(io.ErrUnexpectedEOF, "bytearray/plain: the value is %d byte, but only %d byte are left in the page", l, remaining)
+	}
+
+	buf := b.alloc(int(l))
 	_, err := io.ReadFull(b.r, buf)
 	if err != nil {
+		if b.length == 0 {
+			// the length is already read, so the value must be there
+			err = unexpectedEOF(err)
+		}
 		return nil, err
 	}
 
 	return buf, nil
 }
 
-func (b *byteArrayPlainDecoder) decodeValues(dst []interface{}) (int, error) {
+func (b *byteArrayPlainDecoder) decodeByteArrayBatch(dst [][]byte) (int, error) {
+	// the values of the previous batch are not valid anymore
+	b.scratch = b.scratch[:0]
+
 	var err error
 	for i := range dst {
 		if dst[i], err = b.next(); err != nil {
@@ -54,30 +143,65 @@ func (b *byteArrayPlainDecoder) decodeValues(dst []interface{}) (int, error) {
 	return len(dst), nil
 }
 
+func (b *byteArrayPlainDecoder) decodeValues(dst []interface{}) (int, error) {
+	b.values = growByteArrays(b.values, len(dst))
+	n, err := b.decodeByteArrayBatch(b.values)
+	boxByteArrays(dst, b.values[:n])
+	return n, err
+}
+
+// growByteArrays returns a slice of n values, it reuses the array of buf if it is big enough.
+func growByteArrays(buf [][]byte, n int) [][]byte {
+	if cap(buf) < n {
+		return make([][]byte, n)
+	}
+	return buf[:n]
+}
+
+// boxByteArrays copies the values into dst and clears them in the scratch slice, so the scratch slice doesn't keep
+// the decoded values alive.
+func boxByteArrays(dst []interface{}, values [][]byte) {
+	for i := range values {
+		dst[i] = values[i]
+		values[i] = nil
+	}
+}
+
 type byteArrayPlainEncoder struct {
-	w io.Writer
+	w    io.Writer
+	size int
 
 	length int
+	// column is the fixed length column of the encoder, if it is known the length is validated against it in init
+	column *parquet.SchemaElement
+	lenBuf [4]byte
 }
 
 func (b *byteArrayPlainEncoder) init(w io.Writer) error {
 	b.w = w
+	b.size = 0
 
-	return nil
+	return checkFixedLength(b.column, b.length)
+}
+
+func (b *byteArrayPlainEncoder) estimatedSize() int {
+	return b.size
 }
 
 func (b *byteArrayPlainEncoder) writeBytes(data []byte) error {
 	l := b.length
 	if l == 0 { // variable length
 		l = len(data)
-		l32 := int32(l)
-		if err := binary.Write(b.w, binary.LittleEndian, l32); err != nil {
+		binary.LittleEndian.PutUint32(b.lenBuf[:], uint32(l))
+		if err := writeFull(b.w, b.lenBuf[:]); err != nil {
 			return err
 		}
+		b.size += 4
 	} else if len(data) != l {
 		return errors.Errorf("the byte array should be with length %d but is %d", l, len(data))
 	}
 
+	b.size += len(data)
 	return writeFull(b.w, data)
 }
 
@@ -91,6 +215,16 @@ func (b *byteArrayPlainEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+func (b *byteArrayPlainEncoder) encodeByteArrays(values [][]byte) error {
+	for i := range values {
+		if err := b.writeBytes(values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (*byteArrayPlainEncoder) Close() error {
 	return nil
 }
@@ -99,18 +233,48 @@ type byteArrayDeltaLengthDecoder struct {
 	r        io.Reader
 	position int
 	lens     []int32
+
+	// the limit of the number of lens, see setMaxValues
+	limitValues bool
+	maxValues   int32
+
+	values [][]byte
+}
+
+func (b *byteArrayDeltaLengthDecoder) setMaxValues(n int32) {
+	b.limitValues = true
+	b.maxValues = n
 }
 
 func (b *byteArrayDeltaLengthDecoder) init(r io.Reader) error {
 	b.r = r
 	b.position = 0
 	lensDecoder := int32DeltaBPDecoder{}
+	if b.limitValues {
+		lensDecoder.setMaxValues(b.maxValues)
+	}
 	if err := lensDecoder.init(r); err != nil {
 		return err
 	}
 
 	b.lens = make([]int32, lensDecoder.valuesCount)
-	return decodeInt32(&lensDecoder, b.lens)
+	if err := decodeInt32(&lensDecoder, b.lens); err != nil {
+		return err
+	}
+
+	// check all the lens before the first value is allocated
+	var total int64
+	for i, l := range b.lens {
+		if l < 0 {
+			return errors.Errorf("bytearray/delta: len of the value at position %d is negative", i)
+		}
+		total += int64(l)
+	}
+	if remaining, ok := remainingBytes(r); ok && total > int64(remaining) {
+		return errors.Errorf("bytearray/delta: the values are %d byte in total, but only %d byte are left in the page", total, remaining)
+	}
+
+	return nil
 }
 
 func (b *byteArrayDeltaLengthDecoder) next() ([]byte, error) {
@@ -120,14 +284,14 @@ func (b *byteArrayDeltaLengthDecoder) next() ([]byte, error) {
 	size := int(b.lens[b.position])
 	value := make([]byte, size)
 	if _, err := io.ReadFull(b.r, value); err != nil {
-		return nil, errors.Wrap(err, "there is no byte left")
+		return nil, errors.Wrap(unexpectedEOF(err), "there is no byte left")
 	}
 	b.position++
 
 	return value, nil
 }
 
-func (b *byteArrayDeltaLengthDecoder) decodeValues(dst []interface{}) (int, error) {
+func (b *byteArrayDeltaLengthDecoder) decodeByteArrayBatch(dst [][]byte) (int, error) {
 	total := len(dst)
 	for i := 0; i < total; i++ {
 		v, err := b.next()
@@ -139,28 +303,59 @@ func (b *byteArrayDeltaLengthDecoder) decodeValues(dst []interface{}) (int, erro
 	return total, nil
 }
 
+func (b *byteArrayDeltaLengthDecoder) decodeValues(dst []interface{}) (int, error) {
+	b.values = growByteArrays(b.values, len(dst))
+	n, err := b.decodeByteArrayBatch(b.values)
+	boxByteArrays(dst, b.values[:n])
+	return n, err
+}
+
 // this type is used inside the byteArrayDeltaEncoder, the Close method should do the actual write, not before.
 type byteArrayDeltaLengthEncoder struct {
 	w    io.Writer
 	buf  *bytes.Buffer
-	lens []interface{}
+	lens []int32
+	// lensBits is the sum of the bit widths of the lens, for the size estimation
+	lensBits int
+
+	// the block size and mini block count of the lens encoder, if not set the defaults are used
+	blockSize      int
+	miniBlockCount int
+}
+
+// newLensEncoder returns the delta encoder for the lengths with the block size and the mini block count, or
+// the defaults if they are not set.
+func newLensEncoder(blockSize, miniBlockCount int) *int32DeltaBPEncoder {
+	if blockSize == 0 && miniBlockCount == 0 {
+		blockSize, miniBlockCount = defaultDeltaBlockSize, defaultDeltaMiniBlockCount
+	}
+
+	return &int32DeltaBPEncoder{
+		deltaBitPackEncoder32: deltaBitPackEncoder32{
+			blockSize:      blockSize,
+			miniBlockCount: miniBlockCount,
+		},
+	}
 }
 
 func (b *byteArrayDeltaLengthEncoder) init(w io.Writer) error {
 	b.w = w
 	b.buf = &bytes.Buffer{}
+	b.lens = b.lens[:0]
+	b.lensBits = 0
 	return nil
 }
 
 func (b *byteArrayDeltaLengthEncoder) writeOne(data []byte) error {
 	b.lens = append(b.lens, int32(len(data)))
+	b.lensBits += bits.Len(uint(len(data)))
 	return writeFull(b.buf, data)
 }
 
 func (b *byteArrayDeltaLengthEncoder) encodeValues(values []interface{}) error {
 	if b.lens == nil {
 		// this is just for the first time, maybe we need to copy and increase the cap in the next calls?
-		b.lens = make([]interface{}, 0, len(values))
+		b.lens = make([]int32, 0, len(values))
 	}
 	for i := range values {
 		if err := b.writeOne(values[i].([]byte)); err != nil {
@@ -171,29 +366,76 @@ func (b *byteArrayDeltaLengthEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
-func (b *byteArrayDeltaLengthEncoder) Close() error {
-	enc := &int32DeltaBPEncoder{
-		deltaBitPackEncoder32: deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
+func (b *byteArrayDeltaLengthEncoder) encodeByteArrays(values [][]byte) error {
+	if b.lens == nil {
+		b.lens = make([]int32, 0, len(values))
+	}
+	for i := range values {
+		if err := b.writeOne(values[i]); err != nil {
+			return err
+		}
 	}
 
-	if err := encodeValue(b.w, enc, b.lens); err != nil {
+	return nil
+}
+
+// estimatedSize estimates the delta encoded lens with the bit widths of the lens themselves plus the block header.
+func (b *byteArrayDeltaLengthEncoder) estimatedSize() int {
+	return b.buf.Len() + estimateLensSize(len(b.lens), b.lensBits)
+}
+
+// estimateLensSize estimates the size of count delta encoded lengths, lensBits is the sum of their bit widths.
+func estimateLensSize(count, lensBits int) int {
+	if count == 0 {
+		return 0
+	}
+	return 4*binary.MaxVarintLen64 + (lensBits+7)/8
+}
+
+func (b *byteArrayDeltaLengthEncoder) Close() error {
+	return encodeLens(b.w, newLensEncoder(b.blockSize, b.miniBlockCount), b.lens, b.buf.Bytes())
+}
+
+// encodeLens writes the lens with the delta encoder enc, followed by the data.
+func encodeLens(w io.Writer, enc *int32DeltaBPEncoder, lens []int32, data []byte) error {
+	if err := enc.init(w); err != nil {
+		return err
+	}
+	if err := enc.encodeInt32s(lens); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
 		return err
 	}
 
-	return writeFull(b.w, b.buf.Bytes())
+	return writeFull(w, data)
 }
 
 type byteArrayDeltaDecoder struct {
 	suffixDecoder byteArrayDeltaLengthDecoder
 	prefixLens    []int32
 	previousValue []byte
+
+	// if the length is set, then this is a fix size array decoder and every value must have this length
+	length int
+	column *parquet.SchemaElement
+
+	values [][]byte
+}
+
+func (d *byteArrayDeltaDecoder) setMaxValues(n int32) {
+	d.suffixDecoder.setMaxValues(n)
 }
 
 func (d *byteArrayDeltaDecoder) init(r io.Reader) error {
+	if err := checkFixedLength(d.column, d.length); err != nil {
+		return err
+	}
+
 	lensDecoder := deltaBitPackDecoder32{}
+	if d.suffixDecoder.limitValues {
+		lensDecoder.setMaxValues(d.suffixDecoder.maxValues)
+	}
 	if err := lensDecoder.init(r); err != nil {
 		return err
 	}
@@ -215,6 +457,13 @@ func (d *byteArrayDeltaDecoder) init(r io.Reader) error {
 }
 
 func (d *byteArrayDeltaDecoder) decodeValues(dst []interface{}) (int, error) {
+	d.values = growByteArrays(d.values, len(dst))
+	n, err := d.decodeByteArrayBatch(d.values)
+	boxByteArrays(dst, d.values[:n])
+	return n, err
+}
+
+func (d *byteArrayDeltaDecoder) decodeByteArrayBatch(dst [][]byte) (int, error) {
 	total := len(dst)
 	for i := 0; i < total; i++ {
 		suffix, err := d.suffixDecoder.next()
@@ -223,15 +472,18 @@ func (d *byteArrayDeltaDecoder) decodeValues(dst []interface{}) (int, error) {
 		}
 		// after this line no error is acceptable
 		prefixLen := int(d.prefixLens[d.suffixDecoder.position-1])
-		value := make([]byte, 0, prefixLen+len(suffix))
-		if len(d.previousValue) < prefixLen {
+		if prefixLen < 0 || len(d.previousValue) < prefixLen {
 			// prevent panic from invalid input
-			return 0, errors.Errorf("invalid prefix len in the stream, the value is %d byte but the it needs %d byte", len(d.previousValue), prefixLen)
+			return i, errors.Errorf("invalid prefix len in the stream, the value is %d byte but the it needs %d byte", len(d.previousValue), prefixLen)
 		}
+		value := make([]byte, 0, prefixLen+len(suffix))
 		if prefixLen > 0 {
 			value = append(value, d.previousValue[:prefixLen]...)
 		}
 		value = append(value, suffix...)
+		if d.length > 0 && len(value) != d.length {
+			return i, errors.Errorf("bytearray/delta: the value at position %d is %d byte, but it should be %d byte", d.suffixDecoder.position-1, len(value), d.length)
+		}
 		d.previousValue = value
 		dst[i] = value
 	}
@@ -242,49 +494,89 @@ func (d *byteArrayDeltaDecoder) decodeValues(dst []interface{}) (int, error) {
 type byteArrayDeltaEncoder struct {
 	w io.Writer
 
-	prefixLens    []interface{}
+	// if the length is set, then this is a fix size array encoder
+	length int
+	column *parquet.SchemaElement
+
+	// the block size and mini block count of the lens encoders, if not set the defaults are used
+	blockSize      int
+	miniBlockCount int
+
+	prefixLens    []int32
+	prefixBits    int
 	previousValue []byte
 
 	values *byteArrayDeltaLengthEncoder
 }
 
 func (b *byteArrayDeltaEncoder) init(w io.Writer) error {
+	if err := checkFixedLength(b.column, b.length); err != nil {
+		return err
+	}
+
 	b.w = w
 	b.prefixLens = nil
+	b.prefixBits = 0
 	b.previousValue = []byte{}
-	b.values = &byteArrayDeltaLengthEncoder{}
+	b.values = &byteArrayDeltaLengthEncoder{
+		blockSize:      b.blockSize,
+		miniBlockCount: b.miniBlockCount,
+	}
 	return b.values.init(w)
 }
 
 func (b *byteArrayDeltaEncoder) encodeValues(values []interface{}) error {
 	if b.prefixLens == nil {
-		b.prefixLens = make([]interface{}, 0, len(values))
-		b.values.lens = make([]interface{}, 0, len(values))
+		b.prefixLens = make([]int32, 0, len(values))
+		b.values.lens = make([]int32, 0, len(values))
 	}
 
 	for i := range values {
-		data := values[i].([]byte)
-		pLen := prefix(b.previousValue, data)
-		b.prefixLens = append(b.prefixLens, int32(pLen))
-		if err := b.values.writeOne(data[pLen:]); err != nil {
+		if err := b.encodeOne(values[i].([]byte)); err != nil {
 			return err
 		}
-		b.previousValue = data
 	}
 
 	return nil
 }
 
-func (b *byteArrayDeltaEncoder) Close() error {
-	// write the lens first
-	enc := &int32DeltaBPEncoder{
-		deltaBitPackEncoder32: deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
+func (b *byteArrayDeltaEncoder) encodeByteArrays(values [][]byte) error {
+	if b.prefixLens == nil {
+		b.prefixLens = make([]int32, 0, len(values))
+		b.values.lens = make([]int32, 0, len(values))
+	}
+
+	for i := range values {
+		if err := b.encodeOne(values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *byteArrayDeltaEncoder) encodeOne(data []byte) error {
+	if b.length > 0 && len(data) != b.length {
+		return errors.Errorf("the byte array should be with length %d but is %d", b.length, len(data))
+	}
+	pLen := prefix(b.previousValue, data)
+	b.prefixLens = append(b.prefixLens, int32(pLen))
+	b.prefixBits += bits.Len(uint(pLen))
+	if err := b.values.writeOne(data[pLen:]); err != nil {
+		return err
 	}
+	b.previousValue = data
+
+	return nil
+}
 
-	if err := encodeValue(b.w, enc, b.prefixLens); err != nil {
+func (b *byteArrayDeltaEncoder) estimatedSize() int {
+	return b.values.estimatedSize() + estimateLensSize(len(b.prefixLens), b.prefixBits)
+}
+
+func (b *byteArrayDeltaEncoder) Close() error {
+	// write the lens first
+	if err := encodeLens(b.w, newLensEncoder(b.blockSize, b.miniBlockCount), b.prefixLens, nil); err != nil {
 		return err
 	}
 
@@ -292,8 +584,7 @@ func (b *byteArrayDeltaEncoder) Close() error {
 }
 
 type byteArrayStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max []byte
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -322,43 +613,13 @@ func (is *byteArrayStore) repetitionType() parquet.FieldRepetitionType {
 
 func (is *byteArrayStore) reset(repetitionType parquet.FieldRepetitionType) {
 	is.repTyp = repetitionType
-	is.min = nil
-	is.max = nil
-}
-
-func (is *byteArrayStore) maxValue() []byte {
-	return is.max
 }
 
-func (is *byteArrayStore) minValue() []byte {
-	return is.min
-}
-
-func (is *byteArrayStore) setMinMax(j []byte) error {
-	if is.TypeLength != nil && *is.TypeLength > 0 && int32(len(j)) != *is.TypeLength {
-		return errors.Errorf("the size of data should be %d but is %d", *is.TypeLength, len(j))
-	}
-	// For nil value there is no need to set the min/max
-	if j == nil {
-		return nil
-	}
-	if is.max == nil || is.min == nil {
-		is.min = j
-		is.max = j
-		return nil
-	}
-
-	if bytes.Compare(j, is.min) < 0 {
-		is.min = j
-	}
-	if bytes.Compare(j, is.max) > 0 {
-		is.max = j
+func (is *byteArrayStore) getValues(v interface{}) ([]interface{}, error) {
+	if is.LogicalType != nil && is.LogicalType.IsSetUUID() {
+		return is.getUUIDValues(v)
 	}
 
-	return nil
-}
-
-func (is *byteArrayStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case []byte:
@@ -375,6 +636,46 @@ func (is *byteArrayStore) getValues(v interface{}) ([]interface{}, error) {
 		return nil, errors.Errorf("unsupported type for storing in []byte column %T => %+v", v, v)
 	}
 
+	for _, val := range vals {
+		if err := is.checkLength(val.([]byte)); err != nil {
+			return nil, err
+		}
+	}
+
+	return vals, nil
+}
+
+// checkLength returns an error if the column is a FIXED_LEN_BYTE_ARRAY and the value doesn't have its length.
+func (is *byteArrayStore) checkLength(v []byte) error {
+	if is.TypeLength != nil && *is.TypeLength > 0 && int32(len(v)) != *is.TypeLength {
+		return errors.Errorf("the size of data should be %d but is %d", *is.TypeLength, len(v))
+	}
+	return nil
+}
+
+// getUUIDValues returns the values of a UUID column, which can be given as []byte, [16]byte or string in the canonical
+// format "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", or as slices of them for repeated columns.
+func (is *byteArrayStore) getUUIDValues(v interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if _, ok := v.([]byte); ok || rv.Kind() != reflect.Slice {
+		b, err := uuidBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{b}, nil
+	}
+
+	if is.repTyp != parquet.FieldRepetitionType_REPEATED {
+		return nil, errors.Errorf("the value is not repeated but it is an array")
+	}
+	vals := make([]interface{}, rv.Len())
+	for j := range vals {
+		b, err := uuidBytes(rv.Index(j).Interface())
+		if err != nil {
+			return nil, err
+		}
+		vals[j] = b
+	}
 	return vals, nil
 }
 
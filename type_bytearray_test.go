@@ -1,6 +1,15 @@
 package goparquet
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
 
 func TestFuzzCrashByteArrayPlainDecoderNext(t *testing.T) {
 	data := []byte("PAR1\x15\x00\x15\xac\x02\x15\xac\x02,\x150\x15\x00\x15\x06\x15" +
@@ -25,3 +34,249 @@ func TestFuzzCrashByteArrayPlainDecoderNext(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func fixedDeltaValues() []interface{} {
+	// every value shares a prefix of 0 to 16 bytes with the previous one
+	values := make([]interface{}, 0, 100)
+	previous := make([]byte, 16)
+	for i := 0; i < 100; i++ {
+		pLen := i % 17
+		value := make([]byte, 16)
+		copy(value, previous[:pLen])
+		for j := pLen; j < 16; j++ {
+			value[j] = byte(i + j + 1)
+		}
+		if pLen < 16 && value[pLen] == previous[pLen] {
+			value[pLen]++
+		}
+		values = append(values, value)
+		previous = value
+	}
+
+	return values
+}
+
+func TestByteArrayDeltaFixedLength(t *testing.T) {
+	values := fixedDeltaValues()
+
+	buf := &bytes.Buffer{}
+	enc := &byteArrayDeltaEncoder{length: 16}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encodeValues(values))
+	require.NoError(t, enc.Close())
+
+	dec := &byteArrayDeltaDecoder{length: 16}
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	for i := 0; i < len(values); i++ {
+		require.Equal(t, i%17, int(dec.prefixLens[i]))
+	}
+	read := make([]interface{}, len(values))
+	n, err := dec.decodeValues(read)
+	require.NoError(t, err)
+	require.Equal(t, len(values), n)
+	require.Equal(t, values, read)
+
+	// a reader expecting a different length rejects the values
+	dec = &byteArrayDeltaDecoder{length: 12}
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	_, err = dec.decodeValues(read)
+	require.Error(t, err)
+
+	enc = &byteArrayDeltaEncoder{length: 16}
+	require.NoError(t, enc.init(&bytes.Buffer{}))
+	require.Error(t, enc.encodeValues([]interface{}{make([]byte, 16), make([]byte, 15)}))
+}
+
+func TestWriteThenReadFixedLenDeltaByteArray(t *testing.T) {
+	newWriter := func(buf *bytes.Buffer) *FileWriter {
+		w := NewFileWriter(buf)
+		typeLen := int32(16)
+		s, err := NewFixedByteArrayStore(parquet.Encoding_DELTA_BYTE_ARRAY, false, &ColumnParameters{TypeLength: &typeLen})
+		require.NoError(t, err)
+		require.NoError(t, w.AddColumn("fixed", NewDataColumn(s, parquet.FieldRepetitionType_REQUIRED)))
+		return w
+	}
+
+	values := fixedDeltaValues()
+	buf := &bytes.Buffer{}
+	w := newWriter(buf)
+	for _, v := range values {
+		require.NoError(t, w.AddData(map[string]interface{}{"fixed": v}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for i := range values {
+		row, err := r.NextRow()
+		require.NoError(t, err, fmt.Sprint(i))
+		require.Equal(t, values[i], row["fixed"])
+	}
+
+	w = newWriter(&bytes.Buffer{})
+	err = w.AddData(map[string]interface{}{"fixed": make([]byte, 10)})
+	require.EqualError(t, err, `invalid value of column "fixed": the size of data should be 16 but is 10`)
+	require.NoError(t, w.Close())
+}
+
+func TestFixedByteArrayLength(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required fixed_len_byte_array(4) code;
+		optional group tags (LIST) {
+			repeated group list {
+				required fixed_len_byte_array(2) element;
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"code": []byte("abcd")}))
+	err = w.AddData(map[string]interface{}{"code": []byte("abc")})
+	require.EqualError(t, err, `invalid value of column "code": the size of data should be 4 but is 3`)
+	err = w.AddData(map[string]interface{}{
+		"code": []byte("abcd"),
+		"tags": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": []byte("ab")}, {"element": []byte("abc")}},
+		},
+	})
+	require.EqualError(t, err, `invalid value of column "tags.list.element": the size of data should be 2 but is 3`)
+	// the invalid records were not added
+	require.Equal(t, int64(1), w.rowGroupNumRecords())
+
+	sd, err = parquetschema.ParseSchemaDefinition(`message test { required fixed_len_byte_array(4) code; }`)
+	require.NoError(t, err)
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	err = w.WriteColumns(map[string]interface{}{"code": [][]byte{[]byte("abcd"), []byte("abcde")}})
+	require.EqualError(t, err, `column "code": row 1: the size of data should be 4 but is 5`)
+	err = w.WriteColumns(map[string]interface{}{"code": []interface{}{[]byte("abcd"), []byte("ab")}})
+	require.EqualError(t, err, `column "code": row 1: the size of data should be 4 but is 2`)
+}
+
+func TestByteArrayPlainDecoderScratch(t *testing.T) {
+	values := make([]interface{}, 100)
+	for i := range values {
+		values[i] = []byte(fmt.Sprintf("value %d", i))
+	}
+
+	buf := &bytes.Buffer{}
+	enc := &byteArrayPlainEncoder{}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encodeValues(values))
+	require.NoError(t, enc.Close())
+
+	// the arena is too small on purpose, it has to grow in the middle of a batch
+	dec := &byteArrayPlainDecoder{}
+	dec.setScratch(make([]byte, 0, 16))
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	first := make([][]byte, 50)
+	n, err := dec.decodeByteArrayBatch(first)
+	require.NoError(t, err)
+	require.Equal(t, 50, n)
+	for i := range first {
+		require.Equal(t, values[i], first[i])
+	}
+	kept := append([]byte(nil), first[49]...)
+
+	second := make([][]byte, 50)
+	n, err = dec.decodeByteArrayBatch(second)
+	require.NoError(t, err)
+	require.Equal(t, 50, n)
+	for i := range second {
+		require.Equal(t, values[50+i], second[i])
+	}
+	require.Equal(t, values[49], kept)
+
+	// without the scratch arena the values are still valid after the next call
+	dec.setScratch(nil)
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+	n, err = dec.decodeByteArrayBatch(first)
+	require.NoError(t, err)
+	require.Equal(t, 50, n)
+	n, err = dec.decodeByteArrayBatch(second)
+	require.NoError(t, err)
+	require.Equal(t, 50, n)
+	for i := range first {
+		require.Equal(t, values[i], first[i])
+	}
+}
+
+func BenchmarkByteArrayPlainDecoder(b *testing.B) {
+	const rows = 1000000
+	buf := &bytes.Buffer{}
+	enc := &byteArrayPlainEncoder{}
+	require.NoError(b, enc.init(buf))
+	values := make([][]byte, 1000)
+	for i := 0; i < rows/len(values); i++ {
+		for j := range values {
+			values[j] = []byte(fmt.Sprintf("string value %d", i*len(values)+j))
+		}
+		require.NoError(b, enc.encodeByteArrays(values))
+	}
+	require.NoError(b, enc.Close())
+	data := buf.Bytes()
+
+	for _, scratch := range []bool{false, true} {
+		name := "copy"
+		if scratch {
+			name = "scratch"
+		}
+		b.Run(name, func(b *testing.B) {
+			dec := &byteArrayPlainDecoder{}
+			if scratch {
+				dec.setScratch(make([]byte, 0, 32*1024))
+			}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				require.NoError(b, dec.init(bytes.NewReader(data)))
+				for read := 0; read < rows; read += len(values) {
+					if _, err := dec.decodeByteArrayBatch(values); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestByteArrayDecodersRejectHugeLengths(t *testing.T) {
+	// a plain value that claims to be 2GB, with only 3 byte of data after it
+	data := []byte{0xff, 0xff, 0xff, 0x7f, 'a', 'b', 'c'}
+	dec := &byteArrayPlainDecoder{}
+	require.NoError(t, dec.init(bytes.NewReader(data)))
+	n, err := dec.decodeValues(make([]interface{}, 1))
+	require.EqualError(t, err, "bytearray/plain: the value is 2147483647 byte, but only 3 byte are left in the page: unexpected EOF")
+	require.Equal(t, 0, n)
+
+	buf := &bytes.Buffer{}
+	lens := newLensEncoder(0, 0)
+	require.NoError(t, lens.init(buf))
+	require.NoError(t, lens.encodeInt32s([]int32{3, math.MaxInt32}))
+	require.NoError(t, lens.Close())
+	buf.WriteString("abcdef")
+
+	deltaDec := &byteArrayDeltaLengthDecoder{}
+	err = deltaDec.init(bytes.NewReader(buf.Bytes()))
+	require.EqualError(t, err, "bytearray/delta: the values are 2147483650 byte in total, but only 6 byte are left in the page")
+}
+
+func TestFixedLenByteArrayValidation(t *testing.T) {
+	column := func(l int32) *parquet.SchemaElement {
+		return &parquet.SchemaElement{Name: "fixed", Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: &l}
+	}
+
+	require.EqualError(t, (&byteArrayPlainEncoder{length: 3, column: column(4)}).init(&bytes.Buffer{}),
+		`column "fixed": the byte array length is 3, but the type length is 4`)
+	require.EqualError(t, (&byteArrayDeltaEncoder{length: 0, column: column(0)}).init(&bytes.Buffer{}),
+		`column "fixed": the type length must be positive, it is 0`)
+	require.EqualError(t, (&byteArrayDeltaDecoder{length: 4, column: column(3)}).init(bytes.NewReader(nil)),
+		`column "fixed": the byte array length is 4, but the type length is 3`)
+	require.NoError(t, (&byteArrayPlainEncoder{length: 3, column: column(3)}).init(&bytes.Buffer{}))
+
+	// the page is truncated in the middle of the last value
+	dec := &byteArrayPlainDecoder{length: 3, column: column(3)}
+	require.EqualError(t, dec.init(bytes.NewReader([]byte("abcdefgh"))),
+		`column "fixed": bytearray/plain: the page has 8 byte, which is not a multiple of the value length 3`)
+	require.NoError(t, dec.init(bytes.NewReader([]byte("abcdefghi"))))
+}
@@ -25,34 +25,136 @@ func (d *dictDecoder) init(r io.Reader) error {
 		return err
 	}
 	w := int(buf[0])
-	if w < 0 || w > 32 {
-		return errors.Errorf("invalid bitwidth %d", w)
+	if w > 32 {
+		return errors.Errorf("dict: invalid bit width %d", w)
 	}
-	if w >= 0 {
-		d.keys = newHybridDecoder(w)
-		return d.keys.init(r)
+
+	d.keys = newHybridDecoder(w)
+	return d.keys.init(r)
+}
+
+func (d *dictDecoder) check(n int) error {
+	if d.keys == nil {
+		return errors.New("dict: decoder is not initialized")
+	}
+	if len(d.values) == 0 && n > 0 {
+		return errors.New("dict: no value is inside dictionary")
+	}
+	return nil
+}
+
+// nextValue returns the dictionary value of the next key, pos is the position in the current batch and is only
+// used for the error message.
+func (d *dictDecoder) nextValue(pos int) (interface{}, error) {
+	key, err := d.keys.next()
+	if err != nil {
+		return nil, err
 	}
 
-	return errors.New("bit width zero with non-empty dictionary")
+	if size := int32(len(d.values)); key < 0 || key >= size {
+		return nil, errors.Errorf("dict: invalid index %d at position %d, dictionary contains %d values", key, pos, size)
+	}
+
+	return d.values[key], nil
 }
 
 func (d *dictDecoder) decodeValues(dst []interface{}) (int, error) {
-	if d.keys == nil {
-		return 0, errors.New("no value is inside dictionary")
+	if err := d.check(len(dst)); err != nil {
+		return 0, err
+	}
+
+	for i := range dst {
+		v, err := d.nextValue(i)
+		if err != nil {
+			return i, err
+		}
+		dst[i] = v
+	}
+
+	return len(dst), nil
+}
+
+func (d *dictDecoder) decodeInt32Batch(dst []int32) (int, error) {
+	if err := d.check(len(dst)); err != nil {
+		return 0, err
 	}
-	size := int32(len(d.values))
 
 	for i := range dst {
-		key, err := d.keys.next()
+		v, err := d.nextValue(i)
 		if err != nil {
 			return i, err
 		}
+		switch t := v.(type) {
+		case int32:
+			dst[i] = t
+		case uint32:
+			dst[i] = int32(t)
+		default:
+			return i, errors.Errorf("dict: value of type %T is not an int32", v)
+		}
+	}
+
+	return len(dst), nil
+}
+
+func (d *dictDecoder) decodeInt64Batch(dst []int64) (int, error) {
+	if err := d.check(len(dst)); err != nil {
+		return 0, err
+	}
 
-		if key < 0 || key >= size {
-			return 0, errors.Errorf("dict: invalid index %d, values count are %d", key, size)
+	for i := range dst {
+		v, err := d.nextValue(i)
+		if err != nil {
+			return i, err
+		}
+		switch t := v.(type) {
+		case int64:
+			dst[i] = t
+		case uint64:
+			dst[i] = int64(t)
+		default:
+			return i, errors.Errorf("dict: value of type %T is not an int64", v)
+		}
+	}
+
+	return len(dst), nil
+}
+
+func (d *dictDecoder) decodeFloat64Batch(dst []float64) (int, error) {
+	if err := d.check(len(dst)); err != nil {
+		return 0, err
+	}
+
+	for i := range dst {
+		v, err := d.nextValue(i)
+		if err != nil {
+			return i, err
+		}
+		t, ok := v.(float64)
+		if !ok {
+			return i, errors.Errorf("dict: value of type %T is not a float64", v)
 		}
+		dst[i] = t
+	}
+
+	return len(dst), nil
+}
+
+func (d *dictDecoder) decodeByteArrayBatch(dst [][]byte) (int, error) {
+	if err := d.check(len(dst)); err != nil {
+		return 0, err
+	}
 
-		dst[i] = d.values[key]
+	for i := range dst {
+		v, err := d.nextValue(i)
+		if err != nil {
+			return i, err
+		}
+		t, ok := v.([]byte)
+		if !ok {
+			return i, errors.Errorf("dict: value of type %T is not a byte array", v)
+		}
+		dst[i] = t
 	}
 
 	return len(dst), nil
@@ -67,6 +169,14 @@ type dictStore struct {
 	readPos    int
 	nullCount  int32
 	noDictMode bool
+	// noIndex is set by the writer for the columns that never use a dictionary, their values are only appended and
+	// not hashed to find the distinct values.
+	noIndex bool
+
+	// typed holds the values of a row group that was added with FileWriter.WriteColumns, as []int32, []int64,
+	// []float64 or [][]byte. In this case values and data are empty.
+	typed      interface{}
+	typedCount int32
 }
 
 func (d *dictStore) init() {
@@ -76,6 +186,9 @@ func (d *dictStore) init() {
 	d.nullCount = 0
 	d.readPos = 0
 	d.size = 0
+	d.valueSize = 0
+	d.typed = nil
+	d.typedCount = 0
 }
 
 func (d *dictStore) assemble() []interface{} {
@@ -91,6 +204,11 @@ func (d *dictStore) assemble() []interface{} {
 }
 
 func (d *dictStore) getIndex(in interface{}, size int) int32 {
+	if d.noIndex {
+		d.values = append(d.values, in)
+		return int32(len(d.values) - 1)
+	}
+
 	key := mapKey(in)
 	if idx, ok := d.indices[key]; ok {
 		return idx
@@ -129,6 +247,9 @@ func (d *dictStore) getNextValue() (interface{}, error) {
 }
 
 func (d *dictStore) numValues() int32 {
+	if d.typed != nil {
+		return d.typedCount
+	}
 	return int32(len(d.data))
 }
 
@@ -180,10 +301,28 @@ func (d *dictEncoder) Close() error {
 	return enc.Close()
 }
 
+// estimatedSize returns the size of the bit width byte and the indices as bit-packed runs with the current
+// dictionary size.
+func (d *dictEncoder) estimatedSize() int {
+	if len(d.data) == 0 {
+		return 0
+	}
+	w := bits.Len(uint(len(d.values)))
+	groups := (len(d.data) + 7) / 8
+	return 1 + groups*w + (groups+maxBitPackedGroups-1)/maxBitPackedGroups
+}
+
 func (d *dictEncoder) init(w io.Writer) error {
 	d.w = w
-	d.dictStore.init()
+	if d.indices == nil {
+		d.dictStore.init()
+		return nil
+	}
 
+	// keep the dictionary of the column chunk, only the indices of the page are new
+	d.data = d.data[:0]
+	d.nullCount = 0
+	d.size = 0
 	return nil
 }
 
@@ -195,6 +334,40 @@ func (d *dictEncoder) encodeValues(values []interface{}) error {
 	return nil
 }
 
+// The typed methods of the dictionary encoder still box the values, since they are the keys of the dictionary.
+
+func (d *dictEncoder) encodeInt32s(values []int32) error {
+	for i := range values {
+		d.addValue(values[i], 0)
+	}
+
+	return nil
+}
+
+func (d *dictEncoder) encodeInt64s(values []int64) error {
+	for i := range values {
+		d.addValue(values[i], 0)
+	}
+
+	return nil
+}
+
+func (d *dictEncoder) encodeFloat64s(values []float64) error {
+	for i := range values {
+		d.addValue(values[i], 0)
+	}
+
+	return nil
+}
+
+func (d *dictEncoder) encodeByteArrays(values [][]byte) error {
+	for i := range values {
+		d.addValue(values[i], 0)
+	}
+
+	return nil
+}
+
 // just for tests
 func (d *dictEncoder) getValues() []interface{} {
 	return d.values
@@ -1,8 +1,10 @@
 package goparquet
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/stretchr/testify/require"
 )
 
@@ -172,3 +174,50 @@ func TestFuzzCrashDictDecoderDecodeValues(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func TestDictDecoderByteArray(t *testing.T) {
+	dictValues := []interface{}{[]byte("foo"), []byte("bar"), []byte("baz")}
+	typ := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY)}
+
+	for _, enc := range []parquet.Encoding{parquet.Encoding_PLAIN_DICTIONARY, parquet.Encoding_RLE_DICTIONARY} {
+		dec, err := getValuesDecoder(enc, typ, dictValues)
+		require.NoError(t, err)
+
+		// bit width 2, RLE run of 3 times index 2, followed by a bit-packed run with 0, 1, 2, 1, 0, 0, 0, 0
+		data := []byte{2, 3 << 1, 2, 1<<1 | 1, 0x64, 0x00}
+		require.NoError(t, dec.init(bytes.NewReader(data)))
+
+		dst := make([]interface{}, 8)
+		n, err := dec.decodeValues(dst)
+		require.NoError(t, err)
+		require.Equal(t, 8, n)
+		require.Equal(t, []interface{}{
+			[]byte("baz"), []byte("baz"), []byte("baz"),
+			[]byte("foo"), []byte("bar"), []byte("baz"), []byte("bar"), []byte("foo"),
+		}, dst)
+	}
+}
+
+func TestDictDecoderInvalidIndex(t *testing.T) {
+	dec := &dictDecoder{values: []interface{}{[]byte("foo"), []byte("bar"), []byte("baz")}}
+
+	// bit width 3, bit-packed run with 0, 1, 2, 3, 4, 5, 6, 7
+	require.NoError(t, dec.init(bytes.NewReader([]byte{3, 1<<1 | 1, 0x88, 0xc6, 0xfa})))
+
+	dst := make([]interface{}, 8)
+	n, err := dec.decodeValues(dst)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid index 3 at position 3, dictionary contains 3 values")
+	require.Equal(t, 3, n)
+}
+
+func TestDictDecoderInvalidInput(t *testing.T) {
+	dec := &dictDecoder{values: []interface{}{int32(1)}}
+	require.Error(t, dec.init(bytes.NewReader([]byte{33})))
+	require.Error(t, dec.init(bytes.NewReader(nil)))
+
+	dec = &dictDecoder{}
+	require.NoError(t, dec.init(bytes.NewReader([]byte{1, 8 << 1, 0})))
+	_, err := dec.decodeValues(make([]interface{}, 1))
+	require.Error(t, err)
+}
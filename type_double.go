@@ -11,6 +11,9 @@ import (
 
 type doublePlainDecoder struct {
 	r io.Reader
+
+	buf    []byte
+	values []float64
 }
 
 func (d *doublePlainDecoder) init(r io.Reader) error {
@@ -20,19 +23,47 @@ func (d *doublePlainDecoder) init(r io.Reader) error {
 }
 
 func (d *doublePlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var data uint64
-	for i := range dst {
-		if err := binary.Read(d.r, binary.LittleEndian, &data); err != nil {
-			return i, err
-		}
-		dst[i] = math.Float64frombits(data)
+	d.values = growFloat64s(d.values, len(dst))
+	n, err := d.decodeFloat64Batch(d.values)
+	boxFloat64s(dst, d.values[:n])
+	return n, err
+}
+
+func (d *doublePlainDecoder) decodeFloat64Batch(dst []float64) (int, error) {
+	size := len(dst) * 8
+	if cap(d.buf) < size {
+		d.buf = make([]byte, size)
+	}
+	buf := d.buf[:size]
+
+	n, err := readFixedSize(d.r, buf, 8)
+	for i := 0; i < n; i++ {
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:]))
+	}
+	if err != nil {
+		return n, err
 	}
 
 	return len(dst), nil
 }
 
+// growFloat64s returns a slice of n values, it reuses the array of buf if it is big enough.
+func growFloat64s(buf []float64, n int) []float64 {
+	if cap(buf) < n {
+		return make([]float64, n)
+	}
+	return buf[:n]
+}
+
+func boxFloat64s(dst []interface{}, values []float64) {
+	for i := range values {
+		dst[i] = values[i]
+	}
+}
+
 type doublePlainEncoder struct {
-	w io.Writer
+	w    io.Writer
+	size int
 }
 
 func (d *doublePlainEncoder) Close() error {
@@ -41,22 +72,100 @@ func (d *doublePlainEncoder) Close() error {
 
 func (d *doublePlainEncoder) init(w io.Writer) error {
 	d.w = w
+	d.size = 0
 
 	return nil
 }
 
+func (d *doublePlainEncoder) estimatedSize() int {
+	return d.size
+}
+
 func (d *doublePlainEncoder) encodeValues(values []interface{}) error {
-	data := make([]uint64, len(values))
+	data := make([]byte, len(values)*8)
+	for i := range values {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(values[i].(float64)))
+	}
+
+	d.size += len(data)
+	return writeFull(d.w, data)
+}
+
+func (d *doublePlainEncoder) encodeFloat64s(values []float64) error {
+	data := make([]byte, len(values)*8)
+	for i := range values {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(values[i]))
+	}
+
+	d.size += len(data)
+	return writeFull(d.w, data)
+}
+
+type doubleByteStreamSplitDecoder struct {
+	byteStreamSplitDecoder
+
+	values []float64
+}
+
+func (d *doubleByteStreamSplitDecoder) init(r io.Reader) error {
+	return d.initNumValues(r, -1)
+}
+
+func (d *doubleByteStreamSplitDecoder) initNumValues(r io.Reader, n int32) error {
+	d.width = 8
+	return d.byteStreamSplitDecoder.initNumValues(r, n)
+}
+
+func (d *doubleByteStreamSplitDecoder) decodeFloat64Batch(dst []float64) (int, error) {
+	var buf [8]byte
+	for i := range dst {
+		if err := d.nextValue(buf[:]); err != nil {
+			return i, err
+		}
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+	}
+
+	return len(dst), nil
+}
+
+func (d *doubleByteStreamSplitDecoder) decodeValues(dst []interface{}) (int, error) {
+	d.values = growFloat64s(d.values, len(dst))
+	n, err := d.decodeFloat64Batch(d.values)
+	boxFloat64s(dst, d.values[:n])
+	return n, err
+}
+
+type doubleByteStreamSplitEncoder struct {
+	byteStreamSplitEncoder
+}
+
+func (d *doubleByteStreamSplitEncoder) init(w io.Writer) error {
+	d.width = 8
+	return d.byteStreamSplitEncoder.init(w)
+}
+
+func (d *doubleByteStreamSplitEncoder) encodeValues(values []interface{}) error {
+	var buf [8]byte
 	for i := range values {
-		data[i] = math.Float64bits(values[i].(float64))
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(values[i].(float64)))
+		d.addValue(buf[:])
 	}
 
-	return binary.Write(d.w, binary.LittleEndian, data)
+	return nil
+}
+
+func (d *doubleByteStreamSplitEncoder) encodeFloat64s(values []float64) error {
+	var buf [8]byte
+	for i := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(values[i]))
+		d.addValue(buf[:])
+	}
+
+	return nil
 }
 
 type doubleStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max float64
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -82,42 +191,12 @@ func (f *doubleStore) repetitionType() parquet.FieldRepetitionType {
 
 func (f *doubleStore) reset(rep parquet.FieldRepetitionType) {
 	f.repTyp = rep
-	f.min = math.MaxFloat64
-	f.max = -math.MaxFloat64
-}
-
-func (f *doubleStore) maxValue() []byte {
-	if f.max == -math.MaxFloat64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, math.Float64bits(f.max))
-	return ret
-}
-
-func (f *doubleStore) minValue() []byte {
-	if f.min == math.MaxFloat64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, math.Float64bits(f.min))
-	return ret
-}
-
-func (f *doubleStore) setMinMax(j float64) {
-	if j < f.min {
-		f.min = j
-	}
-	if j > f.max {
-		f.max = j
-	}
 }
 
 func (f *doubleStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case float64:
-		f.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []float64:
 		if f.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -125,7 +204,6 @@ func (f *doubleStore) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			f.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
@@ -12,6 +12,8 @@ import (
 
 type floatPlainDecoder struct {
 	r io.Reader
+
+	buf []byte
 }
 
 func (f *floatPlainDecoder) init(r io.Reader) error {
@@ -21,19 +23,26 @@ func (f *floatPlainDecoder) init(r io.Reader) error {
 }
 
 func (f *floatPlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var data uint32
-	for i := range dst {
-		if err := binary.Read(f.r, binary.LittleEndian, &data); err != nil {
-			return i, err
-		}
-		dst[i] = math.Float32frombits(data)
+	size := len(dst) * 4
+	if cap(f.buf) < size {
+		f.buf = make([]byte, size)
+	}
+	buf := f.buf[:size]
+
+	n, err := readFixedSize(f.r, buf, 4)
+	for i := 0; i < n; i++ {
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	if err != nil {
+		return n, err
 	}
 
 	return len(dst), nil
 }
 
 type floatPlainEncoder struct {
-	w io.Writer
+	w    io.Writer
+	size int
 }
 
 func (d *floatPlainEncoder) Close() error {
@@ -42,22 +51,71 @@ func (d *floatPlainEncoder) Close() error {
 
 func (d *floatPlainEncoder) init(w io.Writer) error {
 	d.w = w
+	d.size = 0
 
 	return nil
 }
 
+func (d *floatPlainEncoder) estimatedSize() int {
+	return d.size
+}
+
 func (d *floatPlainEncoder) encodeValues(values []interface{}) error {
-	data := make([]uint32, len(values))
+	data := make([]byte, len(values)*4)
 	for i := range values {
-		data[i] = math.Float32bits(values[i].(float32))
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(values[i].(float32)))
 	}
 
-	return binary.Write(d.w, binary.LittleEndian, data)
+	d.size += len(data)
+	return writeFull(d.w, data)
+}
+
+type floatByteStreamSplitDecoder struct {
+	byteStreamSplitDecoder
+}
+
+func (d *floatByteStreamSplitDecoder) init(r io.Reader) error {
+	return d.initNumValues(r, -1)
+}
+
+func (d *floatByteStreamSplitDecoder) initNumValues(r io.Reader, n int32) error {
+	d.width = 4
+	return d.byteStreamSplitDecoder.initNumValues(r, n)
+}
+
+func (d *floatByteStreamSplitDecoder) decodeValues(dst []interface{}) (int, error) {
+	var buf [4]byte
+	for i := range dst {
+		if err := d.nextValue(buf[:]); err != nil {
+			return i, err
+		}
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))
+	}
+
+	return len(dst), nil
+}
+
+type floatByteStreamSplitEncoder struct {
+	byteStreamSplitEncoder
+}
+
+func (d *floatByteStreamSplitEncoder) init(w io.Writer) error {
+	d.width = 4
+	return d.byteStreamSplitEncoder.init(w)
+}
+
+func (d *floatByteStreamSplitEncoder) encodeValues(values []interface{}) error {
+	var buf [4]byte
+	for i := range values {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(values[i].(float32)))
+		d.addValue(buf[:])
+	}
+
+	return nil
 }
 
 type floatStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max float32
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -83,42 +141,12 @@ func (f *floatStore) repetitionType() parquet.FieldRepetitionType {
 
 func (f *floatStore) reset(rep parquet.FieldRepetitionType) {
 	f.repTyp = rep
-	f.min = math.MaxFloat32
-	f.max = -math.MaxFloat32
-}
-
-func (f *floatStore) maxValue() []byte {
-	if f.max == -math.MaxFloat32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, math.Float32bits(f.max))
-	return ret
-}
-
-func (f *floatStore) minValue() []byte {
-	if f.min == math.MaxFloat32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, math.Float32bits(f.min))
-	return ret
-}
-
-func (f *floatStore) setMinMax(j float32) {
-	if j < f.min {
-		f.min = j
-	}
-	if j > f.max {
-		f.max = j
-	}
 }
 
 func (f *floatStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case float32:
-		f.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []float32:
 		if f.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -126,7 +154,6 @@ func (f *floatStore) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			f.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
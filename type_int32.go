@@ -3,7 +3,6 @@ package goparquet
 import (
 	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/pkg/errors"
@@ -12,6 +11,9 @@ import (
 type int32PlainDecoder struct {
 	unSigned bool
 	r        io.Reader
+
+	buf    []byte
+	values []int32
 }
 
 func (i *int32PlainDecoder) init(r io.Reader) error {
@@ -20,25 +22,55 @@ func (i *int32PlainDecoder) init(r io.Reader) error {
 	return nil
 }
 
+func (i *int32PlainDecoder) decodeInt32Batch(dst []int32) (int, error) {
+	size := len(dst) * 4
+	if cap(i.buf) < size {
+		i.buf = make([]byte, size)
+	}
+	buf := i.buf[:size]
+
+	n, err := readFixedSize(i.r, buf, 4)
+	for idx := 0; idx < n; idx++ {
+		dst[idx] = int32(binary.LittleEndian.Uint32(buf[idx*4:]))
+	}
+	if err != nil {
+		return n, err
+	}
+
+	return len(dst), nil
+}
+
 func (i *int32PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var d int32
-	for idx := range dst {
-		if err := binary.Read(i.r, binary.LittleEndian, &d); err != nil {
-			return idx, err
-		}
-		if i.unSigned {
-			dst[idx] = uint32(d)
+	i.values = growInt32s(i.values, len(dst))
+	n, err := i.decodeInt32Batch(i.values)
+	boxInt32s(dst, i.values[:n], i.unSigned)
+	return n, err
+}
+
+// growInt32s returns a slice of n values, it reuses the array of buf if it is big enough.
+func growInt32s(buf []int32, n int) []int32 {
+	if cap(buf) < n {
+		return make([]int32, n)
+	}
+	return buf[:n]
+}
+
+func boxInt32s(dst []interface{}, values []int32, unSigned bool) {
+	for i := range values {
+		if unSigned {
+			dst[i] = uint32(values[i])
 		} else {
-			dst[idx] = d
+			dst[i] = values[i]
 		}
 	}
-
-	return len(dst), nil
 }
 
 type int32PlainEncoder struct {
-	unSigned bool
-	w        io.Writer
+	w    io.Writer
+	size int
+
+	buf    []byte
+	values []int32
 }
 
 func (i *int32PlainEncoder) Close() error {
@@ -47,62 +79,81 @@ func (i *int32PlainEncoder) Close() error {
 
 func (i *int32PlainEncoder) init(w io.Writer) error {
 	i.w = w
+	i.size = 0
 
 	return nil
 }
 
+func (i *int32PlainEncoder) estimatedSize() int {
+	return i.size
+}
+
 func (i *int32PlainEncoder) encodeValues(values []interface{}) error {
-	d := make([]int32, len(values))
-	if i.unSigned {
-		for i := range values {
-			d[i] = int32(values[i].(uint32))
-		}
-	} else {
-		for j := range values {
-			d[j] = values[j].(int32)
-		}
+	i.values = growInt32s(i.values, len(values))
+	for j := range values {
+		i.values[j] = int32Value(values[j])
 	}
-	return binary.Write(i.w, binary.LittleEndian, d)
+	return i.encodeInt32s(i.values)
+}
+
+func (i *int32PlainEncoder) encodeInt32s(values []int32) error {
+	size := len(values) * 4
+	if cap(i.buf) < size {
+		i.buf = make([]byte, size)
+	}
+	buf := i.buf[:size]
+
+	for j := range values {
+		binary.LittleEndian.PutUint32(buf[j*4:], uint32(values[j]))
+	}
+	i.size += len(buf)
+	return writeFull(i.w, buf)
 }
 
 type int32DeltaBPDecoder struct {
 	unSigned bool
 	deltaBitPackDecoder32
+
+	values []int32
 }
 
-func (d *int32DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
+func (d *int32DeltaBPDecoder) decodeInt32Batch(dst []int32) (int, error) {
 	for i := range dst {
 		u, err := d.next()
 		if err != nil {
 			return i, err
 		}
-		if d.unSigned {
-			dst[i] = uint32(u)
-		} else {
-			dst[i] = u
-		}
+		dst[i] = u
 	}
 
 	return len(dst), nil
 }
 
+func (d *int32DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
+	d.values = growInt32s(d.values, len(dst))
+	n, err := d.decodeInt32Batch(d.values)
+	boxInt32s(dst, d.values[:n], d.unSigned)
+	return n, err
+}
+
 type int32DeltaBPEncoder struct {
-	unSigned bool
 	deltaBitPackEncoder32
 }
 
-func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
-	if d.unSigned {
-		for i := range values {
-			if err := d.addInt32(int32(values[i].(uint32))); err != nil {
-				return err
-			}
+func (d *int32DeltaBPEncoder) encodeInt32s(values []int32) error {
+	for i := range values {
+		if err := d.addInt32(values[i]); err != nil {
+			return err
 		}
-	} else {
-		for i := range values {
-			if err := d.addInt32(values[i].(int32)); err != nil {
-				return err
-			}
+	}
+
+	return nil
+}
+
+func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
+	for i := range values {
+		if err := d.addInt32(int32Value(values[i])); err != nil {
+			return err
 		}
 	}
 
@@ -110,8 +161,7 @@ func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
 }
 
 type int32Store struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max int32
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -137,42 +187,18 @@ func (is *int32Store) repetitionType() parquet.FieldRepetitionType {
 
 func (is *int32Store) reset(rep parquet.FieldRepetitionType) {
 	is.repTyp = rep
-	is.min = math.MaxInt32
-	is.max = math.MinInt32
 }
 
-func (is *int32Store) maxValue() []byte {
-	if is.max == math.MinInt32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, uint32(is.max))
-	return ret
-}
-
-func (is *int32Store) minValue() []byte {
-	if is.min == math.MaxInt32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, uint32(is.min))
-	return ret
-}
-
-func (is *int32Store) setMinMax(j int32) {
-	if j < is.min {
-		is.min = j
-	}
-	if j > is.max {
-		is.max = j
-	}
+// unsigned returns true if the column has an unsigned integer type, its values can be given as uint32, which are
+// kept as int32 like the values of signed columns.
+func (is *int32Store) unsigned() bool {
+	return isUnsignedInt32(&parquet.SchemaElement{ConvertedType: is.ConvertedType, LogicalType: is.LogicalType})
 }
 
 func (is *int32Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case int32:
-		is.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []int32:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -180,9 +206,24 @@ func (is *int32Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			is.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
+	case uint32:
+		if !is.unsigned() {
+			return nil, errors.Errorf("uint32 values are only supported for unsigned int32 columns")
+		}
+		vals = []interface{}{int32(typed)}
+	case []uint32:
+		if !is.unsigned() {
+			return nil, errors.Errorf("[]uint32 values are only supported for unsigned int32 columns")
+		}
+		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
+			return nil, errors.Errorf("the value is not repeated but it is an array")
+		}
+		vals = make([]interface{}, len(typed))
+		for j := range typed {
+			vals[j] = int32(typed[j])
+		}
 	default:
 		return nil, errors.Errorf("unsupported type for storing in int32 column: %T => %+v", v, v)
 	}
@@ -3,7 +3,6 @@ package goparquet
 import (
 	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/pkg/errors"
@@ -12,6 +11,9 @@ import (
 type int64PlainDecoder struct {
 	unSigned bool
 	r        io.Reader
+
+	buf    []byte
+	values []int64
 }
 
 func (i *int64PlainDecoder) init(r io.Reader) error {
@@ -20,25 +22,55 @@ func (i *int64PlainDecoder) init(r io.Reader) error {
 	return nil
 }
 
+func (i *int64PlainDecoder) decodeInt64Batch(dst []int64) (int, error) {
+	size := len(dst) * 8
+	if cap(i.buf) < size {
+		i.buf = make([]byte, size)
+	}
+	buf := i.buf[:size]
+
+	n, err := readFixedSize(i.r, buf, 8)
+	for idx := 0; idx < n; idx++ {
+		dst[idx] = int64(binary.LittleEndian.Uint64(buf[idx*8:]))
+	}
+	if err != nil {
+		return n, err
+	}
+
+	return len(dst), nil
+}
+
 func (i *int64PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var d int64
+	i.values = growInt64s(i.values, len(dst))
+	n, err := i.decodeInt64Batch(i.values)
+	boxInt64s(dst, i.values[:n], i.unSigned)
+	return n, err
+}
 
-	for idx := range dst {
-		if err := binary.Read(i.r, binary.LittleEndian, &d); err != nil {
-			return idx, err
-		}
-		if i.unSigned {
-			dst[idx] = uint64(d)
+// growInt64s returns a slice of n values, it reuses the array of buf if it is big enough.
+func growInt64s(buf []int64, n int) []int64 {
+	if cap(buf) < n {
+		return make([]int64, n)
+	}
+	return buf[:n]
+}
+
+func boxInt64s(dst []interface{}, values []int64, unSigned bool) {
+	for i := range values {
+		if unSigned {
+			dst[i] = uint64(values[i])
 		} else {
-			dst[idx] = d
+			dst[i] = values[i]
 		}
 	}
-	return len(dst), nil
 }
 
 type int64PlainEncoder struct {
-	unSigned bool
-	w        io.Writer
+	w    io.Writer
+	size int
+
+	buf    []byte
+	values []int64
 }
 
 func (i *int64PlainEncoder) Close() error {
@@ -47,63 +79,81 @@ func (i *int64PlainEncoder) Close() error {
 
 func (i *int64PlainEncoder) init(w io.Writer) error {
 	i.w = w
+	i.size = 0
 
 	return nil
 }
 
+func (i *int64PlainEncoder) estimatedSize() int {
+	return i.size
+}
+
 func (i *int64PlainEncoder) encodeValues(values []interface{}) error {
-	d := make([]int64, len(values))
-	if i.unSigned {
-		for i := range values {
-			d[i] = int64(values[i].(uint64))
-		}
-	} else {
-		for i := range values {
-			d[i] = values[i].(int64)
-		}
+	i.values = growInt64s(i.values, len(values))
+	for j := range values {
+		i.values[j] = int64Value(values[j])
+	}
+	return i.encodeInt64s(i.values)
+}
+
+func (i *int64PlainEncoder) encodeInt64s(values []int64) error {
+	size := len(values) * 8
+	if cap(i.buf) < size {
+		i.buf = make([]byte, size)
+	}
+	buf := i.buf[:size]
+
+	for j := range values {
+		binary.LittleEndian.PutUint64(buf[j*8:], uint64(values[j]))
 	}
-	return binary.Write(i.w, binary.LittleEndian, d)
+	i.size += len(buf)
+	return writeFull(i.w, buf)
 }
 
 type int64DeltaBPDecoder struct {
 	unSigned bool
 	deltaBitPackDecoder64
+
+	values []int64
 }
 
-func (d *int64DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
+func (d *int64DeltaBPDecoder) decodeInt64Batch(dst []int64) (int, error) {
 	for i := range dst {
 		u, err := d.next()
 		if err != nil {
 			return i, err
 		}
-		if d.unSigned {
-			dst[i] = uint64(u)
-		} else {
-			dst[i] = u
-		}
+		dst[i] = u
 	}
 
 	return len(dst), nil
 }
 
-type int64DeltaBPEncoder struct {
-	unSigned bool
+func (d *int64DeltaBPDecoder) decodeValues(dst []interface{}) (int, error) {
+	d.values = growInt64s(d.values, len(dst))
+	n, err := d.decodeInt64Batch(d.values)
+	boxInt64s(dst, d.values[:n], d.unSigned)
+	return n, err
+}
 
+type int64DeltaBPEncoder struct {
 	deltaBitPackEncoder64
 }
 
-func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
-	if d.unSigned {
-		for i := range values {
-			if err := d.addInt64(int64(values[i].(uint64))); err != nil {
-				return err
-			}
+func (d *int64DeltaBPEncoder) encodeInt64s(values []int64) error {
+	for i := range values {
+		if err := d.addInt64(values[i]); err != nil {
+			return err
 		}
-	} else {
-		for i := range values {
-			if err := d.addInt64(values[i].(int64)); err != nil {
-				return err
-			}
+	}
+
+	return nil
+}
+
+func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
+	for i := range values {
+		if err := d.addInt64(int64Value(values[i])); err != nil {
+			return err
 		}
 	}
 
@@ -111,8 +161,7 @@ func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
 }
 
 type int64Store struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max int64
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -138,42 +187,18 @@ func (is *int64Store) repetitionType() parquet.FieldRepetitionType {
 
 func (is *int64Store) reset(rep parquet.FieldRepetitionType) {
 	is.repTyp = rep
-	is.min = math.MaxInt64
-	is.max = math.MinInt64
 }
 
-func (is *int64Store) maxValue() []byte {
-	if is.max == math.MinInt64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, uint64(is.max))
-	return ret
-}
-
-func (is *int64Store) minValue() []byte {
-	if is.min == math.MaxInt64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, uint64(is.min))
-	return ret
-}
-
-func (is *int64Store) setMinMax(j int64) {
-	if j < is.min {
-		is.min = j
-	}
-	if j > is.max {
-		is.max = j
-	}
+// unsigned returns true if the column has an unsigned integer type, its values can be given as uint64, which are
+// kept as int64 like the values of signed columns.
+func (is *int64Store) unsigned() bool {
+	return isUnsignedInt64(&parquet.SchemaElement{ConvertedType: is.ConvertedType, LogicalType: is.LogicalType})
 }
 
 func (is *int64Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case int64:
-		is.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []int64:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -181,9 +206,24 @@ func (is *int64Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			is.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
+	case uint64:
+		if !is.unsigned() {
+			return nil, errors.Errorf("uint64 values are only supported for unsigned int64 columns")
+		}
+		vals = []interface{}{int64(typed)}
+	case []uint64:
+		if !is.unsigned() {
+			return nil, errors.Errorf("[]uint64 values are only supported for unsigned int64 columns")
+		}
+		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
+			return nil, errors.Errorf("the value is not repeated but it is an array")
+		}
+		vals = make([]interface{}, len(typed))
+		for j := range typed {
+			vals[j] = int64(typed[j])
+		}
 	default:
 		return nil, errors.Errorf("unsupported type for storing in int64 column: %T => %+v", v, v)
 	}
@@ -10,6 +10,8 @@ import (
 
 type int96PlainDecoder struct {
 	r io.Reader
+
+	buf [12]byte
 }
 
 func (i *int96PlainDecoder) init(r io.Reader) error {
@@ -19,30 +21,22 @@ func (i *int96PlainDecoder) init(r io.Reader) error {
 }
 
 func (i *int96PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	idx := 0
-	for range dst {
-		var data [12]byte
-		// this one is a little tricky do not use ReadFull here
-		n, err := i.r.Read(data[:])
-		// make sure we handle the read data first then handle the error
-		if n == 12 {
-			dst[idx] = data
-			idx++
-		}
-
-		if err != nil && (n == 0 || n == 12) {
+	for idx := range dst {
+		// io.EOF only if there is no byte left, io.ErrUnexpectedEOF on a partial value
+		if _, err := io.ReadFull(i.r, i.buf[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return idx, errors.Wrap(err, "not enough byte to read the Int96")
+			}
 			return idx, err
 		}
-
-		if err != nil {
-			return idx, errors.Wrap(err, "not enough byte to read the Int96")
-		}
+		dst[idx] = i.buf
 	}
 	return len(dst), nil
 }
 
 type int96PlainEncoder struct {
-	w io.Writer
+	w    io.Writer
+	size int
 }
 
 func (i *int96PlainEncoder) Close() error {
@@ -51,10 +45,15 @@ func (i *int96PlainEncoder) Close() error {
 
 func (i *int96PlainEncoder) init(w io.Writer) error {
 	i.w = w
+	i.size = 0
 
 	return nil
 }
 
+func (i *int96PlainEncoder) estimatedSize() int {
+	return i.size
+}
+
 func (i *int96PlainEncoder) encodeValues(values []interface{}) error {
 	data := make([]byte, len(values)*12)
 	for j := range values {
@@ -62,6 +61,7 @@ func (i *int96PlainEncoder) encodeValues(values []interface{}) error {
 		copy(data[j*12:], i96[:])
 	}
 
+	i.size += len(data)
 	return writeFull(i.w, data)
 }
 
@@ -85,9 +85,6 @@ func (is *int96Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case [12]byte:
-		if err := is.setMinMax(typed[:]); err != nil {
-			return nil, err
-		}
 		vals = []interface{}{typed}
 	case [][12]byte:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -95,9 +92,6 @@ func (is *int96Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			if err := is.setMinMax(typed[j][:]); err != nil {
-				return nil, err
-			}
 			vals[j] = typed[j]
 		}
 	default:
@@ -3,11 +3,14 @@ package goparquet
 import (
 	"bytes"
 	"io"
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
+	"testing/iotest"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +30,8 @@ type encodingFixtures struct {
 	enc  valuesEncoder
 	dec  valuesDecoder
 	rand func() interface{}
+	// the hybrid RLE encoding pads the last bit-packed group with zero values, so the stream may contain more values
+	padded bool
 }
 
 var (
@@ -49,7 +54,7 @@ var (
 		},
 		{
 			name: "Uint32Plain",
-			enc:  &int32PlainEncoder{unSigned: true},
+			enc:  &int32PlainEncoder{},
 			dec:  &int32PlainDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint32(rand.Int())
@@ -57,7 +62,7 @@ var (
 		},
 		{
 			name: "Uint32Delta",
-			enc:  &int32DeltaBPEncoder{unSigned: true, deltaBitPackEncoder32: deltaBitPackEncoder32{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int32DeltaBPEncoder{deltaBitPackEncoder32: deltaBitPackEncoder32{blockSize: 128, miniBlockCount: 4}},
 			dec:  &int32DeltaBPDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint32(rand.Int())
@@ -81,7 +86,7 @@ var (
 		},
 		{
 			name: "Uint64Plain",
-			enc:  &int64PlainEncoder{unSigned: true},
+			enc:  &int64PlainEncoder{},
 			dec:  &int64PlainDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint64(rand.Int63())
@@ -89,7 +94,7 @@ var (
 		},
 		{
 			name: "Uint64Delta",
-			enc:  &int64DeltaBPEncoder{unSigned: true, deltaBitPackEncoder64: deltaBitPackEncoder64{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int64DeltaBPEncoder{deltaBitPackEncoder64: deltaBitPackEncoder64{blockSize: 128, miniBlockCount: 4}},
 			dec:  &int64DeltaBPDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint64(rand.Int63())
@@ -124,6 +129,22 @@ var (
 				return rand.Float32()
 			},
 		},
+		{
+			name: "DoubleByteStreamSplit",
+			enc:  &doubleByteStreamSplitEncoder{},
+			dec:  &doubleByteStreamSplitDecoder{},
+			rand: func() interface{} {
+				return rand.NormFloat64()
+			},
+		},
+		{
+			name: "FloatByteStreamSplit",
+			enc:  &floatByteStreamSplitEncoder{},
+			dec:  &floatByteStreamSplitDecoder{},
+			rand: func() interface{} {
+				return float32(rand.NormFloat64())
+			},
+		},
 		{
 			name: "BooleanRLE",
 			enc:  &booleanRLEEncoder{},
@@ -131,6 +152,7 @@ var (
 			rand: func() interface{} {
 				return rand.Int()%2 == 0
 			},
+			padded: true,
 		},
 		{
 			name: "BooleanPlain",
@@ -139,6 +161,7 @@ var (
 			rand: func() interface{} {
 				return rand.Int()%2 == 0
 			},
+			padded: true,
 		},
 		{
 			name: "DictionaryInt32",
@@ -147,6 +170,7 @@ var (
 			rand: func() interface{} {
 				return rand.Int31n(100)
 			},
+			padded: true,
 		},
 		{
 			name: "DictionaryInt96",
@@ -160,6 +184,7 @@ var (
 
 				return data
 			},
+			padded: true,
 		},
 		{
 			name: "ByteArrayFixedLen",
@@ -247,11 +272,271 @@ func TestTypes(t *testing.T) {
 			require.Equal(t, ret[bufLen:], arr2[:bufRead-bufLen])
 			n, err = data.dec.decodeValues(ret)
 			require.Equal(t, io.EOF, err)
+			if data.padded {
+				require.True(t, n-(2*bufLen-bufRead) < 8, "more than one group of padding")
+				n = 2*bufLen - bufRead
+			}
 			require.Equal(t, ret[:n], arr2[bufRead-bufLen:])
 		})
 	}
 }
 
+func TestEncoderEstimatedSize(t *testing.T) {
+	for _, data := range encFixtures {
+		t.Run(data.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			require.NoError(t, data.enc.init(w))
+			empty := data.enc.estimatedSize()
+
+			var last int
+			for i := 0; i < 10; i++ {
+				require.NoError(t, data.enc.encodeValues(buildRandArray(100, data.rand)))
+				size := data.enc.estimatedSize()
+				require.True(t, size >= last, "the estimated size should not shrink, was %d now %d", last, size)
+				last = size
+			}
+			require.NoError(t, data.enc.Close())
+
+			actual := w.Len()
+			assert.True(t, last >= actual/2 && last <= 2*actual+empty, "estimated %d byte, but the actual size is %d byte", last, actual)
+		})
+	}
+}
+
+func TestDecodeValuesPartialReads(t *testing.T) {
+	for _, data := range encFixtures {
+		t.Run(data.name, func(t *testing.T) {
+			values := buildRandArray(10, data.rand)
+			w := &bytes.Buffer{}
+			require.NoError(t, data.enc.init(w))
+			require.NoError(t, data.enc.encodeValues(values))
+			require.NoError(t, data.enc.Close())
+			if d, ok := data.dec.(dictValuesDecoder); ok {
+				d.setValues(data.enc.(dictValuesEncoder).getValues())
+			}
+			require.NoError(t, data.dec.init(bytes.NewReader(w.Bytes())))
+
+			var (
+				read []interface{}
+				err  error
+			)
+			for err == nil {
+				batch := make([]interface{}, 3)
+				var n int
+				n, err = data.dec.decodeValues(batch)
+				read = append(read, batch[:n]...)
+				if err == nil {
+					require.Equal(t, 3, n)
+				}
+			}
+			require.Equal(t, io.EOF, err)
+			if data.padded {
+				require.True(t, len(read)-len(values) < 8, "more than one group of padding")
+				read = read[:len(values)]
+			}
+			require.Equal(t, values, read)
+
+			// the decoder stays at the end
+			n, err := data.dec.decodeValues(make([]interface{}, 3))
+			require.Equal(t, io.EOF, err)
+			require.Equal(t, 0, n)
+		})
+	}
+}
+
+func TestByteArrayDecodersTruncatedValue(t *testing.T) {
+	values := []interface{}{[]byte("abc"), []byte("defgh")}
+	for _, data := range []struct {
+		name    string
+		enc     valuesEncoder
+		dec     valuesDecoder
+		initErr bool
+	}{
+		{name: "plain", enc: &byteArrayPlainEncoder{}, dec: &byteArrayPlainDecoder{}},
+		// the lens are checked against the data on init
+		{name: "delta length", enc: &byteArrayDeltaLengthEncoder{}, dec: &byteArrayDeltaLengthDecoder{}, initErr: true},
+	} {
+		t.Run(data.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			require.NoError(t, data.enc.init(w))
+			require.NoError(t, data.enc.encodeValues(values))
+			require.NoError(t, data.enc.Close())
+
+			// the length of the last value is there, but not the value itself
+			err := data.dec.init(bytes.NewReader(w.Bytes()[:w.Len()-5]))
+			if data.initErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			read := make([]interface{}, 3)
+			n, err := data.dec.decodeValues(read)
+			require.Error(t, err)
+			require.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+			require.Equal(t, 1, n)
+			require.Equal(t, values[:1], read[:n])
+		})
+	}
+}
+
+func TestBooleanPlainPartialBatches(t *testing.T) {
+	values := buildRandArray(1001, func() interface{} {
+		return rand.Int()%2 == 0
+	})
+
+	buf := &bytes.Buffer{}
+	enc := &booleanPlainEncoder{}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encodeValues(values[:333]))
+	require.NoError(t, enc.encodeValues(values[333:]))
+	require.NoError(t, enc.Close())
+	require.Equal(t, 126, buf.Len())
+
+	dec := &booleanPlainDecoder{}
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes())))
+
+	var read []interface{}
+	for _, size := range []int{3, 5, 1, 13, 7, 972} {
+		batch := make([]interface{}, size)
+		n, err := dec.decodeValues(batch)
+		require.NoError(t, err)
+		require.Equal(t, size, n)
+		read = append(read, batch...)
+	}
+	require.Equal(t, values, read)
+
+	// the padding of the last byte is readable, but the stream ends after it
+	batch := make([]interface{}, 10)
+	n, err := dec.decodeValues(batch)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 7, n)
+}
+
+func TestBooleanPlainEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc := &booleanPlainEncoder{}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.Close())
+	require.Equal(t, 0, buf.Len())
+}
+
+func TestInt96PlainDecoderShortReads(t *testing.T) {
+	values := buildRandArray(10, func() interface{} {
+		var data [12]byte
+		for i := 0; i < 12; i++ {
+			data[i] = byte(rand.Intn(256))
+		}
+		return data
+	})
+
+	buf := &bytes.Buffer{}
+	enc := &int96PlainEncoder{}
+	require.NoError(t, enc.init(buf))
+	require.NoError(t, enc.encodeValues(values))
+	require.NoError(t, enc.Close())
+
+	// the reader returns one byte per read call
+	dec := &int96PlainDecoder{}
+	require.NoError(t, dec.init(iotest.OneByteReader(bytes.NewReader(buf.Bytes()))))
+	read := make([]interface{}, 11)
+	n, err := dec.decodeValues(read)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 10, n)
+	require.Equal(t, values, read[:n])
+
+	// the last value is truncated
+	require.NoError(t, dec.init(bytes.NewReader(buf.Bytes()[:buf.Len()-5])))
+	n, err = dec.decodeValues(read)
+	require.Error(t, err)
+	require.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+	require.Equal(t, 9, n)
+	require.Equal(t, values[:9], read[:n])
+}
+
+func TestFloatingPointPlainSpecialValues(t *testing.T) {
+	doubles := []interface{}{math.NaN(), math.Inf(1), math.Inf(-1), math.Copysign(0, -1), 0.0, math.MaxFloat64, math.SmallestNonzeroFloat64}
+	floats := []interface{}{float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.Copysign(0, -1)), float32(0), float32(math.MaxFloat32), float32(math.SmallestNonzeroFloat32)}
+
+	bitsOf := func(v interface{}) uint64 {
+		switch f := v.(type) {
+		case float32:
+			return uint64(math.Float32bits(f))
+		case float64:
+			return math.Float64bits(f)
+		}
+		panic("invalid type")
+	}
+
+	fixtures := []struct {
+		name   string
+		enc    valuesEncoder
+		dec    valuesDecoder
+		values []interface{}
+	}{
+		{"double", &doublePlainEncoder{}, &doublePlainDecoder{}, doubles},
+		{"float", &floatPlainEncoder{}, &floatPlainDecoder{}, floats},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, f.enc.init(buf))
+			require.NoError(t, f.enc.encodeValues(f.values))
+			require.NoError(t, f.enc.Close())
+
+			require.NoError(t, f.dec.init(bytes.NewReader(buf.Bytes())))
+			read := make([]interface{}, len(f.values)+1)
+			n, err := f.dec.decodeValues(read)
+			require.Equal(t, io.EOF, err)
+			require.Equal(t, len(f.values), n)
+			for i := range f.values {
+				require.Equal(t, bitsOf(f.values[i]), bitsOf(read[i]))
+			}
+
+			// a partial value at the end
+			require.NoError(t, f.dec.init(bytes.NewReader(buf.Bytes()[:buf.Len()-1])))
+			n, err = f.dec.decodeValues(read)
+			require.Equal(t, io.ErrUnexpectedEOF, err)
+			require.Equal(t, len(f.values)-1, n)
+		})
+	}
+}
+
+func benchmarkPlainDecoder(b *testing.B, enc valuesEncoder, dec valuesDecoder, size int, rand func() interface{}) {
+	const count = 1000000
+	values := buildRandArray(count, rand)
+	buf := &bytes.Buffer{}
+	require.NoError(b, enc.init(buf))
+	require.NoError(b, enc.encodeValues(values))
+	require.NoError(b, enc.Close())
+	data := buf.Bytes()
+
+	dst := make([]interface{}, count)
+	b.SetBytes(int64(count * size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := dec.init(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := dec.decodeValues(dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFloatPlainDecoder(b *testing.B) {
+	benchmarkPlainDecoder(b, &floatPlainEncoder{}, &floatPlainDecoder{}, 4, func() interface{} {
+		return rand.Float32()
+	})
+}
+
+func BenchmarkDoublePlainDecoder(b *testing.B) {
+	benchmarkPlainDecoder(b, &doublePlainEncoder{}, &doublePlainDecoder{}, 8, func() interface{} {
+		return rand.Float64()
+	})
+}
+
 func convertToInterface(arr interface{}) []interface{} {
 	v := reflect.ValueOf(arr)
 	ret := make([]interface{}, v.Len())
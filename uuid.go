@@ -0,0 +1,79 @@
+package goparquet
+
+import (
+	"encoding/hex"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// uuidConversion is how the values of UUID columns are returned in reading.
+type uuidConversion int
+
+const (
+	// uuidsAsBytes returns the values as []byte like all fixed length byte arrays.
+	uuidsAsBytes uuidConversion = iota
+	// uuidsAsArrays returns the values as [16]byte.
+	uuidsAsArrays
+	// uuidsAsStrings returns the values in the canonical format "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx".
+	uuidsAsStrings
+)
+
+// isUUID returns true if the column elem has the UUID logical type.
+func isUUID(elem *parquet.SchemaElement) bool {
+	if elem == nil {
+		return false
+	}
+	lt := elem.GetLogicalType()
+	return lt != nil && lt.IsSetUUID()
+}
+
+// formatUUID returns the UUID in the canonical format with lower case hex digits.
+func formatUUID(u [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf)
+}
+
+// parseUUID parses a UUID in the canonical format "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" with upper or lower case hex
+// digits.
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.Errorf("invalid UUID %q, it must have the format xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", s)
+	}
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return u, errors.Errorf("invalid UUID %q, it must have the format xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", s)
+	}
+	return u, nil
+}
+
+// uuidBytes returns the value v of a UUID column as a []byte. v can be a []byte, a [16]byte or a string in the
+// canonical format.
+func uuidBytes(v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case []byte:
+		if len(x) != 16 {
+			return nil, errors.Errorf("invalid UUID of %d bytes, it must have 16 bytes", len(x))
+		}
+		return x, nil
+	case [16]byte:
+		return x[:], nil
+	case string:
+		u, err := parseUUID(x)
+		if err != nil {
+			return nil, err
+		}
+		return u[:], nil
+	}
+	return nil, errors.Errorf("unsupported type for storing in UUID column %T => %+v", v, v)
+}
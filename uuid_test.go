@@ -0,0 +1,161 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUUID(t *testing.T) {
+	u, err := parseUUID("123E4567-e89b-12d3-a456-426614174000")
+	require.NoError(t, err)
+	require.Equal(t, [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}, u)
+	require.Equal(t, "123e4567-e89b-12d3-a456-426614174000", formatUUID(u))
+
+	for _, s := range []string{
+		"",
+		"123e4567e89b12d3a456426614174000",
+		"123e4567-e89b-12d3-a456-42661417400",
+		"123e4567-e89b-12d3-a456_426614174000",
+		"123e4567-e89b-12d3-a456-42661417400g",
+		"{123e4567-e89b-12d3-a456-426614174000}",
+	} {
+		_, err := parseUUID(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestReadWriteUUIDs(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`
+		message test {
+			required fixed_len_byte_array(16) id (UUID);
+			optional fixed_len_byte_array(16) opt (UUID);
+			repeated fixed_len_byte_array(16) rep (UUID);
+		}
+	`)
+	require.NoError(t, err)
+
+	ids := []string{
+		"123e4567-e89b-12d3-a456-426614174000",
+		"00000000-0000-0000-0000-000000000000",
+		"ffffffff-ffff-ffff-ffff-ffffffffffff",
+	}
+	arrays := make([][16]byte, len(ids))
+	for i := range ids {
+		arrays[i], err = parseUUID(ids[i])
+		require.NoError(t, err)
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	// the values can be given as [16]byte, string or []byte
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":  arrays[0],
+		"opt": ids[1],
+		"rep": []string{ids[2], ids[0]},
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":  arrays[1][:],
+		"rep": [][16]byte{arrays[1]},
+	}))
+
+	err = w.AddData(map[string]interface{}{"id": "123e4567-e89b-12d3-a456"})
+	require.EqualError(t, err, `invalid value of column "id": invalid UUID "123e4567-e89b-12d3-a456", it must have the format xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx`)
+	err = w.AddData(map[string]interface{}{"id": []byte{1, 2, 3}})
+	require.EqualError(t, err, `invalid value of column "id": invalid UUID of 3 bytes, it must have 16 bytes`)
+	err = w.AddData(map[string]interface{}{"id": int64(1)})
+	require.EqualError(t, err, `invalid value of column "id": unsupported type for storing in UUID column int64 => 1`)
+	err = w.AddData(map[string]interface{}{"id": []string{ids[0]}})
+	require.EqualError(t, err, `invalid value of column "id": the value is not repeated but it is an array`)
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithUUIDsAsArrays())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumRows())
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":  arrays[0],
+		"opt": arrays[1],
+		"rep": [][16]byte{arrays[2], arrays[0]},
+	}, row)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":  arrays[1],
+		"rep": [][16]byte{arrays[1]},
+	}, row)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithUUIDsAsStrings())
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":  ids[0],
+		"opt": ids[1],
+		"rep": []string{ids[2], ids[0]},
+	}, row)
+
+	// without an option the values are returned as they are stored
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()), "id")
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": arrays[0][:]}, row)
+}
+
+func TestUUIDGoldenFile(t *testing.T) {
+	// The file was assembled byte by byte from the format spec, without a parquet library. The u column is a
+	// FIXED_LEN_BYTE_ARRAY(16) with the UUID logical type, in the layout of arrow with data page v1: a PLAIN
+	// dictionary page and an RLE_DICTIONARY data page. The raw column has no logical type and is not converted.
+	data, err := ioutil.ReadFile("testdata/uuid.parquet")
+	require.NoError(t, err)
+
+	ids := []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"",
+		"00000000-0000-0000-0000-000000000000",
+		"f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"ffffffff-ffff-ffff-ffff-ffffffffffff",
+	}
+	raw := func(i int) []byte {
+		b := make([]byte, 16)
+		for j := range b {
+			b[j] = byte(i + j)
+		}
+		return b
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithUUIDsAsStrings())
+	require.NoError(t, err)
+	require.True(t, r.GetColumnByName("u").Element().GetLogicalType().IsSetUUID())
+	for i, id := range ids {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		expected := map[string]interface{}{"id": int32(i), "raw": raw(i)}
+		if id != "" {
+			expected["u"] = id
+		}
+		require.Equal(t, expected, row, "row %d", i)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithUUIDsAsArrays())
+	require.NoError(t, err)
+	for i, id := range ids {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		if id != "" {
+			u, err := parseUUID(id)
+			require.NoError(t, err)
+			require.Equal(t, u, row["u"], "row %d", i)
+		}
+		require.Equal(t, raw(i), row["raw"], "row %d", i)
+	}
+}
@@ -0,0 +1,100 @@
+package goparquet
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// ZstdDefaultLevel is the zstd level of the default ZSTD compressor, it is the default level of the zstd library.
+const ZstdDefaultLevel = 3
+
+var (
+	// zstdDecoder is shared by all ZSTD compressors, the decoder doesn't depend on the level and DecodeAll can be
+	// called concurrently.
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+	zstdDecoderOnce sync.Once
+)
+
+// zstdCompressor is the ZSTD codec. The encoder is created on first use, it can be used concurrently by up to
+// GOMAXPROCS goroutines at the same time, e.g. by the goroutines of WithCompressionConcurrency.
+type zstdCompressor struct {
+	level int
+
+	once    sync.Once
+	encoder *zstd.Encoder
+	err     error
+}
+
+// NewZstdCompressor returns a ZSTD block compressor that compresses with the given zstd level, between 1 and 22.
+// The levels are mapped to the speed levels of github.com/klauspost/compress/zstd. Register it with
+// RegisterBlockCompressor to use a different level than ZstdDefaultLevel.
+func NewZstdCompressor(level int) (BlockCompressor, error) {
+	if level < 1 || level > 22 {
+		return nil, errors.Errorf("zstd: invalid level %d, it must be between 1 and 22", level)
+	}
+
+	return &zstdCompressor{level: level}, nil
+}
+
+func (z *zstdCompressor) WithOptions(opts CompressorOptions) (BlockCompressor, error) {
+	if opts.Level == 0 {
+		return NewZstdCompressor(ZstdDefaultLevel)
+	}
+	return NewZstdCompressor(opts.Level)
+}
+
+func (z *zstdCompressor) CompressBlock(block []byte) ([]byte, error) {
+	z.once.Do(func() {
+		z.encoder, z.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(z.level)), zstd.WithEncoderConcurrency(runtime.GOMAXPROCS(0)))
+	})
+	if z.err != nil {
+		return nil, z.err
+	}
+
+	return z.encoder.EncodeAll(block, nil), nil
+}
+
+func (z *zstdCompressor) DecompressBlock(block []byte) ([]byte, error) {
+	return z.DecompressBlockSize(block, -1)
+}
+
+func (z *zstdCompressor) DecompressBlockSize(block []byte, size int) ([]byte, error) {
+	if size < 0 {
+		dec, err := getZstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(block, nil)
+	}
+
+	return z.decompressBlockTo(make([]byte, size), block)
+}
+
+func (z *zstdCompressor) decompressBlockTo(dst, block []byte) ([]byte, error) {
+	dec, err := getZstdDecoder()
+	if err != nil {
+		return nil, err
+	}
+
+	// check the content size of the frame before it is decoded.
+	var h zstd.Header
+	if err := h.Decode(block); err != nil {
+		return nil, err
+	}
+	if h.FrameContentSize > uint64(len(dst)) {
+		return nil, errors.Errorf("zstd: the frame has %d byte, but the page has %d byte", h.FrameContentSize, len(dst))
+	}
+
+	return dec.DecodeAll(block, dst[:0])
+}
+
+func getZstdDecoder() (*zstd.Decoder, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	})
+	return zstdDecoder, zstdDecoderErr
+}